@@ -7,8 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -62,6 +66,17 @@ type Config struct {
 	// internally conflicting River-generated keys more likely.
 	AdvisoryLockPrefix int32
 
+	// BatchCompleter enables a job completer that finalizes worked jobs in
+	// batches. Completions are accumulated for a short window (or until a
+	// maximum batch size is reached) and then set with a single query, which
+	// substantially reduces database round trips on high throughput
+	// installations at the cost of a small delay before a job's final state is
+	// visible.
+	//
+	// Defaults to false, in which case jobs are completed individually, with up
+	// to 100 completions running concurrently.
+	BatchCompleter bool
+
 	// CancelledJobRetentionPeriod is the amount of time to keep cancelled jobs
 	// around before they're removed permanently.
 	//
@@ -74,6 +89,14 @@ type Config struct {
 	// Defaults to 24 hours.
 	CompletedJobRetentionPeriod time.Duration
 
+	// DependencyFailureAction determines what happens to a pending job (one
+	// inserted with InsertOpts.DependsOn) when one of the jobs it depends on is
+	// cancelled or discarded instead of completing. The pending job is
+	// finalized right away without waiting on any of its other dependencies.
+	//
+	// Defaults to DependencyFailureActionCancel.
+	DependencyFailureAction DependencyFailureAction
+
 	// DiscardedJobRetentionPeriod is the amount of time to keep discarded jobs
 	// around before they're removed permanently.
 	//
@@ -118,6 +141,14 @@ type Config struct {
 	// given process.)
 	ID string
 
+	// InsertHooks is a list of hooks invoked for every job inserted by the
+	// client, including through Insert, InsertTx, InsertMany, InsertManyTx,
+	// and periodic jobs. Hooks are run in the order given after insert
+	// parameters have been computed from InsertOpts and defaults, and may
+	// modify those parameters or return an error to reject the insert. See
+	// InsertHook for details.
+	InsertHooks []InsertHook
+
 	// JobTimeout is the maximum amount of time a job is allowed to run before its
 	// context is cancelled. A timeout of zero means JobTimeoutDefault will be
 	// used, whereas a value of -1 means the job's context will not be cancelled
@@ -135,6 +166,18 @@ type Config struct {
 	// in the client.
 	PeriodicJobs []*PeriodicJob
 
+	// PollOnly starts the client in poll-only mode, where it doesn't use
+	// Postgres LISTEN/NOTIFY. This is useful in environments where LISTEN isn't
+	// supported, like behind PgBouncer in transaction pooling mode.
+	//
+	// In poll-only mode, no notifier is started. Producers rely solely on
+	// FetchPollInterval to find new jobs rather than being woken by insert
+	// notifications, so new jobs may take up to FetchPollInterval to start
+	// being worked. Cancellation of running jobs is detected by polling for
+	// cancelled jobs at the same interval, and leader election relies purely
+	// on the expiration of leadership TTLs.
+	PollOnly bool
+
 	// Queues is a list of queue names for this client to operate on along with
 	// configuration for the queue like the maximum number of workers to run for
 	// each queue.
@@ -160,6 +203,12 @@ type Config struct {
 	// will result in jobs being stuck for longer than necessary before they are
 	// retried.
 	//
+	// Jobs that record heartbeats with JobHeartbeat are instead considered
+	// stuck once this amount of time has passed since their last heartbeat, so
+	// long running jobs that record heartbeats regularly can keep running
+	// beyond this duration while jobs whose workers have died are still
+	// rescued promptly.
+	//
 	// RescueStuckJobsAfter must be greater than JobTimeout. Otherwise, jobs
 	// would become eligible for rescue while they're still running.
 	//
@@ -172,6 +221,29 @@ type Config struct {
 	// Defaults to DefaultRetryPolicy.
 	RetryPolicy ClientRetryPolicy
 
+	// Schema is the name of the Postgres schema containing River's tables, as
+	// raised by rivermigrate with the same schema. When set, every query made
+	// by the client targets tables in this schema and notification topics are
+	// scoped to it, so multiple independent River installations can coexist
+	// in a single database without configuring the connection's search_path.
+	//
+	// Defaults to empty, in which case tables are found through the
+	// connection's search_path.
+	Schema string
+
+	// WorkerMiddleware is a list of middleware that's invoked around the Work
+	// function of every job worked by the client. Middleware is run in the
+	// order given, so the first middleware in the list is the outermost and
+	// the first to be invoked, and the last is invoked immediately before any
+	// Worker-specific middleware (see WorkerWithMiddleware) and the job's Work
+	// function.
+	//
+	// Middleware may modify the context passed down the chain, and may return
+	// an error without invoking the next function in the chain to prevent a
+	// job from being worked, in which case the error is handled exactly like
+	// one returned from Work.
+	WorkerMiddleware []rivertype.WorkerMiddleware
+
 	// Workers is a bundle of registered job workers.
 	//
 	// This field may be omitted for a program that's only enqueueing jobs
@@ -184,6 +256,10 @@ type Config struct {
 	// functions in cases where the CancellableSleep helper is used to sleep.
 	disableSleep bool
 
+	// Maximum number of jobs affected by each batch of a bulk operation like
+	// JobCancelMany. Not currently exposed for configuration.
+	jobManyBatchSize int
+
 	// Scheduler run interval. Shared between the scheduler and producer/job
 	// executors, but not currently exposed for configuration.
 	schedulerInterval time.Duration
@@ -196,6 +272,9 @@ func (c *Config) validate() error {
 	if c.CompletedJobRetentionPeriod < 0 {
 		return errors.New("CompletedJobRetentionPeriod cannot be less than zero")
 	}
+	if c.DependencyFailureAction != DependencyFailureActionCancel && c.DependencyFailureAction != DependencyFailureActionDiscard {
+		return fmt.Errorf("DependencyFailureAction must be one of %q or %q", DependencyFailureActionCancel, DependencyFailureActionDiscard)
+	}
 	if c.DiscardedJobRetentionPeriod < 0 {
 		return errors.New("DiscardedJobRetentionPeriod cannot be less than zero")
 	}
@@ -220,12 +299,15 @@ func (c *Config) validate() error {
 	if c.RescueStuckJobsAfter < c.JobTimeout {
 		return errors.New("RescueStuckJobsAfter cannot be less than JobTimeout")
 	}
+	// Notification topics are prefixed with the schema, and Postgres channel
+	// names are limited to 63 bytes, the longest topic being the 17 byte
+	// `river_job_control` plus a separating dot.
+	if len(c.Schema) > 45 {
+		return errors.New("Schema cannot be longer than 45 characters")
+	}
 
 	for queue, queueConfig := range c.Queues {
-		if queueConfig.MaxWorkers < 1 || queueConfig.MaxWorkers > QueueNumWorkersMax {
-			return fmt.Errorf("invalid number of workers for queue %q: %d", queue, queueConfig.MaxWorkers)
-		}
-		if err := validateQueueName(queue); err != nil {
+		if err := queueConfig.validate(queue); err != nil {
 			return err
 		}
 	}
@@ -245,7 +327,55 @@ func (c *Config) willExecuteJobs() bool {
 }
 
 // QueueConfig contains queue-specific configuration.
+//
+// A queue configured with ConcurrencyLimits, FairnessMetadataKey,
+// PriorityAgingInterval, or RateLimits for particular kinds fetches jobs with a
+// query that ranks every available job in the queue, which is more expensive
+// than a plain fetch for queues with very large backlogs.
 type QueueConfig struct {
+	// ConcurrencyLimits limit the number of jobs of particular kinds in the
+	// queue that may be running at once across all clients, optionally
+	// partitioned by values in each job's args or metadata. Jobs over a limit
+	// stay available in the queue while other jobs continue to be worked. See
+	// ConcurrencyLimit.
+	//
+	// Like GlobalMaxWorkers, limits are enforced while fetching new jobs, with
+	// fetches from different clients serialized by a Postgres advisory lock.
+	// All clients working the queue should be configured with the same
+	// limits.
+	ConcurrencyLimits []ConcurrencyLimit
+
+	// FairnessMetadataKey enables fair scheduling between jobs in the queue
+	// based on the value of this key in their metadata, like a tenant or
+	// customer ID. Jobs of the same priority are fetched round-robin across
+	// each distinct value, with tenants that have fewer jobs already running
+	// going first, so a large burst of jobs inserted for one tenant doesn't
+	// hold up jobs for others that were inserted after it. Jobs without the
+	// key in their metadata are treated as a tenant of their own.
+	//
+	// Defaults to empty, in which case jobs are fetched in order of priority
+	// and then scheduled time.
+	FairnessMetadataKey string
+
+	// GlobalMaxWorkers is the maximum number of jobs in the queue that may be
+	// running at once across all clients, regardless of how many are working
+	// the queue. It's useful to cap concurrency against a resource like a
+	// third-party API, which would otherwise scale with the number of
+	// processes running River.
+	//
+	// The limit is enforced by counting the queue's running jobs while fetching
+	// new ones, with fetches from different clients serialized by a Postgres
+	// advisory lock. Jobs count against the limit as long as they're running,
+	// including those left running by a client that crashed until they're
+	// rescued. A client that's at the limit picks up capacity freed by jobs
+	// finishing on other clients on its next fetch, which may not happen until
+	// FetchPollInterval has elapsed. All clients working the queue should be
+	// configured with the same value.
+	//
+	// Defaults to 0, which means no global limit. Each client still runs no
+	// more than MaxWorkers jobs from the queue.
+	GlobalMaxWorkers int
+
 	// MaxWorkers is the maximum number of workers to run for the queue, or put
 	// otherwise, the maximum parallelism to run.
 	//
@@ -257,6 +387,44 @@ type QueueConfig struct {
 	//
 	// Requires a minimum of 1, and a maximum of 10,000.
 	MaxWorkers int
+
+	// PriorityAgingInterval enables priority aging, in which the effective
+	// priority of a job waiting in the queue improves by one for every
+	// interval that's elapsed since it was scheduled, until it reaches the
+	// highest priority of 1. For example, with an interval of 10 minutes, a
+	// priority 4 job that's been waiting for 20 minutes is fetched as if it
+	// were priority 2. This keeps a steady stream of high priority jobs from
+	// starving jobs of a lower priority indefinitely.
+	//
+	// Defaults to 0, which disables priority aging.
+	PriorityAgingInterval time.Duration
+
+	// RateLimits limit the rate at which jobs in the queue, or jobs of
+	// particular kinds, are started. Each limit may be enforced by each client
+	// on its own, or across all clients. See RateLimit.
+	RateLimits []RateLimit
+}
+
+func (c QueueConfig) validate(queueName string) error {
+	if err := validateConcurrencyLimits(queueName, c.ConcurrencyLimits); err != nil {
+		return err
+	}
+	if err := validateRateLimits(queueName, c.RateLimits); err != nil {
+		return err
+	}
+	if c.GlobalMaxWorkers < 0 {
+		return fmt.Errorf("invalid number of global workers for queue %q: %d", queueName, c.GlobalMaxWorkers)
+	}
+	if c.MaxWorkers < 1 || c.MaxWorkers > QueueNumWorkersMax {
+		return fmt.Errorf("invalid number of workers for queue %q: %d", queueName, c.MaxWorkers)
+	}
+	if c.PriorityAgingInterval < 0 {
+		return fmt.Errorf("invalid priority aging interval for queue %q: %s", queueName, c.PriorityAgingInterval)
+	}
+	if err := validateQueueName(queueName); err != nil {
+		return err
+	}
+	return nil
 }
 
 // Client is a single isolated instance of River. Your application may use
@@ -292,6 +460,23 @@ type Client[TTx any] struct {
 	uniqueInserter       *dbunique.UniqueInserter
 	wg                   sync.WaitGroup
 
+	// Contexts that producers are run with, kept so that producers for queues
+	// added with QueueAdd after the client has started can be run with them
+	// too. Set by Start.
+	producerFetchCtx context.Context //nolint:containedctx
+	producerWorkCtx  context.Context //nolint:containedctx
+
+	// Producers that are running, keyed by queue name. A producer stays in
+	// here until it has stopped, whether because its queue was removed or
+	// because the client stopped, so a removed queue can't be added again
+	// before then.
+	producerRunsByQueueName map[string]*producerRun
+
+	// Protects producersByQueueName, producerRunsByQueueName, and the producer
+	// contexts, which may be modified while the client is running by QueueAdd
+	// and QueueRemove.
+	producersMu sync.Mutex
+
 	// workCancel cancels the context used for all work goroutines. Normal Stop
 	// does not cancel that context.
 	workCancel context.CancelCauseFunc
@@ -302,8 +487,10 @@ type clientTestSignals struct {
 	electedLeader rivercommon.TestSignal[struct{}] // notifies when elected leader
 
 	jobCleaner          *maintenance.JobCleanerTestSignals
+	jobExpirer          *maintenance.JobExpirerTestSignals
 	jobRescuer          *maintenance.JobRescuerTestSignals
 	jobScheduler        *maintenance.JobSchedulerTestSignals
+	pendingJobPromoter  *maintenance.PendingJobPromoterTestSignals
 	periodicJobEnqueuer *maintenance.PeriodicJobEnqueuerTestSignals
 	reindexer           *maintenance.ReindexerTestSignals
 }
@@ -314,12 +501,18 @@ func (ts *clientTestSignals) Init() {
 	if ts.jobCleaner != nil {
 		ts.jobCleaner.Init()
 	}
+	if ts.jobExpirer != nil {
+		ts.jobExpirer.Init()
+	}
 	if ts.jobRescuer != nil {
 		ts.jobRescuer.Init()
 	}
 	if ts.jobScheduler != nil {
 		ts.jobScheduler.Init()
 	}
+	if ts.pendingJobPromoter != nil {
+		ts.pendingJobPromoter.Init()
+	}
 	if ts.periodicJobEnqueuer != nil {
 		ts.periodicJobEnqueuer.Init()
 	}
@@ -334,6 +527,11 @@ var (
 	// return this error.
 	ErrNotFound = rivertype.ErrNotFound
 
+	// ErrJobRunning is returned when a job is running and can't be acted upon.
+	// For example, attempting to delete a running job with JobDelete will
+	// return this error.
+	ErrJobRunning = rivertype.ErrJobRunning
+
 	errMissingConfig                 = errors.New("missing config")
 	errMissingDatabasePoolWithQueues = errors.New("must have a non-nil database pool to execute jobs (either use a driver with database pool or don't configure Queues)")
 	errMissingDriver                 = errors.New("missing database driver (try wrapping a Pgx pool with river/riverdriver/riverpgxv5.New)")
@@ -399,22 +597,29 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 	// here, even if it's only carrying over the original value.
 	config = &Config{
 		AdvisoryLockPrefix:          config.AdvisoryLockPrefix,
+		BatchCompleter:              config.BatchCompleter,
 		CancelledJobRetentionPeriod: valutil.ValOrDefault(config.CancelledJobRetentionPeriod, maintenance.CancelledJobRetentionPeriodDefault),
 		CompletedJobRetentionPeriod: valutil.ValOrDefault(config.CompletedJobRetentionPeriod, maintenance.CompletedJobRetentionPeriodDefault),
+		DependencyFailureAction:     valutil.ValOrDefault(config.DependencyFailureAction, DependencyFailureActionCancel),
 		DiscardedJobRetentionPeriod: valutil.ValOrDefault(config.DiscardedJobRetentionPeriod, maintenance.DiscardedJobRetentionPeriodDefault),
 		ErrorHandler:                config.ErrorHandler,
 		FetchCooldown:               valutil.ValOrDefault(config.FetchCooldown, FetchCooldownDefault),
 		FetchPollInterval:           valutil.ValOrDefault(config.FetchPollInterval, FetchPollIntervalDefault),
 		ID:                          config.ID,
+		InsertHooks:                 config.InsertHooks,
 		JobTimeout:                  valutil.ValOrDefault(config.JobTimeout, JobTimeoutDefault),
 		Logger:                      logger,
 		PeriodicJobs:                config.PeriodicJobs,
-		Queues:                      config.Queues,
+		PollOnly:                    config.PollOnly,
+		Queues:                      maps.Clone(config.Queues),
 		ReindexerSchedule:           config.ReindexerSchedule,
 		RescueStuckJobsAfter:        valutil.ValOrDefault(config.RescueStuckJobsAfter, rescueAfter),
 		RetryPolicy:                 retryPolicy,
+		Schema:                      config.Schema,
+		WorkerMiddleware:            config.WorkerMiddleware,
 		Workers:                     config.Workers,
 		disableSleep:                config.disableSleep,
+		jobManyBatchSize:            valutil.ValOrDefault(config.jobManyBatchSize, jobManyBatchSizeDefault),
 		schedulerInterval:           valutil.ValOrDefault(config.schedulerInterval, maintenance.JobSchedulerIntervalDefault),
 	}
 
@@ -431,6 +636,10 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		return nil, err
 	}
 
+	if config.Schema != "" {
+		driver = driver.WithSchema(config.Schema)
+	}
+
 	archetype := &baseservice.Archetype{
 		DisableSleep: config.disableSleep,
 		Logger:       config.Logger,
@@ -438,17 +647,23 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		TimeNowUTC:   func() time.Time { return time.Now().UTC() },
 	}
 
-	completer := jobcompleter.NewAsyncCompleter(archetype, driver.GetExecutor(), 100)
+	var completer jobcompleter.JobCompleter
+	if config.BatchCompleter {
+		completer = jobcompleter.NewBatchCompleter(archetype, driver.GetExecutor(), 0, 0)
+	} else {
+		completer = jobcompleter.NewAsyncCompleter(archetype, driver.GetExecutor(), 100)
+	}
 
 	client := &Client[TTx]{
-		completer:            completer,
-		config:               config,
-		driver:               driver,
-		monitor:              newClientMonitor(),
-		producersByQueueName: make(map[string]*producer),
-		stopComplete:         make(chan struct{}),
-		subscriptions:        make(map[int]*eventSubscription),
-		testSignals:          clientTestSignals{},
+		completer:               completer,
+		config:                  config,
+		driver:                  driver,
+		monitor:                 newClientMonitor(),
+		producersByQueueName:    make(map[string]*producer),
+		producerRunsByQueueName: make(map[string]*producerRun),
+		stopComplete:            make(chan struct{}),
+		subscriptions:           make(map[int]*eventSubscription),
+		testSignals:             clientTestSignals{},
 		uniqueInserter: baseservice.Init(archetype, &dbunique.UniqueInserter{
 			AdvisoryLockPrefix: config.AdvisoryLockPrefix,
 		}),
@@ -470,7 +685,11 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		// we'll need to add a config for this.
 		instanceName := "default"
 
-		client.notifier = notifier.New(archetype, driver.GetListener(), client.monitor.SetNotifierStatus, logger)
+		// In poll-only mode no notifier is started, and components that would
+		// otherwise use one fall back to polling.
+		if !config.PollOnly {
+			client.notifier = notifier.New(archetype, driver.GetListener(), client.monitor.SetNotifierStatus, logger)
+		}
 
 		var err error
 		client.elector, err = leadership.NewElector(driver.GetExecutor(), client.notifier, instanceName, client.ID(), 5*time.Second, 10*time.Second, logger)
@@ -498,6 +717,12 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 			client.testSignals.jobCleaner = &jobCleaner.TestSignals
 		}
 
+		{
+			jobExpirer := maintenance.NewJobExpirer(archetype, &maintenance.JobExpirerConfig{}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, jobExpirer)
+			client.testSignals.jobExpirer = &jobExpirer.TestSignals
+		}
+
 		{
 			jobRescuer := maintenance.NewRescuer(archetype, &maintenance.JobRescuerConfig{
 				ClientRetryPolicy: retryPolicy,
@@ -521,6 +746,14 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 			client.testSignals.jobScheduler = &jobScheduler.TestSignals
 		}
 
+		{
+			pendingJobPromoter := maintenance.NewPendingJobPromoter(archetype, &maintenance.PendingJobPromoterConfig{
+				DependencyFailedState: config.DependencyFailureAction.jobState(),
+			}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, pendingJobPromoter)
+			client.testSignals.pendingJobPromoter = &pendingJobPromoter.TestSignals
+		}
+
 		{
 			emptyOpts := PeriodicJobOpts{}
 			periodicJobs := make([]*maintenance.PeriodicJob, 0, len(config.PeriodicJobs))
@@ -533,8 +766,9 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 				}
 
 				periodicJobs = append(periodicJobs, &maintenance.PeriodicJob{
-					ConstructorFunc: func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
-						return insertParamsFromArgsAndOptions(periodicJob.constructorFunc())
+					ConstructorFunc: func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
+						args, insertOpts := periodicJob.constructorFunc()
+						return insertParamsFromArgsAndOptions(ctx, config, args, insertOpts)
 					},
 					RunOnStart:   opts.RunOnStart,
 					ScheduleFunc: periodicJob.scheduleFunc.Next,
@@ -640,11 +874,15 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 			}
 		}()
 
-		c.wg.Add(2)
-		go func() {
-			c.notifier.Run(fetchNewWorkCtx)
-			c.wg.Done()
-		}()
+		if c.notifier != nil {
+			c.wg.Add(1)
+			go func() {
+				c.notifier.Run(fetchNewWorkCtx)
+				c.wg.Done()
+			}()
+		}
+
+		c.wg.Add(1)
 		go func() {
 			c.elector.Run(fetchNewWorkCtx)
 			c.wg.Done()
@@ -664,6 +902,17 @@ func (c *Client[TTx]) signalStopComplete(ctx context.Context) {
 	// Wait for producers, notifier, and elector to exit:
 	c.wg.Wait()
 
+	// With producers exited, their contexts are no longer usable. Forget them
+	// so that queues added with QueueAdd from here on are kept for the next
+	// start, just like those added before the client was first started.
+	func() {
+		c.producersMu.Lock()
+		defer c.producersMu.Unlock()
+
+		c.producerFetchCtx = nil
+		c.producerWorkCtx = nil
+	}()
+
 	// Once the producers have all finished, we know that completers have at least
 	// enqueued any remaining work. Wait for the completer to finish.
 	//
@@ -822,7 +1071,7 @@ func (c *Client[TTx]) distributeJob(job *rivertype.JobRow, stats *JobStatistics)
 		event = &Event{Kind: EventKindJobCompleted, Job: job, JobStats: stats}
 	case JobStateScheduled:
 		event = &Event{Kind: EventKindJobSnoozed, Job: job, JobStats: stats}
-	case JobStateAvailable, JobStateDiscarded, JobStateRetryable, JobStateRunning:
+	case JobStateAvailable, JobStateDiscarded, JobStatePending, JobStateRetryable, JobStateRunning:
 		event = &Event{Kind: EventKindJobFailed, Job: job, JobStats: stats}
 	default:
 		// linter exhaustive rule prevents this from being reached
@@ -925,20 +1174,7 @@ func (c *Client[TTx]) handleLeadershipChange(ctx context.Context, notification *
 
 func (c *Client[TTx]) provisionProducers() error {
 	for queue, queueConfig := range c.config.Queues {
-		config := &producerConfig{
-			ClientID:          c.config.ID,
-			ErrorHandler:      c.config.ErrorHandler,
-			FetchCooldown:     c.config.FetchCooldown,
-			FetchPollInterval: c.config.FetchPollInterval,
-			JobTimeout:        c.config.JobTimeout,
-			MaxWorkerCount:    uint16(queueConfig.MaxWorkers),
-			Notifier:          c.notifier,
-			Queue:             queue,
-			RetryPolicy:       c.config.RetryPolicy,
-			SchedulerInterval: c.config.schedulerInterval,
-			Workers:           c.config.Workers,
-		}
-		producer, err := newProducer(&c.baseService.Archetype, c.driver.GetExecutor(), c.completer, config)
+		producer, err := c.newProducer(queue, queueConfig)
 		if err != nil {
 			return err
 		}
@@ -948,16 +1184,93 @@ func (c *Client[TTx]) provisionProducers() error {
 	return nil
 }
 
+func (c *Client[TTx]) newProducer(queue string, queueConfig QueueConfig) (*producer, error) {
+	// Without a notifier, queue pauses and resumes are only found by polling,
+	// so do so as often as jobs are fetched.
+	queuePollInterval := queuePollIntervalDefault
+	if c.config.PollOnly {
+		queuePollInterval = c.config.FetchPollInterval
+	}
+
+	return newProducer(&c.baseService.Archetype, c.driver.GetExecutor(), c.completer, &producerConfig{
+		AdvisoryLockPrefix:    c.config.AdvisoryLockPrefix,
+		ClientID:              c.config.ID,
+		ConcurrencyLimits:     concurrencyLimitsToDriver(queueConfig.ConcurrencyLimits),
+		ErrorHandler:          c.config.ErrorHandler,
+		FairnessMetadataKey:   queueConfig.FairnessMetadataKey,
+		FetchCooldown:         c.config.FetchCooldown,
+		FetchPollInterval:     c.config.FetchPollInterval,
+		GlobalMaxWorkerCount:  queueConfig.GlobalMaxWorkers,
+		JobTimeout:            c.config.JobTimeout,
+		MaxWorkerCount:        uint16(queueConfig.MaxWorkers),
+		Notifier:              c.notifier,
+		PollOnly:              c.config.PollOnly,
+		PriorityAgingInterval: queueConfig.PriorityAgingInterval,
+		Queue:                 queue,
+		QueuePollInterval:     queuePollInterval,
+		RateLimits:            sliceutil.Map(queueConfig.RateLimits, func(l RateLimit) *RateLimit { return &l }),
+		RetryPolicy:           c.config.RetryPolicy,
+		SchedulerInterval:     c.config.schedulerInterval,
+		WorkerMiddleware:      c.config.WorkerMiddleware,
+		Workers:               c.config.Workers,
+	})
+}
+
+// producerRun tracks a running producer so that it can be stopped on its own
+// when its queue is removed with QueueRemove.
+type producerRun struct {
+	fetchCancel context.CancelCauseFunc
+	removed     bool // protected by Client.producersMu
+	stopped     chan struct{}
+	workCancel  context.CancelCauseFunc
+}
+
 func (c *Client[TTx]) runProducers(fetchNewWorkCtx, workCtx context.Context) {
-	c.wg.Add(len(c.producersByQueueName))
-	for _, producer := range c.producersByQueueName {
-		producer := producer
+	c.producersMu.Lock()
+	defer c.producersMu.Unlock()
 
-		go func() {
-			defer c.wg.Done()
-			producer.Run(fetchNewWorkCtx, workCtx, c.monitor.SetProducerStatus)
-		}()
+	c.producerFetchCtx = fetchNewWorkCtx
+	c.producerWorkCtx = workCtx
+
+	for queue, producer := range c.producersByQueueName {
+		c.runProducer(queue, producer)
+	}
+}
+
+// runProducer runs a producer in a goroutine with contexts derived from the
+// client's so that it can be stopped individually. Must be called with
+// producersMu held.
+func (c *Client[TTx]) runProducer(queue string, producer *producer) {
+	fetchCtx, fetchCancel := context.WithCancelCause(c.producerFetchCtx)
+	workCtx, workCancel := context.WithCancelCause(c.producerWorkCtx)
+
+	run := &producerRun{
+		fetchCancel: fetchCancel,
+		stopped:     make(chan struct{}),
+		workCancel:  workCancel,
 	}
+	c.producerRunsByQueueName[queue] = run
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(run.stopped)
+
+		producer.Run(fetchCtx, workCtx, c.monitor.SetProducerStatus)
+		fetchCancel(nil)
+		workCancel(nil)
+
+		c.producersMu.Lock()
+		defer c.producersMu.Unlock()
+
+		delete(c.producerRunsByQueueName, queue)
+
+		// A removed producer's status is only dropped once it's stopped so
+		// that it doesn't reappear as the producer reports its shutdown.
+		if run.removed {
+			c.monitor.RemoveProducerStatus(queue)
+		}
+	}()
 }
 
 // JobCancel cancels the job with the given ID. If possible, the job is
@@ -1053,6 +1366,188 @@ func (c *Client[TTx]) jobCancel(ctx context.Context, exec riverdriver.Executor,
 	})
 }
 
+// JobCancelMany cancels all jobs matching the given filters, following the
+// same rules as JobCancel: jobs that are still in the queue are cancelled
+// immediately, while running jobs are marked for cancellation and the clients
+// running them are notified to cancel their context. Jobs that are already
+// finalized aren't changed. Returns the number of jobs that were cancelled or
+// marked for cancellation.
+//
+//	params := river.NewJobFilterParams().Kinds("my_kind").Queues("default")
+//	numCancelled, err := client.JobCancelMany(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Jobs are cancelled in batches of 1,000, each in its own transaction so that
+// cancelling a large number of jobs doesn't hold locks on all of them at once.
+// If an error occurs, the number of jobs cancelled by the batches that
+// succeeded before it is returned along with it. Jobs inserted while
+// cancellation is underway may or may not be cancelled.
+func (c *Client[TTx]) JobCancelMany(ctx context.Context, params *JobFilterParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
+	}
+
+	return c.jobCancelMany(ctx, c.driver.GetExecutor(), params)
+}
+
+// JobCancelManyTx cancels all jobs matching the given filters within the
+// specified transaction, following the same rules as JobCancel. This variant
+// lets a caller cancel jobs atomically alongside other database changes.
+// Cancellations don't take effect until the transaction commits, and if the
+// transaction rolls back, so too are the cancellations. Returns the number of
+// jobs that were cancelled or marked for cancellation.
+//
+// Jobs are cancelled in batches of 1,000, all of which are part of the given
+// transaction.
+func (c *Client[TTx]) JobCancelManyTx(ctx context.Context, tx TTx, params *JobFilterParams) (int, error) {
+	return c.jobCancelMany(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobCancelMany(ctx context.Context, exec riverdriver.Executor, params *JobFilterParams) (int, error) {
+	return c.jobMany(params, func(afterID int64, filter *riverdriver.JobFilter) (*riverdriver.JobManyResult, error) {
+		return exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+			AfterID:           afterID,
+			CancelAttemptedAt: c.baseService.TimeNowUTC(),
+			Filter:            filter,
+			JobControlTopic:   string(notifier.NotificationTopicJobControl),
+			Max:               c.config.jobManyBatchSize,
+		})
+	})
+}
+
+// JobDelete deletes the job with the given ID. Jobs in any state other than
+// running may be deleted, and once deleted, are removed from the database
+// permanently. Running jobs can't be deleted because the client working them
+// would still try to update them when they finish; cancel them with JobCancel
+// first instead.
+//
+// Returns the deleted JobRow. Returns ErrNotFound if the job doesn't exist, or
+// ErrJobRunning if it's running.
+func (c *Client[TTx]) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	return c.driver.GetExecutor().JobDelete(ctx, id)
+}
+
+// JobDeleteTx deletes the job with the given ID within the specified
+// transaction. This variant lets a caller delete a job atomically alongside
+// other database changes. A deleted job isn't removed until the transaction
+// commits, and if the transaction rolls back, so too is the deletion. Jobs in
+// any state other than running may be deleted.
+//
+// Returns the deleted JobRow. Returns ErrNotFound if the job doesn't exist, or
+// ErrJobRunning if it's running.
+func (c *Client[TTx]) JobDeleteTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobRow, error) {
+	return c.driver.UnwrapExecutor(tx).JobDelete(ctx, id)
+}
+
+// JobDeleteMany deletes all jobs matching the given filters. Running jobs are
+// never deleted, even if they match. Returns the number of jobs deleted.
+//
+//	params := river.NewJobFilterParams().States(rivertype.JobStateDiscarded).CreatedBefore(cutoff)
+//	numDeleted, err := client.JobDeleteMany(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Jobs are deleted in batches of 1,000, each in its own transaction so that
+// deleting a large number of jobs doesn't hold locks on all of them at once.
+// If an error occurs, the number of jobs deleted by the batches that succeeded
+// before it is returned along with it.
+func (c *Client[TTx]) JobDeleteMany(ctx context.Context, params *JobFilterParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
+	}
+
+	return c.jobDeleteMany(ctx, c.driver.GetExecutor(), params)
+}
+
+// JobDeleteManyTx deletes all jobs matching the given filters within the
+// specified transaction. Running jobs are never deleted, even if they match.
+// This variant lets a caller delete jobs atomically alongside other database
+// changes. Deleted jobs aren't removed until the transaction commits, and if
+// the transaction rolls back, so too are the deletions. Returns the number of
+// jobs deleted.
+//
+// Jobs are deleted in batches of 1,000, all of which are part of the given
+// transaction.
+func (c *Client[TTx]) JobDeleteManyTx(ctx context.Context, tx TTx, params *JobFilterParams) (int, error) {
+	return c.jobDeleteMany(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobDeleteMany(ctx context.Context, exec riverdriver.Executor, params *JobFilterParams) (int, error) {
+	return c.jobMany(params, func(afterID int64, filter *riverdriver.JobFilter) (*riverdriver.JobManyResult, error) {
+		return exec.JobDeleteMany(ctx, &riverdriver.JobDeleteManyParams{
+			AfterID: afterID,
+			Filter:  filter,
+			Max:     c.config.jobManyBatchSize,
+		})
+	})
+}
+
+// jobMany runs a bulk operation batch by batch, paging through matching jobs
+// in ID order until a batch comes back smaller than the batch size. Returns
+// the total number of jobs affected.
+func (c *Client[TTx]) jobMany(params *JobFilterParams, runBatch func(afterID int64, filter *riverdriver.JobFilter) (*riverdriver.JobManyResult, error)) (int, error) {
+	if params == nil {
+		return 0, errors.New("params must not be nil; use NewJobFilterParams to affect all jobs")
+	}
+
+	var (
+		afterID int64
+		filter  = params.toDriverFilter()
+		numJobs int
+	)
+
+	for {
+		res, err := runBatch(afterID, filter)
+		if err != nil {
+			return numJobs, err
+		}
+
+		numJobs += res.NumJobs
+
+		if res.NumJobs < c.config.jobManyBatchSize {
+			return numJobs, nil
+		}
+
+		afterID = res.LastID
+	}
+}
+
+// JobCountByState returns the number of jobs in each state. Every state is
+// included in the result, with a count of zero if there are no jobs in it.
+//
+// Counting is done in the database rather than by listing jobs, but it still
+// visits every job, so it may be slow on very large job tables.
+func (c *Client[TTx]) JobCountByState(ctx context.Context) (map[rivertype.JobState]int, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return jobCountByState(ctx, c.driver.GetExecutor())
+}
+
+// JobCountByStateTx returns the number of jobs in each state, within a
+// transaction. Every state is included in the result, with a count of zero if
+// there are no jobs in it.
+func (c *Client[TTx]) JobCountByStateTx(ctx context.Context, tx TTx) (map[rivertype.JobState]int, error) {
+	return jobCountByState(ctx, c.driver.UnwrapExecutor(tx))
+}
+
+func jobCountByState(ctx context.Context, exec riverdriver.Executor) (map[rivertype.JobState]int, error) {
+	counts, err := exec.JobCountByState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	countByState := make(map[rivertype.JobState]int, len(jobStateAll))
+	for _, state := range jobStateAll {
+		countByState[state] = counts[state]
+	}
+	return countByState, nil
+}
+
 // JobGet fetches a single job by its ID. Returns the up-to-date JobRow for the
 // specified jobID if it exists. Returns ErrNotFound if the job doesn't exist.
 func (c *Client[TTx]) JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error) {
@@ -1067,7 +1562,8 @@ func (c *Client[TTx]) JobGetTx(ctx context.Context, tx TTx, id int64) (*rivertyp
 }
 
 // JobRetry updates the job with the given ID to make it immediately available
-// to be retried. Jobs in the running state are not touched, while jobs in any
+// to be retried. Jobs in the running state are not touched, and neither are
+// pending jobs, which must wait for their dependencies to complete. Jobs in any
 // other state are made available. To prevent jobs already waiting in the queue
 // from being set back in line, the job's scheduled_at field is set to the
 // current time only if it's not already in the past.
@@ -1084,10 +1580,11 @@ func (c *Client[TTx]) JobRetry(ctx context.Context, id int64) (*rivertype.JobRow
 // visible to be worked until the transaction commits, and if the transaction
 // rolls back, so too is the retried job.
 //
-// Jobs in the running state are not touched, while jobs in any other state are
-// made available. To prevent jobs already waiting in the queue from being set
-// back in line, the job's scheduled_at field is set to the current time only if
-// it's not already in the past.
+// Jobs in the running state are not touched, and neither are pending jobs,
+// which must wait for their dependencies to complete. Jobs in any other state
+// are made available. To prevent jobs already waiting in the queue from being
+// set back in line, the job's scheduled_at field is set to the current time only
+// if it's not already in the past.
 //
 // MaxAttempts is also incremented by one if the job has already exhausted its
 // max attempts.
@@ -1095,13 +1592,204 @@ func (c *Client[TTx]) JobRetryTx(ctx context.Context, tx TTx, id int64) (*rivert
 	return c.driver.UnwrapExecutor(tx).JobRetry(ctx, id)
 }
 
+// JobRetryMany makes all jobs matching the given filters immediately available
+// to be retried, following the same rules as JobRetry: running and pending jobs
+// aren't touched, jobs already waiting in the queue aren't set back in line, and
+// MaxAttempts is incremented by one for jobs that have exhausted their
+// attempts. Returns the number of jobs retried.
+//
+//	params := river.NewJobFilterParams().Kinds("my_kind").States(rivertype.JobStateDiscarded)
+//	numRetried, err := client.JobRetryMany(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Jobs are retried in batches of 1,000, each in its own transaction so that
+// retrying a large number of jobs doesn't hold locks on all of them at once.
+// If an error occurs, the number of jobs retried by the batches that succeeded
+// before it is returned along with it.
+func (c *Client[TTx]) JobRetryMany(ctx context.Context, params *JobFilterParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
+	}
+
+	return c.jobRetryMany(ctx, c.driver.GetExecutor(), params)
+}
+
+// JobRetryManyTx makes all jobs matching the given filters immediately
+// available to be retried within the specified transaction, following the same
+// rules as JobRetry. This variant lets a caller retry jobs atomically alongside
+// other database changes. Retried jobs aren't visible to be worked until the
+// transaction commits, and if the transaction rolls back, so too are the
+// retries. Returns the number of jobs retried.
+//
+// Jobs are retried in batches of 1,000, all of which are part of the given
+// transaction.
+func (c *Client[TTx]) JobRetryManyTx(ctx context.Context, tx TTx, params *JobFilterParams) (int, error) {
+	return c.jobRetryMany(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobRetryMany(ctx context.Context, exec riverdriver.Executor, params *JobFilterParams) (int, error) {
+	return c.jobMany(params, func(afterID int64, filter *riverdriver.JobFilter) (*riverdriver.JobManyResult, error) {
+		return exec.JobRetryMany(ctx, &riverdriver.JobRetryManyParams{
+			AfterID: afterID,
+			Filter:  filter,
+			Max:     c.config.jobManyBatchSize,
+		})
+	})
+}
+
+// JobUpdateParams are parameters for JobUpdate and JobUpdateTx. Properties left
+// at their zero value aren't changed.
+type JobUpdateParams struct {
+	// MaxAttempts is the new maximum number of total attempts (including both
+	// the original run and all retries) before a job is abandoned and set as
+	// discarded.
+	MaxAttempts int
+
+	// Metadata is a JSON object blob that's merged into the job's existing
+	// metadata. Keys present in both are overwritten with the new value, while
+	// keys only present in the job's existing metadata are left untouched.
+	Metadata []byte
+
+	// Priority is the new priority of the job, between 1 and 4.
+	Priority int
+
+	// Queue is the name of the queue to move the job to.
+	Queue string
+
+	// ScheduledAt is the new time at which the job is scheduled to run. An
+	// available job rescheduled for the future is made scheduled, while a
+	// scheduled or retryable job rescheduled for now or the past is made
+	// available so that it's worked right away.
+	ScheduledAt time.Time
+
+	// Tags replace the job's existing tags. Set to an empty non-nil slice to
+	// remove all tags.
+	Tags []string
+}
+
+// JobUpdate updates the properties of the job with the given ID, which may be
+// used to reschedule or reprioritize a job that's waiting to be worked. Jobs
+// in any state other than running may be updated.
+//
+//	job, err := client.JobUpdate(ctx, jobID, &river.JobUpdateParams{
+//		Priority:    1,
+//		ScheduledAt: time.Now(),
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+//
+// If the job is available after being updated, clients working its queue are
+// notified so that they pick it up immediately.
+//
+// Returns the updated JobRow. Returns ErrNotFound if the job doesn't exist, or
+// ErrJobRunning if it's running.
+func (c *Client[TTx]) JobUpdate(ctx context.Context, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	job, err := c.jobUpdate(ctx, tx, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// JobUpdateTx updates the properties of the job with the given ID within the
+// specified transaction. This variant lets a caller update a job atomically
+// alongside other database changes. Clients aren't notified of the change
+// until the transaction commits, and if it rolls back, the job is left
+// unchanged. Jobs in any state other than running may be updated.
+//
+// Returns the updated JobRow. Returns ErrNotFound if the job doesn't exist, or
+// ErrJobRunning if it's running.
+func (c *Client[TTx]) JobUpdateTx(ctx context.Context, tx TTx, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	return c.jobUpdate(ctx, c.driver.UnwrapExecutor(tx), id, params)
+}
+
+func (c *Client[TTx]) jobUpdate(ctx context.Context, exec riverdriver.Executor, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	if params == nil {
+		params = &JobUpdateParams{}
+	}
+
+	if params.MaxAttempts < 0 {
+		return nil, errors.New("max attempts must be greater than zero")
+	}
+
+	if params.Priority < 0 || params.Priority > 4 {
+		return nil, errors.New("priority must be between 1 and 4")
+	}
+
+	if params.Queue != "" {
+		if err := validateQueueName(params.Queue); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(params.Metadata) > 0 {
+		var metadataMap map[string]any
+		if err := json.Unmarshal(params.Metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("metadata must be a JSON object: %w", err)
+		}
+	}
+
+	job, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+		ID:                  id,
+		MaxAttemptsDoUpdate: params.MaxAttempts != 0,
+		MaxAttempts:         params.MaxAttempts,
+		MetadataDoMerge:     len(params.Metadata) > 0,
+		Metadata:            params.Metadata,
+		Now:                 c.baseService.TimeNowUTC(),
+		PriorityDoUpdate:    params.Priority != 0,
+		Priority:            params.Priority,
+		QueueDoUpdate:       params.Queue != "",
+		Queue:               params.Queue,
+		ScheduledAtDoUpdate: !params.ScheduledAt.IsZero(),
+		ScheduledAt:         params.ScheduledAt.UTC(),
+		TagsDoUpdate:        params.Tags != nil,
+		Tags:                params.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Insert notifications are sent by a trigger that only fires on insert, so
+	// send one manually to have producers fetch a job that's now available.
+	if job.State == rivertype.JobStateAvailable {
+		payload, err := json.Marshal(&insertPayload{Queue: job.Queue})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := exec.Notify(ctx, string(notifier.NotificationTopicInsert), string(payload)); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
 // ID returns the unique ID of this client as set in its config or
 // auto-generated if not specified.
 func (c *Client[TTx]) ID() string {
 	return c.config.ID
 }
 
-func insertParamsFromArgsAndOptions(args JobArgs, insertOpts *InsertOpts) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
+func insertParamsFromArgsAndOptions(ctx context.Context, config *Config, args JobArgs, insertOpts *InsertOpts) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
 	encodedArgs, err := json.Marshal(args)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error marshaling args to JSON: %w", err)
@@ -1120,21 +1808,10 @@ func insertParamsFromArgsAndOptions(args JobArgs, insertOpts *InsertOpts) (*rive
 	priority := valutil.FirstNonZero(insertOpts.Priority, jobInsertOpts.Priority, rivercommon.PriorityDefault)
 	queue := valutil.FirstNonZero(insertOpts.Queue, jobInsertOpts.Queue, rivercommon.QueueDefault)
 
-	if err := validateQueueName(queue); err != nil {
-		return nil, nil, err
-	}
-
 	tags := insertOpts.Tags
 	if insertOpts.Tags == nil {
 		tags = jobInsertOpts.Tags
 	}
-	if tags == nil {
-		tags = []string{}
-	}
-
-	if priority > 4 {
-		return nil, nil, errors.New("priority must be between 1 and 4")
-	}
 
 	uniqueOpts := insertOpts.UniqueOpts
 	if uniqueOpts.isEmpty() {
@@ -1144,16 +1821,12 @@ func insertParamsFromArgsAndOptions(args JobArgs, insertOpts *InsertOpts) (*rive
 		return nil, nil, err
 	}
 
-	metadata := insertOpts.Metadata
-	if len(metadata) == 0 {
-		metadata = []byte("{}")
-	}
-
 	insertParams := &riverdriver.JobInsertFastParams{
+		DependsOn:   insertOpts.DependsOn,
 		EncodedArgs: encodedArgs,
 		Kind:        args.Kind(),
 		MaxAttempts: maxAttempts,
-		Metadata:    metadata,
+		Metadata:    insertOpts.Metadata,
 		Priority:    priority,
 		Queue:       queue,
 		State:       rivertype.JobStateAvailable,
@@ -1162,12 +1835,58 @@ func insertParamsFromArgsAndOptions(args JobArgs, insertOpts *InsertOpts) (*rive
 
 	if !insertOpts.ScheduledAt.IsZero() {
 		insertParams.ScheduledAt = &insertOpts.ScheduledAt
+	}
+
+	expiresAt := insertOpts.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = jobInsertOpts.ExpiresAt
+	}
+	if !expiresAt.IsZero() {
+		insertParams.ExpiresAt = &expiresAt
+	}
+
+	for _, hook := range config.InsertHooks {
+		if err := hook.Insert(ctx, args, insertParams); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Validation happens after hooks have run so that any changes they've
+	// made are validated as well.
+	if err := validateQueueName(insertParams.Queue); err != nil {
+		return nil, nil, err
+	}
+
+	if insertParams.Priority < 1 || insertParams.Priority > 4 {
+		return nil, nil, errors.New("priority must be between 1 and 4")
+	}
+
+	if len(insertParams.Metadata) == 0 {
+		insertParams.Metadata = []byte("{}")
+	}
+
+	if insertParams.ScheduledAt != nil && !insertParams.ScheduledAt.IsZero() {
 		insertParams.State = rivertype.JobStateScheduled
 	}
 
+	// Jobs with dependencies wait in pending regardless of whether they're
+	// scheduled. They'll be moved to scheduled instead of available on
+	// promotion if their scheduled time hasn't yet arrived.
+	if len(insertParams.DependsOn) > 0 || insertOpts.pending {
+		insertParams.State = rivertype.JobStatePending
+	}
+
+	if insertParams.Tags == nil {
+		insertParams.Tags = []string{}
+	}
+
 	return insertParams, (*dbunique.UniqueOpts)(&uniqueOpts), nil
 }
 
+// Default maximum number of jobs affected by each batch of a bulk operation
+// like JobCancelMany.
+const jobManyBatchSizeDefault = 1_000
+
 var errNoDriverDBPool = errors.New("driver must have non-nil database pool to use non-transactional methods like Insert and InsertMany (try InsertTx or InsertManyTx instead")
 
 // Insert inserts a new job with the provided args. Job opts can be used to
@@ -1211,11 +1930,15 @@ func (c *Client[TTx]) insert(ctx context.Context, exec riverdriver.Executor, arg
 		return nil, err
 	}
 
-	params, uniqueOpts, err := insertParamsFromArgsAndOptions(args, opts)
+	params, uniqueOpts, err := insertParamsFromArgsAndOptions(ctx, c.config, args, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := checkDependenciesExist(ctx, exec, []*riverdriver.JobInsertFastParams{params}); err != nil {
+		return nil, err
+	}
+
 	jobInsertRes, err := c.uniqueInserter.JobInsert(ctx, exec, params, uniqueOpts)
 	if err != nil {
 		return nil, err
@@ -1254,12 +1977,18 @@ func (c *Client[TTx]) InsertMany(ctx context.Context, params []InsertManyParams)
 		return 0, errNoDriverDBPool
 	}
 
-	insertParams, err := c.insertManyParams(params)
+	insertParams, err := c.insertManyParams(ctx, params)
 	if err != nil {
 		return 0, err
 	}
 
-	return c.driver.GetExecutor().JobInsertFastMany(ctx, insertParams)
+	exec := c.driver.GetExecutor()
+
+	if err := checkDependenciesExist(ctx, exec, insertParams); err != nil {
+		return 0, err
+	}
+
+	return exec.JobInsertFastMany(ctx, insertParams)
 }
 
 // InsertManyTx inserts many jobs at once using Postgres' `COPY FROM` mechanism,
@@ -1282,17 +2011,23 @@ func (c *Client[TTx]) InsertMany(ctx context.Context, params []InsertManyParams)
 // changes. An inserted job isn't visible to be worked until the transaction
 // commits, and if the transaction rolls back, so too is the inserted job.
 func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertManyParams) (int64, error) {
-	insertParams, err := c.insertManyParams(params)
+	insertParams, err := c.insertManyParams(ctx, params)
 	if err != nil {
 		return 0, err
 	}
 
-	return c.driver.UnwrapExecutor(tx).JobInsertFastMany(ctx, insertParams)
+	exec := c.driver.UnwrapExecutor(tx)
+
+	if err := checkDependenciesExist(ctx, exec, insertParams); err != nil {
+		return 0, err
+	}
+
+	return exec.JobInsertFastMany(ctx, insertParams)
 }
 
 // Validates input parameters for an a batch insert operation and generates a
 // set of batch insert parameters.
-func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*riverdriver.JobInsertFastParams, error) {
+func (c *Client[TTx]) insertManyParams(ctx context.Context, params []InsertManyParams) ([]*riverdriver.JobInsertFastParams, error) {
 	if len(params) < 1 {
 		return nil, errors.New("no jobs to insert")
 	}
@@ -1313,7 +2048,7 @@ func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*riverdrive
 		}
 
 		var err error
-		insertParams[i], _, err = insertParamsFromArgsAndOptions(param.Args, param.InsertOpts)
+		insertParams[i], _, err = insertParamsFromArgsAndOptions(ctx, c.config, param.Args, param.InsertOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -1322,6 +2057,39 @@ func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*riverdrive
 	return insertParams, nil
 }
 
+// Checks that all the jobs that jobs about to be inserted depend on exist, so
+// that a mistyped dependency ID doesn't go unnoticed and let a job run before
+// the work it was meant to wait for (a missing dependency is considered
+// satisfied on promotion). The check uses the same executor as the insert so
+// that dependencies inserted earlier in the same transaction are found.
+func checkDependenciesExist(ctx context.Context, exec riverdriver.Executor, insertParams []*riverdriver.JobInsertFastParams) error {
+	var dependsOn []int64
+	for _, params := range insertParams {
+		dependsOn = append(dependsOn, params.DependsOn...)
+	}
+	if len(dependsOn) < 1 {
+		return nil
+	}
+
+	jobs, err := exec.JobGetByIDMany(ctx, dependsOn)
+	if err != nil {
+		return err
+	}
+
+	existingIDs := make(map[int64]struct{}, len(jobs))
+	for _, job := range jobs {
+		existingIDs[job.ID] = struct{}{}
+	}
+
+	for _, id := range dependsOn {
+		if _, ok := existingIDs[id]; !ok {
+			return fmt.Errorf("job dependency %d doesn't exist: %w", id, ErrNotFound)
+		}
+	}
+
+	return nil
+}
+
 // Validates job args prior to insertion. Currently, verifies that a worker to
 // handle the kind is registered in the configured workers bundle. An
 // insert-only client doesn't require a workers bundle be configured though, so
@@ -1398,3 +2166,745 @@ func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListPara
 
 	return dblist.JobList(ctx, c.driver.UnwrapExecutor(tx), dbParams)
 }
+
+// QueueAdd adds a queue to a client that's configured to work jobs, as if it
+// had been included in Config.Queues. If the client is running, a producer for
+// the queue starts working jobs from it immediately, and otherwise (before the
+// client is started or after it's stopped) it does so once the client starts.
+// The queue's name and config are validated in the same way as those in
+// Config.Queues.
+//
+// Returns an error if the client wasn't configured with Queues and Workers, if
+// the queue is already configured, or if the client is in the middle of
+// stopping.
+func (c *Client[TTx]) QueueAdd(name string, queueConfig QueueConfig) error {
+	// An elector is only initialized for a client that'll execute jobs, and
+	// without one (along with a notifier and maintenance services), the client
+	// can't be started to work any queues.
+	if c.elector == nil {
+		return errors.New("client must be configured with Queues and Workers to add a queue")
+	}
+
+	if err := queueConfig.validate(name); err != nil {
+		return err
+	}
+
+	c.producersMu.Lock()
+	defer c.producersMu.Unlock()
+
+	if _, ok := c.producersByQueueName[name]; ok {
+		return fmt.Errorf("queue %q is already configured", name)
+	}
+	if _, ok := c.producerRunsByQueueName[name]; ok {
+		return fmt.Errorf("queue %q is still being removed", name)
+	}
+	if c.producerFetchCtx != nil && c.producerFetchCtx.Err() != nil {
+		return errors.New("client is stopping; wait for it to stop before adding a queue")
+	}
+
+	producer, err := c.newProducer(name, queueConfig)
+	if err != nil {
+		return err
+	}
+
+	c.config.Queues[name] = queueConfig
+	c.producersByQueueName[name] = producer
+	c.monitor.SetProducerStatus(name, componentstatus.Uninitialized)
+
+	if c.producerFetchCtx != nil {
+		c.runProducer(name, producer)
+	}
+
+	return nil
+}
+
+// QueueGet fetches a single queue by its name. Returns ErrNotFound if the
+// queue doesn't exist, which is the case until a client has started working
+// it.
+func (c *Client[TTx]) QueueGet(ctx context.Context, name string) (*rivertype.Queue, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.driver.GetExecutor().QueueGet(ctx, name)
+}
+
+// QueueGetTx fetches a single queue by its name, within a transaction. Returns
+// ErrNotFound if the queue doesn't exist, which is the case until a client has
+// started working it.
+func (c *Client[TTx]) QueueGetTx(ctx context.Context, tx TTx, name string) (*rivertype.Queue, error) {
+	return c.driver.UnwrapExecutor(tx).QueueGet(ctx, name)
+}
+
+// QueueList returns a list of all queues that have been worked by a client,
+// sorted by name. Each queue's PausedAt indicates whether it's currently
+// paused.
+//
+//	params := river.NewQueueListParams().First(10)
+//	queueRows, err := client.QueueList(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) QueueList(ctx context.Context, params *QueueListParams) ([]*rivertype.Queue, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	if params == nil {
+		params = NewQueueListParams()
+	}
+
+	return c.driver.GetExecutor().QueueList(ctx, int(params.paginationCount))
+}
+
+// QueueListTx returns a list of all queues that have been worked by a client,
+// sorted by name, within a transaction.
+func (c *Client[TTx]) QueueListTx(ctx context.Context, tx TTx, params *QueueListParams) ([]*rivertype.Queue, error) {
+	if params == nil {
+		params = NewQueueListParams()
+	}
+
+	return c.driver.UnwrapExecutor(tx).QueueList(ctx, int(params.paginationCount))
+}
+
+// QueuePause pauses the queue with the given name. Once paused, no client
+// working the queue fetches any new jobs from it, although jobs that are
+// already running are allowed to finish. The pause is broadcast to clients
+// immediately, and because it's persisted, clients that start later honor it
+// as well until the queue is resumed with QueueResume. Pausing a queue that's
+// already paused has no effect.
+//
+// A queue that no client has started working yet is created in a paused
+// state, so that it can be paused ahead of time.
+func (c *Client[TTx]) QueuePause(ctx context.Context, name string) error {
+	if !c.driver.HasPool() {
+		return errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := c.queueControl(ctx, tx, name, queueControlActionPause); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// QueuePauseTx pauses the queue with the given name, within a transaction.
+// This variant lets a caller pause a queue atomically alongside other database
+// changes. Clients aren't notified of the pause until the transaction commits,
+// and if it rolls back, the queue isn't paused.
+//
+// A queue that no client has started working yet is created in a paused
+// state, so that it can be paused ahead of time.
+func (c *Client[TTx]) QueuePauseTx(ctx context.Context, tx TTx, name string) error {
+	return c.queueControl(ctx, c.driver.UnwrapExecutor(tx), name, queueControlActionPause)
+}
+
+// QueueRemove removes a queue from the client so that it stops working jobs
+// from it. If drain is true, the queue's producer stops fetching new jobs, but
+// waits for any jobs that it's already working to finish like it would for
+// Stop. If drain is false, the contexts of any jobs being worked are cancelled
+// like they would be for StopAndCancel, although they still have to return
+// before the producer stops.
+//
+// QueueRemove blocks until the queue's producer has stopped. If the provided
+// context is done first, it returns the context's error, but the producer
+// continues stopping in the background. The queue can't be added again until
+// it has stopped.
+//
+// Removing a queue only affects this client. Jobs in the queue remain in the
+// database and may be worked by other clients, or by this one if the queue is
+// added again.
+func (c *Client[TTx]) QueueRemove(ctx context.Context, name string, drain bool) error {
+	run, err := func() (*producerRun, error) {
+		c.producersMu.Lock()
+		defer c.producersMu.Unlock()
+
+		if _, ok := c.producersByQueueName[name]; !ok {
+			return nil, fmt.Errorf("queue %q is not configured", name)
+		}
+
+		delete(c.config.Queues, name)
+		delete(c.producersByQueueName, name)
+
+		run, ok := c.producerRunsByQueueName[name]
+		if !ok {
+			// Not running because the client hasn't started or has stopped,
+			// so there's no producer to stop.
+			c.monitor.RemoveProducerStatus(name)
+			return nil, nil
+		}
+
+		run.removed = true
+		return run, nil
+	}()
+	if err != nil || run == nil {
+		return err
+	}
+
+	c.baseService.Logger.InfoContext(ctx, c.baseService.Name+": Removing queue", slog.String("queue", name), slog.Bool("drain", drain))
+
+	if !drain {
+		run.workCancel(rivercommon.ErrShutdown)
+	}
+	run.fetchCancel(rivercommon.ErrShutdown)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-run.stopped:
+		return nil
+	}
+}
+
+// QueueResume resumes the queue with the given name, after which clients
+// working the queue immediately start fetching jobs from it again. Resuming a
+// queue that isn't paused has no effect.
+//
+// Returns ErrNotFound if the queue doesn't exist, which is the case until a
+// client has started working it.
+func (c *Client[TTx]) QueueResume(ctx context.Context, name string) error {
+	if !c.driver.HasPool() {
+		return errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := c.queueControl(ctx, tx, name, queueControlActionResume); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// QueueResumeTx resumes the queue with the given name, within a transaction.
+// This variant lets a caller resume a queue atomically alongside other
+// database changes. Clients aren't notified of the resume until the
+// transaction commits, and if it rolls back, the queue stays paused.
+//
+// Returns ErrNotFound if the queue doesn't exist, which is the case until a
+// client has started working it.
+func (c *Client[TTx]) QueueResumeTx(ctx context.Context, tx TTx, name string) error {
+	return c.queueControl(ctx, c.driver.UnwrapExecutor(tx), name, queueControlActionResume)
+}
+
+// QueueSetMaxWorkers changes the maximum number of workers of a queue that's
+// configured for this client, as if it had been configured with the new
+// QueueConfig.MaxWorkers. It may be called while the client is running, in
+// which case a higher maximum is put to use right away, and a lower one takes
+// effect as jobs that are already running finish.
+//
+// The change only affects this client. To change the maximum for all clients
+// working the queue, use QueueUpdate instead, which takes precedence over the
+// maximum set here.
+//
+// Returns an error if the queue isn't configured, or if maxWorkers is invalid
+// in the same way as it would be in QueueConfig.
+func (c *Client[TTx]) QueueSetMaxWorkers(name string, maxWorkers int) error {
+	c.producersMu.Lock()
+	defer c.producersMu.Unlock()
+
+	producer, ok := c.producersByQueueName[name]
+	if !ok {
+		return fmt.Errorf("queue %q is not configured", name)
+	}
+
+	queueConfig := c.config.Queues[name]
+	queueConfig.MaxWorkers = maxWorkers
+	if err := queueConfig.validate(name); err != nil {
+		return err
+	}
+
+	c.config.Queues[name] = queueConfig
+	producer.SetMaxWorkerCount(uint16(maxWorkers))
+
+	return nil
+}
+
+// QueueStatsParams are parameters for QueueStats and QueueStatsTx.
+type QueueStatsParams struct {
+	// Queues limits the returned statistics to the queues with the given
+	// names. Statistics for all queues are returned if empty.
+	Queues []string
+
+	// RunDurationWindow is how far back to look for completed jobs when
+	// calculating run durations. Only jobs completed within the window are
+	// considered.
+	//
+	// Defaults to 1 hour.
+	RunDurationWindow time.Duration
+}
+
+// Default window of completed jobs used to calculate run durations in
+// QueueStats.
+const queueStatsRunDurationWindowDefault = 1 * time.Hour
+
+// QueueStats are aggregate statistics about the jobs in a queue, as returned by
+// QueueStats and QueueStatsTx.
+type QueueStats struct {
+	// CountByKind is the number of jobs of each kind in the queue, broken down
+	// by state. Only states with at least one job are included.
+	CountByKind map[string]map[rivertype.JobState]int
+
+	// CountByState is the number of jobs in the queue in each state. Every
+	// state is included, with a count of zero if there are no jobs in it.
+	CountByState map[rivertype.JobState]int
+
+	// Name is the name of the queue.
+	Name string
+
+	// OldestAvailableScheduledAt is the scheduled time of the queue's oldest
+	// available job, which indicates how long jobs have been waiting to be
+	// worked. Nil if the queue has no available jobs.
+	OldestAvailableScheduledAt *time.Time
+
+	// RunDurationP50 is the median time it took to run the queue's jobs that
+	// completed within QueueStatsParams.RunDurationWindow, measured from the
+	// start of their last attempt until they completed. Zero if no jobs
+	// completed within the window.
+	RunDurationP50 time.Duration
+
+	// RunDurationP95 is the 95th percentile time it took to run the queue's
+	// jobs that completed within QueueStatsParams.RunDurationWindow. Zero if no
+	// jobs completed within the window.
+	RunDurationP95 time.Duration
+}
+
+// QueueStats returns aggregate statistics for each queue that has jobs,
+// including counts of its jobs by state and kind, the scheduled time of its
+// oldest available job, and the run durations of its recently completed jobs.
+// Queues are sorted by name.
+//
+//	stats, err := client.QueueStats(ctx, &river.QueueStatsParams{Queues: []string{river.QueueDefault}})
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Statistics are calculated in the database rather than by listing jobs, but
+// counting still visits every job in the included queues, so it may be slow
+// on very large job tables.
+func (c *Client[TTx]) QueueStats(ctx context.Context, params *QueueStatsParams) ([]*QueueStats, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.queueStats(ctx, c.driver.GetExecutor(), params)
+}
+
+// QueueStatsTx returns aggregate statistics for each queue that has jobs,
+// within a transaction. See QueueStats for details.
+func (c *Client[TTx]) QueueStatsTx(ctx context.Context, tx TTx, params *QueueStatsParams) ([]*QueueStats, error) {
+	return c.queueStats(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) queueStats(ctx context.Context, exec riverdriver.Executor, params *QueueStatsParams) ([]*QueueStats, error) {
+	if params == nil {
+		params = &QueueStatsParams{}
+	}
+
+	if params.RunDurationWindow < 0 {
+		return nil, errors.New("run duration window must not be negative")
+	}
+
+	counts, err := exec.JobCountByQueueKindAndState(ctx, &riverdriver.JobCountByQueueKindAndStateParams{
+		Queues: params.Queues,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobQueueStats, err := exec.JobQueueStats(ctx, &riverdriver.JobQueueStatsParams{
+		FinalizedAfter: c.baseService.TimeNowUTC().Add(-valutil.ValOrDefault(params.RunDurationWindow, queueStatsRunDurationWindowDefault)),
+		Queues:         params.Queues,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statsByQueue := make(map[string]*QueueStats)
+	queueStatsFor := func(queue string) *QueueStats {
+		stats, ok := statsByQueue[queue]
+		if !ok {
+			stats = &QueueStats{
+				CountByKind:  make(map[string]map[rivertype.JobState]int),
+				CountByState: make(map[rivertype.JobState]int, len(jobStateAll)),
+				Name:         queue,
+			}
+			for _, state := range jobStateAll {
+				stats.CountByState[state] = 0
+			}
+			statsByQueue[queue] = stats
+		}
+		return stats
+	}
+
+	for _, count := range counts {
+		stats := queueStatsFor(count.Queue)
+
+		stats.CountByState[count.State] += count.Count
+
+		if _, ok := stats.CountByKind[count.Kind]; !ok {
+			stats.CountByKind[count.Kind] = make(map[rivertype.JobState]int)
+		}
+		stats.CountByKind[count.Kind][count.State] = count.Count
+	}
+
+	for _, queueStats := range jobQueueStats {
+		stats := queueStatsFor(queueStats.Queue)
+
+		stats.OldestAvailableScheduledAt = queueStats.OldestAvailableScheduledAt
+		stats.RunDurationP50 = queueStats.RunDurationP50
+		stats.RunDurationP95 = queueStats.RunDurationP95
+	}
+
+	allStats := make([]*QueueStats, 0, len(statsByQueue))
+	for _, stats := range statsByQueue {
+		allStats = append(allStats, stats)
+	}
+	slices.SortFunc(allStats, func(a, b *QueueStats) int { return strings.Compare(a.Name, b.Name) })
+	return allStats, nil
+}
+
+// QueueUpdateParams are parameters for QueueUpdate and QueueUpdateTx.
+type QueueUpdateParams struct {
+	// MaxWorkers is the maximum number of workers that each client working the
+	// queue runs for it, taking precedence over the QueueConfig.MaxWorkers of
+	// every client. Zero removes a maximum set previously, after which clients
+	// go back to using their configured maximum.
+	//
+	// Requires a minimum of 0, and a maximum of 10,000.
+	MaxWorkers int
+}
+
+// QueueUpdate updates the persisted settings of the queue with the given name.
+// The change is broadcast to all clients working the queue immediately, and
+// because it's persisted, clients that start later honor it as well.
+//
+// Returns the updated queue. Returns ErrNotFound if the queue doesn't exist,
+// which is the case until a client has started working it.
+func (c *Client[TTx]) QueueUpdate(ctx context.Context, name string, params *QueueUpdateParams) (*rivertype.Queue, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	queue, err := c.queueUpdate(ctx, tx, name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// QueueUpdateTx updates the persisted settings of the queue with the given
+// name, within a transaction. Clients aren't notified of the change until the
+// transaction commits, and if it rolls back, the queue's settings are left
+// unchanged.
+//
+// Returns the updated queue. Returns ErrNotFound if the queue doesn't exist,
+// which is the case until a client has started working it.
+func (c *Client[TTx]) QueueUpdateTx(ctx context.Context, tx TTx, name string, params *QueueUpdateParams) (*rivertype.Queue, error) {
+	return c.queueUpdate(ctx, c.driver.UnwrapExecutor(tx), name, params)
+}
+
+func (c *Client[TTx]) queueUpdate(ctx context.Context, exec riverdriver.Executor, name string, params *QueueUpdateParams) (*rivertype.Queue, error) {
+	if params == nil {
+		params = &QueueUpdateParams{}
+	}
+
+	if params.MaxWorkers < 0 || params.MaxWorkers > QueueNumWorkersMax {
+		return nil, fmt.Errorf("invalid number of workers for queue %q: %d", name, params.MaxWorkers)
+	}
+
+	updateParams := &riverdriver.QueueUpdateMetadataParams{Name: name}
+	if params.MaxWorkers == 0 {
+		updateParams.MetadataDeleteKeys = []string{queueMetadataKeyMaxWorkers}
+	} else {
+		metadataUpdates, err := json.Marshal(map[string]int{queueMetadataKeyMaxWorkers: params.MaxWorkers})
+		if err != nil {
+			return nil, err
+		}
+		updateParams.MetadataUpdates = metadataUpdates
+	}
+
+	queue, err := exec.QueueUpdateMetadata(ctx, updateParams)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(&queueControlPayload{Action: queueControlActionUpdate, MaxWorkers: params.MaxWorkers, Queue: name, UpdatedAt: queue.UpdatedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exec.Notify(ctx, string(notifier.NotificationTopicQueueControl), string(payload)); err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// queueControl pauses or resumes a queue and notifies producers working it of
+// the change. Notifications are only delivered once the transaction of the
+// given executor commits.
+func (c *Client[TTx]) queueControl(ctx context.Context, exec riverdriver.Executor, name string, action queueControlAction) error {
+	var (
+		queue *rivertype.Queue
+		err   error
+	)
+	switch action {
+	case queueControlActionPause:
+		// Pausing creates the queue if it doesn't exist, so make sure that its
+		// name is one that could be worked.
+		if err := validateQueueName(name); err != nil {
+			return err
+		}
+		queue, err = exec.QueuePause(ctx, name)
+	case queueControlActionResume:
+		queue, err = exec.QueueResume(ctx, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&queueControlPayload{Action: action, Queue: name, UpdatedAt: queue.UpdatedAt})
+	if err != nil {
+		return err
+	}
+
+	return exec.Notify(ctx, string(notifier.NotificationTopicQueueControl), string(payload))
+}
+
+// WorkflowCancel cancels all tasks of the workflow with the given ID that
+// haven't yet been finalized. Tasks are cancelled in the same way as with
+// JobCancel, so tasks that are currently running are marked for cancellation
+// and have their context cancelled, but will continue running until their
+// worker returns. The provided context is used for the underlying Postgres
+// queries and can be used to cancel the operation or apply a timeout.
+//
+// Returns the workflow with its tasks in their up-to-date state. Returns
+// ErrNotFound if no workflow with the given ID exists.
+func (c *Client[TTx]) WorkflowCancel(ctx context.Context, workflowID string) (*WorkflowResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := c.workflowCancel(ctx, tx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// WorkflowCancelTx cancels all tasks of the workflow with the given ID that
+// haven't yet been finalized, within the specified transaction. This variant
+// lets a caller cancel a workflow atomically alongside other database changes.
+// Cancellation doesn't take effect until the transaction commits, and if the
+// transaction rolls back, so too is the cancellation.
+//
+// Tasks are cancelled in the same way as with JobCancel, so tasks that are
+// currently running are marked for cancellation and have their context
+// cancelled, but will continue running until their worker returns.
+//
+// Returns the workflow with its tasks in their up-to-date state. Returns
+// ErrNotFound if no workflow with the given ID exists.
+func (c *Client[TTx]) WorkflowCancelTx(ctx context.Context, tx TTx, workflowID string) (*WorkflowResult, error) {
+	return c.workflowCancel(ctx, c.driver.UnwrapExecutor(tx), workflowID)
+}
+
+func (c *Client[TTx]) workflowCancel(ctx context.Context, exec riverdriver.Executor, workflowID string) (*WorkflowResult, error) {
+	res, err := c.workflowGet(ctx, exec, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range res.Tasks {
+		if task.Job.FinalizedAt != nil {
+			continue
+		}
+
+		task.Job, err = c.jobCancel(ctx, exec, task.Job.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error cancelling workflow task %q: %w", task.Name, err)
+		}
+	}
+
+	return res, nil
+}
+
+// WorkflowGet fetches the workflow with the given ID along with all of its
+// tasks, which can be used to check on its progress. Returns ErrNotFound if no
+// workflow with the given ID exists.
+func (c *Client[TTx]) WorkflowGet(ctx context.Context, workflowID string) (*WorkflowResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.workflowGet(ctx, c.driver.GetExecutor(), workflowID)
+}
+
+// WorkflowGetTx fetches the workflow with the given ID along with all of its
+// tasks, within a transaction. Returns ErrNotFound if no workflow with the
+// given ID exists.
+func (c *Client[TTx]) WorkflowGetTx(ctx context.Context, tx TTx, workflowID string) (*WorkflowResult, error) {
+	return c.workflowGet(ctx, c.driver.UnwrapExecutor(tx), workflowID)
+}
+
+func (c *Client[TTx]) workflowGet(ctx context.Context, exec riverdriver.Executor, workflowID string) (*WorkflowResult, error) {
+	tasks, err := workflowTasks(ctx, exec, workflowID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) < 1 {
+		return nil, ErrNotFound
+	}
+
+	metadata, err := workflowMetadataFromJob(tasks[0].Job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkflowResult{
+		ID:    workflowID,
+		Name:  metadata.Name,
+		Tasks: tasks,
+	}, nil
+}
+
+// WorkflowTaskDeps fetches the tasks that the given workflow task depends on,
+// keyed by task name. It's meant to be used from within a worker to read the
+// outputs of a task's upstream tasks:
+//
+//	func (w *MyWorker) Work(ctx context.Context, job *river.Job[MyArgs]) error {
+//		client := river.ClientFromContext[pgx.Tx](ctx)
+//
+//		deps, err := client.WorkflowTaskDeps(ctx, job.JobRow)
+//		if err != nil {
+//			return err
+//		}
+//
+//		var output MyOutput
+//		if err := deps["upstream_task"].Output(&output); err != nil {
+//			return err
+//		}
+//
+//		...
+//	}
+//
+// Returns an error if the job isn't part of a workflow. Dependencies that no
+// longer exist (e.g. because they completed and were subsequently removed by
+// the job cleaner) are omitted from the result.
+func (c *Client[TTx]) WorkflowTaskDeps(ctx context.Context, job *rivertype.JobRow) (map[string]*WorkflowTask, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.workflowTaskDeps(ctx, c.driver.GetExecutor(), job)
+}
+
+// WorkflowTaskDepsTx fetches the tasks that the given workflow task depends
+// on, keyed by task name, within a transaction. See WorkflowTaskDeps for
+// details.
+func (c *Client[TTx]) WorkflowTaskDepsTx(ctx context.Context, tx TTx, job *rivertype.JobRow) (map[string]*WorkflowTask, error) {
+	return c.workflowTaskDeps(ctx, c.driver.UnwrapExecutor(tx), job)
+}
+
+func (c *Client[TTx]) workflowTaskDeps(ctx context.Context, exec riverdriver.Executor, job *rivertype.JobRow) (map[string]*WorkflowTask, error) {
+	metadata, err := workflowMetadataFromJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata.ID == "" {
+		return nil, errors.New("job is not part of a workflow")
+	}
+
+	deps := make(map[string]*WorkflowTask, len(metadata.Deps))
+	if len(metadata.Deps) < 1 {
+		return deps, nil
+	}
+
+	tasks, err := workflowTasks(ctx, exec, metadata.ID, metadata.Deps)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		deps[task.Name] = task
+	}
+
+	return deps, nil
+}
+
+// workflowTasks lists the tasks of the workflow with the given ID, optionally
+// restricted to the given task names, ordered by job ID.
+func workflowTasks(ctx context.Context, exec riverdriver.Executor, workflowID string, taskNames []string) ([]*WorkflowTask, error) {
+	metadataFragment, err := json.Marshal(map[string]string{metadataKeyWorkflowID: workflowID})
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := "metadata @> @metadata_fragment::jsonb"
+	namedArgs := map[string]any{"metadata_fragment": string(metadataFragment)}
+
+	if len(taskNames) > 0 {
+		conditions += "\n  AND metadata->>'" + metadataKeyWorkflowTask + "' = any(@workflow_tasks::text[])"
+		namedArgs["workflow_tasks"] = taskNames
+	}
+
+	jobs, err := dblist.JobList(ctx, exec, &dblist.JobListParams{
+		Conditions: conditions,
+		LimitCount: math.MaxInt32,
+		NamedArgs:  namedArgs,
+		OrderBy:    []dblist.JobListOrderBy{{Expr: "id", Order: dblist.SortOrderAsc}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*WorkflowTask, len(jobs))
+	for i, job := range jobs {
+		metadata, err := workflowMetadataFromJob(job)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks[i] = &WorkflowTask{
+			Deps: metadata.Deps,
+			Job:  job,
+			Name: metadata.Task,
+		}
+	}
+
+	return tasks, nil
+}
@@ -68,6 +68,15 @@ func (m *clientMonitor) SetProducerStatus(queueName string, status componentstat
 	m.bufferStatusUpdate()
 }
 
+// RemoveProducerStatus removes the status of a producer whose queue has been
+// removed from the client.
+func (m *clientMonitor) RemoveProducerStatus(queueName string) {
+	m.statusSnapshotMu.Lock()
+	defer m.statusSnapshotMu.Unlock()
+	delete(m.currentSnapshot.Producers, queueName)
+	m.bufferStatusUpdate()
+}
+
 func (m *clientMonitor) SetElectorStatus(newStatus componentstatus.ElectorStatus) {
 	m.statusSnapshotMu.Lock()
 	defer m.statusSnapshotMu.Unlock()
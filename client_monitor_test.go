@@ -111,4 +111,14 @@ func Test_Monitor(t *testing.T) {
 		require.Equal(componentstatus.Uninitialized, update.Producers["queue1"])
 		require.Equal(componentstatus.Healthy, update.Producers["queue2"])
 	})
+
+	t.Run("ProducerRemoved", func(t *testing.T) {
+		t.Parallel()
+		require := require.New(t)
+		monitor, snapshotCh := setup(t, "queue1", "queue2")
+
+		monitor.RemoveProducerStatus("queue2")
+		update := awaitSnapshot(t, snapshotCh)
+		require.Equal(map[string]componentstatus.Status{"queue1": componentstatus.Uninitialized}, update.Producers)
+	})
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -16,11 +17,15 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
 
 	"github.com/riverqueue/river/internal/componentstatus"
+	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/maintenance"
+	"github.com/riverqueue/river/internal/notifier"
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/riverinternaltest"
 	"github.com/riverqueue/river/internal/riverinternaltest/testfactory"
@@ -28,7 +33,9 @@ import (
 	"github.com/riverqueue/river/internal/util/ptrutil"
 	"github.com/riverqueue/river/internal/util/sliceutil"
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivermigrate"
 	"github.com/riverqueue/river/rivertype"
 )
 
@@ -217,622 +224,2946 @@ func Test_Client(t *testing.T) {
 		riverinternaltest.WaitOrTimeout(t, workedChan)
 	})
 
-	t.Run("JobCancelErrorReturned", func(t *testing.T) {
+	t.Run("BatchCompleter", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		config := newTestConfig(t, nil)
+		config.BatchCompleter = true
+
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
 
 		type JobArgs struct {
 			JobArgsReflectKind[JobArgs]
 		}
 
 		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			return JobCancel(errors.New("a persisted internal error"))
+			return nil
 		}))
 
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
 		startClient(ctx, t, client)
 
 		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
 		require.NoError(t, err)
 
-		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
-		require.Equal(t, EventKindJobCancelled, event.Kind)
-		require.Equal(t, JobStateCancelled, event.Job.State)
-		require.WithinDuration(t, time.Now(), *event.Job.FinalizedAt, 2*time.Second)
-
-		updatedJob, err := client.JobGet(ctx, insertedJob.ID)
-		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
-		require.WithinDuration(t, time.Now(), *updatedJob.FinalizedAt, 2*time.Second)
+		event := riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertedJob.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, event.Job.State)
 	})
 
-	t.Run("JobSnoozeErrorReturned", func(t *testing.T) {
+	t.Run("DependsOn", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
 		type JobArgs struct {
 			JobArgsReflectKind[JobArgs]
 		}
 
 		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			return JobSnooze(15 * time.Minute)
+			return nil
 		}))
 
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
 		startClient(ctx, t, client)
+		client.testSignals.electedLeader.WaitOrTimeout()
 
-		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		firstJob, err := client.Insert(ctx, &JobArgs{}, nil)
 		require.NoError(t, err)
 
-		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
-		require.Equal(t, EventKindJobSnoozed, event.Kind)
-		require.Equal(t, JobStateScheduled, event.Job.State)
-		require.WithinDuration(t, time.Now().Add(15*time.Minute), event.Job.ScheduledAt, 2*time.Second)
-
-		updatedJob, err := client.JobGet(ctx, insertedJob.ID)
+		secondJob, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{DependsOn: []int64{firstJob.ID}})
 		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateScheduled, updatedJob.State)
-		require.WithinDuration(t, time.Now().Add(15*time.Minute), updatedJob.ScheduledAt, 2*time.Second)
+		require.Equal(t, rivertype.JobStatePending, secondJob.State)
+		require.Equal(t, []int64{firstJob.ID}, secondJob.DependsOn)
+
+		// The first job completes, after which the second job is promoted by
+		// the leader and worked.
+		event := riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, firstJob.ID, event.Job.ID)
+
+		event = riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, secondJob.ID, event.Job.ID)
 	})
 
-	// This helper is used to test cancelling a job both _in_ a transaction and
-	// _outside of_ a transaction. The exact same test logic applies to each case,
-	// the only difference is a different cancelFunc provided by the specific
-	// subtest.
-	cancelRunningJobTestHelper := func(t *testing.T, cancelFunc func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error)) { //nolint:thelper
-		client, bundle := setup(t)
+	t.Run("Workflow", func(t *testing.T) {
+		t.Parallel()
 
-		jobStartedChan := make(chan int64)
+		client, _ := setup(t)
 
 		type JobArgs struct {
 			JobArgsReflectKind[JobArgs]
+			Name string `json:"name"`
 		}
 
+		// Each task outputs its name prefixed by the outputs of its upstream
+		// tasks, so the final task's output reflects the whole workflow.
 		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			jobStartedChan <- job.ID
-			<-ctx.Done()
-			return ctx.Err()
+			deps, err := ClientFromContext[pgx.Tx](ctx).WorkflowTaskDeps(ctx, job.JobRow)
+			if err != nil {
+				return err
+			}
+
+			depNames := maps.Keys(deps)
+			slices.Sort(depNames)
+
+			var output string
+			for _, depName := range depNames {
+				var depOutput string
+				if err := deps[depName].Output(&depOutput); err != nil {
+					return err
+				}
+				output += depOutput + ">"
+			}
+
+			return RecordOutput(ctx, output+job.Args.Name)
 		}))
 
-		statusUpdateCh := client.monitor.RegisterUpdates()
-		startClient(ctx, t, client)
-		waitForClientHealthy(ctx, t, statusUpdateCh)
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
 
-		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
-		require.NoError(t, err)
+		startClient(ctx, t, client)
+		client.testSignals.electedLeader.WaitOrTimeout()
 
-		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
-		require.Equal(t, insertedJob.ID, startedJobID)
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", &JobArgs{Name: "a"}, nil, nil)
+		workflow.Add("b", &JobArgs{Name: "b"}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+		workflow.Add("c", &JobArgs{Name: "c"}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+		workflow.Add("d", &JobArgs{Name: "d"}, nil, &WorkflowTaskOpts{Deps: []string{"b", "c"}})
 
-		// Cancel the job:
-		updatedJob, err := cancelFunc(ctx, bundle.dbPool, client, insertedJob.ID)
+		params, err := workflow.Prepare()
 		require.NoError(t, err)
-		require.NotNil(t, updatedJob)
-		// Job is still actively running at this point because the query wouldn't
-		// modify that column for a running job:
-		require.Equal(t, rivertype.JobStateRunning, updatedJob.State)
-
-		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
-		require.Equal(t, EventKindJobCancelled, event.Kind)
-		require.Equal(t, JobStateCancelled, event.Job.State)
-		require.WithinDuration(t, time.Now(), *event.Job.FinalizedAt, 2*time.Second)
 
-		jobAfterCancel, err := client.JobGet(ctx, insertedJob.ID)
+		_, err = client.InsertMany(ctx, params)
 		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateCancelled, jobAfterCancel.State)
-		require.WithinDuration(t, time.Now(), *jobAfterCancel.FinalizedAt, 2*time.Second)
-	}
 
-	t.Run("CancelRunningJob", func(t *testing.T) {
-		t.Parallel()
+		// Tasks are worked in dependency order, with b and c in either order.
+		var completedNames []string
+		for i := 0; i < 4; i++ {
+			event := riverinternaltest.WaitOrTimeout(t, subscribeChan)
 
-		cancelRunningJobTestHelper(t, func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error) {
-			return client.JobCancel(ctx, jobID)
-		})
-	})
+			var args JobArgs
+			require.NoError(t, json.Unmarshal(event.Job.EncodedArgs, &args))
+			completedNames = append(completedNames, args.Name)
+		}
+		require.Equal(t, "a", completedNames[0])
+		require.ElementsMatch(t, []string{"b", "c"}, completedNames[1:3])
+		require.Equal(t, "d", completedNames[3])
 
-	t.Run("CancelRunningJobInTx", func(t *testing.T) {
-		t.Parallel()
+		workflowRes, err := client.WorkflowGet(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.True(t, workflowRes.Finalized())
+		require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateCompleted: 4}, workflowRes.CountByState())
 
-		cancelRunningJobTestHelper(t, func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error) {
-			var (
-				job *rivertype.JobRow
-				err error
-			)
-			txErr := pgx.BeginFunc(ctx, dbPool, func(tx pgx.Tx) error {
-				job, err = client.JobCancelTx(ctx, tx, jobID)
-				return err
-			})
-			require.NoError(t, txErr)
-			return job, err
-		})
+		var output string
+		require.NoError(t, workflowRes.Task("d").Output(&output))
+		require.Equal(t, "a>b>a>c>d", output)
 	})
 
-	t.Run("CancelScheduledJob", func(t *testing.T) {
+	t.Run("RecordOutput", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		jobStartedChan := make(chan int64)
-
 		type JobArgs struct {
 			JobArgsReflectKind[JobArgs]
 		}
 
+		type JobOutput struct {
+			Result int `json:"result"`
+		}
+
 		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			jobStartedChan <- job.ID
-			<-ctx.Done()
-			return ctx.Err()
+			return RecordOutput(ctx, JobOutput{Result: 123})
 		}))
 
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
 		startClient(ctx, t, client)
 
-		insertedJob, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(5 * time.Minute)})
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
 		require.NoError(t, err)
+		require.Nil(t, insertedJob.Output())
 
-		// Cancel the job:
-		updatedJob, err := client.JobCancel(ctx, insertedJob.ID)
+		event := riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertedJob.ID, event.Job.ID)
+		require.JSONEq(t, `{"result": 123}`, string(event.Job.Output()))
+
+		job, err := client.JobGet(ctx, insertedJob.ID)
 		require.NoError(t, err)
-		require.NotNil(t, updatedJob)
-		require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
-		require.WithinDuration(t, time.Now(), *updatedJob.FinalizedAt, 2*time.Second)
+
+		var output JobOutput
+		require.NoError(t, json.Unmarshal(job.Output(), &output))
+		require.Equal(t, JobOutput{Result: 123}, output)
 	})
 
-	t.Run("CancelNonExistentJob", func(t *testing.T) {
+	t.Run("JobHeartbeat", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		type JobProgress struct {
+			Processed int `json:"processed"`
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			return JobHeartbeatWithProgress(ctx, JobProgress{Processed: 10})
+		}))
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		t.Cleanup(cancel)
+
 		startClient(ctx, t, client)
 
-		// Cancel an unknown job ID:
-		jobAfter, err := client.JobCancel(ctx, 0)
-		require.ErrorIs(t, err, ErrNotFound)
-		require.Nil(t, jobAfter)
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
 
-		// Cancel an unknown job ID, within a transaction:
-		err = dbutil.WithTx(ctx, client.driver.GetExecutor(), func(ctx context.Context, exec riverdriver.ExecutorTx) error {
-			jobAfter, err := exec.JobCancel(ctx, &riverdriver.JobCancelParams{ID: 0})
-			require.ErrorIs(t, err, ErrNotFound)
-			require.Nil(t, jobAfter)
-			return nil
-		})
+		event := riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, insertedJob.ID, event.Job.ID)
+
+		job, err := client.JobGet(ctx, insertedJob.ID)
 		require.NoError(t, err)
+		require.NotNil(t, job.HeartbeatAt)
+		require.JSONEq(t, `{"processed": 10}`, string(job.Progress()))
 	})
 
-	t.Run("AlternateSchema", func(t *testing.T) {
+	t.Run("PollOnly", func(t *testing.T) {
 		t.Parallel()
 
-		_, bundle := setup(t)
+		config := newTestConfig(t, nil)
+		config.PollOnly = true
 
-		// Reconfigure the pool with an alternate schema, initialize a new pool
-		dbPoolConfig := bundle.dbPool.Config() // a copy of the original config
-		dbPoolConfig.ConnConfig.RuntimeParams["search_path"] = "alternate_schema"
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
+		require.Nil(t, client.notifier)
 
-		dbPool, err := pgxpool.NewWithConfig(ctx, dbPoolConfig)
-		require.NoError(t, err)
-		t.Cleanup(dbPool.Close)
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
 
-		client, err := NewClient(riverpgxv5.New(dbPool), newTestConfig(t, nil))
+		workedChan := make(chan struct{})
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			workedChan <- struct{}{}
+			return nil
+		}))
+
+		startClient(ctx, t, client)
+
+		_, err := client.Insert(ctx, &JobArgs{}, nil)
 		require.NoError(t, err)
 
-		// We don't actually verify that River's functional on another schema so
-		// that we don't have to raise and migrate it. We cheat a little by
-		// configuring a different schema and then verifying that we can't find
-		// a `river_job` to confirm we're point there.
-		_, err = client.Insert(ctx, &noOpArgs{}, nil)
-		var pgErr *pgconn.PgError
-		require.ErrorAs(t, err, &pgErr)
-		require.Equal(t, pgerrcode.UndefinedTable, pgErr.Code)
-		// PgError has SchemaName and TableName properties, but unfortunately
-		// neither contain a useful value in this case.
-		require.Equal(t, `relation "river_job" does not exist`, pgErr.Message)
+		riverinternaltest.WaitOrTimeout(t, workedChan)
+
+		// Leadership is still gained without the notifier.
+		client.testSignals.electedLeader.WaitOrTimeout()
 	})
 
-	t.Run("StopAndCancel", func(t *testing.T) {
+	t.Run("QueuePauseAndResume", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
-		jobStartedChan := make(chan int64)
-		jobDoneChan := make(chan struct{})
 
 		type JobArgs struct {
 			JobArgsReflectKind[JobArgs]
 		}
 
+		workedChan := make(chan struct{}, 10)
+
 		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
-			jobStartedChan <- job.ID
-			<-ctx.Done()
-			require.ErrorIs(t, context.Cause(ctx), rivercommon.ErrShutdown)
-			close(jobDoneChan)
+			workedChan <- struct{}{}
 			return nil
 		}))
 
+		// Producers register their queue and subscribe to queue control
+		// notifications before reporting themselves healthy.
+		statusUpdateCh := client.monitor.RegisterUpdates()
 		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 
-		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		// Listen for queue control notifications as well. Subscriptions are
+		// invoked in order, so by the time this one has been, the producer's
+		// has been too.
+		queueControlChan := make(chan struct{}, 10)
+		sub := client.notifier.Listen(notifier.NotificationTopicQueueControl, func(topic notifier.NotificationTopic, payload string) {
+			queueControlChan <- struct{}{}
+		})
+		t.Cleanup(sub.Unlisten)
+
+		require.NoError(t, client.QueuePause(ctx, QueueDefault))
+		riverinternaltest.WaitOrTimeout(t, queueControlChan)
+
+		queue, err := client.QueueGet(ctx, QueueDefault)
 		require.NoError(t, err)
+		require.NotNil(t, queue.PausedAt)
 
-		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
-		require.Equal(t, insertedJob.ID, startedJobID)
+		_, err = client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
 
 		select {
-		case <-client.Stopped():
-			t.Fatal("expected client to not be stopped yet")
-		default:
+		case <-workedChan:
+			require.FailNow(t, "Job unexpectedly worked in paused queue")
+		case <-time.After(5 * client.config.FetchPollInterval):
 		}
 
-		require.NoError(t, client.StopAndCancel(ctx))
-
-		riverinternaltest.WaitOrTimeout(t, client.Stopped())
+		require.NoError(t, client.QueueResume(ctx, QueueDefault))
+		riverinternaltest.WaitOrTimeout(t, workedChan)
 	})
-}
 
-func Test_Client_Stop(t *testing.T) {
-	t.Parallel()
+	t.Run("Schema", func(t *testing.T) {
+		t.Parallel()
 
-	ctx := context.Background()
+		dbPool := riverinternaltest.TestDB(ctx, t)
 
-	// Performs continual job insertion on a number of background goroutines.
-	// Returns a `finish` function that should be deferred to stop insertion and
-	// safely stop goroutines.
-	doParallelContinualInsertion := func(ctx context.Context, t *testing.T, client *Client[pgx.Tx]) func() {
-		t.Helper()
+		// Test databases are reused between runs, so make sure the schema is
+		// always dropped afterwards (and before in case a prior run crashed).
+		const schema = "custom_schema"
+		_, err := dbPool.Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE; CREATE SCHEMA "+schema)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, err := dbPool.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+			require.NoError(t, err)
+		})
 
-		ctx, cancel := context.WithCancel(ctx)
+		_, err = rivermigrate.New(riverpgxv5.New(dbPool), &rivermigrate.Config{Schema: schema}).
+			Migrate(ctx, rivermigrate.DirectionUp, nil)
+		require.NoError(t, err)
 
-		var wg sync.WaitGroup
-		for i := 0; i < 10; i++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					default:
-					}
+		config := newTestConfig(t, nil)
+		config.Schema = schema
 
-					_, err := client.Insert(ctx, callbackArgs{}, nil)
-					if errors.Is(err, context.Canceled) {
-						return
-					}
-					require.NoError(t, err)
+		client := newTestClient(t, dbPool, config)
 
-					// Sleep a brief time between inserts.
-					client.baseService.CancellableSleepRandomBetween(ctx, 1*time.Microsecond, 10*time.Millisecond)
-				}
-			}()
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
 		}
 
-		return func() {
-			cancel()
-			wg.Wait()
+		workedChan := make(chan struct{})
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			workedChan <- struct{}{}
+			return nil
+		}))
+
+		startClient(ctx, t, client)
+
+		job, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riverinternaltest.WaitOrTimeout(t, workedChan)
+
+		var numJobs int
+		require.NoError(t, dbPool.QueryRow(ctx, "SELECT count(*) FROM "+schema+".river_job WHERE id = $1", job.ID).Scan(&numJobs))
+		require.Equal(t, 1, numJobs)
+
+		// Nothing was inserted into River's tables on the search path.
+		require.NoError(t, dbPool.QueryRow(ctx, "SELECT count(*) FROM river_job").Scan(&numJobs))
+		require.Zero(t, numJobs)
+	})
+
+	t.Run("StartInsertAndWorkWithDatabaseSQLDriver", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		stdPool := stdlib.OpenDBFromPool(bundle.dbPool)
+		t.Cleanup(func() { require.NoError(t, stdPool.Close()) })
+
+		config := newTestConfig(t, nil)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		workedChan := make(chan struct{})
+
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			workedChan <- struct{}{}
+			return nil
+		}))
+
+		client, err := NewClient(riverdatabasesql.New(stdPool), config)
+		require.NoError(t, err)
+
+		require.NoError(t, client.Start(ctx))
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			require.NoError(t, client.Stop(ctx))
+		})
+
+		_, err = client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		riverinternaltest.WaitOrTimeout(t, workedChan)
+	})
+
+	t.Run("JobCancelErrorReturned", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			return JobCancel(errors.New("a persisted internal error"))
+		}))
+
+		startClient(ctx, t, client)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobCancelled, event.Kind)
+		require.Equal(t, JobStateCancelled, event.Job.State)
+		require.WithinDuration(t, time.Now(), *event.Job.FinalizedAt, 2*time.Second)
+
+		updatedJob, err := client.JobGet(ctx, insertedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
+		require.WithinDuration(t, time.Now(), *updatedJob.FinalizedAt, 2*time.Second)
+	})
+
+	t.Run("JobDiscardErrorReturned", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			return JobDiscard(errors.New("a permanent error"))
+		}))
+
+		startClient(ctx, t, client)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, JobStateDiscarded, event.Job.State)
+		require.WithinDuration(t, time.Now(), *event.Job.FinalizedAt, 2*time.Second)
+
+		updatedJob, err := client.JobGet(ctx, insertedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, updatedJob.State)
+		require.Less(t, updatedJob.Attempt, updatedJob.MaxAttempts)
+	})
+
+	t.Run("JobSnoozeErrorReturned", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			return JobSnooze(15 * time.Minute)
+		}))
+
+		startClient(ctx, t, client)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobSnoozed, event.Kind)
+		require.Equal(t, JobStateScheduled, event.Job.State)
+		require.WithinDuration(t, time.Now().Add(15*time.Minute), event.Job.ScheduledAt, 2*time.Second)
+
+		updatedJob, err := client.JobGet(ctx, insertedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, updatedJob.State)
+		require.WithinDuration(t, time.Now().Add(15*time.Minute), updatedJob.ScheduledAt, 2*time.Second)
+	})
+
+	// This helper is used to test cancelling a job both _in_ a transaction and
+	// _outside of_ a transaction. The exact same test logic applies to each case,
+	// the only difference is a different cancelFunc provided by the specific
+	// subtest.
+	cancelRunningJobTestHelper := func(t *testing.T, cancelFunc func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error)) { //nolint:thelper
+		client, bundle := setup(t)
+
+		jobStartedChan := make(chan int64)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
 		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		statusUpdateCh := client.monitor.RegisterUpdates()
+		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
+		require.Equal(t, insertedJob.ID, startedJobID)
+
+		// Cancel the job:
+		updatedJob, err := cancelFunc(ctx, bundle.dbPool, client, insertedJob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updatedJob)
+		// Job is still actively running at this point because the query wouldn't
+		// modify that column for a running job:
+		require.Equal(t, rivertype.JobStateRunning, updatedJob.State)
+
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobCancelled, event.Kind)
+		require.Equal(t, JobStateCancelled, event.Job.State)
+		require.WithinDuration(t, time.Now(), *event.Job.FinalizedAt, 2*time.Second)
+
+		jobAfterCancel, err := client.JobGet(ctx, insertedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, jobAfterCancel.State)
+		require.WithinDuration(t, time.Now(), *jobAfterCancel.FinalizedAt, 2*time.Second)
 	}
 
-	t.Run("not started", func(t *testing.T) {
+	t.Run("CancelRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		cancelRunningJobTestHelper(t, func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error) {
+			return client.JobCancel(ctx, jobID)
+		})
+	})
+
+	t.Run("CancelRunningJobInTx", func(t *testing.T) {
+		t.Parallel()
+
+		cancelRunningJobTestHelper(t, func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error) {
+			var (
+				job *rivertype.JobRow
+				err error
+			)
+			txErr := pgx.BeginFunc(ctx, dbPool, func(tx pgx.Tx) error {
+				job, err = client.JobCancelTx(ctx, tx, jobID)
+				return err
+			})
+			require.NoError(t, txErr)
+			return job, err
+		})
+	})
+
+	t.Run("CancelManyRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		cancelRunningJobTestHelper(t, func(ctx context.Context, dbPool *pgxpool.Pool, client *Client[pgx.Tx], jobID int64) (*rivertype.JobRow, error) {
+			numCancelled, err := client.JobCancelMany(ctx, NewJobFilterParams())
+			if err != nil {
+				return nil, err
+			}
+			require.Equal(t, 1, numCancelled)
+			return client.JobGet(ctx, jobID)
+		})
+	})
+
+	t.Run("CancelScheduledJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		jobStartedChan := make(chan int64)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		startClient(ctx, t, client)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(5 * time.Minute)})
+		require.NoError(t, err)
+
+		// Cancel the job:
+		updatedJob, err := client.JobCancel(ctx, insertedJob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updatedJob)
+		require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
+		require.WithinDuration(t, time.Now(), *updatedJob.FinalizedAt, 2*time.Second)
+	})
+
+	t.Run("CancelNonExistentJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		startClient(ctx, t, client)
+
+		// Cancel an unknown job ID:
+		jobAfter, err := client.JobCancel(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, jobAfter)
+
+		// Cancel an unknown job ID, within a transaction:
+		err = dbutil.WithTx(ctx, client.driver.GetExecutor(), func(ctx context.Context, exec riverdriver.ExecutorTx) error {
+			jobAfter, err := exec.JobCancel(ctx, &riverdriver.JobCancelParams{ID: 0})
+			require.ErrorIs(t, err, ErrNotFound)
+			require.Nil(t, jobAfter)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("AlternateSchema", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		// Reconfigure the pool with an alternate schema, initialize a new pool
+		dbPoolConfig := bundle.dbPool.Config() // a copy of the original config
+		dbPoolConfig.ConnConfig.RuntimeParams["search_path"] = "alternate_schema"
+
+		dbPool, err := pgxpool.NewWithConfig(ctx, dbPoolConfig)
+		require.NoError(t, err)
+		t.Cleanup(dbPool.Close)
+
+		client, err := NewClient(riverpgxv5.New(dbPool), newTestConfig(t, nil))
+		require.NoError(t, err)
+
+		// We don't actually verify that River's functional on another schema so
+		// that we don't have to raise and migrate it. We cheat a little by
+		// configuring a different schema and then verifying that we can't find
+		// a `river_job` to confirm we're point there.
+		_, err = client.Insert(ctx, &noOpArgs{}, nil)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, pgerrcode.UndefinedTable, pgErr.Code)
+		// PgError has SchemaName and TableName properties, but unfortunately
+		// neither contain a useful value in this case.
+		require.Equal(t, `relation "river_job" does not exist`, pgErr.Message)
+	})
+
+	t.Run("StopAndCancel", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		jobStartedChan := make(chan int64)
+		jobDoneChan := make(chan struct{})
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
+			<-ctx.Done()
+			require.ErrorIs(t, context.Cause(ctx), rivercommon.ErrShutdown)
+			close(jobDoneChan)
+			return nil
+		}))
+
+		startClient(ctx, t, client)
+
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, nil)
+		require.NoError(t, err)
+
+		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
+		require.Equal(t, insertedJob.ID, startedJobID)
+
+		select {
+		case <-client.Stopped():
+			t.Fatal("expected client to not be stopped yet")
+		default:
+		}
+
+		require.NoError(t, client.StopAndCancel(ctx))
+
+		riverinternaltest.WaitOrTimeout(t, client.Stopped())
+	})
+}
+
+func Test_Client_Stop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Performs continual job insertion on a number of background goroutines.
+	// Returns a `finish` function that should be deferred to stop insertion and
+	// safely stop goroutines.
+	doParallelContinualInsertion := func(ctx context.Context, t *testing.T, client *Client[pgx.Tx]) func() {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(ctx)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					default:
+					}
+
+					_, err := client.Insert(ctx, callbackArgs{}, nil)
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					require.NoError(t, err)
+
+					// Sleep a brief time between inserts.
+					client.baseService.CancellableSleepRandomBetween(ctx, 1*time.Microsecond, 10*time.Millisecond)
+				}
+			}()
+		}
+
+		return func() {
+			cancel()
+			wg.Wait()
+		}
+	}
+
+	t.Run("not started", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		client := newTestClient(t, dbPool, newTestConfig(t, nil))
+
+		err := client.Stop(ctx)
+		require.Error(t, err)
+		require.Equal(t, "client not started", err.Error())
+	})
+
+	t.Run("no jobs in progress", func(t *testing.T) {
+		t.Parallel()
+		client := runNewTestClient(ctx, t, newTestConfig(t, nil))
+
+		// Should shut down quickly:
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		require.NoError(t, client.Stop(ctx))
+	})
+
+	t.Run("jobs in progress, completing promptly", func(t *testing.T) {
+		t.Parallel()
+		require := require.New(t)
+		doneCh := make(chan struct{})
+		startedCh := make(chan int64)
+
+		client := runNewTestClient(ctx, t, newTestConfig(t, makeAwaitCallback(startedCh, doneCh)))
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		// enqueue job:
+		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
+		require.NoError(err)
+
+		var startedJobID int64
+		select {
+		case startedJobID = <-startedCh:
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("timed out waiting for job to start")
+		}
+		require.Equal(insertedJob.ID, startedJobID)
+
+		// Should not shut down immediately, not until jobs are given the signal to
+		// complete:
+		go func() {
+			<-time.After(50 * time.Millisecond)
+			close(doneCh)
+		}()
+
+		require.NoError(client.Stop(ctx))
+	})
+
+	t.Run("jobs in progress, failing to complete before stop context", func(t *testing.T) {
+		t.Parallel()
+
+		jobDoneChan := make(chan struct{})
+		jobStartedChan := make(chan int64)
+
+		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobStartedChan <- job.ID:
+			}
+
+			select {
+			case <-ctx.Done():
+				require.FailNow(t, "Did not expect job to be cancelled")
+			case <-jobDoneChan:
+			}
+
+			return nil
+		}
+
+		client := runNewTestClient(ctx, t, newTestConfig(t, callbackFunc))
+
+		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
+		require.NoError(t, err)
+
+		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
+		require.Equal(t, insertedJob.ID, startedJobID)
+
+		go func() {
+			<-time.After(100 * time.Millisecond)
+			close(jobDoneChan)
+		}()
+
+		t.Logf("Shutting down client with timeout, but while jobs are still in progress")
+
+		// Context should expire while jobs are still in progress:
+		stopCtx, stopCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		t.Cleanup(stopCancel)
+
+		err = client.Stop(stopCtx)
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		select {
+		case <-jobDoneChan:
+			require.FailNow(t, "Expected Stop to return before job was done")
+		default:
+		}
+	})
+
+	t.Run("with continual insertion, no jobs are left running", func(t *testing.T) {
+		t.Parallel()
+
+		startedCh := make(chan int64)
+		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
+			select {
+			case startedCh <- job.ID:
+			default:
+			}
+			return nil
+		}
+
+		config := newTestConfig(t, callbackFunc)
+		client := runNewTestClient(ctx, t, config)
+
+		finish := doParallelContinualInsertion(ctx, t, client)
+		defer finish()
+
+		// Wait for at least one job to start
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+
+		require.NoError(t, client.Stop(ctx))
+
+		runningJobs, err := client.JobList(ctx, NewJobListParams().State(rivertype.JobStateRunning))
+		require.NoError(t, err)
+		require.Empty(t, runningJobs, "expected no jobs to be left running")
+	})
+
+	t.Run("WithSubscriber", func(t *testing.T) {
+		t.Parallel()
+
+		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error { return nil }
+
+		client := runNewTestClient(ctx, t, newTestConfig(t, callbackFunc))
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
+		defer cancel()
+
+		finish := doParallelContinualInsertion(ctx, t, client)
+		defer finish()
+
+		// Arbitrarily wait for 100 jobs to come through.
+		for i := 0; i < 100; i++ {
+			riverinternaltest.WaitOrTimeout(t, subscribeChan)
+		}
+
+		require.NoError(t, client.Stop(ctx))
+	})
+}
+
+func Test_Client_StopAndCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("jobs in progress, only completing when context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		jobDoneChan := make(chan struct{})
+		jobStartedChan := make(chan int64)
+
+		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
+			defer close(jobDoneChan)
+
+			// indicate the job has started, unless context is already done:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobStartedChan <- job.ID:
+			}
+
+			t.Logf("Job waiting for context cancellation")
+			defer t.Logf("Job finished")
+
+			select {
+			case <-ctx.Done(): // don't stop running until context is canceled
+			case <-time.After(10 * time.Second):
+				require.FailNow(t, "Job should've been cancelled by now")
+			}
+
+			return nil
+		}
+		config := newTestConfig(t, callbackFunc)
+		client := runNewTestClient(ctx, t, config)
+
+		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
+		require.NoError(t, err)
+
+		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
+		require.Equal(t, insertedJob.ID, startedJobID)
+
+		t.Logf("Initiating hard stop, while jobs are still in progress")
+
+		stopCtx, stopCancel := context.WithTimeout(ctx, time.Second)
+		t.Cleanup(stopCancel)
+
+		stopStartedAt := time.Now()
+
+		err = client.StopAndCancel(stopCtx)
+		require.NoError(t, err)
+
+		t.Logf("Waiting on job to be done")
+
+		select {
+		case <-jobDoneChan:
+		default:
+			require.FailNow(t, "Expected job to be done before stop returns")
+		}
+
+		// Stop should be ~immediate:
+		//
+		// TODO: client stop seems to take a widely variable amount of time,
+		// between 1ms and >50ms, due to the JobComplete query taking that long.
+		// Investigate and solve that if we can, or consider reworking this test.
+		require.WithinDuration(t, time.Now(), stopStartedAt, 200*time.Millisecond)
+	})
+}
+
+type callbackWithCustomTimeoutArgs struct {
+	TimeoutValue time.Duration `json:"timeout"`
+}
+
+func (callbackWithCustomTimeoutArgs) Kind() string { return "callbackWithCustomTimeout" }
+
+type callbackWorkerWithCustomTimeout struct {
+	WorkerDefaults[callbackWithCustomTimeoutArgs]
+	fn func(context.Context, *Job[callbackWithCustomTimeoutArgs]) error
+}
+
+func (w *callbackWorkerWithCustomTimeout) Work(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
+	return w.fn(ctx, job)
+}
+
+func (w *callbackWorkerWithCustomTimeout) Timeout(job *Job[callbackWithCustomTimeoutArgs]) time.Duration {
+	return job.Args.TimeoutValue
+}
+
+func Test_Client_JobContextInheritsFromProvidedContext(t *testing.T) {
+	t.Parallel()
+
+	deadline := time.Now().Add(2 * time.Minute)
+
+	require := require.New(t)
+	jobCtxCh := make(chan context.Context)
+	doneCh := make(chan struct{})
+	close(doneCh)
+
+	callbackFunc := func(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
+		// indicate the job has started, unless context is already done:
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case jobCtxCh <- ctx:
+		}
+		return nil
+	}
+	config := newTestConfig(t, nil)
+	AddWorker(config.Workers, &callbackWorkerWithCustomTimeout{fn: callbackFunc})
+
+	// Set a deadline and a value on the context for the client so we can verify
+	// it's propagated through to the job:
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	t.Cleanup(cancel)
+
+	type customContextKey string
+	ctx = context.WithValue(ctx, customContextKey("BestGoPostgresQueue"), "River")
+	client := runNewTestClient(ctx, t, config)
+
+	insertCtx, insertCancel := context.WithTimeout(ctx, time.Second)
+	t.Cleanup(insertCancel)
+
+	// enqueue job:
+	_, err := client.Insert(insertCtx, callbackWithCustomTimeoutArgs{TimeoutValue: 5 * time.Minute}, nil)
+	require.NoError(err)
+
+	var jobCtx context.Context
+	select {
+	case jobCtx = <-jobCtxCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for job to start")
+	}
+
+	require.Equal("River", jobCtx.Value(customContextKey("BestGoPostgresQueue")), "job should persist the context value from the client context")
+	jobDeadline, ok := jobCtx.Deadline()
+	require.True(ok, "job should have a deadline")
+	require.Equal(deadline, jobDeadline, "job should have the same deadline as the client context (shorter than the job's timeout)")
+}
+
+func Test_Client_ClientFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var clientResult *Client[pgx.Tx]
+	jobDoneChan := make(chan struct{})
+	config := newTestConfig(t, func(ctx context.Context, j *Job[callbackArgs]) error {
+		clientResult = ClientFromContext[pgx.Tx](ctx)
+		close(jobDoneChan)
+		return nil
+	})
+	client := runNewTestClient(ctx, t, config)
+
+	_, err := client.Insert(ctx, callbackArgs{}, nil)
+	require.NoError(t, err)
+
+	riverinternaltest.WaitOrTimeout(t, jobDoneChan)
+
+	require.NotNil(t, clientResult)
+	require.Equal(t, client, clientResult)
+}
+
+func Test_Client_Insert(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct{}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{}
+	}
+
+	t.Run("Succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, jobRow.Attempt)
+		require.Equal(t, rivercommon.MaxAttemptsDefault, jobRow.MaxAttempts)
+		require.JSONEq(t, "{}", string(jobRow.Metadata))
+		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
+		require.Equal(t, PriorityDefault, jobRow.Priority)
+		require.Equal(t, QueueDefault, jobRow.Queue)
+		require.Equal(t, []string{}, jobRow.Tags)
+	})
+
+	t.Run("WithInsertHooks", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.InsertHooks = []InsertHook{
+			InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+				params.Metadata = []byte(`{"tenant_id": "tenant_123"}`)
+				params.Tags = append(params.Tags, "hooked")
+				return nil
+			}),
+		}
+		client := newTestClient(t, dbPool, config)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"tenant_id": "tenant_123"}`, string(jobRow.Metadata))
+		require.Equal(t, []string{"hooked"}, jobRow.Tags)
+	})
+
+	t.Run("InsertHookRejectsInsert", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		hookErr := errors.New("insert rejected")
+		config.InsertHooks = []InsertHook{
+			InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+				return hookErr
+			}),
+		}
+		client := newTestClient(t, dbPool, config)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.ErrorIs(t, err, hookErr)
+		require.Nil(t, jobRow)
+	})
+
+	t.Run("WithInsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{
+			MaxAttempts: 17,
+			Metadata:    []byte(`{"foo": "bar"}`),
+			Priority:    3,
+			Queue:       "custom",
+			Tags:        []string{"custom"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, jobRow.Attempt)
+		require.Equal(t, 17, jobRow.MaxAttempts)
+		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
+		require.JSONEq(t, `{"foo": "bar"}`, string(jobRow.Metadata))
+		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
+		require.Equal(t, 3, jobRow.Priority)
+		require.Equal(t, "custom", jobRow.Queue)
+		require.Equal(t, []string{"custom"}, jobRow.Tags)
+	})
+
+	t.Run("WithInsertOptsScheduledAtZeroTime", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{
+			ScheduledAt: time.Time{},
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
+	})
+
+	t.Run("ErrorsOnMissingDependency", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		dependencyJob, err := client.Insert(ctx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		jobRow, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{DependsOn: []int64{dependencyJob.ID, 123_456_789}})
+		require.ErrorIs(t, err, ErrNotFound)
+		require.EqualError(t, err, "job dependency 123456789 doesn't exist: not found")
+		require.Nil(t, jobRow)
+	})
+
+	t.Run("ErrorsOnInvalidQueueName", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{Queue: "invalid*queue"})
+		require.ErrorContains(t, err, "queue name is invalid")
+	})
+
+	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		_, _ = setup(t)
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riverinternaltest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, err = client.Insert(ctx, &noOpArgs{}, nil)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+
+	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
+		var unknownJobKindErr *UnknownJobKindError
+		require.ErrorAs(t, err, &unknownJobKindErr)
+		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+	})
+
+	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		client.config.Workers = nil
+
+		_, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func Test_Client_InsertTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		tx pgx.Tx
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		tx, err := dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		return client, &testBundle{
+			tx: tx,
+		}
+	}
+
+	t.Run("Succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		jobRow, err := client.InsertTx(ctx, bundle.tx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, jobRow.Attempt)
+		require.Equal(t, rivercommon.MaxAttemptsDefault, jobRow.MaxAttempts)
+		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
+		require.Equal(t, PriorityDefault, jobRow.Priority)
+		require.Equal(t, QueueDefault, jobRow.Queue)
+		require.Equal(t, []string{}, jobRow.Tags)
+
+		// Job is not visible outside of the transaction.
+		_, err = client.JobGet(ctx, jobRow.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("WithInsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		jobRow, err := client.InsertTx(ctx, bundle.tx, &noOpArgs{}, &InsertOpts{
+			MaxAttempts: 17,
+			Priority:    3,
+			Queue:       "custom",
+			Tags:        []string{"custom"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, jobRow.Attempt)
+		require.Equal(t, 17, jobRow.MaxAttempts)
+		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
+		require.Equal(t, 3, jobRow.Priority)
+		require.Equal(t, "custom", jobRow.Queue)
+		require.Equal(t, []string{"custom"}, jobRow.Tags)
+	})
+
+	// A client's allowed to send nil to their driver so they can, for example,
+	// easily use test transactions in their test suite.
+	t.Run("WithDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riverinternaltest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, err = client.InsertTx(ctx, bundle.tx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_, err := client.InsertTx(ctx, bundle.tx, &unregisteredJobArgs{}, nil)
+		var unknownJobKindErr *UnknownJobKindError
+		require.ErrorAs(t, err, &unknownJobKindErr)
+		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+	})
+
+	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		client.config.Workers = nil
+
+		_, err := client.InsertTx(ctx, bundle.tx, &unregisteredJobArgs{}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func Test_Client_InsertMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct{}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{}
+	}
+
+	t.Run("SucceedsWithMultipleJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Queue: "foo", Priority: 2}},
+			{Args: noOpArgs{}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind()) //nolint:goconst
+	})
+
+	t.Run("WithInsertOptsScheduledAtZeroTime", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: &noOpArgs{}, InsertOpts: &InsertOpts{ScheduledAt: time.Time{}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1, "Expected to find exactly one job of kind: "+(noOpArgs{}).Kind())
+		jobRow := jobs[0]
+		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
+	})
+
+	t.Run("WithInsertHooks", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.InsertHooks = []InsertHook{
+			InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+				params.Queue = "from_hook"
+				return nil
+			}),
+		}
+		client := newTestClient(t, dbPool, config)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Queue: "foo"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		require.Equal(t, "from_hook", jobs[0].Queue)
+		require.Equal(t, "from_hook", jobs[1].Queue)
+	})
+
+	t.Run("ErrorsOnInvalidQueueName", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: &noOpArgs{}, InsertOpts: &InsertOpts{Queue: "invalid*queue"}},
+		})
+		require.ErrorContains(t, err, "queue name is invalid")
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		_, _ = setup(t)
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riverinternaltest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{}},
+		})
+		require.ErrorIs(t, err, errNoDriverDBPool)
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("ErrorsWithZeroJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{})
+		require.EqualError(t, err, "no jobs to insert")
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: unregisteredJobArgs{}},
+		})
+		var unknownJobKindErr *UnknownJobKindError
+		require.ErrorAs(t, err, &unknownJobKindErr)
+		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		client.config.Workers = nil
+
+		_, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: unregisteredJobArgs{}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("ErrorsOnInsertOptsUniqueOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		count, err := client.InsertMany(ctx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}},
+		})
+		require.EqualError(t, err, "UniqueOpts are not supported for batch inserts")
+		require.Equal(t, int64(0), count)
+	})
+}
+
+func Test_Client_InsertManyTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		tx pgx.Tx
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		tx, err := dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		return client, &testBundle{
+			tx: tx,
+		}
+	}
+
+	t.Run("SucceedsWithMultipleJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Queue: "foo", Priority: 2}},
+			{Args: noOpArgs{}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		jobs, err := client.driver.UnwrapExecutor(bundle.tx).JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind())
+
+		require.NoError(t, bundle.tx.Commit(ctx))
+
+		// Ensure the jobs are visible outside the transaction:
+		jobs, err = client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind())
+	})
+
+	t.Run("DependsOnJobInsertedInSameTx", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		dependencyJob, err := client.InsertTx(ctx, bundle.tx, &noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{DependsOn: []int64{dependencyJob.ID}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	})
+
+	t.Run("ErrorsOnMissingDependency", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}},
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{DependsOn: []int64{123_456_789}}},
+		})
+		require.ErrorIs(t, err, ErrNotFound)
+
+		jobs, err := client.driver.UnwrapExecutor(bundle.tx).JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("SupportsScheduledJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		startClient(ctx, t, client)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{{noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Minute)}}})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		insertedJobs, err := client.driver.UnwrapExecutor(bundle.tx).JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		require.NoError(t, err)
+		require.Len(t, insertedJobs, 1)
+		require.Equal(t, rivertype.JobStateScheduled, insertedJobs[0].State)
+		require.WithinDuration(t, time.Now().Add(time.Minute), insertedJobs[0].ScheduledAt, 2*time.Second)
+	})
+
+	// A client's allowed to send nil to their driver so they can, for example,
+	// easily use test transactions in their test suite.
+	t.Run("WithDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riverinternaltest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	})
+
+	t.Run("ErrorsWithZeroJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{})
+		require.EqualError(t, err, "no jobs to insert")
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: unregisteredJobArgs{}},
+		})
+		var unknownJobKindErr *UnknownJobKindError
+		require.ErrorAs(t, err, &unknownJobKindErr)
+		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+		require.Equal(t, int64(0), count)
+	})
+
+	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		client.config.Workers = nil
+
+		_, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: unregisteredJobArgs{}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("ErrorsOnInsertOptsUniqueOpts", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
+			{Args: noOpArgs{}, InsertOpts: &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}},
+		})
+		require.EqualError(t, err, "UniqueOpts are not supported for batch inserts")
+		require.Equal(t, int64(0), count)
+	})
+}
+
+func Test_Client_JobCancelMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.jobManyBatchSize = 2 // small so that multiple batches are exercised
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("CancelsMatchingJobsInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		matchingJobs := make([]*rivertype.JobRow, 5)
+		for i := range matchingJobs {
+			matchingJobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("bad_kind")})
+		}
+		otherKindJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("good_kind")})
+		completedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Kind: ptrutil.Ptr("bad_kind"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		numCancelled, err := client.JobCancelMany(ctx, NewJobFilterParams().Kinds("bad_kind"))
+		require.NoError(t, err)
+		require.Equal(t, 5, numCancelled)
+
+		for _, job := range matchingJobs {
+			updatedJob, err := client.JobGet(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
+			require.NotNil(t, updatedJob.FinalizedAt)
+		}
+
+		updatedJob, err := client.JobGet(ctx, otherKindJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+
+		// Already finalized, so unchanged.
+		updatedJob, err = client.JobGet(ctx, completedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, updatedJob.State)
+	})
+
+	t.Run("TxVariantAlsoCancelsJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("bad_queue")})
+
+		var numCancelled int
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			numCancelled, err = client.JobCancelManyTx(ctx, tx, NewJobFilterParams().Queues("bad_queue"))
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numCancelled)
+
+		updatedJob, err := client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, updatedJob.State)
+	})
+
+	t.Run("ErrorOnNilParams", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobCancelMany(ctx, nil)
+		require.EqualError(t, err, "params must not be nil; use NewJobFilterParams to affect all jobs")
+	})
+}
+
+func Test_Client_JobCountByState(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("CountsJobsInEachState", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		countByState, err := client.JobCountByState(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[rivertype.JobState]int{
+			rivertype.JobStateAvailable: 2,
+			rivertype.JobStateCancelled: 0,
+			rivertype.JobStateCompleted: 0,
+			rivertype.JobStateDiscarded: 0,
+			rivertype.JobStatePending:   0,
+			rivertype.JobStateRetryable: 0,
+			rivertype.JobStateRunning:   1,
+			rivertype.JobStateScheduled: 0,
+		}, countByState)
+	})
+
+	t.Run("TxVariantCountsUncommittedJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		_, err = client.InsertTx(ctx, tx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		countByState, err := client.JobCountByStateTx(ctx, tx)
+		require.NoError(t, err)
+		require.Equal(t, 1, countByState[rivertype.JobStateAvailable])
+
+		countByState, err = client.JobCountByState(ctx)
+		require.NoError(t, err)
+		require.Zero(t, countByState[rivertype.JobStateAvailable])
+	})
+}
+
+func Test_Client_JobDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("DeletesANonRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
+
+		deletedJob, err := client.JobDelete(ctx, newJob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, deletedJob)
+		require.Equal(t, newJob.ID, deletedJob.ID)
+
+		_, err = client.JobGet(ctx, newJob.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("TxVariantAlsoDeletesJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		var deletedJob *rivertype.JobRow
+		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			deletedJob, err = client.JobDeleteTx(ctx, tx, newJob.ID)
+			return err
+		})
+		require.NoError(t, err)
+		require.NotNil(t, deletedJob)
+		require.Equal(t, newJob.ID, deletedJob.ID)
+
+		_, err = client.JobGet(ctx, newJob.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ReturnsErrJobRunningIfJobIsRunning", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		deletedJob, err := client.JobDelete(ctx, runningJob.ID)
+		require.ErrorIs(t, err, ErrJobRunning)
+		require.Nil(t, deletedJob)
+
+		// Job is still there.
+		_, err = client.JobGet(ctx, runningJob.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		deletedJob, err := client.JobDelete(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, deletedJob)
+	})
+}
+
+func Test_Client_JobDeleteMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.jobManyBatchSize = 2 // small so that multiple batches are exercised
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("DeletesMatchingJobsInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now()
+
+		matchingJobs := make([]*rivertype.JobRow, 5)
+		for i := range matchingJobs {
+			matchingJobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		}
+		availableJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		numDeleted, err := client.JobDeleteMany(ctx, NewJobFilterParams().States(rivertype.JobStateDiscarded))
+		require.NoError(t, err)
+		require.Equal(t, 5, numDeleted)
+
+		for _, job := range matchingJobs {
+			_, err := client.JobGet(ctx, job.ID)
+			require.ErrorIs(t, err, ErrNotFound)
+		}
+
+		_, err = client.JobGet(ctx, availableJob.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("DoesNotDeleteRunningJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		availableJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		numDeleted, err := client.JobDeleteMany(ctx, NewJobFilterParams())
+		require.NoError(t, err)
+		require.Equal(t, 1, numDeleted)
+
+		_, err = client.JobGet(ctx, availableJob.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.JobGet(ctx, runningJob.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("TxVariantAlsoDeletesJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"tenant": "a"}`)})
+		otherJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"tenant": "b"}`)})
+
+		var numDeleted int
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			numDeleted, err = client.JobDeleteManyTx(ctx, tx, NewJobFilterParams().Metadata(`{"tenant": "a"}`))
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numDeleted)
+
+		_, err = client.JobGet(ctx, job.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.JobGet(ctx, otherJob.ID)
+		require.NoError(t, err)
+	})
+}
+
+func Test_Client_JobGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct{}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{}
+	}
+
+	t.Run("FetchesAnExistingJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		job, err := client.JobGet(ctx, newJob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+
+		require.Equal(t, newJob.ID, job.ID)
+		require.Equal(t, newJob.State, job.State)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		job, err := client.JobGet(ctx, 0)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, job)
+	})
+}
+
+func Test_Client_JobList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			exec: client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("FiltersByKind", func(t *testing.T) { //nolint:dupl
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1")})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1")})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_2")})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().Kinds("test_kind_1"))
+		require.NoError(t, err)
+		// jobs ordered by ScheduledAt ASC by default
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().Kinds("test_kind_2"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByQueue", func(t *testing.T) { //nolint:dupl
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_2")})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().Queues("queue_1"))
+		require.NoError(t, err)
+		// jobs ordered by ScheduledAt ASC by default
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().Queues("queue_2"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByState", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateAvailable))
+		require.NoError(t, err)
+		// jobs ordered by ScheduledAt ASC by default
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State(JobStateRunning))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("SortsAvailableRetryableAndScheduledJobsByScheduledAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		states := map[rivertype.JobState]rivertype.JobState{
+			JobStateAvailable: rivertype.JobStateAvailable,
+			JobStateRetryable: rivertype.JobStateRetryable,
+			JobStateScheduled: rivertype.JobStateScheduled,
+		}
+		for state, dbState := range states {
+			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), ScheduledAt: &now})
+			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+
+			jobs, err := client.JobList(ctx, NewJobListParams().State(state))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+			jobs, err = client.JobList(ctx, NewJobListParams().State(state).OrderBy(JobListOrderByTime, SortOrderDesc))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		}
+	})
+
+	t.Run("SortsCancelledCompletedAndDiscardedJobsByFinalizedAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		states := map[rivertype.JobState]rivertype.JobState{
+			JobStateCancelled: rivertype.JobStateCancelled,
+			JobStateCompleted: rivertype.JobStateCompleted,
+			JobStateDiscarded: rivertype.JobStateDiscarded,
+		}
+		for state, dbState := range states {
+			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), FinalizedAt: ptrutil.Ptr(now.Add(-10 * time.Second))})
+			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), FinalizedAt: ptrutil.Ptr(now.Add(-15 * time.Second))})
+
+			jobs, err := client.JobList(ctx, NewJobListParams().State(state))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+			jobs, err = client.JobList(ctx, NewJobListParams().State(state).OrderBy(JobListOrderByTime, SortOrderDesc))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		}
+	})
+
+	t.Run("SortsRunningJobsByAttemptedAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: &now})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateRunning))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State(JobStateRunning).OrderBy(JobListOrderByTime, SortOrderDesc))
+		require.NoError(t, err)
+		// Sort order was explicitly reversed:
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("WithNilParamsFiltersToAvailableByDefault", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: &now})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		jobs, err := client.JobList(ctx, nil)
+		require.NoError(t, err)
+		// sort order is switched by ScheduledAt values:
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByMultipleStates", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().States(rivertype.JobStateAvailable, rivertype.JobStateRetryable))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByIDs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID, job3.ID))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByPriorities", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(1)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(2)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().Priorities(2, 3))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByTags", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{"tag1"}})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{"tag1", "tag2"}})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{"tag3"}})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().TagsAny("tag2", "tag3"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().TagsAll("tag1", "tag2"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().TagsAll("tag1"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByTimeRanges", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-3 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(-3 * time.Hour))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-2 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(-2 * time.Hour))})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Hour))})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job5 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().CreatedAfter(now.Add(-150*time.Minute)).CreatedBefore(now.Add(-90*time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().ScheduledBefore(now.Add(-90*time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().ScheduledAfter(now.Add(-90*time.Minute)).ScheduledBefore(now.Add(-30*time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State(rivertype.JobStateCompleted).FinalizedAfter(now.Add(-90*time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job5.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State("").FinalizedBefore(now.Add(-90*time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job4.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByAttempt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(0)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(5)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().AttemptAtLeast(2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().AttemptAtLeast(1).AttemptAtMost(4))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByAttemptedBy", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_2")})
+
+		_, err := bundle.exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+			AttemptedBy: "other_client",
+			Max:         1,
+			Queue:       "queue_1",
+		})
+		require.NoError(t, err)
+
+		jobs, err := client.JobList(ctx, NewJobListParams().State("").AttemptedBy("other_client", "unused_client"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByArgs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 1, "name": "a"}`)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 2, "name": "b"}`)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().Args(`{"customer_id": 1}`))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("OrdersByIDWithPagination", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		// Scheduled in reverse order of insertion so that ordering by time
+		// would give a different result.
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute))})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(-2 * time.Minute))})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByID, SortOrderAsc).First(2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByID, SortOrderAsc).After(JobListCursorFromJob(job2)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByID, SortOrderDesc).After(JobListCursorFromJob(job3)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("OrdersByPriorityWithPagination", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(1)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3)})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(2)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job4.ID, job1.ID, job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderAsc).After(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByPriority, SortOrderDesc).After(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job4.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("OrdersByCreatedAtWithPagination", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Minute))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-3 * time.Minute))})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-2 * time.Minute))})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByCreatedAt, SortOrderAsc))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByCreatedAt, SortOrderAsc).After(JobListCursorFromJob(job3)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("OrdersMixedStatesByCreatedAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		// States that use different time fields fall back to created_at.
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)), FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().States(rivertype.JobStateAvailable, rivertype.JobStateCompleted))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().States(rivertype.JobStateAvailable, rivertype.JobStateCompleted).After(JobListCursorFromJob(job2)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("PaginatesWithAfter", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: &now})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: &now})
+		job5 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		job6 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: &now})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().After(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State(rivertype.JobStateRunning).After(JobListCursorFromJob(job3)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job4.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State(rivertype.JobStateCompleted).After(JobListCursorFromJob(job5)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job6.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("MetadataOnly", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"foo": "bar"}`)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"baz": "value"}`)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"baz": "value"}`)})
+
+		jobs, err := client.JobList(ctx, NewJobListParams().State("").Metadata(`{"foo": "bar"}`))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		jobs, err = client.JobList(ctx, NewJobListParams().State("").Metadata(`{"baz": "value"}`).OrderBy(JobListOrderByTime, SortOrderDesc))
+		require.NoError(t, err)
+		// Sort order was explicitly reversed:
+		require.Equal(t, []int64{job3.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("WithCancelledContext", func(t *testing.T) {
 		t.Parallel()
 
+		client, _ := setup(t)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel() // cancel immediately
+
+		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateRunning))
+		require.ErrorIs(t, context.Canceled, err)
+		require.Empty(t, jobs)
+	})
+}
+
+func Test_Client_JobRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
 		dbPool := riverinternaltest.TestDB(ctx, t)
-		client := newTestClient(t, dbPool, newTestConfig(t, nil))
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
 
-		err := client.Stop(ctx)
+		return client, &testBundle{dbPool: dbPool}
+	}
+
+	t.Run("UpdatesAJobScheduledInTheFutureToBeImmediatelyAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
+
+		job, err := client.JobRetry(ctx, newJob.ID)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+		require.WithinDuration(t, time.Now().UTC(), job.ScheduledAt, 5*time.Second)
+	})
+
+	t.Run("TxVariantAlsoUpdatesJobToAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
+
+		var jobAfter *rivertype.JobRow
+
+		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			jobAfter, err = client.JobRetryTx(ctx, tx, newJob.ID)
+			return err
+		})
+		require.NoError(t, err)
+		require.NotNil(t, jobAfter)
+
+		require.Equal(t, rivertype.JobStateAvailable, jobAfter.State)
+		require.WithinDuration(t, time.Now().UTC(), jobAfter.ScheduledAt, 5*time.Second)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		job, err := client.JobRetry(ctx, 0)
 		require.Error(t, err)
-		require.Equal(t, "client not started", err.Error())
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, job)
 	})
+}
 
-	t.Run("no jobs in progress", func(t *testing.T) {
+func Test_Client_JobRetryMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.jobManyBatchSize = 2 // small so that multiple batches are exercised
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("RetriesMatchingJobsInBatches", func(t *testing.T) {
 		t.Parallel()
-		client := runNewTestClient(ctx, t, newTestConfig(t, nil))
 
-		// Should shut down quickly:
-		ctx, cancel := context.WithTimeout(ctx, time.Second)
-		defer cancel()
+		client, bundle := setup(t)
 
-		require.NoError(t, client.Stop(ctx))
+		now := time.Now()
+
+		matchingJobs := make([]*rivertype.JobRow, 5)
+		for i := range matchingJobs {
+			matchingJobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(25), FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		}
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		numRetried, err := client.JobRetryMany(ctx, NewJobFilterParams().CreatedBefore(time.Now().Add(time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, 5, numRetried)
+
+		for _, job := range matchingJobs {
+			updatedJob, err := client.JobGet(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+			require.Nil(t, updatedJob.FinalizedAt)
+			require.Equal(t, job.MaxAttempts+1, updatedJob.MaxAttempts)
+		}
+
+		// Running jobs aren't touched.
+		updatedJob, err := client.JobGet(ctx, runningJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, updatedJob.State)
 	})
 
-	t.Run("jobs in progress, completing promptly", func(t *testing.T) {
+	t.Run("TxVariantAlsoRetriesJobs", func(t *testing.T) {
 		t.Parallel()
-		require := require.New(t)
-		doneCh := make(chan struct{})
-		startedCh := make(chan int64)
 
-		client := runNewTestClient(ctx, t, newTestConfig(t, makeAwaitCallback(startedCh, doneCh)))
+		client, bundle := setup(t)
 
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(time.Now().Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStateScheduled)})
 
-		// enqueue job:
-		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
-		require.NoError(err)
+		var numRetried int
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			numRetried, err = client.JobRetryManyTx(ctx, tx, NewJobFilterParams().States(rivertype.JobStateScheduled))
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numRetried)
 
-		var startedJobID int64
-		select {
-		case startedJobID = <-startedCh:
-		case <-time.After(500 * time.Millisecond):
-			t.Fatal("timed out waiting for job to start")
+		updatedJob, err := client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+	})
+}
+
+func Test_Client_JobUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool    *pgxpool.Pool
+		doneCh    chan struct{}
+		exec      riverdriver.Executor
+		startedCh chan int64
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		var (
+			dbPool    = riverinternaltest.TestDB(ctx, t)
+			doneCh    = make(chan struct{})
+			startedCh = make(chan int64)
+		)
+
+		config := newTestConfig(t, makeAwaitCallback(startedCh, doneCh))
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool:    dbPool,
+			doneCh:    doneCh,
+			exec:      client.driver.GetExecutor(),
+			startedCh: startedCh,
 		}
-		require.Equal(insertedJob.ID, startedJobID)
+	}
 
-		// Should not shut down immediately, not until jobs are given the signal to
-		// complete:
-		go func() {
-			<-time.After(50 * time.Millisecond)
-			close(doneCh)
-		}()
+	t.Run("UpdatesProperties", func(t *testing.T) {
+		t.Parallel()
 
-		require.NoError(client.Stop(ctx))
+		client, _ := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{
+			Metadata: []byte(`{"foo": "bar", "baz": "qux"}`),
+			Tags:     []string{"tag1"},
+		})
+		require.NoError(t, err)
+
+		updatedJob, err := client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{
+			MaxAttempts: 7,
+			Metadata:    []byte(`{"baz": "new", "extra": 1}`),
+			Priority:    3,
+			Queue:       "other_queue",
+			Tags:        []string{"tag2", "tag3"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 7, updatedJob.MaxAttempts)
+		require.JSONEq(t, `{"foo": "bar", "baz": "new", "extra": 1}`, string(updatedJob.Metadata))
+		require.Equal(t, 3, updatedJob.Priority)
+		require.Equal(t, "other_queue", updatedJob.Queue)
+		require.Equal(t, []string{"tag2", "tag3"}, updatedJob.Tags)
+
+		// Properties not specified are left alone.
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+		require.WithinDuration(t, newJob.ScheduledAt, updatedJob.ScheduledAt, time.Microsecond)
 	})
 
-	t.Run("jobs in progress, failing to complete before stop context", func(t *testing.T) {
+	t.Run("LeavesJobUnchangedWithEmptyParams", func(t *testing.T) {
 		t.Parallel()
 
-		jobDoneChan := make(chan struct{})
-		jobStartedChan := make(chan int64)
+		client, _ := setup(t)
 
-		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case jobStartedChan <- job.ID:
-			}
+		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Tags: []string{"tag1"}})
+		require.NoError(t, err)
 
-			select {
-			case <-ctx.Done():
-				require.FailNow(t, "Did not expect job to be cancelled")
-			case <-jobDoneChan:
-			}
+		updatedJob, err := client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{})
+		require.NoError(t, err)
+		require.Equal(t, newJob.MaxAttempts, updatedJob.MaxAttempts)
+		require.Equal(t, newJob.Priority, updatedJob.Priority)
+		require.Equal(t, newJob.Queue, updatedJob.Queue)
+		require.Equal(t, newJob.State, updatedJob.State)
+		require.Equal(t, newJob.Tags, updatedJob.Tags)
+	})
+
+	t.Run("SchedulesAvailableJobInFuture", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, newJob.State)
+
+		scheduledAt := time.Now().Add(time.Hour)
+
+		updatedJob, err := client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{ScheduledAt: scheduledAt})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, updatedJob.State)
+		require.WithinDuration(t, scheduledAt, updatedJob.ScheduledAt, time.Microsecond)
+	})
 
-			return nil
-		}
+	t.Run("MakesScheduledJobAvailableAndWorksIt", func(t *testing.T) {
+		t.Parallel()
 
-		client := runNewTestClient(ctx, t, newTestConfig(t, callbackFunc))
+		client, bundle := setup(t)
 
-		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
+		startClient(ctx, t, client)
+		t.Cleanup(func() { close(bundle.doneCh) })
+
+		newJob, err := client.Insert(ctx, &callbackArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
 		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
 
-		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
-		require.Equal(t, insertedJob.ID, startedJobID)
+		updatedJob, err := client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{ScheduledAt: time.Now()})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
 
-		go func() {
-			<-time.After(100 * time.Millisecond)
-			close(jobDoneChan)
-		}()
+		require.Equal(t, newJob.ID, riverinternaltest.WaitOrTimeout(t, bundle.startedCh))
+	})
 
-		t.Logf("Shutting down client with timeout, but while jobs are still in progress")
+	t.Run("MakesRetryableJobAvailable", func(t *testing.T) {
+		t.Parallel()
 
-		// Context should expire while jobs are still in progress:
-		stopCtx, stopCancel := context.WithTimeout(ctx, 50*time.Millisecond)
-		t.Cleanup(stopCancel)
+		client, bundle := setup(t)
 
-		err = client.Stop(stopCtx)
-		require.Equal(t, context.DeadlineExceeded, err)
+		retryableJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			ScheduledAt: ptrutil.Ptr(time.Now().Add(time.Hour)),
+			State:       ptrutil.Ptr(rivertype.JobStateRetryable),
+		})
 
-		select {
-		case <-jobDoneChan:
-			require.FailNow(t, "Expected Stop to return before job was done")
-		default:
-		}
+		updatedJob, err := client.JobUpdate(ctx, retryableJob.ID, &JobUpdateParams{ScheduledAt: time.Now()})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
 	})
 
-	t.Run("with continual insertion, no jobs are left running", func(t *testing.T) {
+	t.Run("TxVariantAlsoUpdatesJob", func(t *testing.T) {
 		t.Parallel()
 
-		startedCh := make(chan int64)
-		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
-			select {
-			case startedCh <- job.ID:
-			default:
-			}
-			return nil
-		}
+		client, bundle := setup(t)
 
-		config := newTestConfig(t, callbackFunc)
-		client := runNewTestClient(ctx, t, config)
+		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
 
-		finish := doParallelContinualInsertion(ctx, t, client)
-		defer finish()
+		var updatedJob *rivertype.JobRow
+		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			updatedJob, err = client.JobUpdateTx(ctx, tx, newJob.ID, &JobUpdateParams{Priority: 2})
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, updatedJob.Priority)
 
-		// Wait for at least one job to start
-		riverinternaltest.WaitOrTimeout(t, startedCh)
+		job, err := client.JobGet(ctx, newJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, job.Priority)
+	})
 
-		require.NoError(t, client.Stop(ctx))
+	t.Run("ReturnsErrJobRunningIfJobIsRunning", func(t *testing.T) {
+		t.Parallel()
 
-		runningJobs, err := client.JobList(ctx, NewJobListParams().State(rivertype.JobStateRunning))
+		client, bundle := setup(t)
+
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		updatedJob, err := client.JobUpdate(ctx, runningJob.ID, &JobUpdateParams{Priority: 2})
+		require.ErrorIs(t, err, ErrJobRunning)
+		require.Nil(t, updatedJob)
+
+		job, err := client.JobGet(ctx, runningJob.ID)
 		require.NoError(t, err)
-		require.Empty(t, runningJobs, "expected no jobs to be left running")
+		require.Equal(t, runningJob.Priority, job.Priority)
 	})
 
-	t.Run("WithSubscriber", func(t *testing.T) {
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
-		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error { return nil }
+		client, _ := setup(t)
 
-		client := runNewTestClient(ctx, t, newTestConfig(t, callbackFunc))
+		updatedJob, err := client.JobUpdate(ctx, 0, &JobUpdateParams{Priority: 2})
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, updatedJob)
+	})
 
-		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted)
-		defer cancel()
+	t.Run("ValidatesParams", func(t *testing.T) {
+		t.Parallel()
 
-		finish := doParallelContinualInsertion(ctx, t, client)
-		defer finish()
+		client, _ := setup(t)
 
-		// Arbitrarily wait for 100 jobs to come through.
-		for i := 0; i < 100; i++ {
-			riverinternaltest.WaitOrTimeout(t, subscribeChan)
-		}
+		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
 
-		require.NoError(t, client.Stop(ctx))
+		_, err = client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{MaxAttempts: -1})
+		require.EqualError(t, err, "max attempts must be greater than zero")
+
+		_, err = client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{Priority: 5})
+		require.EqualError(t, err, "priority must be between 1 and 4")
+
+		_, err = client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{Queue: "invalid*queue"})
+		require.ErrorContains(t, err, "queue name is invalid")
+
+		_, err = client.JobUpdate(ctx, newJob.ID, &JobUpdateParams{Metadata: []byte(`["not", "an", "object"]`)})
+		require.ErrorContains(t, err, "metadata must be a JSON object")
 	})
 }
 
-func Test_Client_StopAndCancel(t *testing.T) {
+func Test_Client_QueueAdd(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	t.Run("jobs in progress, only completing when context is canceled", func(t *testing.T) {
-		t.Parallel()
+	type testBundle struct {
+		workedChan chan string
+	}
 
-		jobDoneChan := make(chan struct{})
-		jobStartedChan := make(chan int64)
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
 
-		callbackFunc := func(ctx context.Context, job *Job[callbackArgs]) error {
-			defer close(jobDoneChan)
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
 
-			// indicate the job has started, unless context is already done:
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case jobStartedChan <- job.ID:
-			}
+		workedChan := make(chan string, 10)
 
-			t.Logf("Job waiting for context cancellation")
-			defer t.Logf("Job finished")
+		config := newTestConfig(t, nil)
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			workedChan <- job.Queue
+			return nil
+		}))
 
-			select {
-			case <-ctx.Done(): // don't stop running until context is canceled
-			case <-time.After(10 * time.Second):
-				require.FailNow(t, "Job should've been cancelled by now")
-			}
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
 
-			return nil
-		}
-		config := newTestConfig(t, callbackFunc)
-		client := runNewTestClient(ctx, t, config)
+		return client, &testBundle{workedChan: workedChan}
+	}
 
-		insertedJob, err := client.Insert(ctx, callbackArgs{}, nil)
-		require.NoError(t, err)
+	insertJob := func(t *testing.T, client *Client[pgx.Tx], queue string) {
+		t.Helper()
 
-		startedJobID := riverinternaltest.WaitOrTimeout(t, jobStartedChan)
-		require.Equal(t, insertedJob.ID, startedJobID)
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
 
-		t.Logf("Initiating hard stop, while jobs are still in progress")
+		_, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{Queue: queue})
+		require.NoError(t, err)
+	}
 
-		stopCtx, stopCancel := context.WithTimeout(ctx, time.Second)
-		t.Cleanup(stopCancel)
+	t.Run("AddsQueueToRunningClient", func(t *testing.T) {
+		t.Parallel()
 
-		stopStartedAt := time.Now()
+		client, bundle := setup(t)
 
-		err = client.StopAndCancel(stopCtx)
-		require.NoError(t, err)
+		statusUpdateCh := client.monitor.RegisterUpdates()
+		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 
-		t.Logf("Waiting on job to be done")
+		require.NoError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 2}))
+		require.Equal(t, QueueConfig{MaxWorkers: 2}, client.config.Queues["new_queue"])
 
-		select {
-		case <-jobDoneChan:
-		default:
-			require.FailNow(t, "Expected job to be done before stop returns")
-		}
+		insertJob(t, client, "new_queue")
+		require.Equal(t, "new_queue", riverinternaltest.WaitOrTimeout(t, bundle.workedChan))
 
-		// Stop should be ~immediate:
-		//
-		// TODO: client stop seems to take a widely variable amount of time,
-		// between 1ms and >50ms, due to the JobComplete query taking that long.
-		// Investigate and solve that if we can, or consider reworking this test.
-		require.WithinDuration(t, time.Now(), stopStartedAt, 200*time.Millisecond)
+		// The new producer's status is tracked along with the others.
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 	})
-}
 
-type callbackWithCustomTimeoutArgs struct {
-	TimeoutValue time.Duration `json:"timeout"`
-}
+	t.Run("AddsQueueBeforeStart", func(t *testing.T) {
+		t.Parallel()
 
-func (callbackWithCustomTimeoutArgs) Kind() string { return "callbackWithCustomTimeout" }
+		client, bundle := setup(t)
 
-type callbackWorkerWithCustomTimeout struct {
-	WorkerDefaults[callbackWithCustomTimeoutArgs]
-	fn func(context.Context, *Job[callbackWithCustomTimeoutArgs]) error
-}
+		require.NoError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 2}))
 
-func (w *callbackWorkerWithCustomTimeout) Work(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
-	return w.fn(ctx, job)
-}
+		startClient(ctx, t, client)
 
-func (w *callbackWorkerWithCustomTimeout) Timeout(job *Job[callbackWithCustomTimeoutArgs]) time.Duration {
-	return job.Args.TimeoutValue
-}
+		insertJob(t, client, "new_queue")
+		require.Equal(t, "new_queue", riverinternaltest.WaitOrTimeout(t, bundle.workedChan))
+	})
 
-func Test_Client_JobContextInheritsFromProvidedContext(t *testing.T) {
-	t.Parallel()
+	t.Run("AddsQueueAfterStop", func(t *testing.T) {
+		t.Parallel()
 
-	deadline := time.Now().Add(2 * time.Minute)
+		client, _ := setup(t)
 
-	require := require.New(t)
-	jobCtxCh := make(chan context.Context)
-	doneCh := make(chan struct{})
-	close(doneCh)
+		startClient(ctx, t, client)
+		require.NoError(t, client.Stop(ctx))
 
-	callbackFunc := func(ctx context.Context, job *Job[callbackWithCustomTimeoutArgs]) error {
-		// indicate the job has started, unless context is already done:
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case jobCtxCh <- ctx:
-		}
-		return nil
-	}
-	config := newTestConfig(t, nil)
-	AddWorker(config.Workers, &callbackWorkerWithCustomTimeout{fn: callbackFunc})
+		require.NoError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 2}))
+		require.Equal(t, QueueConfig{MaxWorkers: 2}, client.config.Queues["new_queue"])
+		require.NotContains(t, client.producerRunsByQueueName, "new_queue")
+	})
 
-	// Set a deadline and a value on the context for the client so we can verify
-	// it's propagated through to the job:
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
-	t.Cleanup(cancel)
+	t.Run("ErrorsOnAlreadyConfiguredQueue", func(t *testing.T) {
+		t.Parallel()
 
-	type customContextKey string
-	ctx = context.WithValue(ctx, customContextKey("BestGoPostgresQueue"), "River")
-	client := runNewTestClient(ctx, t, config)
+		client, _ := setup(t)
 
-	insertCtx, insertCancel := context.WithTimeout(ctx, time.Second)
-	t.Cleanup(insertCancel)
+		require.EqualError(t, client.QueueAdd(QueueDefault, QueueConfig{MaxWorkers: 2}), `queue "default" is already configured`)
+	})
 
-	// enqueue job:
-	_, err := client.Insert(insertCtx, callbackWithCustomTimeoutArgs{TimeoutValue: 5 * time.Minute}, nil)
-	require.NoError(err)
+	t.Run("ErrorsOnInvalidConfig", func(t *testing.T) {
+		t.Parallel()
 
-	var jobCtx context.Context
-	select {
-	case jobCtx = <-jobCtxCh:
-	case <-time.After(5 * time.Second):
-		t.Fatal("timed out waiting for job to start")
-	}
+		client, _ := setup(t)
 
-	require.Equal("River", jobCtx.Value(customContextKey("BestGoPostgresQueue")), "job should persist the context value from the client context")
-	jobDeadline, ok := jobCtx.Deadline()
-	require.True(ok, "job should have a deadline")
-	require.Equal(deadline, jobDeadline, "job should have the same deadline as the client context (shorter than the job's timeout)")
-}
+		require.EqualError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 0}), `invalid number of workers for queue "new_queue": 0`)
+		require.EqualError(t, client.QueueAdd("", QueueConfig{MaxWorkers: 1}), "queue name cannot be empty")
+		require.NotContains(t, client.config.Queues, "new_queue")
+	})
 
-func Test_Client_ClientFromContext(t *testing.T) {
-	t.Parallel()
+	t.Run("ErrorsOnClientNotWorkingJobs", func(t *testing.T) {
+		t.Parallel()
 
-	ctx := context.Background()
+		config := newTestConfig(t, nil)
+		config.Queues = nil
 
-	var clientResult *Client[pgx.Tx]
-	jobDoneChan := make(chan struct{})
-	config := newTestConfig(t, func(ctx context.Context, j *Job[callbackArgs]) error {
-		clientResult = ClientFromContext[pgx.Tx](ctx)
-		close(jobDoneChan)
-		return nil
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
+
+		require.EqualError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 1}), "client must be configured with Queues and Workers to add a queue")
 	})
-	client := runNewTestClient(ctx, t, config)
 
-	_, err := client.Insert(ctx, callbackArgs{}, nil)
-	require.NoError(t, err)
+	t.Run("DoesNotModifyOriginalConfig", func(t *testing.T) {
+		t.Parallel()
 
-	riverinternaltest.WaitOrTimeout(t, jobDoneChan)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
 
-	require.NotNil(t, clientResult)
-	require.Equal(t, client, clientResult)
+		require.NoError(t, client.QueueAdd("new_queue", QueueConfig{MaxWorkers: 1}))
+		require.NotContains(t, config.Queues, "new_queue")
+	})
 }
 
-func Test_Client_Insert(t *testing.T) {
+func Test_Client_QueueGet(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	type testBundle struct{}
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
@@ -841,113 +3172,96 @@ func Test_Client_Insert(t *testing.T) {
 		config := newTestConfig(t, nil)
 		client := newTestClient(t, dbPool, config)
 
-		return client, &testBundle{}
+		return client, &testBundle{exec: client.driver.GetExecutor()}
 	}
 
-	t.Run("Succeeds", func(t *testing.T) {
+	t.Run("FetchesAnExistingQueue", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		jobRow, err := client.Insert(ctx, &noOpArgs{}, nil)
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{PausedAt: ptrutil.Ptr(time.Now())})
+
+		queueFetched, err := client.QueueGet(ctx, queue.Name)
 		require.NoError(t, err)
-		require.Equal(t, 0, jobRow.Attempt)
-		require.Equal(t, rivercommon.MaxAttemptsDefault, jobRow.MaxAttempts)
-		require.JSONEq(t, "{}", string(jobRow.Metadata))
-		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
-		require.Equal(t, PriorityDefault, jobRow.Priority)
-		require.Equal(t, QueueDefault, jobRow.Queue)
-		require.Equal(t, []string{}, jobRow.Tags)
+		require.Equal(t, queue.Name, queueFetched.Name)
+		require.NotNil(t, queueFetched.PausedAt)
 	})
 
-	t.Run("WithInsertOpts", func(t *testing.T) {
+	t.Run("ReturnsErrNotFoundIfQueueDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		jobRow, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{
-			MaxAttempts: 17,
-			Metadata:    []byte(`{"foo": "bar"}`),
-			Priority:    3,
-			Queue:       "custom",
-			Tags:        []string{"custom"},
-		})
-		require.NoError(t, err)
-		require.Equal(t, 0, jobRow.Attempt)
-		require.Equal(t, 17, jobRow.MaxAttempts)
-		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
-		require.JSONEq(t, `{"foo": "bar"}`, string(jobRow.Metadata))
-		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
-		require.Equal(t, 3, jobRow.Priority)
-		require.Equal(t, "custom", jobRow.Queue)
-		require.Equal(t, []string{"custom"}, jobRow.Tags)
+		queue, err := client.QueueGet(ctx, "nonexistent_queue")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, queue)
 	})
+}
 
-	t.Run("WithInsertOptsScheduledAtZeroTime", func(t *testing.T) {
-		t.Parallel()
+func Test_Client_QueueList(t *testing.T) {
+	t.Parallel()
 
-		client, _ := setup(t)
+	ctx := context.Background()
 
-		jobRow, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{
-			ScheduledAt: time.Time{},
-		})
-		require.NoError(t, err)
-		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
-	})
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
 
-	t.Run("ErrorsOnInvalidQueueName", func(t *testing.T) {
-		t.Parallel()
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
 
-		client, _ := setup(t)
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
 
-		_, err := client.Insert(ctx, &noOpArgs{}, &InsertOpts{Queue: "invalid*queue"})
-		require.ErrorContains(t, err, "queue name is invalid")
-	})
+		return client, &testBundle{exec: client.driver.GetExecutor()}
+	}
 
-	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
+	t.Run("ListsQueuesInOrder", func(t *testing.T) {
 		t.Parallel()
 
-		_, _ = setup(t)
-
-		client, err := NewClient(riverpgxv5.New(nil), &Config{
-			Logger: riverinternaltest.Logger(t),
-		})
-		require.NoError(t, err)
-
-		_, err = client.Insert(ctx, &noOpArgs{}, nil)
-		require.ErrorIs(t, err, errNoDriverDBPool)
-	})
+		client, bundle := setup(t)
 
-	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
-		t.Parallel()
+		queue2 := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue_2")})
+		queue1 := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue_1"), PausedAt: ptrutil.Ptr(time.Now())})
+		queue3 := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue_3")})
 
-		client, _ := setup(t)
+		queues, err := client.QueueList(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{queue1.Name, queue2.Name, queue3.Name}, sliceutil.Map(queues, func(queue *rivertype.Queue) string { return queue.Name }))
+		require.NotNil(t, queues[0].PausedAt)
+		require.Nil(t, queues[1].PausedAt)
 
-		_, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
-		var unknownJobKindErr *UnknownJobKindError
-		require.ErrorAs(t, err, &unknownJobKindErr)
-		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+		queues, err = client.QueueList(ctx, NewQueueListParams().First(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{queue1.Name, queue2.Name}, sliceutil.Map(queues, func(queue *rivertype.Queue) string { return queue.Name }))
 	})
 
-	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+	t.Run("IncludesQueuesOfStartedClient", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		client.config.Workers = nil
+		statusUpdateCh := client.monitor.RegisterUpdates()
+		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 
-		_, err := client.Insert(ctx, &unregisteredJobArgs{}, nil)
+		queues, err := client.QueueList(ctx, nil)
 		require.NoError(t, err)
+		require.Len(t, queues, 1)
+		require.Equal(t, QueueDefault, queues[0].Name)
 	})
 }
 
-func Test_Client_InsertTx(t *testing.T) {
+func Test_Client_QueuePause(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
 	type testBundle struct {
-		tx pgx.Tx
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
 	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
@@ -957,366 +3271,325 @@ func Test_Client_InsertTx(t *testing.T) {
 		config := newTestConfig(t, nil)
 		client := newTestClient(t, dbPool, config)
 
-		tx, err := dbPool.Begin(ctx)
-		require.NoError(t, err)
-		t.Cleanup(func() { tx.Rollback(ctx) })
-
 		return client, &testBundle{
-			tx: tx,
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
 		}
 	}
 
-	t.Run("Succeeds", func(t *testing.T) {
+	t.Run("PausesAndResumes", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		jobRow, err := client.InsertTx(ctx, bundle.tx, &noOpArgs{}, nil)
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{})
+
+		require.NoError(t, client.QueuePause(ctx, queue.Name))
+
+		queueFetched, err := client.QueueGet(ctx, queue.Name)
 		require.NoError(t, err)
-		require.Equal(t, 0, jobRow.Attempt)
-		require.Equal(t, rivercommon.MaxAttemptsDefault, jobRow.MaxAttempts)
-		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
-		require.Equal(t, PriorityDefault, jobRow.Priority)
-		require.Equal(t, QueueDefault, jobRow.Queue)
-		require.Equal(t, []string{}, jobRow.Tags)
+		require.NotNil(t, queueFetched.PausedAt)
 
-		// Job is not visible outside of the transaction.
-		_, err = client.JobGet(ctx, jobRow.ID)
-		require.ErrorIs(t, err, ErrNotFound)
+		require.NoError(t, client.QueueResume(ctx, queue.Name))
+
+		queueFetched, err = client.QueueGet(ctx, queue.Name)
+		require.NoError(t, err)
+		require.Nil(t, queueFetched.PausedAt)
 	})
 
-	t.Run("WithInsertOpts", func(t *testing.T) {
+	t.Run("PausesInTx", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		jobRow, err := client.InsertTx(ctx, bundle.tx, &noOpArgs{}, &InsertOpts{
-			MaxAttempts: 17,
-			Priority:    3,
-			Queue:       "custom",
-			Tags:        []string{"custom"},
-		})
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{})
+
+		tx, err := bundle.dbPool.Begin(ctx)
 		require.NoError(t, err)
-		require.Equal(t, 0, jobRow.Attempt)
-		require.Equal(t, 17, jobRow.MaxAttempts)
-		require.Equal(t, (&noOpArgs{}).Kind(), jobRow.Kind)
-		require.Equal(t, 3, jobRow.Priority)
-		require.Equal(t, "custom", jobRow.Queue)
-		require.Equal(t, []string{"custom"}, jobRow.Tags)
-	})
+		t.Cleanup(func() { tx.Rollback(ctx) })
 
-	// A client's allowed to send nil to their driver so they can, for example,
-	// easily use test transactions in their test suite.
-	t.Run("WithDriverWithoutPool", func(t *testing.T) {
-		t.Parallel()
+		require.NoError(t, client.QueuePauseTx(ctx, tx, queue.Name))
 
-		_, bundle := setup(t)
+		queueFetched, err := client.QueueGetTx(ctx, tx, queue.Name)
+		require.NoError(t, err)
+		require.NotNil(t, queueFetched.PausedAt)
 
-		client, err := NewClient(riverpgxv5.New(nil), &Config{
-			Logger: riverinternaltest.Logger(t),
-		})
+		// Not visible outside the transaction until it commits.
+		queueFetched, err = client.QueueGet(ctx, queue.Name)
 		require.NoError(t, err)
+		require.Nil(t, queueFetched.PausedAt)
 
-		_, err = client.InsertTx(ctx, bundle.tx, &noOpArgs{}, nil)
+		require.NoError(t, client.QueueResumeTx(ctx, tx, queue.Name))
+
+		queueFetched, err = client.QueueGetTx(ctx, tx, queue.Name)
 		require.NoError(t, err)
+		require.Nil(t, queueFetched.PausedAt)
 	})
 
-	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+	t.Run("PauseCreatesQueueIfNotExists", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		_, err := client.InsertTx(ctx, bundle.tx, &unregisteredJobArgs{}, nil)
-		var unknownJobKindErr *UnknownJobKindError
-		require.ErrorAs(t, err, &unknownJobKindErr)
-		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
+		require.NoError(t, client.QueuePause(ctx, "nonexistent_queue"))
+
+		queueFetched, err := client.QueueGet(ctx, "nonexistent_queue")
+		require.NoError(t, err)
+		require.NotNil(t, queueFetched.PausedAt)
 	})
 
-	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+	t.Run("ResumeReturnsErrNotFoundIfQueueDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
-
-		client.config.Workers = nil
+		client, _ := setup(t)
 
-		_, err := client.InsertTx(ctx, bundle.tx, &unregisteredJobArgs{}, nil)
-		require.NoError(t, err)
+		require.ErrorIs(t, client.QueueResume(ctx, "nonexistent_queue"), ErrNotFound)
 	})
 }
 
-func Test_Client_InsertMany(t *testing.T) {
+func Test_Client_QueueRemove(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	type testBundle struct{}
+	type testBundle struct {
+		jobStartedChan chan int64
+		subscribeChan  <-chan *Event
+	}
+
+	type JobArgs struct {
+		JobArgsReflectKind[JobArgs]
+	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
 
-		dbPool := riverinternaltest.TestDB(ctx, t)
+		jobStartedChan := make(chan int64, 10)
+
 		config := newTestConfig(t, nil)
-		client := newTestClient(t, dbPool, config)
+		config.Queues["other_queue"] = QueueConfig{MaxWorkers: 1}
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStartedChan <- job.ID
 
-		return client, &testBundle{}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(200 * time.Millisecond):
+				return nil
+			}
+		}))
+
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobFailed)
+		t.Cleanup(cancel)
+
+		return client, &testBundle{
+			jobStartedChan: jobStartedChan,
+			subscribeChan:  subscribeChan,
+		}
 	}
 
-	t.Run("SucceedsWithMultipleJobs", func(t *testing.T) {
+	t.Run("DrainsRunningJobs", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Queue: "foo", Priority: 2}},
-			{Args: noOpArgs{}},
-		})
-		require.NoError(t, err)
-		require.Equal(t, int64(2), count)
+		statusUpdateCh := client.monitor.RegisterUpdates()
+		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 
-		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{Queue: "other_queue"})
 		require.NoError(t, err)
-		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind()) //nolint:goconst
-	})
 
-	t.Run("WithInsertOptsScheduledAtZeroTime", func(t *testing.T) {
-		t.Parallel()
+		riverinternaltest.WaitOrTimeout(t, bundle.jobStartedChan)
 
-		client, _ := setup(t)
+		require.NoError(t, client.QueueRemove(ctx, "other_queue", true))
+		require.NotContains(t, client.config.Queues, "other_queue")
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: &noOpArgs{}, InsertOpts: &InsertOpts{ScheduledAt: time.Time{}}},
-		})
+		// The job was allowed to finish normally.
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertedJob.ID, event.Job.ID)
+
+		// Jobs inserted afterwards aren't worked.
+		_, err = client.Insert(ctx, &JobArgs{}, &InsertOpts{Queue: "other_queue"})
 		require.NoError(t, err)
-		require.Equal(t, int64(1), count)
 
-		jobs, err := client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		select {
+		case <-bundle.jobStartedChan:
+			require.FailNow(t, "Job unexpectedly worked in removed queue")
+		case <-time.After(5 * client.config.FetchPollInterval):
+		}
+
+		// Other queues are unaffected, and the client stays healthy without
+		// the removed queue's producer.
+		_, err = client.Insert(ctx, &JobArgs{}, nil)
 		require.NoError(t, err)
-		require.Len(t, jobs, 1, "Expected to find exactly one job of kind: "+(noOpArgs{}).Kind())
-		jobRow := jobs[0]
-		require.WithinDuration(t, time.Now(), jobRow.ScheduledAt, 2*time.Second)
+		riverinternaltest.WaitOrTimeout(t, bundle.jobStartedChan)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
 	})
 
-	t.Run("ErrorsOnInvalidQueueName", func(t *testing.T) {
+	t.Run("CancelsRunningJobsWithoutDrain", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: &noOpArgs{}, InsertOpts: &InsertOpts{Queue: "invalid*queue"}},
-		})
-		require.ErrorContains(t, err, "queue name is invalid")
-		require.Equal(t, int64(0), count)
-	})
+		startClient(ctx, t, client)
 
-	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
-		t.Parallel()
+		insertedJob, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{Queue: "other_queue"})
+		require.NoError(t, err)
 
-		_, _ = setup(t)
+		riverinternaltest.WaitOrTimeout(t, bundle.jobStartedChan)
 
-		client, err := NewClient(riverpgxv5.New(nil), &Config{
-			Logger: riverinternaltest.Logger(t),
-		})
-		require.NoError(t, err)
+		require.NoError(t, client.QueueRemove(ctx, "other_queue", false))
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: noOpArgs{}},
-		})
-		require.ErrorIs(t, err, errNoDriverDBPool)
-		require.Equal(t, int64(0), count)
+		event := riverinternaltest.WaitOrTimeout(t, bundle.subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, insertedJob.ID, event.Job.ID)
 	})
 
-	t.Run("ErrorsWithZeroJobs", func(t *testing.T) {
+	t.Run("CanBeAddedAgain", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{})
-		require.EqualError(t, err, "no jobs to insert")
-		require.Equal(t, int64(0), count)
+		startClient(ctx, t, client)
+
+		require.NoError(t, client.QueueRemove(ctx, "other_queue", true))
+		require.NoError(t, client.QueueAdd("other_queue", QueueConfig{MaxWorkers: 1}))
+
+		_, err := client.Insert(ctx, &JobArgs{}, &InsertOpts{Queue: "other_queue"})
+		require.NoError(t, err)
+		riverinternaltest.WaitOrTimeout(t, bundle.jobStartedChan)
 	})
 
-	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+	t.Run("CanBeAddedAgainAfterStop", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: unregisteredJobArgs{}},
-		})
-		var unknownJobKindErr *UnknownJobKindError
-		require.ErrorAs(t, err, &unknownJobKindErr)
-		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
-		require.Equal(t, int64(0), count)
+		startClient(ctx, t, client)
+		require.NoError(t, client.Stop(ctx))
+
+		require.NoError(t, client.QueueRemove(ctx, "other_queue", true))
+		require.NotContains(t, client.producerRunsByQueueName, "other_queue")
+
+		require.NoError(t, client.QueueAdd("other_queue", QueueConfig{MaxWorkers: 1}))
+		require.Equal(t, QueueConfig{MaxWorkers: 1}, client.config.Queues["other_queue"])
 	})
 
-	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+	t.Run("RemovesQueueBeforeStart", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		client.config.Workers = nil
-
-		_, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: unregisteredJobArgs{}},
-		})
-		require.NoError(t, err)
+		require.NoError(t, client.QueueRemove(ctx, "other_queue", true))
+		require.NotContains(t, client.producersByQueueName, "other_queue")
+		require.NotContains(t, client.config.Queues, "other_queue")
 	})
 
-	t.Run("ErrorsOnInsertOptsUniqueOpts", func(t *testing.T) {
+	t.Run("ErrorsOnUnknownQueue", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		count, err := client.InsertMany(ctx, []InsertManyParams{
-			{Args: noOpArgs{}, InsertOpts: &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}},
-		})
-		require.EqualError(t, err, "UniqueOpts are not supported for batch inserts")
-		require.Equal(t, int64(0), count)
+		require.EqualError(t, client.QueueRemove(ctx, "unknown_queue", true), `queue "unknown_queue" is not configured`)
 	})
 }
 
-func Test_Client_InsertManyTx(t *testing.T) {
+func Test_Client_QueueSetMaxWorkers(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
 	type testBundle struct {
-		tx pgx.Tx
+		doneCh    chan struct{}
+		startedCh chan int64
 	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
 
-		dbPool := riverinternaltest.TestDB(ctx, t)
-		config := newTestConfig(t, nil)
-		client := newTestClient(t, dbPool, config)
+		var (
+			doneCh    = make(chan struct{})
+			startedCh = make(chan int64)
+		)
 
-		tx, err := dbPool.Begin(ctx)
-		require.NoError(t, err)
-		t.Cleanup(func() { tx.Rollback(ctx) })
+		config := newTestConfig(t, makeAwaitCallback(startedCh, doneCh))
+		config.Queues[QueueDefault] = QueueConfig{MaxWorkers: 1}
+		client := newTestClient(t, riverinternaltest.TestDB(ctx, t), config)
 
 		return client, &testBundle{
-			tx: tx,
+			doneCh:    doneCh,
+			startedCh: startedCh,
 		}
 	}
 
-	t.Run("SucceedsWithMultipleJobs", func(t *testing.T) {
-		t.Parallel()
-
-		client, bundle := setup(t)
-
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
-			{Args: noOpArgs{}, InsertOpts: &InsertOpts{Queue: "foo", Priority: 2}},
-			{Args: noOpArgs{}},
-		})
-		require.NoError(t, err)
-		require.Equal(t, int64(2), count)
-
-		jobs, err := client.driver.UnwrapExecutor(bundle.tx).JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
-		require.NoError(t, err)
-		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind())
-
-		require.NoError(t, bundle.tx.Commit(ctx))
-
-		// Ensure the jobs are visible outside the transaction:
-		jobs, err = client.driver.GetExecutor().JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
-		require.NoError(t, err)
-		require.Len(t, jobs, 2, "Expected to find exactly two jobs of kind: "+(noOpArgs{}).Kind())
-	})
-
-	t.Run("SupportsScheduledJobs", func(t *testing.T) {
+	t.Run("ChangesMaxWorkersOfRunningProducer", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
 		startClient(ctx, t, client)
+		t.Cleanup(func() { close(bundle.doneCh) })
 
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{{noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Minute)}}})
-		require.NoError(t, err)
-		require.Equal(t, int64(1), count)
-
-		insertedJobs, err := client.driver.UnwrapExecutor(bundle.tx).JobGetByKindMany(ctx, []string{(noOpArgs{}).Kind()})
+		_, err := client.Insert(ctx, &callbackArgs{}, nil)
 		require.NoError(t, err)
-		require.Len(t, insertedJobs, 1)
-		require.Equal(t, rivertype.JobStateScheduled, insertedJobs[0].State)
-		require.WithinDuration(t, time.Now().Add(time.Minute), insertedJobs[0].ScheduledAt, 2*time.Second)
-	})
-
-	// A client's allowed to send nil to their driver so they can, for example,
-	// easily use test transactions in their test suite.
-	t.Run("WithDriverWithoutPool", func(t *testing.T) {
-		t.Parallel()
-
-		_, bundle := setup(t)
-
-		client, err := NewClient(riverpgxv5.New(nil), &Config{
-			Logger: riverinternaltest.Logger(t),
-		})
+		_, err = client.Insert(ctx, &callbackArgs{}, nil)
 		require.NoError(t, err)
 
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
-			{Args: noOpArgs{}},
-		})
-		require.NoError(t, err)
-		require.Equal(t, int64(1), count)
-	})
+		riverinternaltest.WaitOrTimeout(t, bundle.startedCh)
 
-	t.Run("ErrorsWithZeroJobs", func(t *testing.T) {
-		t.Parallel()
+		select {
+		case <-bundle.startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond max workers")
+		case <-time.After(5 * client.config.FetchPollInterval):
+		}
 
-		client, bundle := setup(t)
+		require.NoError(t, client.QueueSetMaxWorkers(QueueDefault, 2))
+		require.Equal(t, QueueConfig{MaxWorkers: 2}, client.config.Queues[QueueDefault])
 
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{})
-		require.EqualError(t, err, "no jobs to insert")
-		require.Equal(t, int64(0), count)
+		riverinternaltest.WaitOrTimeout(t, bundle.startedCh)
 	})
 
-	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
+	t.Run("PreservesOtherQueueConfig", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
+		client.config.Queues[QueueDefault] = QueueConfig{GlobalMaxWorkers: 5, MaxWorkers: 1}
 
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
-			{Args: unregisteredJobArgs{}},
-		})
-		var unknownJobKindErr *UnknownJobKindError
-		require.ErrorAs(t, err, &unknownJobKindErr)
-		require.Equal(t, (&unregisteredJobArgs{}).Kind(), unknownJobKindErr.Kind)
-		require.Equal(t, int64(0), count)
+		require.NoError(t, client.QueueSetMaxWorkers(QueueDefault, 2))
+		require.Equal(t, QueueConfig{GlobalMaxWorkers: 5, MaxWorkers: 2}, client.config.Queues[QueueDefault])
 	})
 
-	t.Run("AllowsUnknownJobKindWithoutWorkers", func(t *testing.T) {
+	t.Run("ErrorsOnInvalidMaxWorkers", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
-
-		client.config.Workers = nil
+		client, _ := setup(t)
 
-		_, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
-			{Args: unregisteredJobArgs{}},
-		})
-		require.NoError(t, err)
+		require.EqualError(t, client.QueueSetMaxWorkers(QueueDefault, 0), `invalid number of workers for queue "default": 0`)
+		require.EqualError(t, client.QueueSetMaxWorkers(QueueDefault, QueueNumWorkersMax+1), `invalid number of workers for queue "default": 10001`)
 	})
 
-	t.Run("ErrorsOnInsertOptsUniqueOpts", func(t *testing.T) {
+	t.Run("ErrorsOnUnconfiguredQueue", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		count, err := client.InsertManyTx(ctx, bundle.tx, []InsertManyParams{
-			{Args: noOpArgs{}, InsertOpts: &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}},
-		})
-		require.EqualError(t, err, "UniqueOpts are not supported for batch inserts")
-		require.Equal(t, int64(0), count)
+		require.EqualError(t, client.QueueSetMaxWorkers("other_queue", 2), `queue "other_queue" is not configured`)
 	})
 }
 
-func Test_Client_JobGet(t *testing.T) {
+func Test_Client_QueueStats(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	type testBundle struct{}
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
@@ -1325,269 +3598,453 @@ func Test_Client_JobGet(t *testing.T) {
 		config := newTestConfig(t, nil)
 		client := newTestClient(t, dbPool, config)
 
-		return client, &testBundle{}
+		return client, &testBundle{dbPool: dbPool, exec: client.driver.GetExecutor()}
 	}
 
-	t.Run("FetchesAnExistingJob", func(t *testing.T) {
+	t.Run("ReturnsStatsForEachQueue", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		newJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		now := time.Now().UTC()
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1"), ScheduledAt: ptrutil.Ptr(now.Add(-10 * time.Minute))})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1"), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Minute))})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			AttemptedAt: ptrutil.Ptr(now.Add(-2 * time.Minute)),
+			FinalizedAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
+			Kind:        ptrutil.Ptr("kind1"),
+			Queue:       ptrutil.Ptr("queue2"),
+			State:       ptrutil.Ptr(rivertype.JobStateCompleted),
+		})
+
+		stats, err := client.QueueStats(ctx, nil)
 		require.NoError(t, err)
+		require.Len(t, stats, 2)
+
+		require.Equal(t, "queue1", stats[0].Name)
+		require.Equal(t, 2, stats[0].CountByState[rivertype.JobStateAvailable])
+		require.Equal(t, 1, stats[0].CountByState[rivertype.JobStateRunning])
+		require.Contains(t, stats[0].CountByState, rivertype.JobStateCompleted)
+		require.Zero(t, stats[0].CountByState[rivertype.JobStateCompleted])
+		require.Equal(t, map[string]map[rivertype.JobState]int{
+			"kind1": {rivertype.JobStateAvailable: 2},
+			"kind2": {rivertype.JobStateRunning: 1},
+		}, stats[0].CountByKind)
+		require.NotNil(t, stats[0].OldestAvailableScheduledAt)
+		require.WithinDuration(t, now.Add(-10*time.Minute), *stats[0].OldestAvailableScheduledAt, time.Millisecond)
+		require.Zero(t, stats[0].RunDurationP50)
+		require.Zero(t, stats[0].RunDurationP95)
+
+		require.Equal(t, "queue2", stats[1].Name)
+		require.Equal(t, 1, stats[1].CountByState[rivertype.JobStateCompleted])
+		require.Nil(t, stats[1].OldestAvailableScheduledAt)
+		require.Equal(t, time.Minute, stats[1].RunDurationP50)
+		require.Equal(t, time.Minute, stats[1].RunDurationP95)
+	})
 
-		job, err := client.JobGet(ctx, newJob.ID)
+	t.Run("FiltersByQueue", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1")})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2")})
+
+		stats, err := client.QueueStats(ctx, &QueueStatsParams{Queues: []string{"queue2"}})
 		require.NoError(t, err)
-		require.NotNil(t, job)
+		require.Len(t, stats, 1)
+		require.Equal(t, "queue2", stats[0].Name)
+	})
 
-		require.Equal(t, newJob.ID, job.ID)
-		require.Equal(t, newJob.State, job.State)
+	t.Run("RunDurationWindow", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			AttemptedAt: ptrutil.Ptr(now.Add(-3 * time.Hour)),
+			FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Hour)),
+			State:       ptrutil.Ptr(rivertype.JobStateCompleted),
+		})
+
+		// Outside of the default window of an hour.
+		stats, err := client.QueueStats(ctx, &QueueStatsParams{})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		require.Zero(t, stats[0].RunDurationP50)
+
+		stats, err = client.QueueStats(ctx, &QueueStatsParams{RunDurationWindow: 3 * time.Hour})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		require.Equal(t, time.Hour, stats[0].RunDurationP50)
+
+		_, err = client.QueueStats(ctx, &QueueStatsParams{RunDurationWindow: -1 * time.Hour})
+		require.EqualError(t, err, "run duration window must not be negative")
 	})
 
-	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+	t.Run("TxVariant", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		job, err := client.JobGet(ctx, 0)
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrNotFound)
-		require.Nil(t, job)
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		_, err = client.InsertTx(ctx, tx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		stats, err := client.QueueStatsTx(ctx, tx, nil)
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		require.Equal(t, QueueDefault, stats[0].Name)
+		require.Equal(t, 1, stats[0].CountByState[rivertype.JobStateAvailable])
 	})
 }
 
-func Test_Client_JobList(t *testing.T) {
+func Test_Client_QueueUpdate(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
 	type testBundle struct {
-		exec riverdriver.Executor
+		dbPool    *pgxpool.Pool
+		doneCh    chan struct{}
+		exec      riverdriver.Executor
+		startedCh chan int64
 	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
 
-		dbPool := riverinternaltest.TestDB(ctx, t)
-		config := newTestConfig(t, nil)
+		var (
+			dbPool    = riverinternaltest.TestDB(ctx, t)
+			doneCh    = make(chan struct{})
+			startedCh = make(chan int64)
+		)
+
+		config := newTestConfig(t, makeAwaitCallback(startedCh, doneCh))
+		config.Queues[QueueDefault] = QueueConfig{MaxWorkers: 1}
 		client := newTestClient(t, dbPool, config)
 
 		return client, &testBundle{
-			exec: client.driver.GetExecutor(),
+			dbPool:    dbPool,
+			doneCh:    doneCh,
+			exec:      client.driver.GetExecutor(),
+			startedCh: startedCh,
 		}
 	}
 
-	t.Run("FiltersByKind", func(t *testing.T) { //nolint:dupl
+	t.Run("BroadcastsMaxWorkersToRunningProducers", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1")})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_1")})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("test_kind_2")})
+		statusUpdateCh := client.monitor.RegisterUpdates()
+		startClient(ctx, t, client)
+		waitForClientHealthy(ctx, t, statusUpdateCh)
+		t.Cleanup(func() { close(bundle.doneCh) })
 
-		jobs, err := client.JobList(ctx, NewJobListParams().Kinds("test_kind_1"))
+		_, err := client.Insert(ctx, &callbackArgs{}, nil)
+		require.NoError(t, err)
+		_, err = client.Insert(ctx, &callbackArgs{}, nil)
 		require.NoError(t, err)
-		// jobs ordered by ScheduledAt ASC by default
-		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-		jobs, err = client.JobList(ctx, NewJobListParams().Kinds("test_kind_2"))
+		riverinternaltest.WaitOrTimeout(t, bundle.startedCh)
+
+		select {
+		case <-bundle.startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond max workers")
+		case <-time.After(5 * client.config.FetchPollInterval):
+		}
+
+		queue, err := client.QueueUpdate(ctx, QueueDefault, &QueueUpdateParams{MaxWorkers: 2})
 		require.NoError(t, err)
-		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.JSONEq(t, `{"max_workers": 2}`, string(queue.Metadata))
+
+		riverinternaltest.WaitOrTimeout(t, bundle.startedCh)
 	})
 
-	t.Run("FiltersByQueue", func(t *testing.T) { //nolint:dupl
+	t.Run("SetsMaxWorkers", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_2")})
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{
+			Metadata: []byte(`{"foo": "bar"}`),
+		})
 
-		jobs, err := client.JobList(ctx, NewJobListParams().Queues("queue_1"))
+		queue, err := client.QueueUpdate(ctx, queue.Name, &QueueUpdateParams{MaxWorkers: 3})
 		require.NoError(t, err)
-		// jobs ordered by ScheduledAt ASC by default
-		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.JSONEq(t, `{"foo": "bar", "max_workers": 3}`, string(queue.Metadata))
+	})
 
-		jobs, err = client.JobList(ctx, NewJobListParams().Queues("queue_2"))
+	t.Run("RemovesMaxWorkers", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{
+			Metadata: []byte(`{"foo": "bar", "max_workers": 2}`),
+		})
+
+		queue, err := client.QueueUpdate(ctx, queue.Name, &QueueUpdateParams{MaxWorkers: 0})
 		require.NoError(t, err)
-		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.JSONEq(t, `{"foo": "bar"}`, string(queue.Metadata))
 	})
 
-	t.Run("FiltersByState", func(t *testing.T) {
+	t.Run("UpdatesInTx", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{})
 
-		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateAvailable))
+		tx, err := bundle.dbPool.Begin(ctx)
 		require.NoError(t, err)
-		// jobs ordered by ScheduledAt ASC by default
-		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		t.Cleanup(func() { tx.Rollback(ctx) })
 
-		jobs, err = client.JobList(ctx, NewJobListParams().State(JobStateRunning))
+		queueUpdated, err := client.QueueUpdateTx(ctx, tx, queue.Name, &QueueUpdateParams{MaxWorkers: 2})
 		require.NoError(t, err)
-		require.Equal(t, []int64{job3.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.JSONEq(t, `{"max_workers": 2}`, string(queueUpdated.Metadata))
+
+		// Not visible outside the transaction until it commits.
+		queueFetched, err := client.QueueGet(ctx, queue.Name)
+		require.NoError(t, err)
+		require.JSONEq(t, `{}`, string(queueFetched.Metadata))
 	})
 
-	t.Run("SortsAvailableRetryableAndScheduledJobsByScheduledAt", func(t *testing.T) {
+	t.Run("ErrorsOnInvalidMaxWorkers", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		now := time.Now().UTC()
+		queue := testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{})
 
-		states := map[rivertype.JobState]rivertype.JobState{
-			JobStateAvailable: rivertype.JobStateAvailable,
-			JobStateRetryable: rivertype.JobStateRetryable,
-			JobStateScheduled: rivertype.JobStateScheduled,
-		}
-		for state, dbState := range states {
-			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), ScheduledAt: &now})
-			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		_, err := client.QueueUpdate(ctx, queue.Name, &QueueUpdateParams{MaxWorkers: -1})
+		require.EqualError(t, err, fmt.Sprintf("invalid number of workers for queue %q: -1", queue.Name))
 
-			jobs, err := client.JobList(ctx, NewJobListParams().State(state))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		_, err = client.QueueUpdate(ctx, queue.Name, &QueueUpdateParams{MaxWorkers: QueueNumWorkersMax + 1})
+		require.EqualError(t, err, fmt.Sprintf("invalid number of workers for queue %q: 10001", queue.Name))
+	})
+
+	t.Run("ReturnsErrNotFoundIfQueueDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.QueueUpdate(ctx, "nonexistent_queue", &QueueUpdateParams{MaxWorkers: 2})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func Test_Client_WorkflowCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool}
+	}
+
+	insertWorkflow := func(t *testing.T, client *Client[pgx.Tx]) *Workflow {
+		t.Helper()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+		workflow.Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+
+		params, err := workflow.Prepare()
+		require.NoError(t, err)
+
+		_, err = client.InsertMany(ctx, params)
+		require.NoError(t, err)
 
-			jobs, err = client.JobList(ctx, NewJobListParams().State(state).OrderBy(JobListOrderByTime, SortOrderDesc))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
-		}
-	})
+		return workflow
+	}
 
-	t.Run("SortsCancelledCompletedAndDiscardedJobsByFinalizedAt", func(t *testing.T) {
+	t.Run("CancelsAllTasks", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
-
-		now := time.Now().UTC()
+		client, _ := setup(t)
 
-		states := map[rivertype.JobState]rivertype.JobState{
-			JobStateCancelled: rivertype.JobStateCancelled,
-			JobStateCompleted: rivertype.JobStateCompleted,
-			JobStateDiscarded: rivertype.JobStateDiscarded,
-		}
-		for state, dbState := range states {
-			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), FinalizedAt: ptrutil.Ptr(now.Add(-10 * time.Second))})
-			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(dbState), FinalizedAt: ptrutil.Ptr(now.Add(-15 * time.Second))})
+		workflow := insertWorkflow(t, client)
 
-			jobs, err := client.JobList(ctx, NewJobListParams().State(state))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		workflowRes, err := client.WorkflowCancel(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.True(t, workflowRes.Finalized())
+		require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateCancelled: 2}, workflowRes.CountByState())
 
-			jobs, err = client.JobList(ctx, NewJobListParams().State(state).OrderBy(JobListOrderByTime, SortOrderDesc))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
-		}
+		workflowRes, err = client.WorkflowGet(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateCancelled: 2}, workflowRes.CountByState())
 	})
 
-	t.Run("SortsRunningJobsByAttemptedAt", func(t *testing.T) {
+	t.Run("LeavesFinalizedTasksAlone", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		now := time.Now().UTC()
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: &now})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		workflow := insertWorkflow(t, client)
 
-		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateRunning))
+		workflowRes, err := client.WorkflowGet(ctx, workflow.ID())
 		require.NoError(t, err)
-		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-		jobs, err = client.JobList(ctx, NewJobListParams().State(JobStateRunning).OrderBy(JobListOrderByTime, SortOrderDesc))
+		_, err = client.driver.GetExecutor().JobUpdate(ctx, &riverdriver.JobUpdateParams{
+			ID:                  workflowRes.Task("a").Job.ID,
+			FinalizedAtDoUpdate: true,
+			FinalizedAt:         ptrutil.Ptr(time.Now()),
+			StateDoUpdate:       true,
+			State:               rivertype.JobStateCompleted,
+		})
 		require.NoError(t, err)
-		// Sort order was explicitly reversed:
-		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		workflowRes, err = client.WorkflowCancel(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, workflowRes.Task("a").Job.State)
+		require.Equal(t, rivertype.JobStateCancelled, workflowRes.Task("b").Job.State)
 	})
 
-	t.Run("WithNilParamsFiltersToAvailableByDefault", func(t *testing.T) {
+	t.Run("TxVariantAlsoCancelsTasks", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		now := time.Now().UTC()
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: &now})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
-		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		workflow := insertWorkflow(t, client)
 
-		jobs, err := client.JobList(ctx, nil)
+		var workflowRes *WorkflowResult
+
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			workflowRes, err = client.WorkflowCancelTx(ctx, tx, workflow.ID())
+			return err
+		})
 		require.NoError(t, err)
-		// sort order is switched by ScheduledAt values:
-		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateCancelled: 2}, workflowRes.CountByState())
 	})
 
-	t.Run("PaginatesWithAfter", func(t *testing.T) {
+	t.Run("ReturnsErrNotFoundIfWorkflowDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		now := time.Now().UTC()
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable), ScheduledAt: &now})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
-		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: &now})
-		job5 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
-		job6 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: &now})
+		workflowRes, err := client.WorkflowCancel(ctx, "does_not_exist")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, workflowRes)
+	})
+}
 
-		jobs, err := client.JobList(ctx, NewJobListParams().After(JobListCursorFromJob(job1)))
+func Test_Client_WorkflowGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool}
+	}
+
+	insertWorkflow := func(t *testing.T, client *Client[pgx.Tx], opts *WorkflowOpts) *Workflow {
+		t.Helper()
+
+		workflow := NewWorkflow(opts)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+		workflow.Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+
+		params, err := workflow.Prepare()
 		require.NoError(t, err)
-		require.Equal(t, []int64{job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-		jobs, err = client.JobList(ctx, NewJobListParams().State(rivertype.JobStateRunning).After(JobListCursorFromJob(job3)))
+		_, err = client.InsertMany(ctx, params)
 		require.NoError(t, err)
-		require.Equal(t, []int64{job4.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-		jobs, err = client.JobList(ctx, NewJobListParams().State(rivertype.JobStateCompleted).After(JobListCursorFromJob(job5)))
+		return workflow
+	}
+
+	t.Run("FetchesAnExistingWorkflow", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		workflow := insertWorkflow(t, client, &WorkflowOpts{Name: "my_workflow"})
+
+		// A second workflow to make sure its tasks aren't included.
+		insertWorkflow(t, client, nil)
+
+		workflowRes, err := client.WorkflowGet(ctx, workflow.ID())
 		require.NoError(t, err)
-		require.Equal(t, []int64{job6.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, workflow.ID(), workflowRes.ID)
+		require.Equal(t, "my_workflow", workflowRes.Name)
+		require.False(t, workflowRes.Finalized())
+		require.Equal(t, map[rivertype.JobState]int{
+			rivertype.JobStateAvailable: 1,
+			rivertype.JobStatePending:   1,
+		}, workflowRes.CountByState())
+
+		require.Len(t, workflowRes.Tasks, 2)
+		require.Equal(t, "a", workflowRes.Tasks[0].Name)
+		require.Empty(t, workflowRes.Tasks[0].Deps)
+		require.Equal(t, "b", workflowRes.Tasks[1].Name)
+		require.Equal(t, []string{"a"}, workflowRes.Tasks[1].Deps)
 	})
 
-	t.Run("MetadataOnly", func(t *testing.T) {
+	t.Run("TxVariantAlsoFetchesWorkflow", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"foo": "bar"}`)})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"baz": "value"}`)})
-		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"baz": "value"}`)})
+		workflow := insertWorkflow(t, client, nil)
 
-		jobs, err := client.JobList(ctx, NewJobListParams().State("").Metadata(`{"foo": "bar"}`))
-		require.NoError(t, err)
-		require.Equal(t, []int64{job1.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		var workflowRes *WorkflowResult
 
-		jobs, err = client.JobList(ctx, NewJobListParams().State("").Metadata(`{"baz": "value"}`).OrderBy(JobListOrderByTime, SortOrderDesc))
+		err := pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			workflowRes, err = client.WorkflowGetTx(ctx, tx, workflow.ID())
+			return err
+		})
 		require.NoError(t, err)
-		// Sort order was explicitly reversed:
-		require.Equal(t, []int64{job3.ID, job2.ID}, sliceutil.Map(jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Len(t, workflowRes.Tasks, 2)
 	})
 
-	t.Run("WithCancelledContext", func(t *testing.T) {
+	t.Run("ReturnsErrNotFoundIfWorkflowDoesNotExist", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		ctx, cancel := context.WithCancel(ctx)
-		cancel() // cancel immediately
-
-		jobs, err := client.JobList(ctx, NewJobListParams().State(JobStateRunning))
-		require.ErrorIs(t, context.Canceled, err)
-		require.Empty(t, jobs)
+		workflowRes, err := client.WorkflowGet(ctx, "does_not_exist")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, workflowRes)
 	})
 }
 
-func Test_Client_JobRetry(t *testing.T) {
+func Test_Client_WorkflowTaskDeps(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	type testBundle struct {
-		dbPool *pgxpool.Pool
-	}
+	type testBundle struct{}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
@@ -1596,58 +4053,76 @@ func Test_Client_JobRetry(t *testing.T) {
 		config := newTestConfig(t, nil)
 		client := newTestClient(t, dbPool, config)
 
-		return client, &testBundle{dbPool: dbPool}
+		return client, &testBundle{}
 	}
 
-	t.Run("UpdatesAJobScheduledInTheFutureToBeImmediatelyAvailable", func(t *testing.T) {
+	t.Run("FetchesDepsWithOutputs", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, &InsertOpts{Metadata: []byte(`{"river:output": {"value": "a"}}`)}, nil)
+		workflow.Add("b", noOpArgs{}, nil, nil)
+		workflow.Add("c", noOpArgs{}, nil, nil)
+		workflow.Add("d", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a", "b"}})
+
+		params, err := workflow.Prepare()
 		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
 
-		job, err := client.JobRetry(ctx, newJob.ID)
+		_, err = client.InsertMany(ctx, params)
 		require.NoError(t, err)
-		require.NotNil(t, job)
 
-		require.Equal(t, rivertype.JobStateAvailable, job.State)
-		require.WithinDuration(t, time.Now().UTC(), job.ScheduledAt, 5*time.Second)
+		workflowRes, err := client.WorkflowGet(ctx, workflow.ID())
+		require.NoError(t, err)
+
+		deps, err := client.WorkflowTaskDeps(ctx, workflowRes.Task("d").Job)
+		require.NoError(t, err)
+		require.Len(t, deps, 2)
+		require.Equal(t, workflowRes.Task("a").Job.ID, deps["a"].Job.ID)
+		require.Equal(t, workflowRes.Task("b").Job.ID, deps["b"].Job.ID)
+
+		var output struct {
+			Value string `json:"value"`
+		}
+		require.NoError(t, deps["a"].Output(&output))
+		require.Equal(t, "a", output.Value)
+
+		require.EqualError(t, deps["b"].Output(&output), `workflow task "b" has no recorded output`)
 	})
 
-	t.Run("TxVariantAlsoUpdatesJobToAvailable", func(t *testing.T) {
+	t.Run("NoDeps", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		newJob, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+
+		params, err := workflow.Prepare()
 		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateScheduled, newJob.State)
 
-		var jobAfter *rivertype.JobRow
+		_, err = client.InsertMany(ctx, params)
+		require.NoError(t, err)
 
-		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
-			var err error
-			jobAfter, err = client.JobRetryTx(ctx, tx, newJob.ID)
-			return err
-		})
+		workflowRes, err := client.WorkflowGet(ctx, workflow.ID())
 		require.NoError(t, err)
-		require.NotNil(t, jobAfter)
 
-		require.Equal(t, rivertype.JobStateAvailable, jobAfter.State)
-		require.WithinDuration(t, time.Now().UTC(), jobAfter.ScheduledAt, 5*time.Second)
+		deps, err := client.WorkflowTaskDeps(ctx, workflowRes.Task("a").Job)
+		require.NoError(t, err)
+		require.Empty(t, deps)
 	})
 
-	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+	t.Run("ErrorsOnJobNotInWorkflow", func(t *testing.T) {
 		t.Parallel()
 
 		client, _ := setup(t)
 
-		job, err := client.JobRetry(ctx, 0)
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrNotFound)
-		require.Nil(t, job)
+		job, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		_, err = client.WorkflowTaskDeps(ctx, job)
+		require.EqualError(t, err, "job is not part of a workflow")
 	})
 }
 
@@ -1722,7 +4197,7 @@ func Test_Client_ErrorHandler(t *testing.T) {
 
 		// Bypass the normal Insert function because that will error on an
 		// unknown job.
-		insertParams, _, err := insertParamsFromArgsAndOptions(unregisteredJobArgs{}, nil)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, unregisteredJobArgs{}, nil)
 		require.NoError(t, err)
 		_, err = client.driver.GetExecutor().JobInsertFast(ctx, insertParams)
 		require.NoError(t, err)
@@ -2690,7 +5165,7 @@ func Test_Client_UnknownJobKindErrorsTheJob(t *testing.T) {
 	subscribeChan, cancel := client.Subscribe(EventKindJobFailed)
 	t.Cleanup(cancel)
 
-	insertParams, _, err := insertParamsFromArgsAndOptions(unregisteredJobArgs{}, nil)
+	insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, unregisteredJobArgs{}, nil)
 	require.NoError(err)
 	insertedJob, err := client.driver.GetExecutor().JobInsertFast(ctx, insertParams)
 	require.NoError(err)
@@ -2858,8 +5333,13 @@ func Test_NewClient_Overrides(t *testing.T) {
 
 	retryPolicy := &DefaultClientRetryPolicy{}
 
+	workerMiddleware := WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+		return doInner(ctx)
+	})
+
 	client, err := NewClient(riverpgxv5.New(dbPool), &Config{
 		AdvisoryLockPrefix:          123_456,
+		BatchCompleter:              true,
 		CancelledJobRetentionPeriod: 1 * time.Hour,
 		CompletedJobRetentionPeriod: 2 * time.Hour,
 		DiscardedJobRetentionPeriod: 3 * time.Hour,
@@ -2868,8 +5348,11 @@ func Test_NewClient_Overrides(t *testing.T) {
 		FetchPollInterval:           124 * time.Millisecond,
 		JobTimeout:                  125 * time.Millisecond,
 		Logger:                      logger,
+		PollOnly:                    true,
 		Queues:                      map[string]QueueConfig{QueueDefault: {MaxWorkers: 1}},
 		RetryPolicy:                 retryPolicy,
+		Schema:                      "custom_schema",
+		WorkerMiddleware:            []rivertype.WorkerMiddleware{workerMiddleware},
 		Workers:                     workers,
 		disableSleep:                true,
 	})
@@ -2877,6 +5360,8 @@ func Test_NewClient_Overrides(t *testing.T) {
 
 	require.Equal(t, int32(123_456), client.uniqueInserter.AdvisoryLockPrefix)
 
+	require.IsType(t, &jobcompleter.BatchJobCompleter{}, client.completer)
+
 	jobCleaner := maintenance.GetService[*maintenance.JobCleaner](client.queueMaintainer)
 	require.Equal(t, 1*time.Hour, jobCleaner.Config.CancelledJobRetentionPeriod)
 	require.Equal(t, 2*time.Hour, jobCleaner.Config.CompletedJobRetentionPeriod)
@@ -2890,7 +5375,10 @@ func Test_NewClient_Overrides(t *testing.T) {
 	require.Equal(t, 124*time.Millisecond, client.config.FetchPollInterval)
 	require.Equal(t, 125*time.Millisecond, client.config.JobTimeout)
 	require.Equal(t, logger, client.baseService.Logger)
+	require.True(t, client.config.PollOnly)
 	require.Equal(t, retryPolicy, client.config.RetryPolicy)
+	require.Equal(t, "custom_schema", client.config.Schema)
+	require.Len(t, client.config.WorkerMiddleware, 1)
 	require.True(t, client.baseService.DisableSleep)
 	require.True(t, client.config.disableSleep)
 }
@@ -3044,6 +5532,13 @@ func Test_NewClient_Validations(t *testing.T) {
 				require.Equal(t, 23*time.Hour+maintenance.JobRescuerRescueAfterDefault, client.config.RescueStuckJobsAfter)
 			},
 		},
+		{
+			name: "Schema cannot be longer than 45 characters",
+			configFunc: func(config *Config) {
+				config.Schema = strings.Repeat("a", 46)
+			},
+			wantErr: errors.New("Schema cannot be longer than 45 characters"),
+		},
 		{
 			name: "Queues can be nil when Workers is also nil",
 			configFunc: func(config *Config) {
@@ -3061,6 +5556,34 @@ func Test_NewClient_Validations(t *testing.T) {
 			name:       "Queues can be empty",
 			configFunc: func(config *Config) { config.Queues = make(map[string]QueueConfig) },
 		},
+		{
+			name: "Queues ConcurrencyLimits are validated",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {ConcurrencyLimits: []ConcurrencyLimit{{Kind: "kind1"}}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid number of workers for concurrency limit of kind \"kind1\" in queue \"default\": 0"),
+		},
+		{
+			name: "Queues RateLimits are validated",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, RateLimits: []RateLimit{{Limit: 1}}}}
+			},
+			wantErr: errors.New("invalid period for rate limit of kind \"\" in queue \"default\": 0s"),
+		},
+		{
+			name: "Queues GlobalMaxWorkers can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {GlobalMaxWorkers: -1, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid number of global workers for queue \"default\": -1"),
+		},
+		{
+			name: "Queues PriorityAgingInterval can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, PriorityAgingInterval: -1 * time.Second}}
+			},
+			wantErr: errors.New("invalid priority aging interval for queue \"default\": -1s"),
+		},
 		{
 			name: "Queues MaxWorkers can't be negative",
 			configFunc: func(config *Config) {
@@ -3265,10 +5788,12 @@ func TestClient_JobTimeout(t *testing.T) {
 func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 	t.Parallel()
 
+	ctx := context.Background()
+
 	t.Run("Defaults", func(t *testing.T) {
 		t.Parallel()
 
-		insertParams, uniqueOpts, err := insertParamsFromArgsAndOptions(noOpArgs{}, nil)
+		insertParams, uniqueOpts, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, nil)
 		require.NoError(t, err)
 		require.Equal(t, `{"name":""}`, string(insertParams.EncodedArgs))
 		require.Equal(t, (noOpArgs{}).Kind(), insertParams.Kind)
@@ -3291,7 +5816,7 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 			ScheduledAt: time.Now().Add(time.Hour),
 			Tags:        []string{"tag1", "tag2"},
 		}
-		insertParams, _, err := insertParamsFromArgsAndOptions(noOpArgs{}, opts)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, opts)
 		require.NoError(t, err)
 		require.Equal(t, 42, insertParams.MaxAttempts)
 		require.Equal(t, 2, insertParams.Priority)
@@ -3300,10 +5825,60 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		require.Equal(t, []string{"tag1", "tag2"}, insertParams.Tags)
 	})
 
+	t.Run("ExpiresAt", func(t *testing.T) {
+		t.Parallel()
+
+		expiresAt := time.Now().Add(time.Hour)
+
+		metadata := []byte(`{"big": 9007199254740993, "expires_at": "not a time"}`)
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{
+			ExpiresAt: expiresAt,
+			Metadata:  metadata,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, insertParams.ExpiresAt)
+		require.True(t, expiresAt.Equal(*insertParams.ExpiresAt))
+
+		// Metadata is left exactly as given.
+		require.Equal(t, metadata, insertParams.Metadata)
+	})
+
+	t.Run("DependsOn", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{DependsOn: []int64{123, 456}})
+		require.NoError(t, err)
+		require.Equal(t, []int64{123, 456}, insertParams.DependsOn)
+		require.Equal(t, rivertype.JobStatePending, insertParams.State)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{pending: true})
+		require.NoError(t, err)
+		require.Empty(t, insertParams.DependsOn)
+		require.Equal(t, rivertype.JobStatePending, insertParams.State)
+	})
+
+	t.Run("DependsOnWithScheduledAt", func(t *testing.T) {
+		t.Parallel()
+
+		// Jobs with dependencies are pending even if they're scheduled. They
+		// go to scheduled on promotion if it's not yet time to run them.
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{
+			DependsOn:   []int64{123},
+			ScheduledAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, insertParams.State)
+	})
+
 	t.Run("WorkerInsertOptsOverrides", func(t *testing.T) {
 		t.Parallel()
 
-		insertParams, _, err := insertParamsFromArgsAndOptions(&customInsertOptsJobArgs{}, nil)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, &customInsertOptsJobArgs{}, nil)
 		require.NoError(t, err)
 		// All these come from overrides in customInsertOptsJobArgs's definition:
 		require.Equal(t, 42, insertParams.MaxAttempts)
@@ -3322,7 +5897,7 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 			ByState:  []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateCompleted},
 		}
 
-		_, internalUniqueOpts, err := insertParamsFromArgsAndOptions(noOpArgs{}, &InsertOpts{UniqueOpts: uniqueOpts})
+		_, internalUniqueOpts, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{UniqueOpts: uniqueOpts})
 		require.NoError(t, err)
 		require.Equal(t, uniqueOpts.ByArgs, internalUniqueOpts.ByArgs)
 		require.Equal(t, uniqueOpts.ByPeriod, internalUniqueOpts.ByPeriod)
@@ -3333,7 +5908,7 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 	t.Run("PriorityIsLimitedTo4", func(t *testing.T) {
 		t.Parallel()
 
-		insertParams, _, err := insertParamsFromArgsAndOptions(noOpArgs{}, &InsertOpts{Priority: 5})
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, noOpArgs{}, &InsertOpts{Priority: 5})
 		require.ErrorContains(t, err, "priority must be between 1 and 4")
 		require.Nil(t, insertParams)
 	})
@@ -3342,7 +5917,7 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		t.Parallel()
 
 		args := timeoutTestArgs{TimeoutValue: time.Hour}
-		insertParams, _, err := insertParamsFromArgsAndOptions(args, nil)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, args, nil)
 		require.NoError(t, err)
 		require.Equal(t, `{"timeout_value":3600000000000}`, string(insertParams.EncodedArgs))
 	})
@@ -3354,12 +5929,107 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		// since we already have tests elsewhere for that. Just make sure validation
 		// is running.
 		insertParams, _, err := insertParamsFromArgsAndOptions(
-			noOpArgs{},
+			ctx, &Config{}, noOpArgs{},
 			&InsertOpts{UniqueOpts: UniqueOpts{ByPeriod: 1 * time.Millisecond}},
 		)
 		require.EqualError(t, err, "JobUniqueOpts.ByPeriod should not be less than 1 second")
 		require.Nil(t, insertParams)
 	})
+
+	t.Run("InsertHooksModifyParams", func(t *testing.T) {
+		t.Parallel()
+
+		scheduledAt := time.Now().Add(time.Hour)
+
+		config := &Config{
+			InsertHooks: []InsertHook{
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					require.Equal(t, noOpArgs{Name: "hooked"}, args)
+					params.Metadata = []byte(`{"tenant_id":"tenant_123"}`)
+					params.Priority = 3
+					params.Queue = "from_hook"
+					params.ScheduledAt = &scheduledAt
+					params.Tags = append(params.Tags, "first_hook")
+					return nil
+				}),
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					// Sees modifications from the hook that ran before it.
+					require.Equal(t, "from_hook", params.Queue)
+					params.Tags = append(params.Tags, "second_hook")
+					return nil
+				}),
+			},
+		}
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, config, noOpArgs{Name: "hooked"}, &InsertOpts{Tags: []string{"opts"}})
+		require.NoError(t, err)
+		require.Equal(t, `{"tenant_id":"tenant_123"}`, string(insertParams.Metadata))
+		require.Equal(t, 3, insertParams.Priority)
+		require.Equal(t, "from_hook", insertParams.Queue)
+		require.Equal(t, scheduledAt, *insertParams.ScheduledAt)
+		require.Equal(t, rivertype.JobStateScheduled, insertParams.State)
+		require.Equal(t, []string{"opts", "first_hook", "second_hook"}, insertParams.Tags)
+	})
+
+	t.Run("InsertHookRejectsInsert", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			hookErr           = errors.New("insert rejected")
+			secondHookInvoked bool
+		)
+
+		config := &Config{
+			InsertHooks: []InsertHook{
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					return hookErr
+				}),
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					secondHookInvoked = true
+					return nil
+				}),
+			},
+		}
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, config, noOpArgs{}, nil)
+		require.ErrorIs(t, err, hookErr)
+		require.Nil(t, insertParams)
+		require.False(t, secondHookInvoked)
+	})
+
+	t.Run("InsertHookChangesAreValidated", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			InsertHooks: []InsertHook{
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					params.Priority = 5
+					return nil
+				}),
+			},
+		}
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, config, noOpArgs{}, nil)
+		require.ErrorContains(t, err, "priority must be between 1 and 4")
+		require.Nil(t, insertParams)
+	})
+
+	t.Run("InsertHookPriorityTooLow", func(t *testing.T) {
+		t.Parallel()
+
+		config := &Config{
+			InsertHooks: []InsertHook{
+				InsertHookFunc(func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+					params.Priority = 0
+					return nil
+				}),
+			},
+		}
+
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, config, noOpArgs{}, nil)
+		require.ErrorContains(t, err, "priority must be between 1 and 4")
+		require.Nil(t, insertParams)
+	})
 }
 
 func TestID(t *testing.T) {
@@ -0,0 +1,99 @@
+package river
+
+import (
+	"fmt"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+// ConcurrencyLimit limits the number of jobs of a particular kind in a queue
+// that may be running at once across all clients. It's configured on a queue
+// with QueueConfig.ConcurrencyLimits:
+//
+//	Queues: map[string]river.QueueConfig{
+//		river.QueueDefault: {
+//			ConcurrencyLimits: []river.ConcurrencyLimit{
+//				{Kind: "sync_account", MaxWorkers: 2},
+//				{Kind: "send_report", MaxWorkers: 1, PartitionByArgs: []string{"customer_id"}},
+//			},
+//			MaxWorkers: 100,
+//		},
+//	},
+//
+// Limits are enforced when jobs are fetched, so jobs over a limit stay
+// available in the queue until running jobs finish, and other jobs in the
+// queue continue to be worked in the meantime.
+type ConcurrencyLimit struct {
+	// Kind is the kind of job to limit, as returned by its JobArgs' Kind.
+	Kind string
+
+	// MaxWorkers is the maximum number of jobs of the kind that may be running
+	// at once across all clients. If the limit is partitioned, it applies to
+	// each partition separately.
+	//
+	// Requires a minimum of 1.
+	MaxWorkers int
+
+	// PartitionByArgs are the names of top level keys in a job's encoded args
+	// whose values partition the limit, so that MaxWorkers applies separately
+	// to each distinct combination of values. For example, partitioning by
+	// `customer_id` allows up to MaxWorkers jobs to run at once for each
+	// customer. A key that's missing from a job's args counts as one of the
+	// values in its partition like any other.
+	PartitionByArgs []string
+
+	// PartitionByMetadata are the names of top level keys in a job's metadata
+	// that partition the limit, in the same way as PartitionByArgs. Both may
+	// be used at once.
+	PartitionByMetadata []string
+}
+
+func (l *ConcurrencyLimit) validate(queueName string) error {
+	if l.Kind == "" {
+		return fmt.Errorf("concurrency limit for queue %q must have a kind", queueName)
+	}
+	if l.MaxWorkers < 1 {
+		return fmt.Errorf("invalid number of workers for concurrency limit of kind %q in queue %q: %d", l.Kind, queueName, l.MaxWorkers)
+	}
+	for _, keys := range [][]string{l.PartitionByArgs, l.PartitionByMetadata} {
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("concurrency limit of kind %q in queue %q has an empty partition key", l.Kind, queueName)
+			}
+		}
+	}
+	return nil
+}
+
+func validateConcurrencyLimits(queueName string, limits []ConcurrencyLimit) error {
+	kinds := make(map[string]struct{}, len(limits))
+	for _, limit := range limits {
+		if err := limit.validate(queueName); err != nil {
+			return err
+		}
+		if _, ok := kinds[limit.Kind]; ok {
+			return fmt.Errorf("queue %q has more than one concurrency limit for kind %q", queueName, limit.Kind)
+		}
+		kinds[limit.Kind] = struct{}{}
+	}
+	return nil
+}
+
+// concurrencyLimitsToDriver converts concurrency limits to the form expected
+// by the driver when fetching jobs.
+func concurrencyLimitsToDriver(limits []ConcurrencyLimit) []*riverdriver.JobGetAvailableConcurrencyLimit {
+	if len(limits) < 1 {
+		return nil
+	}
+
+	driverLimits := make([]*riverdriver.JobGetAvailableConcurrencyLimit, len(limits))
+	for i, limit := range limits {
+		driverLimits[i] = &riverdriver.JobGetAvailableConcurrencyLimit{
+			Kind:                limit.Kind,
+			Max:                 limit.MaxWorkers,
+			PartitionByArgs:     limit.PartitionByArgs,
+			PartitionByMetadata: limit.PartitionByMetadata,
+		}
+	}
+	return driverLimits
+}
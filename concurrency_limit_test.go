@@ -0,0 +1,56 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+func TestValidateConcurrencyLimits(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateConcurrencyLimits(QueueDefault, nil))
+	require.NoError(t, validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{
+		{Kind: "kind1", MaxWorkers: 1},
+		{Kind: "kind2", MaxWorkers: 2, PartitionByArgs: []string{"customer_id"}, PartitionByMetadata: []string{"tenant"}},
+	}))
+
+	require.EqualError(t,
+		validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{{MaxWorkers: 1}}),
+		`concurrency limit for queue "default" must have a kind`,
+	)
+	require.EqualError(t,
+		validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{{Kind: "kind1"}}),
+		`invalid number of workers for concurrency limit of kind "kind1" in queue "default": 0`,
+	)
+	require.EqualError(t,
+		validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{{Kind: "kind1", MaxWorkers: 1, PartitionByArgs: []string{""}}}),
+		`concurrency limit of kind "kind1" in queue "default" has an empty partition key`,
+	)
+	require.EqualError(t,
+		validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{{Kind: "kind1", MaxWorkers: 1, PartitionByMetadata: []string{""}}}),
+		`concurrency limit of kind "kind1" in queue "default" has an empty partition key`,
+	)
+	require.EqualError(t,
+		validateConcurrencyLimits(QueueDefault, []ConcurrencyLimit{{Kind: "kind1", MaxWorkers: 1}, {Kind: "kind1", MaxWorkers: 2}}),
+		`queue "default" has more than one concurrency limit for kind "kind1"`,
+	)
+}
+
+func TestConcurrencyLimitsToDriver(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, concurrencyLimitsToDriver(nil))
+	require.Equal(t,
+		[]*riverdriver.JobGetAvailableConcurrencyLimit{
+			{Kind: "kind1", Max: 1},
+			{Kind: "kind2", Max: 2, PartitionByArgs: []string{"customer_id"}, PartitionByMetadata: []string{"tenant"}},
+		},
+		concurrencyLimitsToDriver([]ConcurrencyLimit{
+			{Kind: "kind1", MaxWorkers: 1},
+			{Kind: "kind2", MaxWorkers: 2, PartitionByArgs: []string{"customer_id"}, PartitionByMetadata: []string{"tenant"}},
+		}),
+	)
+}
@@ -9,6 +9,8 @@ type ctxKey int
 
 const (
 	ctxKeyClient ctxKey = iota
+	ctxKeyJobHeartbeater
+	ctxKeyJobOutput
 )
 
 var errClientNotInContext = errors.New("river: client not found in context, can only be used in a Worker")
@@ -30,7 +30,7 @@ func TestDriverDatabaseSQL_Executor(t *testing.T) {
 	t.Cleanup(func() { require.NoError(t, stdPool.Close()) })
 
 	driver := riverdatabasesql.New(nil)
-	riverdrivertest.ExerciseExecutorMigrationOnly(ctx, t, driver, func(ctx context.Context, t *testing.T) *sql.Tx {
+	riverdrivertest.ExerciseExecutorFull(ctx, t, driver, func(ctx context.Context, t *testing.T) *sql.Tx {
 		t.Helper()
 
 		tx, err := stdPool.BeginTx(ctx, nil)
@@ -41,6 +41,22 @@ func TestDriverDatabaseSQL_Executor(t *testing.T) {
 	})
 }
 
+func TestDriverDatabaseSQL_Listener(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	riverdrivertest.ExerciseListener(ctx, t, func(ctx context.Context, t *testing.T) riverdriver.Driver[*sql.Tx] {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		stdPool := stdlib.OpenDBFromPool(dbPool)
+		t.Cleanup(func() { require.NoError(t, stdPool.Close()) })
+
+		return riverdatabasesql.New(stdPool)
+	})
+}
+
 func TestDriverRiverPgxV5_Executor(t *testing.T) {
 	t.Parallel()
 
@@ -28,4 +28,10 @@ type ErrorHandlerResult struct {
 	// permanently. By default it'll continue to follow the configured retry
 	// schedule.
 	SetCancelled bool
+
+	// SetDiscarded can be set to true to discard the job immediately and
+	// permanently, as if it had exhausted all of its attempts. It has the same
+	// effect as returning JobDiscard from a worker. If both SetCancelled and
+	// SetDiscarded are set, the job is cancelled.
+	SetDiscarded bool
 }
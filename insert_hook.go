@@ -0,0 +1,45 @@
+package river
+
+import (
+	"context"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+// InsertHook is invoked for every job inserted by a client, including those
+// inserted with Insert, InsertTx, InsertMany, InsertManyTx, and by periodic
+// jobs. Hooks are configured on a client with Config.InsertHooks.
+//
+// A hook receives the args of the job being inserted along with the insert
+// parameters that have been computed for it from InsertOpts, any
+// JobArgsWithInsertOpts implementation, and client-level defaults. The hook
+// may modify params to change the job's queue, priority, tags, metadata, max
+// attempts, or scheduled time. Kind and EncodedArgs should not be modified.
+// Validation like checking the queue name and priority runs after all hooks
+// have been invoked, and a job with a non-nil ScheduledAt will be inserted as
+// scheduled.
+//
+// Returning an error rejects the insert. The error is returned from the insert
+// function that was called, and any remaining hooks aren't invoked. In the
+// case of a batch insert, no jobs in the batch are inserted.
+type InsertHook interface {
+	Insert(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error
+}
+
+// InsertHookFunc is a function that implements the InsertHook interface so
+// that a plain function can be used as a hook:
+//
+//	config := &river.Config{
+//		InsertHooks: []river.InsertHook{
+//			river.InsertHookFunc(func(ctx context.Context, args river.JobArgs, params *riverdriver.JobInsertFastParams) error {
+//				params.Tags = append(params.Tags, "from_hook")
+//				return nil
+//			}),
+//		},
+//	}
+type InsertHookFunc func(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error
+
+// Insert invokes the function with the given arguments.
+func (f InsertHookFunc) Insert(ctx context.Context, args JobArgs, params *riverdriver.JobInsertFastParams) error {
+	return f(ctx, args, params)
+}
@@ -13,6 +13,40 @@ import (
 // insertion time. These will override any default InsertOpts settings provided
 // by JobArgsWithInsertOpts, as well as any global defaults.
 type InsertOpts struct {
+	// DependsOn is a set of IDs of jobs that must complete before this job
+	// becomes eligible to be worked. A job with dependencies is inserted in the
+	// `pending` state, and is moved to `available` (or `scheduled` if
+	// ScheduledAt is in the future) by a maintenance service once all the jobs
+	// it depends on have completed.
+	//
+	// If any dependency is cancelled or discarded instead, the pending job is
+	// finalized according to the client's Config.DependencyFailureAction.
+	//
+	// Every dependency must exist when the job is inserted, or the insert
+	// returns an error wrapping ErrNotFound. Dependencies inserted earlier in
+	// the same transaction count. A dependency that's deleted afterwards, like
+	// by Client.JobDelete or by the job cleaner once it's been finalized for
+	// long enough, is considered satisfied, so deleting a job that others
+	// depend on before it's completed lets them run.
+	DependsOn []int64
+
+	// ExpiresAt is a deadline before which the job must be started. A job
+	// that's still waiting to be worked when its deadline passes, including
+	// one that's retryable after a previous failed attempt, won't be fetched
+	// for work and is discarded by a maintenance service with an error
+	// recording that it expired. Jobs that have already started running when
+	// the deadline passes are allowed to finish.
+	//
+	// The expiration time is readable through JobRow.ExpiresAt. Because
+	// JobArgsWithInsertOpts.InsertOpts is invoked each time a job is inserted,
+	// it can return a deadline relative to the current time to give all jobs
+	// of a kind a time-to-live:
+	//
+	//	func (MyArgs) InsertOpts() river.InsertOpts {
+	//		return river.InsertOpts{ExpiresAt: time.Now().Add(15 * time.Minute)}
+	//	}
+	ExpiresAt time.Time
+
 	// MaxAttempts is the maximum number of total attempts (including both the
 	// original run and all retries) before a job is abandoned and set as
 	// discarded.
@@ -58,6 +92,30 @@ type InsertOpts struct {
 	// UniqueOpts returns options relating to job uniqueness. An empty struct
 	// avoids setting any worker-level unique options.
 	UniqueOpts UniqueOpts
+
+	// pending indicates that the job has dependencies that aren't expressed
+	// through DependsOn, like those of a workflow task, so it should be
+	// inserted as pending.
+	pending bool
+}
+
+// DependencyFailureAction is the action taken on a pending job when one of the
+// jobs it depends on is cancelled or discarded instead of completing.
+type DependencyFailureAction string
+
+const (
+	// DependencyFailureActionCancel sets the pending job to `cancelled`.
+	DependencyFailureActionCancel DependencyFailureAction = "cancel"
+
+	// DependencyFailureActionDiscard sets the pending job to `discarded`.
+	DependencyFailureActionDiscard DependencyFailureAction = "discard"
+)
+
+func (a DependencyFailureAction) jobState() rivertype.JobState {
+	if a == DependencyFailureActionDiscard {
+		return rivertype.JobStateDiscarded
+	}
+	return rivertype.JobStateCancelled
 }
 
 // UniqueOpts contains parameters for uniqueness for a job.
@@ -112,7 +170,7 @@ type UniqueOpts struct {
 	// Unlike other unique options, ByState gets a default when it's not set for
 	// user convenience. The default is equivalent to:
 	//
-	// 	ByState: []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateCompleted, rivertype.JobStateRunning, rivertype.JobStateRetryable, rivertype.JobStateScheduled}
+	// 	ByState: []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateCompleted, rivertype.JobStatePending, rivertype.JobStateRunning, rivertype.JobStateRetryable, rivertype.JobStateScheduled}
 	//
 	// With this setting, any jobs of the same kind that have been completed or
 	// discarded, but not yet cleaned out by the system, won't count towards the
@@ -138,6 +196,7 @@ var jobStateAll = []rivertype.JobState{ //nolint:gochecknoglobals
 	rivertype.JobStateCancelled,
 	rivertype.JobStateCompleted,
 	rivertype.JobStateDiscarded,
+	rivertype.JobStatePending,
 	rivertype.JobStateRetryable,
 	rivertype.JobStateRunning,
 	rivertype.JobStateScheduled,
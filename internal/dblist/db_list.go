@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/riverqueue/river/internal/util/sliceutil"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivertype"
 )
@@ -14,7 +15,7 @@ const jobList = `-- name: JobList :many
 SELECT
   %s
 FROM
-  river_job
+  /* TEMPLATE: schema */river_job
 %s
 ORDER BY
   %s
@@ -36,13 +37,14 @@ type JobListOrderBy struct {
 
 type JobListParams struct {
 	Conditions string
+	IDs        []int64
 	Kinds      []string
 	LimitCount int32
 	NamedArgs  map[string]any
 	OrderBy    []JobListOrderBy
 	Priorities []int16
 	Queues     []string
-	State      rivertype.JobState
+	States     []rivertype.JobState
 }
 
 func JobList(ctx context.Context, exec riverdriver.Executor, params *JobListParams) ([]*rivertype.JobRow, error) {
@@ -69,22 +71,34 @@ func JobList(ctx context.Context, exec riverdriver.Executor, params *JobListPara
 		}
 	}
 
+	if len(params.IDs) > 0 {
+		writeWhereOrAnd()
+		conditionsBuilder.WriteString("id = any(@ids::bigint[])")
+		namedArgs["ids"] = params.IDs
+	}
+
 	if len(params.Kinds) > 0 {
 		writeWhereOrAnd()
 		conditionsBuilder.WriteString("kind = any(@kinds::text[])")
 		namedArgs["kinds"] = params.Kinds
 	}
 
+	if len(params.Priorities) > 0 {
+		writeWhereOrAnd()
+		conditionsBuilder.WriteString("priority = any(@priorities::smallint[])")
+		namedArgs["priorities"] = params.Priorities
+	}
+
 	if len(params.Queues) > 0 {
 		writeWhereOrAnd()
 		conditionsBuilder.WriteString("queue = any(@queues::text[])")
 		namedArgs["queues"] = params.Queues
 	}
 
-	if params.State != "" {
+	if len(params.States) > 0 {
 		writeWhereOrAnd()
-		conditionsBuilder.WriteString("state = @state::river_job_state")
-		namedArgs["state"] = params.State
+		conditionsBuilder.WriteString("state = any(@states::text[]::/* TEMPLATE: schema */river_job_state[])")
+		namedArgs["states"] = sliceutil.Map(params.States, func(s rivertype.JobState) string { return string(s) })
 	}
 
 	if params.Conditions != "" {
@@ -40,7 +40,7 @@ func TestJobListNoJobs(t *testing.T) {
 		bundle := setup()
 
 		_, err := JobList(ctx, bundle.exec, &JobListParams{
-			State:      rivertype.JobStateCompleted,
+			States:     []rivertype.JobState{rivertype.JobStateCompleted},
 			LimitCount: 1,
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
 		})
@@ -55,7 +55,7 @@ func TestJobListNoJobs(t *testing.T) {
 		_, err := JobList(ctx, bundle.exec, &JobListParams{
 			Conditions: "queue = 'test' AND priority = 1 AND args->>'foo' = @foo",
 			NamedArgs:  pgx.NamedArgs{"foo": "bar"},
-			State:      rivertype.JobStateCompleted,
+			States:     []rivertype.JobState{rivertype.JobStateCompleted},
 			LimitCount: 1,
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
 		})
@@ -123,7 +123,7 @@ func TestJobListWithJobs(t *testing.T) {
 		params := &JobListParams{
 			LimitCount: 3,
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderDesc}},
-			State:      rivertype.JobStateAvailable,
+			States:     []rivertype.JobState{rivertype.JobStateAvailable},
 		}
 
 		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
@@ -150,7 +150,7 @@ func TestJobListWithJobs(t *testing.T) {
 			LimitCount: 2,
 			NamedArgs:  map[string]any{"paths1": []string{"job_num"}, "value1": 2},
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderDesc}},
-			State:      rivertype.JobStateAvailable,
+			States:     []rivertype.JobState{rivertype.JobStateAvailable},
 		}
 
 		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
@@ -172,7 +172,7 @@ func TestJobListWithJobs(t *testing.T) {
 			LimitCount: 2,
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderDesc}},
 			Kinds:      []string{"alternate_kind"},
-			State:      rivertype.JobStateAvailable,
+			States:     []rivertype.JobState{rivertype.JobStateAvailable},
 		}
 
 		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
@@ -194,7 +194,7 @@ func TestJobListWithJobs(t *testing.T) {
 			LimitCount: 2,
 			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderDesc}},
 			Queues:     []string{"priority"},
-			State:      rivertype.JobStateAvailable,
+			States:     []rivertype.JobState{rivertype.JobStateAvailable},
 		}
 
 		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
@@ -206,6 +206,67 @@ func TestJobListWithJobs(t *testing.T) {
 		})
 	})
 
+	t.Run("WithIDs", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		params := &JobListParams{
+			IDs:        []int64{bundle.jobs[1].ID, bundle.jobs[3].ID},
+			LimitCount: 5,
+			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
+		}
+
+		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
+			require.NoError(t, err)
+
+			returnedIDs := sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID })
+			require.Equal(t, []int64{bundle.jobs[1].ID, bundle.jobs[3].ID}, returnedIDs)
+		})
+	})
+
+	t.Run("WithPriorities", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3)})
+
+		params := &JobListParams{
+			LimitCount: 5,
+			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
+			Priorities: []int16{2, 3},
+		}
+
+		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
+			require.NoError(t, err)
+
+			returnedIDs := sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID })
+			require.Equal(t, []int64{job.ID}, returnedIDs)
+		})
+	})
+
+	t.Run("WithMultipleStates", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+
+		params := &JobListParams{
+			LimitCount: 5,
+			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
+			States:     []rivertype.JobState{rivertype.JobStateRunning, rivertype.JobStateScheduled},
+		}
+
+		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
+			require.NoError(t, err)
+
+			returnedIDs := sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID })
+			require.Equal(t, []int64{bundle.jobs[3].ID, job.ID}, returnedIDs)
+		})
+	})
+
 	t.Run("WithMetadataAndNoStateFilter", func(t *testing.T) {
 		t.Parallel()
 
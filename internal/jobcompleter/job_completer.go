@@ -11,6 +11,7 @@ import (
 	"github.com/riverqueue/river/internal/baseservice"
 	"github.com/riverqueue/river/internal/jobstats"
 	"github.com/riverqueue/river/internal/util/timeutil"
+	"github.com/riverqueue/river/internal/util/valutil"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivertype"
 )
@@ -39,6 +40,7 @@ type CompleterJobUpdated struct {
 // to more easily facilitate mocking.
 type PartialExecutor interface {
 	JobSetStateIfRunning(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error)
+	JobSetStateIfRunningMany(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error)
 }
 
 type InlineJobCompleter struct {
@@ -169,12 +171,173 @@ func (c *AsyncJobCompleter) Wait() {
 	_ = c.eg.Wait()
 }
 
+const (
+	BatchCompleterIntervalDefault = 50 * time.Millisecond
+	BatchCompleterMaxInFlight     = 10
+	BatchCompleterMaxSizeDefault  = 1_000
+)
+
+// BatchJobCompleter accumulates completions and applies them in batches using
+// a single query for each batch rather than one query per job. A batch is
+// flushed after Interval has elapsed since its first completion was received,
+// or as soon as it reaches MaxSize, whichever comes first.
+//
+// Like AsyncJobCompleter, completions happen in the background, and
+// JobSetStateIfRunning never returns an error. At most BatchCompleterMaxInFlight
+// batches are completed at once. Once that many are in flight and another batch
+// fills up, JobSetStateIfRunning blocks until one of them finishes, so the
+// number of completions waiting to be applied can't grow without bound when the
+// database falls behind.
+type BatchJobCompleter struct {
+	baseservice.BaseService
+
+	eg              *errgroup.Group
+	exec            PartialExecutor
+	interval        time.Duration
+	maxSize         int
+	subscribeFunc   func(update CompleterJobUpdated)
+	subscribeFuncMu sync.Mutex
+
+	mu      sync.Mutex
+	pending []*batchCompleterSetState
+	timer   *time.Timer
+}
+
+// batchCompleterSetState is a single completion waiting to be flushed as part
+// of a batch.
+type batchCompleterSetState struct {
+	params *riverdriver.JobSetStateIfRunningParams
+	start  time.Time
+	stats  *jobstats.JobStatistics
+}
+
+// NewBatchCompleter returns a new batch completer. Interval and maxSize may be
+// left as zero to use BatchCompleterIntervalDefault and
+// BatchCompleterMaxSizeDefault respectively.
+func NewBatchCompleter(archetype *baseservice.Archetype, exec PartialExecutor, interval time.Duration, maxSize int) *BatchJobCompleter {
+	eg := &errgroup.Group{}
+	eg.SetLimit(BatchCompleterMaxInFlight)
+
+	return baseservice.Init(archetype, &BatchJobCompleter{
+		eg:       eg,
+		exec:     exec,
+		interval: valutil.ValOrDefault(interval, BatchCompleterIntervalDefault),
+		maxSize:  valutil.ValOrDefault(maxSize, BatchCompleterMaxSizeDefault),
+	})
+}
+
+func (c *BatchJobCompleter) JobSetStateIfRunning(stats *jobstats.JobStatistics, params *riverdriver.JobSetStateIfRunningParams) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, &batchCompleterSetState{params: params, start: c.TimeNowUTC(), stats: stats})
+
+	if len(c.pending) >= c.maxSize {
+		c.flushLocked()
+		return nil
+	}
+
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.interval, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.flushLocked()
+		})
+	}
+
+	return nil
+}
+
+func (c *BatchJobCompleter) Subscribe(subscribeFunc func(update CompleterJobUpdated)) {
+	c.subscribeFuncMu.Lock()
+	defer c.subscribeFuncMu.Unlock()
+
+	c.subscribeFunc = subscribeFunc
+}
+
+// Wait flushes any pending completions immediately instead of waiting for the
+// batch interval to elapse, then waits for all flushed batches to finish.
+func (c *BatchJobCompleter) Wait() {
+	func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.flushLocked()
+	}()
+
+	// completeBatch never returns an error.
+	_ = c.eg.Wait()
+}
+
+func (c *BatchJobCompleter) completeBatch(batch []*batchCompleterSetState) {
+	params := make([]*riverdriver.JobSetStateIfRunningParams, len(batch))
+	for i, setState := range batch {
+		params[i] = setState.params
+	}
+
+	jobs, err := withRetries(&c.BaseService, func(ctx context.Context) ([]*rivertype.JobRow, error) {
+		return c.exec.JobSetStateIfRunningMany(ctx, params)
+	})
+	if err != nil {
+		// Already logged by withRetries. Jobs that failed to complete are left
+		// running and will eventually be rescued.
+		return
+	}
+
+	jobsByID := make(map[int64]*rivertype.JobRow, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+
+	now := c.TimeNowUTC()
+
+	c.subscribeFuncMu.Lock()
+	defer c.subscribeFuncMu.Unlock()
+
+	for _, setState := range batch {
+		job, ok := jobsByID[setState.params.ID]
+		if !ok {
+			continue
+		}
+
+		setState.stats.CompleteDuration = now.Sub(setState.start)
+
+		if c.subscribeFunc != nil {
+			c.subscribeFunc(CompleterJobUpdated{Job: job, JobStats: setState.stats})
+		}
+	}
+}
+
+// flushLocked sends all pending completions off to be completed as a batch in
+// the background. Must be called with mu held. If the maximum number of batches
+// are already being completed, blocks until one finishes, which holds off any
+// other completions until then.
+func (c *BatchJobCompleter) flushLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	if len(c.pending) < 1 {
+		return
+	}
+
+	batch := c.pending
+	c.pending = nil
+
+	c.eg.Go(func() error {
+		c.completeBatch(batch)
+		return nil
+	})
+}
+
 // As configued, total time from initial attempt is ~7 seconds (1 + 2 + 4) (not
 // including jitter). I put in a basic retry algorithm to hold us over, but we
 // may want to rethink these numbers and strategy.
 const numRetries = 3
 
-func withRetries(c *baseservice.BaseService, f func(ctx context.Context) (*rivertype.JobRow, error)) (*rivertype.JobRow, error) { //nolint:varnamelen
+func withRetries[T any](c *baseservice.BaseService, f func(ctx context.Context) (T, error)) (T, error) { //nolint:varnamelen
 	retrySecondsWithoutJitter := func(attempt int) float64 {
 		// Uses a different algorithm (2 ** N) compared to retry policies (4 **
 		// N) so we can get more retries sooner: 1, 2, 4, 8
@@ -190,7 +353,7 @@ func withRetries(c *baseservice.BaseService, f func(ctx context.Context) (*river
 		return retrySeconds
 	}
 
-	tryOnce := func() (*rivertype.JobRow, error) {
+	tryOnce := func() (T, error) {
 		ctx := context.Background()
 
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -221,5 +384,6 @@ func withRetries(c *baseservice.BaseService, f func(ctx context.Context) (*river
 	// TODO: this logger doesn't use the user-provided context because it's
 	// not currently available here. It should.
 	c.Logger.Error(c.Name + ": Too many errors; giving up")
-	return nil, lastErr
+	var defaultRes T
+	return defaultRes, lastErr
 }
@@ -3,6 +3,7 @@ package jobcompleter
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -11,14 +12,17 @@ import (
 
 	"github.com/riverqueue/river/internal/jobstats"
 	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/internal/util/sliceutil"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivertype"
 )
 
 type executorMock struct {
-	JobSetStateIfRunningCalled bool
-	JobSetStateIfRunningFunc   func(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error)
-	mu                         sync.Mutex
+	JobSetStateIfRunningCalled     bool
+	JobSetStateIfRunningFunc       func(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error)
+	JobSetStateIfRunningManyCalled bool
+	JobSetStateIfRunningManyFunc   func(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error)
+	mu                             sync.Mutex
 }
 
 func (m *executorMock) JobSetStateIfRunning(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error) {
@@ -29,6 +33,31 @@ func (m *executorMock) JobSetStateIfRunning(ctx context.Context, params *riverdr
 	return m.JobSetStateIfRunningFunc(ctx, params)
 }
 
+// JobSetStateIfRunningMany invokes JobSetStateIfRunningManyFunc if set, and
+// otherwise falls back to invoking JobSetStateIfRunningFunc once for each job
+// so that the same mock can be shared between completer implementations.
+func (m *executorMock) JobSetStateIfRunningMany(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+	m.mu.Lock()
+	m.JobSetStateIfRunningManyCalled = true
+	m.mu.Unlock()
+
+	if m.JobSetStateIfRunningManyFunc != nil {
+		return m.JobSetStateIfRunningManyFunc(ctx, params)
+	}
+
+	jobs := make([]*rivertype.JobRow, 0, len(params))
+	for _, params := range params {
+		job, err := m.JobSetStateIfRunningFunc(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
 func TestInlineJobCompleter_Complete(t *testing.T) {
 	t.Parallel()
 
@@ -45,7 +74,7 @@ func TestInlineJobCompleter_Complete(t *testing.T) {
 	completer := NewInlineCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), adapter)
 	t.Cleanup(completer.Wait)
 
-	err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now()))
+	err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now(), nil))
 	if !errors.Is(err, expectedErr) {
 		t.Errorf("expected %v, got %v", expectedErr, err)
 	}
@@ -101,14 +130,14 @@ func TestAsyncJobCompleter_Complete(t *testing.T) {
 
 	// launch 4 completions, only 2 can be inline due to the concurrency limit:
 	for i := int64(0); i < 2; i++ {
-		if err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now())); err != nil {
+		if err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now(), nil)); err != nil {
 			t.Errorf("expected nil err, got %v", err)
 		}
 	}
 	bgCompletionsStarted := make(chan struct{})
 	go func() {
 		for i := int64(2); i < 4; i++ {
-			if err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now())); err != nil {
+			if err := completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now(), nil)); err != nil {
 				t.Errorf("expected nil err, got %v", err)
 			}
 		}
@@ -171,12 +200,150 @@ func TestAsyncJobCompleter_Wait(t *testing.T) {
 	})
 }
 
+func TestBatchJobCompleter_Complete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CompletesInBatch", func(t *testing.T) {
+		t.Parallel()
+
+		batchCh := make(chan []int64, 10)
+		exec := &executorMock{
+			JobSetStateIfRunningManyFunc: func(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+				batchCh <- sliceutil.Map(params, func(p *riverdriver.JobSetStateIfRunningParams) int64 { return p.ID })
+				return sliceutil.Map(params, func(p *riverdriver.JobSetStateIfRunningParams) *rivertype.JobRow {
+					return &rivertype.JobRow{ID: p.ID, State: p.State}
+				}), nil
+			},
+		}
+
+		completer := NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, 50*time.Millisecond, 100)
+		t.Cleanup(completer.Wait)
+
+		for i := int64(0); i < 4; i++ {
+			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now(), nil)))
+		}
+
+		// All four completions are applied with a single call once the batch
+		// interval elapses.
+		require.Equal(t, []int64{0, 1, 2, 3}, riverinternaltest.WaitOrTimeout(t, batchCh))
+		require.False(t, exec.JobSetStateIfRunningCalled)
+	})
+
+	t.Run("FlushesAtMaxSize", func(t *testing.T) {
+		t.Parallel()
+
+		batchCh := make(chan []int64, 10)
+		exec := &executorMock{
+			JobSetStateIfRunningManyFunc: func(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+				batchCh <- sliceutil.Map(params, func(p *riverdriver.JobSetStateIfRunningParams) int64 { return p.ID })
+				return nil, nil
+			},
+		}
+
+		// Interval is long enough that only reaching max size flushes batches.
+		completer := NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, time.Hour, 2)
+		t.Cleanup(completer.Wait)
+
+		for i := int64(0); i < 5; i++ {
+			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now(), nil)))
+		}
+
+		batches := riverinternaltest.WaitOrTimeoutN(t, batchCh, 2)
+		slices.SortFunc(batches, func(a, b []int64) int { return int(a[0] - b[0]) })
+		require.Equal(t, [][]int64{{0, 1}, {2, 3}}, batches)
+
+		// The final job is still pending, but is flushed by Wait.
+		completer.Wait()
+		require.Equal(t, []int64{4}, riverinternaltest.WaitOrTimeout(t, batchCh))
+	})
+
+	t.Run("BlocksAtMaxInFlight", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			batchCh   = make(chan []int64, BatchCompleterMaxInFlight+1)
+			releaseCh = make(chan struct{})
+		)
+		exec := &executorMock{
+			JobSetStateIfRunningManyFunc: func(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+				batchCh <- sliceutil.Map(params, func(p *riverdriver.JobSetStateIfRunningParams) int64 { return p.ID })
+				<-releaseCh
+				return nil, nil
+			},
+		}
+
+		// A max size of one flushes every completion as its own batch.
+		completer := NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, time.Hour, 1)
+		t.Cleanup(completer.Wait)
+
+		for i := int64(0); i < BatchCompleterMaxInFlight; i++ {
+			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(i, time.Now(), nil)))
+		}
+		riverinternaltest.WaitOrTimeoutN(t, batchCh, BatchCompleterMaxInFlight)
+
+		// With the maximum number of batches in flight, the next completion
+		// blocks until one of them finishes.
+		doneCh := make(chan struct{})
+		go func() {
+			defer close(doneCh)
+			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(BatchCompleterMaxInFlight, time.Now(), nil)))
+		}()
+
+		select {
+		case <-doneCh:
+			require.FailNow(t, "Expected completion to block")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(releaseCh)
+		riverinternaltest.WaitOrTimeout(t, doneCh)
+		require.Equal(t, []int64{BatchCompleterMaxInFlight}, riverinternaltest.WaitOrTimeout(t, batchCh))
+	})
+
+	t.Run("RetriesOnError", func(t *testing.T) {
+		t.Parallel()
+
+		var attempt int
+		expectedErr := errors.New("an error from the completer")
+		exec := &executorMock{
+			JobSetStateIfRunningManyFunc: func(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+				attempt++
+				return nil, expectedErr
+			},
+		}
+
+		completer := NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, 0, 0)
+
+		require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(1, time.Now(), nil)))
+
+		completer.Wait()
+		require.Equal(t, numRetries, attempt)
+	})
+}
+
+func TestBatchJobCompleter_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	testCompleterSubscribe(t, func(exec PartialExecutor) JobCompleter {
+		return NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, 0, 0)
+	})
+}
+
+func TestBatchJobCompleter_Wait(t *testing.T) {
+	t.Parallel()
+
+	testCompleterWait(t, func(exec PartialExecutor) JobCompleter {
+		return NewBatchCompleter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), exec, 0, 0)
+	})
+}
+
 func testCompleterSubscribe(t *testing.T, constructor func(PartialExecutor) JobCompleter) {
 	t.Helper()
 
 	exec := &executorMock{
 		JobSetStateIfRunningFunc: func(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error) {
 			return &rivertype.JobRow{
+				ID:    params.ID,
 				State: rivertype.JobStateCompleted,
 			}, nil
 		},
@@ -190,7 +357,7 @@ func testCompleterSubscribe(t *testing.T, constructor func(PartialExecutor) JobC
 	})
 
 	for i := 0; i < 4; i++ {
-		require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(int64(i), time.Now())))
+		require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(int64(i), time.Now(), nil)))
 	}
 
 	completer.Wait()
@@ -220,7 +387,7 @@ func testCompleterWait(t *testing.T, constructor func(PartialExecutor) JobComple
 	for i := 0; i < 4; i++ {
 		i := i
 		go func() {
-			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(int64(i), time.Now())))
+			require.NoError(t, completer.JobSetStateIfRunning(&jobstats.JobStatistics{}, riverdriver.JobSetStateCompleted(int64(i), time.Now(), nil)))
 		}()
 		<-completeStartedCh // wait for func to actually start
 	}
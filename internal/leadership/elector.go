@@ -59,6 +59,10 @@ type Elector struct {
 // NewElector returns an Elector using the given adapter. The name should correspond
 // to the name of the database + schema combo and should be shared across all Clients
 // running with that combination. The id should be unique to the Client.
+//
+// The notifier may be nil, in which case the elector won't be notified of
+// resignations by other leaders, and relies purely on polling at interval and
+// the expiration of leadership TTLs to elect a new leader.
 func NewElector(exec riverdriver.Executor, notifier *notifier.Notifier, name, id string, interval, ttlPadding time.Duration, logger *slog.Logger) (*Elector, error) {
 	// TODO: validate name + id length/format, interval, etc
 	return &Elector{
@@ -114,8 +118,10 @@ func (e *Elector) Run(ctx context.Context) {
 		}
 	}
 
-	subscription := e.notifier.Listen(notifier.NotificationTopicLeadership, handleNotification)
-	defer subscription.Unlisten()
+	if e.notifier != nil {
+		subscription := e.notifier.Listen(notifier.NotificationTopicLeadership, handleNotification)
+		defer subscription.Unlisten()
+	}
 
 	for {
 		if success := e.gainLeadership(ctx, leadershipNotificationChan); !success {
@@ -0,0 +1,163 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/internal/baseservice"
+	"github.com/riverqueue/river/internal/maintenance/startstop"
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/internal/util/timeutil"
+	"github.com/riverqueue/river/internal/util/valutil"
+	"github.com/riverqueue/river/riverdriver"
+)
+
+const (
+	JobExpirerIntervalDefault = 30 * time.Second
+	JobExpirerLimitDefault    = 10_000
+)
+
+// Test-only properties.
+type JobExpirerTestSignals struct {
+	ExpiredBatch rivercommon.TestSignal[struct{}] // notifies when runOnce finishes a pass
+}
+
+func (ts *JobExpirerTestSignals) Init() {
+	ts.ExpiredBatch.Init()
+}
+
+type JobExpirerConfig struct {
+	// Interval is the amount of time between periodic checks for jobs that
+	// have expired before being started.
+	Interval time.Duration
+
+	// Limit is the maximum number of jobs to expire at once.
+	Limit int
+}
+
+func (c *JobExpirerConfig) mustValidate() *JobExpirerConfig {
+	if c.Interval <= 0 {
+		panic("JobExpirerConfig.Interval must be above zero")
+	}
+	if c.Limit <= 0 {
+		panic("JobExpirerConfig.Limit must be above zero")
+	}
+
+	return c
+}
+
+// JobExpirer periodically discards jobs that have an expiration time (see
+// InsertOpts.ExpiresAt) which passed before they were started. Jobs that are
+// available, pending, retryable, or scheduled are all eligible for expiration,
+// while jobs that are already running are left to finish.
+type JobExpirer struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	TestSignals JobExpirerTestSignals
+
+	config *JobExpirerConfig
+	exec   riverdriver.Executor
+}
+
+func NewJobExpirer(archetype *baseservice.Archetype, config *JobExpirerConfig, exec riverdriver.Executor) *JobExpirer {
+	return baseservice.Init(archetype, &JobExpirer{
+		config: (&JobExpirerConfig{
+			Interval: valutil.ValOrDefault(config.Interval, JobExpirerIntervalDefault),
+			Limit:    valutil.ValOrDefault(config.Limit, JobExpirerLimitDefault),
+		}).mustValidate(),
+		exec: exec,
+	})
+}
+
+func (s *JobExpirer) Start(ctx context.Context) error { //nolint:dupl
+	ctx, shouldStart, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	// Jitter start up slightly so services don't all perform their first run at
+	// exactly the same time.
+	s.CancellableSleepRandomBetween(ctx, JitterMin, JitterMax)
+
+	go func() {
+		// This defer should come first so that it's last out, thereby avoiding
+		// races.
+		defer close(stopped)
+
+		s.Logger.InfoContext(ctx, s.Name+logPrefixRunLoopStarted)
+		defer s.Logger.InfoContext(ctx, s.Name+logPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error expiring jobs", slog.String("error", err.Error()))
+				}
+				continue
+			}
+			s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+				slog.Int("num_jobs_expired", res.NumJobsExpired),
+			)
+		}
+	}()
+
+	return nil
+}
+
+type jobExpirerRunOnceResult struct {
+	NumJobsExpired int
+}
+
+func (s *JobExpirer) runOnce(ctx context.Context) (*jobExpirerRunOnceResult, error) {
+	res := &jobExpirerRunOnceResult{}
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		numExpired, err := func() (int, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
+			defer cancelFunc()
+
+			numExpired, err := s.exec.JobExpire(ctx, &riverdriver.JobExpireParams{
+				Max: s.config.Limit,
+				Now: s.TimeNowUTC(),
+			})
+			if err != nil {
+				return 0, fmt.Errorf("error expiring jobs: %w", err)
+			}
+
+			return numExpired, nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		s.TestSignals.ExpiredBatch.Signal(struct{}{})
+
+		res.NumJobsExpired += numExpired
+
+		// Expired was less than query `LIMIT` which means work is done.
+		if numExpired < s.config.Limit {
+			break
+		}
+
+		s.Logger.InfoContext(ctx, s.Name+": Expired batch of jobs",
+			slog.Int("num_jobs_expired", numExpired),
+		)
+
+		s.CancellableSleepRandomBetween(ctx, BatchBackoffMin, BatchBackoffMax)
+	}
+
+	return res, nil
+}
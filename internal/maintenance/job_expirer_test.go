@@ -0,0 +1,153 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/internal/riverinternaltest/testfactory"
+	"github.com/riverqueue/river/internal/util/ptrutil"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestJobExpirer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*JobExpirer, *testBundle) {
+		t.Helper()
+
+		tx := riverinternaltest.TestTx(ctx, t)
+		bundle := &testBundle{
+			exec: riverpgxv5.New(nil).UnwrapExecutor(tx),
+		}
+
+		expirer := NewJobExpirer(
+			riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(),
+			&JobExpirerConfig{
+				Interval: JobExpirerIntervalDefault,
+				Limit:    10,
+			},
+			bundle.exec)
+		expirer.TestSignals.Init()
+		t.Cleanup(expirer.Stop)
+
+		return expirer, bundle
+	}
+
+	requireJobState := func(t *testing.T, exec riverdriver.Executor, job *rivertype.JobRow, state rivertype.JobState) *rivertype.JobRow {
+		t.Helper()
+		newJob, err := exec.JobGetByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, state, newJob.State)
+		return newJob
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		expirer := NewJobExpirer(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), &JobExpirerConfig{}, nil)
+
+		require.Equal(t, JobExpirerIntervalDefault, expirer.config.Interval)
+		require.Equal(t, JobExpirerLimitDefault, expirer.config.Limit)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		expirer, _ := setup(t)
+		expirer.Logger = riverinternaltest.LoggerWarn(t) // loop started/stop log is very noisy; suppress
+		expirer.TestSignals = JobExpirerTestSignals{}    // deinit so channels don't fill
+
+		runStartStopStress(ctx, t, expirer)
+	})
+
+	t.Run("ExpiresJobsPastDeadline", func(t *testing.T) {
+		t.Parallel()
+
+		expirer, bundle := setup(t)
+
+		now := time.Now().UTC()
+		expired := ptrutil.Ptr(now.Add(-1 * time.Minute))
+		notExpired := ptrutil.Ptr(now.Add(1 * time.Hour))
+
+		availableJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		pendingJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		retryableJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+		scheduledJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+
+		// Not expired because they haven't reached their deadline, have no
+		// deadline, or have already been started.
+		notExpiredJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: notExpired, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		noDeadlineJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		require.NoError(t, expirer.Start(ctx))
+
+		expirer.TestSignals.ExpiredBatch.WaitOrTimeout()
+
+		for _, job := range []*rivertype.JobRow{availableJob, pendingJob, retryableJob, scheduledJob} {
+			updatedJob := requireJobState(t, bundle.exec, job, rivertype.JobStateDiscarded)
+			require.NotNil(t, updatedJob.FinalizedAt)
+			require.NotEmpty(t, updatedJob.Errors)
+			require.Equal(t, "Job expired before it was started", updatedJob.Errors[len(updatedJob.Errors)-1].Error)
+		}
+
+		requireJobState(t, bundle.exec, notExpiredJob, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, noDeadlineJob, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, runningJob, rivertype.JobStateRunning)
+	})
+
+	t.Run("ExpiresInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		expirer, bundle := setup(t)
+		expirer.config.Limit = 10 // reduced size for test speed
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numJobs := expirer.config.Limit + 1
+
+		expired := ptrutil.Ptr(time.Now().Add(-1 * time.Minute))
+
+		jobs := make([]*rivertype.JobRow, numJobs)
+		for i := 0; i < numJobs; i++ {
+			jobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		}
+
+		require.NoError(t, expirer.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		expirer.TestSignals.ExpiredBatch.WaitOrTimeout()
+		expirer.TestSignals.ExpiredBatch.WaitOrTimeout()
+
+		for _, job := range jobs {
+			requireJobState(t, bundle.exec, job, rivertype.JobStateDiscarded)
+		}
+	})
+
+	t.Run("RespectsContextCancellation", func(t *testing.T) {
+		t.Parallel()
+
+		expirer, _ := setup(t)
+		expirer.config.Interval = time.Minute // should only trigger once for the initial run
+
+		ctx, cancelFunc := context.WithCancel(ctx)
+
+		require.NoError(t, expirer.Start(ctx))
+
+		stopped := expirer.Stopped()
+		cancelFunc()
+		riverinternaltest.WaitOrTimeout(t, stopped)
+	})
+}
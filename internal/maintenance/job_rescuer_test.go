@@ -34,10 +34,11 @@ type callbackWorkUnit struct {
 	jobRow   *rivertype.JobRow
 }
 
-func (w *callbackWorkUnit) NextRetry() time.Time           { return time.Now().Add(30 * time.Second) }
-func (w *callbackWorkUnit) Timeout() time.Duration         { return 0 }
-func (w *callbackWorkUnit) Work(ctx context.Context) error { return w.callback(ctx, w.jobRow) }
-func (w *callbackWorkUnit) UnmarshalJob() error            { return nil }
+func (w *callbackWorkUnit) Middleware() []rivertype.WorkerMiddleware { return nil }
+func (w *callbackWorkUnit) NextRetry() time.Time                     { return time.Now().Add(30 * time.Second) }
+func (w *callbackWorkUnit) Timeout() time.Duration                   { return 0 }
+func (w *callbackWorkUnit) Work(ctx context.Context) error           { return w.callback(ctx, w.jobRow) }
+func (w *callbackWorkUnit) UnmarshalJob() error                      { return nil }
 
 type SimpleClientRetryPolicy struct{}
 
@@ -189,6 +190,39 @@ func TestJobRescuer(t *testing.T) {
 		require.Equal(notRunning3After.State, notRunningJob3.State)
 	})
 
+	t.Run("RescuesBasedOnHeartbeats", func(t *testing.T) {
+		t.Parallel()
+
+		rescuer, bundle := setup(t)
+
+		// Started long ago, but recorded a heartbeat recently.
+		heartbeatingJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), HeartbeatAt: ptrutil.Ptr(bundle.rescueHorizon.Add(1 * time.Minute)), MaxAttempts: ptrutil.Ptr(5)})
+
+		// Started long ago, and its last heartbeat was before the horizon too.
+		missedHeartbeatJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), HeartbeatAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Minute)), MaxAttempts: ptrutil.Ptr(5)})
+
+		// Has a heartbeat from before the horizon left over from an earlier
+		// attempt, but its current attempt started recently.
+		staleHeartbeatJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(1 * time.Minute)), HeartbeatAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), MaxAttempts: ptrutil.Ptr(5)})
+
+		require.NoError(t, rescuer.Start(ctx))
+
+		rescuer.TestSignals.FetchedBatch.WaitOrTimeout()
+		rescuer.TestSignals.UpdatedBatch.WaitOrTimeout()
+
+		heartbeatingJobAfter, err := bundle.exec.JobGetByID(ctx, heartbeatingJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, heartbeatingJobAfter.State)
+
+		missedHeartbeatJobAfter, err := bundle.exec.JobGetByID(ctx, missedHeartbeatJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, missedHeartbeatJobAfter.State)
+
+		staleHeartbeatJobAfter, err := bundle.exec.JobGetByID(ctx, staleHeartbeatJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, staleHeartbeatJobAfter.State)
+	})
+
 	t.Run("RescuesInBatches", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,188 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/internal/baseservice"
+	"github.com/riverqueue/river/internal/maintenance/startstop"
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/internal/util/timeutil"
+	"github.com/riverqueue/river/internal/util/valutil"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	PendingJobPromoterIntervalDefault = 1 * time.Second
+	PendingJobPromoterLimitDefault    = 10_000
+)
+
+// Test-only properties.
+type PendingJobPromoterTestSignals struct {
+	PromotedBatch rivercommon.TestSignal[struct{}] // notifies when runOnce finishes a pass
+}
+
+func (ts *PendingJobPromoterTestSignals) Init() {
+	ts.PromotedBatch.Init()
+}
+
+type PendingJobPromoterConfig struct {
+	// DependencyFailedState is the state that pending jobs are moved to in
+	// case one of their dependencies is cancelled or discarded. Must be either
+	// `cancelled` or `discarded`, and defaults to `cancelled`.
+	DependencyFailedState rivertype.JobState
+
+	// Interval is the amount of time between periodic checks for pending jobs
+	// whose dependencies have finished.
+	Interval time.Duration
+
+	// Limit is the maximum number of pending jobs to check at once during
+	// periodic promotion checks.
+	Limit int
+}
+
+func (c *PendingJobPromoterConfig) mustValidate() *PendingJobPromoterConfig {
+	if c.DependencyFailedState != rivertype.JobStateCancelled && c.DependencyFailedState != rivertype.JobStateDiscarded {
+		panic("PendingJobPromoterConfig.DependencyFailedState must be cancelled or discarded")
+	}
+	if c.Interval <= 0 {
+		panic("PendingJobPromoterConfig.Interval must be above zero")
+	}
+	if c.Limit <= 0 {
+		panic("PendingJobPromoterConfig.Limit must be above zero")
+	}
+
+	return c
+}
+
+// PendingJobPromoter periodically moves jobs in `pending` state whose
+// dependencies have all completed over to `available` (or `scheduled` if
+// they're scheduled in the future) so that they're eligible to be worked.
+// Pending jobs with a dependency that was cancelled or discarded are finalized
+// instead so that they don't wait forever.
+type PendingJobPromoter struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	TestSignals PendingJobPromoterTestSignals
+
+	config *PendingJobPromoterConfig
+	exec   riverdriver.Executor
+}
+
+func NewPendingJobPromoter(archetype *baseservice.Archetype, config *PendingJobPromoterConfig, exec riverdriver.Executor) *PendingJobPromoter {
+	return baseservice.Init(archetype, &PendingJobPromoter{
+		config: (&PendingJobPromoterConfig{
+			DependencyFailedState: valutil.ValOrDefault(config.DependencyFailedState, rivertype.JobStateCancelled),
+			Interval:              valutil.ValOrDefault(config.Interval, PendingJobPromoterIntervalDefault),
+			Limit:                 valutil.ValOrDefault(config.Limit, PendingJobPromoterLimitDefault),
+		}).mustValidate(),
+		exec: exec,
+	})
+}
+
+func (s *PendingJobPromoter) Start(ctx context.Context) error { //nolint:dupl
+	ctx, shouldStart, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	// Jitter start up slightly so services don't all perform their first run at
+	// exactly the same time.
+	s.CancellableSleepRandomBetween(ctx, JitterMin, JitterMax)
+
+	go func() {
+		// This defer should come first so that it's last out, thereby avoiding
+		// races.
+		defer close(stopped)
+
+		s.Logger.InfoContext(ctx, s.Name+logPrefixRunLoopStarted)
+		defer s.Logger.InfoContext(ctx, s.Name+logPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error promoting pending jobs", slog.String("error", err.Error()))
+				}
+				continue
+			}
+			s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+				slog.Int("num_jobs_failed", res.NumJobsFailed),
+				slog.Int("num_jobs_promoted", res.NumJobsPromoted),
+			)
+		}
+	}()
+
+	return nil
+}
+
+type pendingJobPromoterRunOnceResult struct {
+	NumJobsFailed   int
+	NumJobsPromoted int
+}
+
+func (s *PendingJobPromoter) runOnce(ctx context.Context) (*pendingJobPromoterRunOnceResult, error) {
+	var (
+		afterID int64
+		res     = &pendingJobPromoterRunOnceResult{}
+	)
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		promoteRes, err := func() (*riverdriver.JobPromotePendingResult, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
+			defer cancelFunc()
+
+			promoteRes, err := s.exec.JobPromotePending(ctx, &riverdriver.JobPromotePendingParams{
+				AfterID:               afterID,
+				DependencyFailedState: s.config.DependencyFailedState,
+				InsertTopic:           string(notifier.NotificationTopicInsert),
+				Max:                   s.config.Limit,
+				Now:                   s.TimeNowUTC(),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error promoting pending jobs: %w", err)
+			}
+
+			return promoteRes, nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		s.TestSignals.PromotedBatch.Signal(struct{}{})
+
+		res.NumJobsFailed += promoteRes.NumFailed
+		res.NumJobsPromoted += promoteRes.NumPromoted
+
+		// Checked was less than query `LIMIT` which means work is done.
+		if promoteRes.NumChecked < s.config.Limit {
+			break
+		}
+
+		afterID = promoteRes.LastID
+
+		s.Logger.InfoContext(ctx, s.Name+": Promoted batch of pending jobs",
+			slog.Int("num_jobs_failed", promoteRes.NumFailed),
+			slog.Int("num_jobs_promoted", promoteRes.NumPromoted),
+		)
+
+		s.CancellableSleepRandomBetween(ctx, BatchBackoffMin, BatchBackoffMax)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,188 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/internal/riverinternaltest/testfactory"
+	"github.com/riverqueue/river/internal/util/ptrutil"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestPendingJobPromoter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*PendingJobPromoter, *testBundle) {
+		t.Helper()
+
+		tx := riverinternaltest.TestTx(ctx, t)
+		bundle := &testBundle{
+			exec: riverpgxv5.New(nil).UnwrapExecutor(tx),
+		}
+
+		promoter := NewPendingJobPromoter(
+			riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(),
+			&PendingJobPromoterConfig{
+				Interval: PendingJobPromoterIntervalDefault,
+				Limit:    10,
+			},
+			bundle.exec)
+		promoter.TestSignals.Init()
+		t.Cleanup(promoter.Stop)
+
+		return promoter, bundle
+	}
+
+	requireJobState := func(t *testing.T, exec riverdriver.Executor, job *rivertype.JobRow, state rivertype.JobState) *rivertype.JobRow {
+		t.Helper()
+		newJob, err := exec.JobGetByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, state, newJob.State)
+		return newJob
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		promoter := NewPendingJobPromoter(riverinternaltest.BaseServiceArchetype(t).WithSleepDisabled(), &PendingJobPromoterConfig{}, nil)
+
+		require.Equal(t, rivertype.JobStateCancelled, promoter.config.DependencyFailedState)
+		require.Equal(t, PendingJobPromoterIntervalDefault, promoter.config.Interval)
+		require.Equal(t, PendingJobPromoterLimitDefault, promoter.config.Limit)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setup(t)
+		promoter.Logger = riverinternaltest.LoggerWarn(t)      // loop started/stop log is very noisy; suppress
+		promoter.TestSignals = PendingJobPromoterTestSignals{} // deinit so channels don't fill
+
+		runStartStopStress(ctx, t, promoter)
+	})
+
+	t.Run("PromotesJobsWithCompletedDependencies", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		completedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		noDependenciesJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+		completedDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		missingDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{123_456_789}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		runningDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID, runningJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		scheduledJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID}, ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, noDependenciesJob, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, completedDependencyJob, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, missingDependencyJob, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, runningDependencyJob, rivertype.JobStatePending)
+		requireJobState(t, bundle.exec, scheduledJob, rivertype.JobStateScheduled)
+	})
+
+	t.Run("FinalizesJobsWithFailedDependencies", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		cancelledJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		discardedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		runningJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		cancelledDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{cancelledJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		discardedDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{discardedJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		// Finalized without waiting for the remaining dependency.
+		mixedDependencyJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{discardedJob.ID, runningJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		for _, job := range []*rivertype.JobRow{cancelledDependencyJob, discardedDependencyJob, mixedDependencyJob} {
+			updatedJob := requireJobState(t, bundle.exec, job, rivertype.JobStateCancelled)
+			require.NotNil(t, updatedJob.FinalizedAt)
+		}
+	})
+
+	t.Run("ConfigurableDependencyFailedState", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+		promoter.config.DependencyFailedState = rivertype.JobStateDiscarded
+
+		now := time.Now().UTC()
+
+		cancelledJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		pendingJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{DependsOn: []int64{cancelledJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, pendingJob, rivertype.JobStateDiscarded)
+	})
+
+	t.Run("PromotesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+		promoter.config.Limit = 10 // reduced size for test speed
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numJobs := promoter.config.Limit + 1
+
+		jobs := make([]*rivertype.JobRow, numJobs)
+		for i := 0; i < numJobs; i++ {
+			jobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+		}
+
+		require.NoError(t, promoter.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		for _, job := range jobs {
+			requireJobState(t, bundle.exec, job, rivertype.JobStateAvailable)
+		}
+	})
+
+	t.Run("RespectsContextCancellation", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setup(t)
+		promoter.config.Interval = time.Minute // should only trigger once for the initial run
+
+		ctx, cancelFunc := context.WithCancel(ctx)
+
+		require.NoError(t, promoter.Start(ctx))
+
+		stopped := promoter.Stopped()
+		cancelFunc()
+		riverinternaltest.WaitOrTimeout(t, stopped)
+	})
+}
@@ -33,7 +33,7 @@ func (ts *PeriodicJobEnqueuerTestSignals) Init() {
 // river.PeriodicJobArgs, but needs a separate type because the enqueuer is in a
 // subpackage.
 type PeriodicJob struct {
-	ConstructorFunc func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error)
+	ConstructorFunc func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error)
 	RunOnStart      bool
 	ScheduleFunc    func(time.Time) time.Time
 
@@ -230,8 +230,8 @@ func (s *PeriodicJobEnqueuer) insertBatch(ctx context.Context, insertParamsMany
 	}
 }
 
-func (s *PeriodicJobEnqueuer) insertParamsFromConstructor(ctx context.Context, constructorFunc func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error)) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, bool) {
-	insertParams, uniqueOpts, err := constructorFunc()
+func (s *PeriodicJobEnqueuer) insertParamsFromConstructor(ctx context.Context, constructorFunc func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error)) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, bool) {
+	insertParams, uniqueOpts, err := constructorFunc(ctx)
 	if err != nil {
 		if errors.Is(err, ErrNoJobToInsert) {
 			s.Logger.InfoContext(ctx, s.Name+": nil returned from periodic job constructor, skipping")
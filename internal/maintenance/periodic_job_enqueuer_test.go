@@ -28,8 +28,8 @@ func TestPeriodicJobEnqueuer(t *testing.T) {
 		waitChan chan (struct{})
 	}
 
-	jobConstructorFunc := func(name string, unique bool) func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
-		return func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
+	jobConstructorFunc := func(name string, unique bool) func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
+		return func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
 			return &riverdriver.JobInsertFastParams{
 				EncodedArgs: []byte("{}"),
 				Kind:        name,
@@ -164,7 +164,7 @@ func TestPeriodicJobEnqueuer(t *testing.T) {
 
 		svc.periodicJobs = []*PeriodicJob{
 			// skip this insert when it returns nil:
-			{ScheduleFunc: periodicIntervalSchedule(time.Second), ConstructorFunc: func() (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
+			{ScheduleFunc: periodicIntervalSchedule(time.Second), ConstructorFunc: func(ctx context.Context) (*riverdriver.JobInsertFastParams, *dbunique.UniqueOpts, error) {
 				return nil, nil, ErrNoJobToInsert
 			}, RunOnStart: true},
 		}
@@ -145,7 +145,7 @@ func (s *Reindexer) reindexOne(ctx context.Context, indexName string) error {
 	ctx, cancel := context.WithTimeout(ctx, s.Config.Timeout)
 	defer cancel()
 
-	_, err := s.exec.Exec(ctx, "REINDEX INDEX CONCURRENTLY "+indexName)
+	_, err := s.exec.Exec(ctx, "REINDEX INDEX CONCURRENTLY "+riverdriver.SchemaTemplate+indexName)
 	if err != nil {
 		return err
 	}
@@ -16,9 +16,10 @@ import (
 type NotificationTopic string
 
 const (
-	NotificationTopicInsert     NotificationTopic = "river_insert"
-	NotificationTopicLeadership NotificationTopic = "river_leadership"
-	NotificationTopicJobControl NotificationTopic = "river_job_control"
+	NotificationTopicInsert       NotificationTopic = "river_insert"
+	NotificationTopicLeadership   NotificationTopic = "river_leadership"
+	NotificationTopicJobControl   NotificationTopic = "river_job_control"
+	NotificationTopicQueueControl NotificationTopic = "river_queue_control"
 )
 
 type NotifyFunc func(topic NotificationTopic, payload string)
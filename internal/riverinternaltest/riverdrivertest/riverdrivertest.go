@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -169,6 +170,246 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		})
 	})
 
+	t.Run("JobCancelMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CancelsMatchingJobsInBatches", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			availableJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			runningJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			scheduledJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+
+			// Not cancelled because already finalized or not matching.
+			completedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, Kind: ptrutil.Ptr("kind1"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			otherKindJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+
+			params := &riverdriver.JobCancelManyParams{
+				CancelAttemptedAt: now,
+				Filter:            &riverdriver.JobFilter{Kinds: []string{"kind1"}},
+				JobControlTopic:   string(notifier.NotificationTopicJobControl),
+				Max:               2,
+			}
+
+			// Max two cancelled on the first pass.
+			res, err := exec.JobCancelMany(ctx, params)
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.JobManyResult{LastID: runningJob.ID, NumJobs: 2}, res)
+
+			// And one more pass gets the last one.
+			params.AfterID = res.LastID
+			res, err = exec.JobCancelMany(ctx, params)
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.JobManyResult{LastID: scheduledJob.ID, NumJobs: 1}, res)
+
+			params.AfterID = res.LastID
+			res, err = exec.JobCancelMany(ctx, params)
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.JobManyResult{}, res)
+
+			for _, job := range []*rivertype.JobRow{availableJob, scheduledJob} {
+				jobAfter, err := exec.JobGetByID(ctx, job.ID)
+				require.NoError(t, err)
+				require.Equal(t, rivertype.JobStateCancelled, jobAfter.State)
+				require.NotNil(t, jobAfter.FinalizedAt)
+			}
+
+			// Running job is left for its client to cancel, but marked as such.
+			jobAfter, err := exec.JobGetByID(ctx, runningJob.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, jobAfter.State)
+			require.Nil(t, jobAfter.FinalizedAt)
+			require.JSONEq(t, fmt.Sprintf(`{"cancel_attempted_at":%q}`, now.Format(time.RFC3339Nano)), string(jobAfter.Metadata))
+
+			for _, job := range []*rivertype.JobRow{completedJob, otherKindJob} {
+				jobAfter, err := exec.JobGetByID(ctx, job.ID)
+				require.NoError(t, err)
+				require.Equal(t, job.State, jobAfter.State)
+			}
+		})
+
+		t.Run("Filters", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			matchingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"tenant": "a"}`), Queue: ptrutil.Ptr("queue1")})
+
+			// Each doesn't match exactly one of the filters.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Metadata: []byte(`{"tenant": "a"}`), Queue: ptrutil.Ptr("queue1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"tenant": "b"}`), Queue: ptrutil.Ptr("queue1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"tenant": "a"}`), Queue: ptrutil.Ptr("queue2")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"tenant": "a"}`), Queue: ptrutil.Ptr("queue1"), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+
+			res, err := exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+				CancelAttemptedAt: now,
+				Filter: &riverdriver.JobFilter{
+					CreatedAfter:     ptrutil.Ptr(now.Add(-time.Hour)),
+					CreatedBefore:    ptrutil.Ptr(now.Add(time.Hour)),
+					Kinds:            []string{"kind1"},
+					MetadataFragment: []byte(`{"tenant": "a"}`),
+					Queues:           []string{"queue1"},
+					States:           []rivertype.JobState{rivertype.JobStateAvailable},
+				},
+				JobControlTopic: string(notifier.NotificationTopicJobControl),
+				Max:             100,
+			})
+			require.NoError(t, err)
+			require.Equal(t, &riverdriver.JobManyResult{LastID: matchingJob.ID, NumJobs: 1}, res)
+
+			// Nothing created outside the time range.
+			res, err = exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+				CancelAttemptedAt: now,
+				Filter:            &riverdriver.JobFilter{CreatedAfter: ptrutil.Ptr(now.Add(time.Hour))},
+				JobControlTopic:   string(notifier.NotificationTopicJobControl),
+				Max:               100,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 0, res.NumJobs)
+		})
+	})
+
+	t.Run("JobCountByQueueKindAndState", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue1")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue2")})
+
+		sortCounts := func(counts []*riverdriver.JobCountByQueueKindAndState) []*riverdriver.JobCountByQueueKindAndState {
+			slices.SortFunc(counts, func(a, b *riverdriver.JobCountByQueueKindAndState) int {
+				return strings.Compare(a.Queue+a.Kind+string(a.State), b.Queue+b.Kind+string(b.State))
+			})
+			return counts
+		}
+
+		counts, err := exec.JobCountByQueueKindAndState(ctx, &riverdriver.JobCountByQueueKindAndStateParams{})
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.JobCountByQueueKindAndState{
+			{Count: 2, Kind: "kind1", Queue: "queue1", State: rivertype.JobStateAvailable},
+			{Count: 1, Kind: "kind1", Queue: "queue1", State: rivertype.JobStateRunning},
+			{Count: 1, Kind: "kind2", Queue: "queue1", State: rivertype.JobStateAvailable},
+			{Count: 1, Kind: "kind1", Queue: "queue2", State: rivertype.JobStateAvailable},
+		}, sortCounts(counts))
+
+		counts, err = exec.JobCountByQueueKindAndState(ctx, &riverdriver.JobCountByQueueKindAndStateParams{
+			Queues: []string{"queue2"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.JobCountByQueueKindAndState{
+			{Count: 1, Kind: "kind1", Queue: "queue2", State: rivertype.JobStateAvailable},
+		}, counts)
+	})
+
+	t.Run("JobCountByState", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		countByState, err := exec.JobCountByState(ctx)
+		require.NoError(t, err)
+		require.Empty(t, countByState)
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		countByState, err = exec.JobCountByState(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[rivertype.JobState]int{
+			rivertype.JobStateAvailable: 2,
+			rivertype.JobStateRunning:   1,
+		}, countByState)
+	})
+
+	t.Run("JobDelete", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("DoesNotDeleteARunningJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				State: ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			jobAfter, err := exec.JobDelete(ctx, job.ID)
+			require.ErrorIs(t, err, rivertype.ErrJobRunning)
+			require.Nil(t, jobAfter)
+
+			jobUpdated, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, jobUpdated.State)
+		})
+
+		for _, state := range []rivertype.JobState{
+			rivertype.JobStateAvailable,
+			rivertype.JobStateCancelled,
+			rivertype.JobStateCompleted,
+			rivertype.JobStateDiscarded,
+			rivertype.JobStatePending,
+			rivertype.JobStateRetryable,
+			rivertype.JobStateScheduled,
+		} {
+			state := state
+
+			t.Run(fmt.Sprintf("DeletesJobIn%sState", state), func(t *testing.T) {
+				t.Parallel()
+
+				exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+				now := time.Now().UTC()
+
+				setFinalized := slices.Contains([]rivertype.JobState{
+					rivertype.JobStateCancelled,
+					rivertype.JobStateCompleted,
+					rivertype.JobStateDiscarded,
+				}, state)
+
+				var finalizedAt *time.Time
+				if setFinalized {
+					finalizedAt = &now
+				}
+
+				job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					FinalizedAt: finalizedAt,
+					ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)),
+					State:       &state,
+				})
+
+				jobAfter, err := exec.JobDelete(ctx, job.ID)
+				require.NoError(t, err)
+				require.NotNil(t, jobAfter)
+				require.Equal(t, job.ID, jobAfter.ID)
+				require.Equal(t, state, jobAfter.State)
+
+				_, err = exec.JobGetByID(ctx, job.ID)
+				require.ErrorIs(t, err, rivertype.ErrNotFound)
+			})
+		}
+
+		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			jobAfter, err := exec.JobDelete(ctx, 0)
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+			require.Nil(t, jobAfter)
+		})
+	})
+
 	t.Run("JobDeleteBefore", func(t *testing.T) {
 		t.Parallel()
 
@@ -228,6 +469,105 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		require.NoError(t, err)
 	})
 
+	t.Run("JobDeleteMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		deletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1")})
+		deletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		deletedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+		// Not deleted because running or in another queue.
+		notDeletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		notDeletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2")})
+
+		params := &riverdriver.JobDeleteManyParams{
+			Filter: &riverdriver.JobFilter{Queues: []string{"queue1"}},
+			Max:    2,
+		}
+
+		// Max two deleted on the first pass.
+		res, err := exec.JobDeleteMany(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobManyResult{LastID: deletedJob2.ID, NumJobs: 2}, res)
+
+		// And one more pass gets the last one.
+		params.AfterID = res.LastID
+		res, err = exec.JobDeleteMany(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobManyResult{LastID: deletedJob3.ID, NumJobs: 1}, res)
+
+		for _, job := range []*rivertype.JobRow{deletedJob1, deletedJob2, deletedJob3} {
+			_, err = exec.JobGetByID(ctx, job.ID)
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		}
+
+		for _, job := range []*rivertype.JobRow{notDeletedJob1, notDeletedJob2} {
+			_, err = exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("JobExpire", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		var (
+			now         = time.Now().UTC()
+			expired     = ptrutil.Ptr(now.Add(-1 * time.Minute))
+			notExpired  = ptrutil.Ptr(now.Add(1 * time.Minute))
+			expiredJob1 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			expiredJob2 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+			expiredJob3 = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+		)
+
+		// Not expired because not an appropriate state.
+		notExpiredJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: expired, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not expired because the deadline hasn't passed, or there's none.
+		notExpiredJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: notExpired, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		notExpiredJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		// Not expired because an `expires_at` key in metadata belongs to the
+		// user and isn't an expiration time, even if it's not a timestamp.
+		notExpiredJob4 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"expires_at": "not a time"}`), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		// Max two expired on the first pass.
+		numExpired, err := exec.JobExpire(ctx, &riverdriver.JobExpireParams{
+			Max: 2,
+			Now: now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, numExpired)
+
+		// And one more pass gets the last one.
+		numExpired, err = exec.JobExpire(ctx, &riverdriver.JobExpireParams{
+			Max: 2,
+			Now: now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numExpired)
+
+		for _, job := range []*rivertype.JobRow{expiredJob1, expiredJob2, expiredJob3} {
+			updatedJob, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateDiscarded, updatedJob.State)
+			require.WithinDuration(t, now, *updatedJob.FinalizedAt, time.Microsecond)
+			require.Len(t, updatedJob.Errors, len(job.Errors)+1)
+			require.Equal(t, "Job expired before it was started", updatedJob.Errors[len(updatedJob.Errors)-1].Error)
+		}
+
+		for _, job := range []*rivertype.JobRow{notExpiredJob1, notExpiredJob2, notExpiredJob3, notExpiredJob4} {
+			updatedJob, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, job.State, updatedJob.State)
+		}
+	})
+
 	t.Run("JobGetAvailable", func(t *testing.T) {
 		t.Parallel()
 
@@ -253,57 +593,434 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		t.Run("ConstrainedToLimit", func(t *testing.T) {
 			t.Parallel()
 
-			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			// Two rows inserted but only one found because of the added limit.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				Max:         1,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+		})
+
+		t.Run("SkipsExpired", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: ptrutil.Ptr(now.Add(-1 * time.Minute))})
+			notExpiredJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: ptrutil.Ptr(now.Add(1 * time.Minute))})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, notExpiredJob.ID, jobRows[0].ID)
+		})
+
+		t.Run("SkipsExpiredWithLimits", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ExpiresAt: ptrutil.Ptr(now.Add(-1 * time.Minute))})
+			notExpiredJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"expires_at": "not a time"}`)})
+
+			// A fairness key routes to the query that applies limits.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				FairnessKey: "tenant",
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, notExpiredJob.ID, jobRows[0].ID)
+		})
+
+		t.Run("ConstrainedToConcurrencyLimits", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			// Limited to two at once, one of which is already running.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("limited"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			limitedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("limited")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("limited")})
+
+			// Limited to one at once for each customer.
+			customer1Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 1}`), Kind: ptrutil.Ptr("partitioned")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 1}`), Kind: ptrutil.Ptr("partitioned")})
+			customer2Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 2}`), Kind: ptrutil.Ptr("partitioned")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 3}`), Kind: ptrutil.Ptr("partitioned"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 3}`), Kind: ptrutil.Ptr("partitioned")})
+
+			// Limited to one at once for each tenant in metadata.
+			tenant1Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("partitioned_metadata"), Metadata: []byte(`{"tenant": "a"}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("partitioned_metadata"), Metadata: []byte(`{"tenant": "a"}`)})
+
+			// Not limited.
+			unlimitedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("unlimited")})
+			unlimitedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("unlimited")})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{
+					{Kind: "limited", Max: 2},
+					{Kind: "partitioned", Max: 1, PartitionByArgs: []string{"customer_id"}},
+					{Kind: "partitioned_metadata", Max: 1, PartitionByMetadata: []string{"tenant"}},
+				},
+				Max:   100,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Equal(t,
+				[]int64{limitedJob.ID, customer1Job.ID, customer2Job.ID, tenant1Job.ID, unlimitedJob1.ID, unlimitedJob2.ID},
+				sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID }),
+			)
+
+			// Every limit has been reached.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{
+					{Kind: "limited", Max: 2},
+					{Kind: "partitioned", Max: 1, PartitionByArgs: []string{"customer_id"}},
+					{Kind: "partitioned_metadata", Max: 1, PartitionByMetadata: []string{"tenant"}},
+				},
+				Max:   100,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+		})
+
+		t.Run("ConstrainedToConcurrencyLimitsAndGlobalMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("unlimited"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			for i := 0; i < 3; i++ {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("limited")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("unlimited")})
+			}
+
+			// One job of the limited kind and two more unlimited ones (for a
+			// global max of four) are eligible, but Max caps it to two.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy:       clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{{Kind: "limited", Max: 1}},
+				GlobalMax:         4,
+				Max:               2,
+				Queue:             rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 2)
+
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy:       clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{{Kind: "limited", Max: 1}},
+				GlobalMax:         4,
+				Max:               100,
+				Queue:             rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+
+			require.Equal(t, "unlimited", jobRows[0].Kind)
+
+			// Both limits have been reached.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy:       clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{{Kind: "limited", Max: 1}},
+				GlobalMax:         4,
+				Max:               100,
+				Queue:             rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+		})
+
+		t.Run("ConstrainedToKindMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			for i := 0; i < 3; i++ {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind3")})
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				KindMax:     map[string]int{"kind1": 1, "kind2": 0},
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			numJobsByKind := make(map[string]int)
+			for _, job := range jobRows {
+				numJobsByKind[job.Kind]++
+			}
+			require.Equal(t, map[string]int{"kind1": 1, "kind3": 3}, numJobsByKind)
+		})
+
+		t.Run("ConstrainedToGlobalMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			for i := 0; i < 3; i++ {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			}
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			// Running jobs in other queues don't count against the limit.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Queue: ptrutil.Ptr("other-queue"),
+				State: ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			// One job is already running, so only two more can be fetched
+			// before the global max is reached.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				GlobalMax:   3,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 2)
+
+			// Max still applies when it's lower than what the global max would
+			// allow.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				GlobalMax:   10,
+				Max:         0,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+
+			// Global max has been reached.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				GlobalMax:   3,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+		})
+
+		t.Run("ConstrainedToQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Queue: ptrutil.Ptr("other-queue"),
+			})
+
+			// Job is in a non-default queue so it's not found.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+		})
+
+		t.Run("ConstrainedToScheduledAtBeforeNow", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				ScheduledAt: ptrutil.Ptr(time.Now().Add(1 * time.Minute)),
+			})
+
+			// Job is scheduled a while from now so it's not found.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+		})
+
+		t.Run("ConstrainedToConcurrencyLimitsWithBacklog", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			// Customer 1 has a backlog far larger than the number of jobs to
+			// fetch, all scheduled before customer 2's job, which must still
+			// be found behind it.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer_id": 1}`), Kind: ptrutil.Ptr("partitioned"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			var customer1Jobs []*rivertype.JobRow
+			for i := 0; i < 10; i++ {
+				customer1Jobs = append(customer1Jobs, testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					EncodedArgs: []byte(`{"customer_id": 1}`),
+					Kind:        ptrutil.Ptr("partitioned"),
+					ScheduledAt: ptrutil.Ptr(now.Add(time.Duration(-20+i) * time.Minute)),
+				}))
+			}
+			customer2Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				EncodedArgs: []byte(`{"customer_id": 2}`),
+				Kind:        ptrutil.Ptr("partitioned"),
+				ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
+			})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				AttemptedBy: clientID,
+				ConcurrencyLimits: []*riverdriver.JobGetAvailableConcurrencyLimit{
+					{Kind: "partitioned", Max: 2, PartitionByArgs: []string{"customer_id"}},
+				},
+				Max:   3,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Equal(t,
+				[]int64{customer1Jobs[0].ID, customer2Job.ID},
+				sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID }),
+			)
+		})
+
+		t.Run("FairnessKey", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			// Tenant 1 has a large burst of jobs scheduled before those of
+			// tenant 2 and those without a tenant.
+			var tenant1Jobs []*rivertype.JobRow
+			for i := 0; i < 5; i++ {
+				tenant1Jobs = append(tenant1Jobs, testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata:    []byte(`{"tenant": "tenant1"}`),
+					ScheduledAt: ptrutil.Ptr(now.Add(time.Duration(-10+i) * time.Minute)),
+				}))
+			}
+			tenant2Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata:    []byte(`{"tenant": "tenant2"}`),
+				ScheduledAt: ptrutil.Ptr(now.Add(-2 * time.Minute)),
+			})
+			noTenantJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
+			})
 
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			// Priority still takes precedence over fairness.
+			priorityJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"tenant": "tenant1"}`),
+				Priority: ptrutil.Ptr(1),
+			})
 
-			// Two rows inserted but only one found because of the added limit.
 			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
 				AttemptedBy: clientID,
-				Max:         1,
+				FairnessKey: "tenant",
+				Max:         4,
 				Queue:       rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
-			require.Len(t, jobRows, 1)
+			require.ElementsMatch(t,
+				[]int64{priorityJob.ID, tenant1Jobs[0].ID, tenant2Job.ID, noTenantJob.ID},
+				sliceutil.Map(jobRows, func(job *rivertype.JobRow) int64 { return job.ID }),
+			)
 		})
 
-		t.Run("ConstrainedToQueue", func(t *testing.T) {
+		t.Run("FairnessKeyWithRunningJobs", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setupExecutor(ctx, t, driver, beginTx)
 
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
-				Queue: ptrutil.Ptr("other-queue"),
-			})
+			now := time.Now().UTC()
+
+			// Tenant 1 already has jobs running, so tenant 2's jobs go first
+			// even though they were scheduled later.
+			for i := 0; i < 2; i++ {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata: []byte(`{"tenant": "tenant1"}`),
+					State:    ptrutil.Ptr(rivertype.JobStateRunning),
+				})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata:    []byte(`{"tenant": "tenant1"}`),
+					ScheduledAt: ptrutil.Ptr(now.Add(-10 * time.Minute)),
+				})
+			}
+			var tenant2JobIDs []int64
+			for i := 0; i < 2; i++ {
+				tenant2JobIDs = append(tenant2JobIDs, testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata:    []byte(`{"tenant": "tenant2"}`),
+					ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
+				}).ID)
+			}
 
-			// Job is in a non-default queue so it's not found.
 			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
 				AttemptedBy: clientID,
-				Max:         100,
+				FairnessKey: "tenant",
+				Max:         2,
 				Queue:       rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
-			require.Empty(t, jobRows)
+			require.ElementsMatch(t, tenant2JobIDs, sliceutil.Map(jobRows, func(job *rivertype.JobRow) int64 { return job.ID }))
 		})
 
-		t.Run("ConstrainedToScheduledAtBeforeNow", func(t *testing.T) {
+		t.Run("PriorityAging", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setupExecutor(ctx, t, driver, beginTx)
 
+			now := time.Now().UTC()
+
+			// A priority 4 job that's been waiting for 25 minutes has an
+			// effective priority of 2 with an aging interval of 10 minutes,
+			// putting it ahead of a newer priority 3 job, but still behind a
+			// priority 1 job.
+			oldJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Priority:    ptrutil.Ptr(4),
+				ScheduledAt: ptrutil.Ptr(now.Add(-25 * time.Minute)),
+			})
+			priority1Job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Priority:    ptrutil.Ptr(1),
+				ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
+			})
 			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
-				ScheduledAt: ptrutil.Ptr(time.Now().Add(1 * time.Minute)),
+				Priority:    ptrutil.Ptr(3),
+				ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute)),
 			})
 
-			// Job is scheduled a while from now so it's not found.
 			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
-				AttemptedBy: clientID,
-				Max:         100,
-				Queue:       rivercommon.QueueDefault,
+				AttemptedBy:           clientID,
+				Max:                   2,
+				PriorityAgingInterval: 10 * time.Minute,
+				Queue:                 rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
-			require.Empty(t, jobRows)
+			require.ElementsMatch(t,
+				[]int64{oldJob.ID, priority1Job.ID},
+				sliceutil.Map(jobRows, func(job *rivertype.JobRow) int64 { return job.ID }),
+			)
 		})
 
 		t.Run("Prioritized", func(t *testing.T) {
@@ -560,7 +1277,18 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			afterHorizon  = horizon.Add(1 * time.Minute)
 		)
 
-		stuckJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		// Not stuck because its last heartbeat was after queried horizon, even
+		// though it was attempted before it. Inserted first so it'd be returned
+		// ahead of the other jobs if it were considered stuck.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+			AttemptedAt: &beforeHorizon,
+			HeartbeatAt: &afterHorizon,
+			State:       ptrutil.Ptr(rivertype.JobStateRunning),
+		})
+
+		// A heartbeat_at key in user metadata is ignored, even if it isn't a
+		// valid timestamp.
+		stuckJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &beforeHorizon, Metadata: []byte(`{"heartbeat_at": "not a time"}`), State: ptrutil.Ptr(rivertype.JobStateRunning)})
 		stuckJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateRunning)})
 
 		// Not returned because we put a maximum of two.
@@ -582,6 +1310,50 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			sliceutil.Map(stuckJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
 	})
 
+	t.Run("JobHeartbeat", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("RecordsHeartbeat", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar", "progress": "user progress"}`),
+				State:    ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			updatedJob, err := exec.JobHeartbeat(ctx, &riverdriver.JobHeartbeatParams{ID: job.ID})
+			require.NoError(t, err)
+			require.NotNil(t, updatedJob.HeartbeatAt)
+			require.Nil(t, updatedJob.Progress())
+			require.JSONEq(t, `{"foo": "bar", "progress": "user progress"}`, string(updatedJob.Metadata))
+
+			updatedJob, err = exec.JobHeartbeat(ctx, &riverdriver.JobHeartbeatParams{ID: job.ID, Progress: []byte(`{"processed": 1}`)})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"processed": 1}`, string(updatedJob.Progress()))
+
+			// Keys in user metadata are left alone.
+			var metadata map[string]any
+			require.NoError(t, json.Unmarshal(updatedJob.Metadata, &metadata))
+			require.Equal(t, "bar", metadata["foo"])
+			require.Equal(t, "user progress", metadata["progress"])
+		})
+
+		t.Run("DoesNotUpdateJobNotRunning", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				State: ptrutil.Ptr(rivertype.JobStateCompleted),
+			})
+
+			_, err := exec.JobHeartbeat(ctx, &riverdriver.JobHeartbeatParams{ID: job.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+	})
+
 	t.Run("JobInsertFast", func(t *testing.T) {
 		t.Parallel()
 
@@ -604,6 +1376,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, 0, job.Attempt)
 			require.Nil(t, job.AttemptedAt)
 			require.WithinDuration(t, now, job.CreatedAt, 2*time.Second)
+			require.Nil(t, job.DependsOn)
 			require.Equal(t, []byte(`{"encoded": "args"}`), job.EncodedArgs)
 			require.Empty(t, job.Errors)
 			require.Nil(t, job.FinalizedAt)
@@ -625,6 +1398,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			now := time.Now().UTC()
 
 			job, err := exec.JobInsertFast(ctx, &riverdriver.JobInsertFastParams{
+				DependsOn:   []int64{123, 456},
 				EncodedArgs: []byte(`{"encoded": "args"}`),
 				Kind:        "test_kind",
 				MaxAttempts: 6,
@@ -639,6 +1413,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, 0, job.Attempt)
 			require.Nil(t, job.AttemptedAt)
 			require.WithinDuration(t, time.Now().UTC(), job.CreatedAt, 2*time.Second)
+			require.Equal(t, []int64{123, 456}, job.DependsOn)
 			require.Equal(t, []byte(`{"encoded": "args"}`), job.EncodedArgs)
 			require.Empty(t, job.Errors)
 			require.Nil(t, job.FinalizedAt)
@@ -675,9 +1450,15 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 				Queue:       rivercommon.QueueDefault,
 				ScheduledAt: &now,
 				State:       rivertype.JobStateAvailable,
-				Tags:        []string{"tag"},
+				Tags:        []string{"tag", "with,comma"},
 			}
 			insertParams[i].ScheduledAt = &now
+
+			// Give every other job dependencies to check they're encoded
+			// correctly alongside jobs with none.
+			if i%2 == 1 {
+				insertParams[i].DependsOn = []int64{int64(i), 123}
+			}
 		}
 
 		count, err := exec.JobInsertFastMany(ctx, insertParams)
@@ -687,7 +1468,12 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		jobsAfter, err := exec.JobGetByKindMany(ctx, []string{"test_kind"})
 		require.NoError(t, err)
 		require.Len(t, jobsAfter, len(insertParams))
-		for _, job := range jobsAfter {
+		for i, job := range jobsAfter {
+			if i%2 == 1 {
+				require.Equal(t, []int64{int64(i), 123}, job.DependsOn)
+			} else {
+				require.Nil(t, job.DependsOn)
+			}
 			require.Equal(t, 0, job.Attempt)
 			require.Nil(t, job.AttemptedAt)
 			require.WithinDuration(t, time.Now().UTC(), job.CreatedAt, 2*time.Second)
@@ -701,7 +1487,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, rivercommon.QueueDefault, job.Queue)
 			requireEqualTime(t, now, job.ScheduledAt)
 			require.Equal(t, rivertype.JobStateAvailable, job.State)
-			require.Equal(t, []string{"tag"}, job.Tags)
+			require.Equal(t, []string{"tag", "with,comma"}, job.Tags)
 		}
 	})
 
@@ -745,6 +1531,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 				Attempt:     3,
 				AttemptedAt: &now,
 				CreatedAt:   &now,
+				DependsOn:   []int64{123, 456},
 				EncodedArgs: []byte(`{"encoded": "args"}`),
 				Errors:      [][]byte{[]byte(`{"error": "message"}`)},
 				FinalizedAt: &now,
@@ -761,6 +1548,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, 3, job.Attempt)
 			requireEqualTime(t, now, *job.AttemptedAt)
 			requireEqualTime(t, now, job.CreatedAt)
+			require.Equal(t, []int64{123, 456}, job.DependsOn)
 			require.Equal(t, []byte(`{"encoded": "args"}`), job.EncodedArgs)
 			require.Equal(t, "message", job.Errors[0].Error)
 			requireEqualTime(t, now, *job.FinalizedAt)
@@ -786,6 +1574,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			Attempt:     ptrutil.Ptr(3),
 			AttemptedAt: &now,
 			CreatedAt:   &now,
+			DependsOn:   []int64{123},
 			EncodedArgs: []byte(`{"encoded": "args"}`),
 			Errors:      [][]byte{[]byte(`{"error": "message"}`)},
 			FinalizedAt: &now,
@@ -807,6 +1596,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		require.Equal(t, job.Attempt, fetchedJob.Attempt)
 		require.Equal(t, job.AttemptedAt, fetchedJob.AttemptedAt)
 		require.Equal(t, job.CreatedAt, fetchedJob.CreatedAt)
+		require.Equal(t, job.DependsOn, fetchedJob.DependsOn)
 		require.Equal(t, job.EncodedArgs, fetchedJob.EncodedArgs)
 		require.Equal(t, "message", fetchedJob.Errors[0].Error)
 		require.Equal(t, job.FinalizedAt, fetchedJob.FinalizedAt)
@@ -825,10 +1615,219 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 
 		exec, _ := setupExecutor(ctx, t, driver, beginTx)
 
-		require.Equal(t, "id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags",
+		require.Equal(t, "id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on",
 			exec.JobListFields())
 	})
 
+	t.Run("JobPromotePending", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		completedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		discardedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		runningJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		promotedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		scheduledJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID}, ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		failedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{discardedJob.ID, runningJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		waitingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID, runningJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		res, err := exec.JobPromotePending(ctx, &riverdriver.JobPromotePendingParams{
+			DependencyFailedState: rivertype.JobStateDiscarded,
+			InsertTopic:           string(notifier.NotificationTopicInsert),
+			Max:                   100,
+			Now:                   now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobPromotePendingResult{LastID: waitingJob.ID, NumChecked: 4, NumFailed: 1, NumPromoted: 2}, res)
+
+		updatedPromotedJob, err := exec.JobGetByID(ctx, promotedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, updatedPromotedJob.State)
+
+		updatedScheduledJob, err := exec.JobGetByID(ctx, scheduledJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, updatedScheduledJob.State)
+
+		updatedFailedJob, err := exec.JobGetByID(ctx, failedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, updatedFailedJob.State)
+		requireEqualTime(t, now, *updatedFailedJob.FinalizedAt)
+
+		updatedWaitingJob, err := exec.JobGetByID(ctx, waitingJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, updatedWaitingJob.State)
+	})
+
+	t.Run("JobPromotePendingAfterID", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		completedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		runningJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// The first pending job can't be promoted yet, but it shouldn't stop
+		// the one after it from being promoted on the next page.
+		waitingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{runningJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+		promotedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{DependsOn: []int64{completedJob.ID}, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		params := &riverdriver.JobPromotePendingParams{
+			DependencyFailedState: rivertype.JobStateDiscarded,
+			InsertTopic:           string(notifier.NotificationTopicInsert),
+			Max:                   1,
+			Now:                   now,
+		}
+
+		res, err := exec.JobPromotePending(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobPromotePendingResult{LastID: waitingJob.ID, NumChecked: 1}, res)
+
+		params.AfterID = res.LastID
+		res, err = exec.JobPromotePending(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobPromotePendingResult{LastID: promotedJob.ID, NumChecked: 1, NumPromoted: 1}, res)
+
+		params.AfterID = res.LastID
+		res, err = exec.JobPromotePending(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobPromotePendingResult{}, res)
+	})
+
+	t.Run("JobPromotePendingWorkflow", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		workflowJob := func(workflowID, task string, deps []string, state rivertype.JobState) *rivertype.JobRow {
+			metadata, err := json.Marshal(map[string]any{"workflow_deps": deps, "workflow_id": workflowID, "workflow_task": task})
+			require.NoError(t, err)
+
+			var finalizedAt *time.Time
+			if state == rivertype.JobStateCompleted || state == rivertype.JobStateDiscarded {
+				finalizedAt = &now
+			}
+
+			return testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, Metadata: metadata, State: &state})
+		}
+
+		// Workflow 1: a -> {b, c} -> d, where a has completed.
+		workflowJob("wf1", "a", nil, rivertype.JobStateCompleted)
+		jobB := workflowJob("wf1", "b", []string{"a"}, rivertype.JobStatePending)
+		jobC := workflowJob("wf1", "c", []string{"a"}, rivertype.JobStatePending)
+		jobD := workflowJob("wf1", "d", []string{"b", "c"}, rivertype.JobStatePending)
+
+		// Workflow 2: a -> b, where a was discarded.
+		workflowJob("wf2", "a", nil, rivertype.JobStateDiscarded)
+		failedJob := workflowJob("wf2", "b", []string{"a"}, rivertype.JobStatePending)
+
+		// Workflow 3 has a task of the same name as workflow 1, but one that
+		// hasn't completed. Make sure it doesn't interfere.
+		workflowJob("wf3", "a", nil, rivertype.JobStateAvailable)
+		waitingJob := workflowJob("wf3", "b", []string{"a"}, rivertype.JobStatePending)
+
+		res, err := exec.JobPromotePending(ctx, &riverdriver.JobPromotePendingParams{
+			DependencyFailedState: rivertype.JobStateCancelled,
+			InsertTopic:           string(notifier.NotificationTopicInsert),
+			Max:                   100,
+			Now:                   now,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobPromotePendingResult{LastID: waitingJob.ID, NumChecked: 5, NumFailed: 1, NumPromoted: 2}, res)
+
+		for _, job := range []*rivertype.JobRow{jobB, jobC} {
+			updatedJob, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+		}
+
+		updatedJobD, err := exec.JobGetByID(ctx, jobD.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, updatedJobD.State)
+
+		updatedFailedJob, err := exec.JobGetByID(ctx, failedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, updatedFailedJob.State)
+
+		updatedWaitingJob, err := exec.JobGetByID(ctx, waitingJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, updatedWaitingJob.State)
+	})
+
+	t.Run("JobQueueStats", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		completedJob := func(queue string, finalizedAt time.Time, runDuration time.Duration) {
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				AttemptedAt: ptrutil.Ptr(finalizedAt.Add(-runDuration)),
+				FinalizedAt: &finalizedAt,
+				Queue:       &queue,
+				State:       ptrutil.Ptr(rivertype.JobStateCompleted),
+			})
+		}
+
+		// queue1 has available jobs and recently completed jobs.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), ScheduledAt: ptrutil.Ptr(now.Add(-10 * time.Minute))})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Minute))})
+		completedJob("queue1", now.Add(-1*time.Minute), 1*time.Second)
+		completedJob("queue1", now.Add(-1*time.Minute), 2*time.Second)
+		completedJob("queue1", now.Add(-1*time.Minute), 3*time.Second)
+
+		// Completed too long ago to be included.
+		completedJob("queue1", now.Add(-2*time.Hour), 1*time.Hour)
+
+		// queue2 only has recently completed jobs.
+		completedJob("queue2", now.Add(-1*time.Minute), 4*time.Second)
+
+		// queue3 only has available jobs.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue3"), ScheduledAt: ptrutil.Ptr(now.Add(-1 * time.Minute))})
+
+		// queue4 has neither, and isn't returned.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue4"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		queueStats, err := exec.JobQueueStats(ctx, &riverdriver.JobQueueStatsParams{
+			FinalizedAfter: now.Add(-1 * time.Hour),
+		})
+		require.NoError(t, err)
+		slices.SortFunc(queueStats, func(a, b *riverdriver.JobQueueStats) int { return strings.Compare(a.Queue, b.Queue) })
+		require.Len(t, queueStats, 3)
+
+		require.Equal(t, "queue1", queueStats[0].Queue)
+		require.NotNil(t, queueStats[0].OldestAvailableScheduledAt)
+		requireEqualTime(t, now.Add(-10*time.Minute), *queueStats[0].OldestAvailableScheduledAt)
+		require.Equal(t, 2*time.Second, queueStats[0].RunDurationP50)
+		require.Equal(t, 2900*time.Millisecond, queueStats[0].RunDurationP95.Round(time.Millisecond))
+
+		require.Equal(t, "queue2", queueStats[1].Queue)
+		require.Nil(t, queueStats[1].OldestAvailableScheduledAt)
+		require.Equal(t, 4*time.Second, queueStats[1].RunDurationP50)
+		require.Equal(t, 4*time.Second, queueStats[1].RunDurationP95)
+
+		require.Equal(t, "queue3", queueStats[2].Queue)
+		require.NotNil(t, queueStats[2].OldestAvailableScheduledAt)
+		require.Zero(t, queueStats[2].RunDurationP50)
+		require.Zero(t, queueStats[2].RunDurationP95)
+
+		queueStats, err = exec.JobQueueStats(ctx, &riverdriver.JobQueueStatsParams{
+			FinalizedAfter: now.Add(-1 * time.Hour),
+			Queues:         []string{"queue2"},
+		})
+		require.NoError(t, err)
+		require.Len(t, queueStats, 1)
+		require.Equal(t, "queue2", queueStats[0].Queue)
+	})
+
 	t.Run("JobRescueMany", func(t *testing.T) {
 		t.Parallel()
 
@@ -900,12 +1899,26 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, rivertype.JobStateRunning, jobUpdated.State)
 		})
 
+		t.Run("DoesNotUpdateAPendingJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				State: ptrutil.Ptr(rivertype.JobStatePending),
+			})
+
+			jobAfter, err := exec.JobRetry(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStatePending, jobAfter.State)
+			require.WithinDuration(t, job.ScheduledAt, jobAfter.ScheduledAt, time.Microsecond)
+		})
+
 		for _, state := range []rivertype.JobState{
 			rivertype.JobStateAvailable,
 			rivertype.JobStateCancelled,
 			rivertype.JobStateCompleted,
 			rivertype.JobStateDiscarded,
-			// TODO(bgentry): add Pending to this list when it's added:
 			rivertype.JobStateRetryable,
 			rivertype.JobStateScheduled,
 		} {
@@ -1005,6 +2018,60 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		})
 	})
 
+	t.Run("JobRetryMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		retriedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(rivercommon.MaxAttemptsDefault), FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		retriedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+		retriedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+
+		// Not retried because running, pending, or already available in the
+		// past.
+		notRetriedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		notRetriedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(-time.Hour))})
+		notRetriedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		params := &riverdriver.JobRetryManyParams{
+			Filter: &riverdriver.JobFilter{},
+			Max:    2,
+		}
+
+		// Max two retried on the first pass.
+		res, err := exec.JobRetryMany(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobManyResult{LastID: retriedJob2.ID, NumJobs: 2}, res)
+
+		// And one more pass gets the last one.
+		params.AfterID = res.LastID
+		res, err = exec.JobRetryMany(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, &riverdriver.JobManyResult{LastID: retriedJob3.ID, NumJobs: 1}, res)
+
+		for _, job := range []*rivertype.JobRow{retriedJob1, retriedJob2, retriedJob3} {
+			jobAfter, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, jobAfter.State)
+			require.Nil(t, jobAfter.FinalizedAt)
+			require.WithinDuration(t, time.Now(), jobAfter.ScheduledAt, 2*time.Second)
+		}
+
+		// Exhausted its attempts, so it gets another one.
+		jobAfter, err := exec.JobGetByID(ctx, retriedJob1.ID)
+		require.NoError(t, err)
+		require.Equal(t, retriedJob1.MaxAttempts+1, jobAfter.MaxAttempts)
+
+		for _, job := range []*rivertype.JobRow{notRetriedJob1, notRetriedJob2, notRetriedJob3} {
+			jobAfter, err := exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, job.State, jobAfter.State)
+			require.Equal(t, job.ScheduledAt, jobAfter.ScheduledAt)
+		}
+	})
+
 	t.Run("JobSchedule", func(t *testing.T) {
 		t.Parallel()
 
@@ -1074,7 +2141,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 				State: ptrutil.Ptr(rivertype.JobStateRunning),
 			})
 
-			jobAfter, err := exec.JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, now))
+			jobAfter, err := exec.JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, now, nil))
 			require.NoError(t, err)
 			require.Equal(t, rivertype.JobStateCompleted, jobAfter.State)
 			require.WithinDuration(t, now, *jobAfter.FinalizedAt, time.Microsecond)
@@ -1084,6 +2151,25 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 			require.Equal(t, rivertype.JobStateCompleted, jobUpdated.State)
 		})
 
+		t.Run("MergesMetadataUpdates", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().UTC()
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar"}`),
+				State:    ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			jobAfter, err := exec.JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, now, []byte(`{"output": {"result": 123}}`)))
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateCompleted, jobAfter.State)
+			require.JSONEq(t, `{"foo": "bar", "output": {"result": 123}}`, string(jobAfter.Metadata))
+			require.JSONEq(t, `{"result": 123}`, string(jobAfter.Output()))
+		})
+
 		t.Run("DoesNotCompleteARetryableJob", func(t *testing.T) {
 			t.Parallel()
 
@@ -1095,7 +2181,7 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 				State: ptrutil.Ptr(rivertype.JobStateRetryable),
 			})
 
-			jobAfter, err := exec.JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, now))
+			jobAfter, err := exec.JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, now, nil))
 			require.NoError(t, err)
 			require.Equal(t, rivertype.JobStateRetryable, jobAfter.State)
 			require.Nil(t, jobAfter.FinalizedAt)
@@ -1209,6 +2295,87 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		})
 	})
 
+	t.Run("JobSetStateIfRunningMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC()
+
+		errPayload, err := json.Marshal(rivertype.AttemptError{
+			Attempt: 1, At: now, Error: "fake error", Trace: "foo.go:123\nbar.go:456",
+		})
+		require.NoError(t, err)
+
+		var (
+			completedJob = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			erroredJob   = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			snoozedJob   = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			cancelledJob = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(fmt.Sprintf(`{"cancel_attempted_at":"%s"}`, now.Format(time.RFC3339))),
+				State:    ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+			notRunningJob = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+		)
+
+		jobsAfter, err := exec.JobSetStateIfRunningMany(ctx, []*riverdriver.JobSetStateIfRunningParams{
+			riverdriver.JobSetStateCompleted(completedJob.ID, now, []byte(`{"output": {"result": 123}}`)),
+			riverdriver.JobSetStateErrorRetryable(erroredJob.ID, now.Add(10*time.Second), errPayload),
+			riverdriver.JobSetStateSnoozed(snoozedJob.ID, now.Add(10*time.Second), 7),
+			riverdriver.JobSetStateErrorRetryable(cancelledJob.ID, now.Add(10*time.Second), errPayload),
+			riverdriver.JobSetStateCompleted(notRunningJob.ID, now, nil),
+		})
+		require.NoError(t, err)
+		require.Len(t, jobsAfter, 5)
+
+		jobsAfterByID := make(map[int64]*rivertype.JobRow, len(jobsAfter))
+		for _, job := range jobsAfter {
+			jobsAfterByID[job.ID] = job
+		}
+
+		{
+			jobAfter := jobsAfterByID[completedJob.ID]
+			require.Equal(t, rivertype.JobStateCompleted, jobAfter.State)
+			require.WithinDuration(t, now, *jobAfter.FinalizedAt, time.Microsecond)
+			require.JSONEq(t, `{"result": 123}`, string(jobAfter.Output()))
+		}
+
+		{
+			jobAfter := jobsAfterByID[erroredJob.ID]
+			require.Equal(t, rivertype.JobStateRetryable, jobAfter.State)
+			require.WithinDuration(t, now.Add(10*time.Second), jobAfter.ScheduledAt, time.Microsecond)
+			require.Nil(t, jobAfter.FinalizedAt)
+			require.Len(t, jobAfter.Errors, 1)
+			require.Equal(t, "fake error", jobAfter.Errors[0].Error)
+			require.Nil(t, jobAfter.Output())
+		}
+
+		{
+			jobAfter := jobsAfterByID[snoozedJob.ID]
+			require.Equal(t, rivertype.JobStateScheduled, jobAfter.State)
+			require.Equal(t, 7, jobAfter.MaxAttempts)
+			require.Empty(t, jobAfter.Errors)
+		}
+
+		{
+			jobAfter := jobsAfterByID[cancelledJob.ID]
+			require.Equal(t, rivertype.JobStateCancelled, jobAfter.State)
+			require.NotNil(t, jobAfter.FinalizedAt)
+			require.WithinDuration(t, cancelledJob.ScheduledAt, jobAfter.ScheduledAt, time.Microsecond)
+			require.Len(t, jobAfter.Errors, 1)
+		}
+
+		{
+			jobAfter := jobsAfterByID[notRunningJob.ID]
+			require.Equal(t, rivertype.JobStateRetryable, jobAfter.State)
+			require.Nil(t, jobAfter.FinalizedAt)
+		}
+
+		jobUpdated, err := exec.JobGetByID(ctx, completedJob.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, jobUpdated.State)
+	})
+
 	t.Run("JobUpdate", func(t *testing.T) {
 		t.Parallel()
 
@@ -1239,6 +2406,152 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		require.Equal(t, rivertype.JobStateDiscarded, updatedJob.State)
 	})
 
+	t.Run("JobUpdateIfNotRunning", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("UpdatesProperties", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar", "baz": "qux"}`),
+				Tags:     []string{"tag1"},
+			})
+
+			updatedJob, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+				ID:                  job.ID,
+				MaxAttemptsDoUpdate: true,
+				MaxAttempts:         7,
+				MetadataDoMerge:     true,
+				Metadata:            []byte(`{"baz": "new"}`),
+				Now:                 time.Now().UTC(),
+				PriorityDoUpdate:    true,
+				Priority:            3,
+				QueueDoUpdate:       true,
+				Queue:               "other_queue",
+				TagsDoUpdate:        true,
+				Tags:                []string{"tag2"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 7, updatedJob.MaxAttempts)
+			require.JSONEq(t, `{"foo": "bar", "baz": "new"}`, string(updatedJob.Metadata))
+			require.Equal(t, 3, updatedJob.Priority)
+			require.Equal(t, "other_queue", updatedJob.Queue)
+			require.Equal(t, []string{"tag2"}, updatedJob.Tags)
+			require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+		})
+
+		t.Run("DoesNotUpdateWithoutDoUpdate", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar"}`),
+				Tags:     []string{"tag1"},
+			})
+
+			updatedJob, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+				ID:          job.ID,
+				MaxAttempts: 7,
+				Metadata:    []byte(`{"baz": "new"}`),
+				Now:         time.Now().UTC(),
+				Priority:    3,
+				Queue:       "other_queue",
+				ScheduledAt: time.Now().Add(time.Hour),
+				Tags:        []string{"tag2"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, job.MaxAttempts, updatedJob.MaxAttempts)
+			require.JSONEq(t, `{"foo": "bar"}`, string(updatedJob.Metadata))
+			require.Equal(t, job.Priority, updatedJob.Priority)
+			require.Equal(t, job.Queue, updatedJob.Queue)
+			requireEqualTime(t, job.ScheduledAt, updatedJob.ScheduledAt)
+			require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+			require.Equal(t, []string{"tag1"}, updatedJob.Tags)
+		})
+
+		t.Run("TransitionsStateOnScheduledAtChange", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			var (
+				now    = time.Now().UTC()
+				future = now.Add(time.Hour)
+			)
+
+			updateScheduledAt := func(job *rivertype.JobRow, scheduledAt time.Time) *rivertype.JobRow {
+				t.Helper()
+
+				updatedJob, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+					ID:                  job.ID,
+					Now:                 now,
+					ScheduledAtDoUpdate: true,
+					ScheduledAt:         scheduledAt,
+				})
+				require.NoError(t, err)
+				requireEqualTime(t, scheduledAt, updatedJob.ScheduledAt)
+				return updatedJob
+			}
+
+			availableJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			require.Equal(t, rivertype.JobStateScheduled, updateScheduledAt(availableJob, future).State)
+
+			scheduledJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ScheduledAt: &future, State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+			require.Equal(t, rivertype.JobStateAvailable, updateScheduledAt(scheduledJob, now).State)
+
+			retryableJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ScheduledAt: &future, State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+			require.Equal(t, rivertype.JobStateAvailable, updateScheduledAt(retryableJob, now.Add(-time.Minute)).State)
+
+			// Retryable jobs moved further into the future stay retryable.
+			retryableJob = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{ScheduledAt: &future, State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+			require.Equal(t, rivertype.JobStateRetryable, updateScheduledAt(retryableJob, future.Add(time.Hour)).State)
+
+			// Jobs in other states have their scheduled time changed, but keep
+			// their state.
+			pendingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+			require.Equal(t, rivertype.JobStatePending, updateScheduledAt(pendingJob, now).State)
+
+			completedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &now, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			require.Equal(t, rivertype.JobStateCompleted, updateScheduledAt(completedJob, future).State)
+		})
+
+		t.Run("DoesNotUpdateRunningJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			updatedJob, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+				ID:               job.ID,
+				Now:              time.Now().UTC(),
+				PriorityDoUpdate: true,
+				Priority:         3,
+			})
+			require.ErrorIs(t, err, rivertype.ErrJobRunning)
+			require.Nil(t, updatedJob)
+
+			job, err = exec.JobGetByID(ctx, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, job.Priority)
+		})
+
+		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_, err := exec.JobUpdateIfNotRunning(ctx, &riverdriver.JobUpdateIfNotRunningParams{
+				ID:  0,
+				Now: time.Now().UTC(),
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+	})
+
 	const (
 		leaderInstanceName = "default"
 		leaderTTL          = 10 * time.Second
@@ -1460,6 +2773,299 @@ func ExerciseExecutorFull[TTx any](ctx context.Context, t *testing.T, driver riv
 		})
 	})
 
+	t.Run("QueueCreateOrSetUpdatedAt", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("InsertsANewQueueWithDefaultUpdatedAt", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			metadata := []byte(`{"foo": "bar"}`)
+			queue, err := exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{
+				Metadata: metadata,
+				Name:     "new-queue",
+			})
+			require.NoError(t, err)
+			require.WithinDuration(t, time.Now(), queue.CreatedAt, 500*time.Millisecond)
+			require.Equal(t, metadata, queue.Metadata)
+			require.Equal(t, "new-queue", queue.Name)
+			require.Nil(t, queue.PausedAt)
+			require.WithinDuration(t, time.Now(), queue.UpdatedAt, 500*time.Millisecond)
+		})
+
+		t.Run("InsertsANewQueueWithCustomPausedAt", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			now := time.Now().Add(-5 * time.Minute)
+			queue, err := exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{
+				Name:     "new-queue",
+				PausedAt: ptrutil.Ptr(now),
+			})
+			require.NoError(t, err)
+			require.Equal(t, "new-queue", queue.Name)
+			require.WithinDuration(t, now, *queue.PausedAt, time.Millisecond)
+		})
+
+		t.Run("UpdatesTheUpdatedAtOfExistingQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			metadata := []byte(`{"foo": "bar"}`)
+			tBefore := time.Now().UTC()
+			queueBefore, err := exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{
+				Metadata:  metadata,
+				Name:      "updateable-queue",
+				UpdatedAt: &tBefore,
+			})
+			require.NoError(t, err)
+			require.WithinDuration(t, tBefore, queueBefore.UpdatedAt, time.Millisecond)
+
+			tAfter := tBefore.Add(2 * time.Second)
+			queueAfter, err := exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{
+				Metadata:  []byte(`{"other": "metadata"}`),
+				Name:      "updateable-queue",
+				UpdatedAt: &tAfter,
+			})
+			require.NoError(t, err)
+
+			// unchanged:
+			require.Equal(t, queueBefore.CreatedAt, queueAfter.CreatedAt)
+			require.Equal(t, metadata, queueAfter.Metadata)
+			require.Equal(t, "updateable-queue", queueAfter.Name)
+			require.Nil(t, queueAfter.PausedAt)
+
+			// Timestamp is bumped:
+			require.WithinDuration(t, tAfter, queueAfter.UpdatedAt, time.Millisecond)
+		})
+	})
+
+	t.Run("QueueGet", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{Metadata: []byte(`{"foo": "bar"}`)})
+
+		queueFetched, err := exec.QueueGet(ctx, queue.Name)
+		require.NoError(t, err)
+		require.WithinDuration(t, queue.CreatedAt, queueFetched.CreatedAt, time.Millisecond)
+		require.Equal(t, queue.Metadata, queueFetched.Metadata)
+		require.Equal(t, queue.Name, queueFetched.Name)
+		require.Nil(t, queueFetched.PausedAt)
+		require.WithinDuration(t, queue.UpdatedAt, queueFetched.UpdatedAt, time.Millisecond)
+
+		_, err = exec.QueueGet(ctx, "nonexistent-queue")
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+	})
+
+	t.Run("QueueList", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		queues, err := exec.QueueList(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, queues)
+
+		// Make queues in reverse alphabetical order to check that they're
+		// returned sorted by name.
+		queue3 := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue3")})
+		queue2 := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue2"), PausedAt: ptrutil.Ptr(time.Now())})
+		queue1 := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{Name: ptrutil.Ptr("queue1")})
+
+		queues, err = exec.QueueList(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, queues, 2)
+		require.Equal(t, queue1.Name, queues[0].Name)
+		require.Nil(t, queues[0].PausedAt)
+		require.Equal(t, queue2.Name, queues[1].Name)
+		require.NotNil(t, queues[1].PausedAt)
+
+		queues, err = exec.QueueList(ctx, 3)
+		require.NoError(t, err)
+		require.Len(t, queues, 3)
+		require.Equal(t, queue3.Name, queues[2].Name)
+	})
+
+	t.Run("QueuePause", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("ExistingPausedQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{
+				PausedAt:  ptrutil.Ptr(time.Now().Add(-5 * time.Minute)),
+				UpdatedAt: ptrutil.Ptr(time.Now().Add(-5 * time.Minute)),
+			})
+
+			queuePaused, err := exec.QueuePause(ctx, queue.Name)
+			require.NoError(t, err)
+			require.Equal(t, queue.Name, queuePaused.Name)
+
+			queueFetched, err := exec.QueueGet(ctx, queue.Name)
+			require.NoError(t, err)
+			require.NotNil(t, queueFetched.PausedAt)
+			require.WithinDuration(t, *queue.PausedAt, *queueFetched.PausedAt, time.Millisecond) // paused_at is unchanged
+			require.WithinDuration(t, time.Now(), queueFetched.UpdatedAt, 500*time.Millisecond)
+		})
+
+		t.Run("ExistingUnpausedQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{})
+			require.Nil(t, queue.PausedAt)
+
+			_, err := exec.QueuePause(ctx, queue.Name)
+			require.NoError(t, err)
+
+			queueFetched, err := exec.QueueGet(ctx, queue.Name)
+			require.NoError(t, err)
+			require.NotNil(t, queueFetched.PausedAt)
+			require.WithinDuration(t, time.Now(), *queueFetched.PausedAt, 500*time.Millisecond)
+		})
+
+		t.Run("NonExistentQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			// The queue is created so that it can be paused before any client
+			// has started working it.
+			queue, err := exec.QueuePause(ctx, "queue1")
+			require.NoError(t, err)
+			require.Equal(t, "queue1", queue.Name)
+			require.NotNil(t, queue.PausedAt)
+			require.WithinDuration(t, time.Now(), *queue.PausedAt, 500*time.Millisecond)
+
+			queueFetched, err := exec.QueueGet(ctx, "queue1")
+			require.NoError(t, err)
+			require.NotNil(t, queueFetched.PausedAt)
+		})
+	})
+
+	t.Run("QueueResume", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("ExistingPausedQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{
+				PausedAt: ptrutil.Ptr(time.Now()),
+			})
+
+			queueResumed, err := exec.QueueResume(ctx, queue.Name)
+			require.NoError(t, err)
+			require.Nil(t, queueResumed.PausedAt)
+
+			queueFetched, err := exec.QueueGet(ctx, queue.Name)
+			require.NoError(t, err)
+			require.Nil(t, queueFetched.PausedAt)
+		})
+
+		t.Run("ExistingUnpausedQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{})
+
+			_, err := exec.QueueResume(ctx, queue.Name)
+			require.NoError(t, err)
+
+			queueFetched, err := exec.QueueGet(ctx, queue.Name)
+			require.NoError(t, err)
+			require.Nil(t, queueFetched.PausedAt)
+		})
+
+		t.Run("NonExistentQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_, err := exec.QueueResume(ctx, "queue1")
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+	})
+
+	t.Run("QueueUpdateMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("DeletesAndUpdatesKeys", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{
+				Metadata:  []byte(`{"bar": "baz", "foo": "foo", "max_workers": 5}`),
+				UpdatedAt: ptrutil.Ptr(time.Now().Add(-1 * time.Hour)),
+			})
+
+			queueUpdated, err := exec.QueueUpdateMetadata(ctx, &riverdriver.QueueUpdateMetadataParams{
+				MetadataDeleteKeys: []string{"max_workers"},
+				MetadataUpdates:    []byte(`{"foo": "updated", "new": 1}`),
+				Name:               queue.Name,
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"bar": "baz", "foo": "updated", "new": 1}`, string(queueUpdated.Metadata))
+			require.True(t, queueUpdated.UpdatedAt.After(queue.UpdatedAt))
+		})
+
+		t.Run("NilDeleteKeys", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{
+				Metadata: []byte(`{"foo": "foo"}`),
+			})
+
+			queueUpdated, err := exec.QueueUpdateMetadata(ctx, &riverdriver.QueueUpdateMetadataParams{
+				MetadataUpdates: []byte(`{"max_workers": 2}`),
+				Name:            queue.Name,
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"foo": "foo", "max_workers": 2}`, string(queueUpdated.Metadata))
+		})
+
+		t.Run("NilUpdates", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			queue := testfactory.Queue(ctx, t, exec, &testfactory.QueueOpts{
+				Metadata: []byte(`{"foo": "foo"}`),
+			})
+
+			queueUpdated, err := exec.QueueUpdateMetadata(ctx, &riverdriver.QueueUpdateMetadataParams{
+				Name: queue.Name,
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"foo": "foo"}`, string(queueUpdated.Metadata))
+		})
+
+		t.Run("NonExistentQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+			_, err := exec.QueueUpdateMetadata(ctx, &riverdriver.QueueUpdateMetadataParams{
+				MetadataUpdates: []byte(`{"foo": "foo"}`),
+				Name:            "queue1",
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+	})
+
 	t.Run("PGAdvisoryXactLock", func(t *testing.T) {
 		t.Parallel()
 
@@ -1591,6 +3197,55 @@ func ExerciseExecutorMigrationOnly[TTx any](ctx context.Context, t *testing.T, d
 		require.Equal(t, 2, migrations[1].Version)
 	})
 
+	t.Run("RateLimitGetMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		require.NoError(t, exec.RateLimitSetMany(ctx, &riverdriver.RateLimitSetManyParams{
+			Key:       []string{"key1", "key2"},
+			Tokens:    []float64{1.5, 2},
+			UpdatedAt: []time.Time{now, now.Add(-1 * time.Minute)},
+		}))
+
+		buckets, err := exec.RateLimitGetMany(ctx, []string{"key1", "key2", "key3"})
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.RateLimitBucket{
+			{Key: "key1", Tokens: 1.5, UpdatedAt: now},
+			{Key: "key2", Tokens: 2, UpdatedAt: now.Add(-1 * time.Minute)},
+		}, buckets)
+	})
+
+	t.Run("RateLimitSetMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setupExecutor(ctx, t, driver, beginTx)
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		require.NoError(t, exec.RateLimitSetMany(ctx, &riverdriver.RateLimitSetManyParams{
+			Key:       []string{"key1"},
+			Tokens:    []float64{5},
+			UpdatedAt: []time.Time{now.Add(-1 * time.Minute)},
+		}))
+
+		// Existing buckets are updated.
+		require.NoError(t, exec.RateLimitSetMany(ctx, &riverdriver.RateLimitSetManyParams{
+			Key:       []string{"key1", "key2"},
+			Tokens:    []float64{-0.5, 3},
+			UpdatedAt: []time.Time{now, now},
+		}))
+
+		buckets, err := exec.RateLimitGetMany(ctx, []string{"key1", "key2"})
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.RateLimitBucket{
+			{Key: "key1", Tokens: -0.5, UpdatedAt: now},
+			{Key: "key2", Tokens: 3, UpdatedAt: now},
+		}, buckets)
+	})
+
 	t.Run("TableExists", func(t *testing.T) {
 		t.Parallel()
 
@@ -1711,6 +3366,31 @@ func ExerciseListener[TTx any](ctx context.Context, t *testing.T, getDriverWithP
 		notification := waitForNotification(ctx, t, listener)
 		require.Equal(t, &riverdriver.Notification{Topic: "topic1", Payload: "payload1"}, notification)
 	})
+
+	t.Run("WithSchema", func(t *testing.T) {
+		t.Parallel()
+
+		listener, bundle := setupListener(ctx, t, getDriverWithPool)
+
+		schemaDriver := bundle.driver.WithSchema("custom_schema")
+
+		schemaListener := schemaDriver.GetListener()
+		t.Cleanup(func() { require.NoError(t, schemaListener.Close(ctx)) })
+		require.NoError(t, schemaListener.Connect(ctx))
+
+		require.NoError(t, listener.Listen(ctx, "topic1"))
+		require.NoError(t, schemaListener.Listen(ctx, "topic1"))
+
+		// Topics are scoped to the schema, so only the listener in the same
+		// schema receives the notification, and its topic is reported without
+		// the schema prefix.
+		require.NoError(t, schemaDriver.GetExecutor().Notify(ctx, "topic1", "payload1"))
+
+		notification := waitForNotification(ctx, t, schemaListener)
+		require.Equal(t, &riverdriver.Notification{Topic: "topic1", Payload: "payload1"}, notification)
+
+		requireNoNotification(ctx, t, listener)
+	})
 }
 
 // requireEqualTime compares to timestamps down the microsecond only. This is
@@ -298,7 +298,7 @@ func TruncateRiverTables(ctx context.Context, pool *pgxpool.Pool) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	tables := []string{"river_job", "river_leader"}
+	tables := []string{"river_job", "river_leader", "river_queue", "river_rate_limit"}
 
 	for _, table := range tables {
 		if _, err := pool.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s;", table)); err != nil {
@@ -4,6 +4,7 @@ package testfactory
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -20,9 +21,12 @@ type JobOpts struct {
 	Attempt     *int
 	AttemptedAt *time.Time
 	CreatedAt   *time.Time
+	DependsOn   []int64
 	EncodedArgs []byte
 	Errors      [][]byte
+	ExpiresAt   *time.Time
 	FinalizedAt *time.Time
+	HeartbeatAt *time.Time
 	Kind        *string
 	MaxAttempts *int
 	Metadata    []byte
@@ -55,9 +59,12 @@ func Job(ctx context.Context, t *testing.T, exec riverdriver.Executor, opts *Job
 		Attempt:     ptrutil.ValOrDefault(opts.Attempt, 0),
 		AttemptedAt: opts.AttemptedAt,
 		CreatedAt:   opts.CreatedAt,
+		DependsOn:   opts.DependsOn,
 		EncodedArgs: encodedArgs,
 		Errors:      opts.Errors,
+		ExpiresAt:   opts.ExpiresAt,
 		FinalizedAt: opts.FinalizedAt,
+		HeartbeatAt: opts.HeartbeatAt,
 		Kind:        ptrutil.ValOrDefault(opts.Kind, "fake_job"),
 		MaxAttempts: ptrutil.ValOrDefault(opts.MaxAttempts, rivercommon.MaxAttemptsDefault),
 		Metadata:    metadata,
@@ -106,6 +113,26 @@ func Migration(ctx context.Context, t *testing.T, exec riverdriver.Executor, opt
 	return migration[0]
 }
 
+type QueueOpts struct {
+	Metadata  []byte
+	Name      *string
+	PausedAt  *time.Time
+	UpdatedAt *time.Time
+}
+
+func Queue(ctx context.Context, t *testing.T, exec riverdriver.Executor, opts *QueueOpts) *rivertype.Queue {
+	t.Helper()
+
+	queue, err := exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{
+		Metadata:  opts.Metadata,
+		Name:      ptrutil.ValOrDefaultFunc(opts.Name, func() string { return fmt.Sprintf("queue_%05d", nextSeq()) }),
+		PausedAt:  opts.PausedAt,
+		UpdatedAt: opts.UpdatedAt,
+	})
+	require.NoError(t, err)
+	return queue
+}
+
 var seq int64 = 1 //nolint:gochecknoglobals
 
 func nextSeq() int {
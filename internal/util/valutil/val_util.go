@@ -4,7 +4,7 @@ import "golang.org/x/exp/constraints"
 
 // ValOrDefault returns the given value if it's non-zero, and otherwise returns
 // the default.
-func ValOrDefault[T constraints.Integer | string](val, defaultVal T) T {
+func ValOrDefault[T constraints.Integer | ~string](val, defaultVal T) T {
 	var zero T
 	if val != zero {
 		return val
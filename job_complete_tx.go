@@ -21,14 +21,24 @@ import (
 //		// handle error
 //	}
 //
+// Any output recorded with RecordOutput from within the job's worker is stored
+// along with the completion.
+//
 // Returns the updated, completed job.
 func JobCompleteTx[TDriver riverdriver.Driver[TTx], TTx any, TArgs JobArgs](ctx context.Context, tx TTx, job *Job[TArgs]) (*Job[TArgs], error) {
 	if job.State != JobStateRunning {
 		return nil, errors.New("job must be running")
 	}
 
+	// Include any output recorded with RecordOutput when called from within a
+	// worker.
+	metadataUpdates, err := jobOutputFromContext(ctx).metadataUpdates()
+	if err != nil {
+		return nil, err
+	}
+
 	var driver TDriver
-	jobRow, err := driver.UnwrapExecutor(tx).JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, time.Now()))
+	jobRow, err := driver.UnwrapExecutor(tx).JobSetStateIfRunning(ctx, riverdriver.JobSetStateCompleted(job.ID, time.Now(), metadataUpdates))
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +59,25 @@ func TestJobCompleteTx(t *testing.T) {
 		require.Equal(t, JobStateCompleted, updatedJob.State)
 	})
 
+	t.Run("CompletesJobWithOutput", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			State: ptrutil.Ptr(JobStateRunning),
+		})
+
+		// Simulates the context that a worker receives.
+		workCtx := withJobOutput(ctx, &jobOutput{})
+		require.NoError(t, RecordOutput(workCtx, map[string]int{"result": 123}))
+
+		completedJob, err := JobCompleteTx[*riverpgxv5.Driver](workCtx, bundle.tx, &Job[JobArgs]{JobRow: job})
+		require.NoError(t, err)
+		require.Equal(t, JobStateCompleted, completedJob.State)
+		require.JSONEq(t, `{"result": 123}`, string(completedJob.Output()))
+	})
+
 	t.Run("ErrorIfNotRunning", func(t *testing.T) {
 		t.Parallel()
 
@@ -68,6 +68,32 @@ func (e *jobCancelError) Is(target error) bool {
 
 func (e *jobCancelError) Unwrap() error { return e.err }
 
+// JobDiscard wraps err and can be returned from a Worker's Work method to
+// discard the job at the end of execution, signaling that the error is
+// permanent and the job shouldn't be retried. Regardless of whether or not the
+// job has any remaining attempts, this will ensure the job does not execute
+// again. Unlike JobCancel, the job ends up `discarded` like a job that's
+// exhausted its attempts rather than `cancelled`.
+func JobDiscard(err error) error {
+	return &jobDiscardError{err: err}
+}
+
+type jobDiscardError struct {
+	err error
+}
+
+func (e *jobDiscardError) Error() string {
+	// should not ever be called, but add a prefix just in case:
+	return "jobDiscardError: " + e.err.Error()
+}
+
+func (e *jobDiscardError) Is(target error) bool {
+	_, ok := target.(*jobDiscardError)
+	return ok
+}
+
+func (e *jobDiscardError) Unwrap() error { return e.err }
+
 // JobSnooze can be returned from a Worker's Work method to cause the job to be
 // tried again after the specified duration. This also has the effect of
 // incrementing the job's MaxAttempts by 1, meaning that jobs can be repeatedly
@@ -100,6 +126,7 @@ var ErrJobCancelledRemotely = JobCancel(errors.New("job cancelled remotely"))
 type jobExecutorResult struct {
 	Err        error
 	NextRetry  time.Time
+	Output     *jobOutput
 	PanicTrace []byte
 	PanicVal   any
 }
@@ -126,9 +153,11 @@ type jobExecutor struct {
 	Completer              jobcompleter.JobCompleter
 	ClientRetryPolicy      ClientRetryPolicy
 	ErrorHandler           ErrorHandler
+	Exec                   riverdriver.Executor
 	InformProducerDoneFunc func(jobRow *rivertype.JobRow)
 	JobRow                 *rivertype.JobRow
 	SchedulerInterval      time.Duration
+	WorkerMiddleware       []rivertype.WorkerMiddleware
 	WorkUnit               workunit.WorkUnit
 
 	// Meant to be used from within the job executor only.
@@ -194,7 +223,7 @@ func (e *jobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 		return &jobExecutorResult{Err: err}
 	}
 
-	{
+	doInner := func(ctx context.Context) error {
 		jobTimeout := e.WorkUnit.Timeout()
 		if jobTimeout == 0 {
 			jobTimeout = e.ClientJobTimeout
@@ -207,11 +236,24 @@ func (e *jobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 			defer cancel()
 		}
 
-		return &jobExecutorResult{Err: e.WorkUnit.Work(ctx)}
+		return e.WorkUnit.Work(ctx)
 	}
+
+	// Client-level middleware wraps worker-specific middleware, which in turn
+	// wraps the job's Work function, so the first middleware configured on the
+	// client is always the first to run.
+	doInner = workerMiddlewareChain(e.WorkUnit.Middleware(), e.JobRow, doInner)
+	doInner = workerMiddlewareChain(e.WorkerMiddleware, e.JobRow, doInner)
+
+	output := &jobOutput{}
+
+	ctx = withJobHeartbeater(ctx, &jobHeartbeater{exec: e.Exec, jobID: e.JobRow.ID})
+	ctx = withJobOutput(ctx, output)
+
+	return &jobExecutorResult{Err: doInner(ctx), Output: output}
 }
 
-func (e *jobExecutor) invokeErrorHandler(ctx context.Context, res *jobExecutorResult) bool {
+func (e *jobExecutor) invokeErrorHandler(ctx context.Context, res *jobExecutorResult) *ErrorHandlerResult {
 	invokeAndHandlePanic := func(funcName string, errorHandler func() *ErrorHandlerResult) *ErrorHandlerResult {
 		defer func() {
 			if panicVal := recover(); panicVal != nil {
@@ -238,7 +280,7 @@ func (e *jobExecutor) invokeErrorHandler(ctx context.Context, res *jobExecutorRe
 		})
 	}
 
-	return errorHandlerRes != nil && errorHandlerRes.SetCancelled
+	return errorHandlerRes
 }
 
 func (e *jobExecutor) reportResult(ctx context.Context, res *jobExecutorResult) {
@@ -275,7 +317,18 @@ func (e *jobExecutor) reportResult(ctx context.Context, res *jobExecutorResult)
 		return
 	}
 
-	if err := e.Completer.JobSetStateIfRunning(e.stats, riverdriver.JobSetStateCompleted(e.JobRow.ID, e.TimeNowUTC())); err != nil {
+	// The job is completed regardless of whether its output could be encoded
+	// because it's otherwise left running until rescued and worked again.
+	metadataUpdates, err := res.Output.metadataUpdates()
+	if err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Error encoding job output; completing without it",
+			slog.String("err", err.Error()),
+			slog.Int64("job_id", e.JobRow.ID),
+		)
+		metadataUpdates = nil
+	}
+
+	if err := e.Completer.JobSetStateIfRunning(e.stats, riverdriver.JobSetStateCompleted(e.JobRow.ID, e.TimeNowUTC(), metadataUpdates)); err != nil {
 		e.Logger.ErrorContext(ctx, e.Name+": Error completing job",
 			slog.String("err", err.Error()),
 			slog.Int64("job_id", e.JobRow.ID),
@@ -286,8 +339,10 @@ func (e *jobExecutor) reportResult(ctx context.Context, res *jobExecutorResult)
 
 func (e *jobExecutor) reportError(ctx context.Context, res *jobExecutorResult) {
 	var (
-		cancelJob bool
-		cancelErr *jobCancelError
+		cancelJob  bool
+		cancelErr  *jobCancelError
+		discardErr *jobDiscardError
+		discardJob bool
 	)
 
 	logAttrs := []any{
@@ -299,15 +354,21 @@ func (e *jobExecutor) reportError(ctx context.Context, res *jobExecutorResult) {
 	case errors.As(res.Err, &cancelErr):
 		cancelJob = true
 		e.Logger.InfoContext(ctx, e.Name+": Job cancelled explicitly", logAttrs...)
+	case errors.As(res.Err, &discardErr):
+		discardJob = true
+		e.Logger.InfoContext(ctx, e.Name+": Job discarded explicitly", logAttrs...)
 	case res.Err != nil:
 		e.Logger.ErrorContext(ctx, e.Name+": Job errored", logAttrs...)
 	case res.PanicVal != nil:
 		e.Logger.ErrorContext(ctx, e.Name+": Job panicked", logAttrs...)
 	}
 
-	if e.ErrorHandler != nil && !cancelJob {
-		// Error handlers also have an opportunity to cancel the job.
-		cancelJob = e.invokeErrorHandler(ctx, res)
+	if e.ErrorHandler != nil && !cancelJob && !discardJob {
+		// Error handlers also have an opportunity to cancel or discard the job.
+		if errorHandlerRes := e.invokeErrorHandler(ctx, res); errorHandlerRes != nil {
+			cancelJob = errorHandlerRes.SetCancelled
+			discardJob = errorHandlerRes.SetDiscarded
+		}
 	}
 
 	attemptErr := rivertype.AttemptError{
@@ -332,7 +393,7 @@ func (e *jobExecutor) reportError(ctx context.Context, res *jobExecutorResult) {
 		return
 	}
 
-	if e.JobRow.Attempt >= e.JobRow.MaxAttempts {
+	if discardJob || e.JobRow.Attempt >= e.JobRow.MaxAttempts {
 		if err := e.Completer.JobSetStateIfRunning(e.stats, riverdriver.JobSetStateDiscarded(e.JobRow.ID, now, errData)); err != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": Failed to discard job and report error", logAttrs...)
 		}
@@ -2,6 +2,7 @@ package river
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -46,6 +47,22 @@ func newWorkUnitFactoryWithCustomRetry(f func() error, nextRetry func() time.Tim
 	}}
 }
 
+// customMiddlewareWorker is a worker that provides its own middleware in
+// addition to any configured on the executor.
+type customMiddlewareWorker struct {
+	WorkerDefaults[callbackArgs]
+	f          func(ctx context.Context) error
+	middleware []rivertype.WorkerMiddleware
+}
+
+func (w *customMiddlewareWorker) Middleware(job *Job[callbackArgs]) []rivertype.WorkerMiddleware {
+	return w.middleware
+}
+
+func (w *customMiddlewareWorker) Work(ctx context.Context, job *Job[callbackArgs]) error {
+	return w.f(ctx)
+}
+
 // A retry policy demonstrating trivial customization.
 type retryPolicyCustom struct {
 	DefaultClientRetryPolicy
@@ -183,6 +200,7 @@ func TestJobExecutor_Execute(t *testing.T) {
 			ClientRetryPolicy:      &retryPolicyNoJitter{},
 			Completer:              bundle.completer,
 			ErrorHandler:           bundle.errorHandler,
+			Exec:                   bundle.exec,
 			InformProducerDoneFunc: func(job *rivertype.JobRow) {},
 			JobRow:                 bundle.jobRow,
 			SchedulerInterval:      riverinternaltest.SchedulerShortInterval,
@@ -221,6 +239,74 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.NotZero(t, jobUpdate.JobStats.RunDuration)
 	})
 
+	t.Run("SuccessWithOutput", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = (&workUnitFactoryWrapper[callbackArgs]{worker: &customMiddlewareWorker{
+			f: func(ctx context.Context) error {
+				return RecordOutput(ctx, map[string]int{"result": 123})
+			},
+		}}).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+		require.JSONEq(t, `{"result": 123}`, string(job.Output()))
+
+		// The completed job that'd be emitted as an event carries the output.
+		jobUpdates := bundle.getUpdatesAndStop()
+		require.Len(t, jobUpdates, 1)
+		require.JSONEq(t, `{"result": 123}`, string(jobUpdates[0].Job.Output()))
+	})
+
+	t.Run("OutputEncodingErrorStillCompletes", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = (&workUnitFactoryWrapper[callbackArgs]{worker: &customMiddlewareWorker{
+			f: func(ctx context.Context) error {
+				// Invalid JSON that'll fail to be encoded into metadata.
+				jobOutputFromContext(ctx).output = json.RawMessage(`{`)
+				return nil
+			},
+		}}).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+		require.Nil(t, job.Output())
+	})
+
+	t.Run("ErrorDoesNotStoreOutput", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = (&workUnitFactoryWrapper[callbackArgs]{worker: &customMiddlewareWorker{
+			f: func(ctx context.Context) error {
+				require.NoError(t, RecordOutput(ctx, map[string]int{"result": 123}))
+				return errors.New("job error")
+			},
+		}}).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Nil(t, job.Output())
+	})
+
 	t.Run("FirstError", func(t *testing.T) {
 		t.Parallel()
 
@@ -348,6 +434,31 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.Equal(t, "", job.Errors[0].Trace)
 	})
 
+	t.Run("JobDiscardErrorDiscardsJobEvenWithRemainingAttempts", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		// ensure we still have remaining attempts:
+		require.Greater(t, bundle.jobRow.MaxAttempts, bundle.jobRow.Attempt)
+
+		discardErr := JobDiscard(errors.New("throw away this job"))
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return discardErr }, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), *job.FinalizedAt, 2*time.Second)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+		require.Len(t, job.Errors, 1)
+		require.WithinDuration(t, time.Now(), job.Errors[0].At, 2*time.Second)
+		require.Equal(t, 1, job.Errors[0].Attempt)
+		require.Equal(t, "jobDiscardError: throw away this job", job.Errors[0].Error)
+		require.Equal(t, "", job.Errors[0].Trace)
+	})
+
 	t.Run("JobSnoozeErrorReschedulesJobAndIncrementsMaxAttempts", func(t *testing.T) {
 		t.Parallel()
 
@@ -487,6 +598,27 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.True(t, bundle.errorHandler.HandleErrorCalled)
 	})
 
+	t.Run("ErrorWithErrorHandlerSetDiscarded", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		workerErr := errors.New("job error")
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { return workerErr }, nil).MakeUnit(bundle.jobRow)
+		bundle.errorHandler.HandleErrorFunc = func(ctx context.Context, job *rivertype.JobRow, err error) *ErrorHandlerResult {
+			return &ErrorHandlerResult{SetDiscarded: true}
+		}
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+
+		require.True(t, bundle.errorHandler.HandleErrorCalled)
+	})
+
 	t.Run("ErrorWithErrorHandlerPanic", func(t *testing.T) {
 		t.Parallel()
 
@@ -603,6 +735,26 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.True(t, bundle.errorHandler.HandlePanicCalled)
 	})
 
+	t.Run("PanicWithPanicHandlerSetDiscarded", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error { panic("panic val") }, nil).MakeUnit(bundle.jobRow)
+		bundle.errorHandler.HandlePanicFunc = func(ctx context.Context, job *rivertype.JobRow, panicVal any) *ErrorHandlerResult {
+			return &ErrorHandlerResult{SetDiscarded: true}
+		}
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateDiscarded, job.State)
+
+		require.True(t, bundle.errorHandler.HandlePanicCalled)
+	})
+
 	t.Run("PanicWithPanicHandlerPanic", func(t *testing.T) {
 		t.Parallel()
 
@@ -623,6 +775,97 @@ func TestJobExecutor_Execute(t *testing.T) {
 		require.True(t, bundle.errorHandler.HandlePanicCalled)
 	})
 
+	t.Run("WorkerMiddleware", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		type ctxKey string
+
+		var calls []string
+		recordingMiddleware := func(name string) rivertype.WorkerMiddleware {
+			return WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				require.Equal(t, bundle.jobRow.ID, job.ID)
+				calls = append(calls, name)
+				return doInner(context.WithValue(ctx, ctxKey(name), true))
+			})
+		}
+
+		executor.WorkerMiddleware = []rivertype.WorkerMiddleware{
+			recordingMiddleware("client_first"),
+			recordingMiddleware("client_second"),
+		}
+		executor.WorkUnit = (&workUnitFactoryWrapper[callbackArgs]{worker: &customMiddlewareWorker{
+			f: func(ctx context.Context) error {
+				calls = append(calls, "work")
+				require.Equal(t, true, ctx.Value(ctxKey("client_first")))
+				require.Equal(t, true, ctx.Value(ctxKey("client_second")))
+				require.Equal(t, true, ctx.Value(ctxKey("worker")))
+				return nil
+			},
+			middleware: []rivertype.WorkerMiddleware{recordingMiddleware("worker")},
+		}}).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		require.Equal(t, []string{"client_first", "client_second", "worker", "work"}, calls)
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+	})
+
+	t.Run("WorkerMiddlewareShortCircuitsWithError", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		var workCalled bool
+		executor.WorkerMiddleware = []rivertype.WorkerMiddleware{
+			WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				return errors.New("middleware error")
+			}),
+		}
+		executor.WorkUnit = newWorkUnitFactoryWithCustomRetry(func() error {
+			workCalled = true
+			return nil
+		}, nil).MakeUnit(bundle.jobRow)
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		require.False(t, workCalled)
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, "middleware error", job.Errors[0].Error)
+	})
+
+	t.Run("WorkerMiddlewarePanic", func(t *testing.T) {
+		t.Parallel()
+
+		executor, bundle := setup(t)
+
+		executor.WorkerMiddleware = []rivertype.WorkerMiddleware{
+			WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				panic("panic val")
+			}),
+		}
+
+		executor.Execute(ctx)
+		executor.Completer.Wait()
+
+		job, err := bundle.exec.JobGetByID(ctx, bundle.jobRow.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRetryable, job.State)
+		require.Len(t, job.Errors, 1)
+		require.Equal(t, "panic val", job.Errors[0].Error)
+		require.NotEmpty(t, job.Errors[0].Trace)
+	})
+
 	t.Run("CancelFuncCleanedUpEvenWithoutCancel", func(t *testing.T) {
 		t.Parallel()
 
@@ -754,3 +997,19 @@ func TestJobCancel(t *testing.T) {
 		require.NotErrorIs(t, err1, &UnknownJobKindError{Kind: "MyJobArgs"})
 	})
 }
+
+func TestJobDiscard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrorsIsReturnsTrueForAnotherErrorOfSameType", func(t *testing.T) {
+		t.Parallel()
+		err1 := JobDiscard(errors.New("some message"))
+		require.ErrorIs(t, err1, JobDiscard(errors.New("another message")))
+	})
+
+	t.Run("ErrorsIsReturnsFalseForADifferentErrorType", func(t *testing.T) {
+		t.Parallel()
+		err1 := JobDiscard(errors.New("some message"))
+		require.NotErrorIs(t, err1, &UnknownJobKindError{Kind: "MyJobArgs"})
+	})
+}
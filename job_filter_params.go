@@ -0,0 +1,109 @@
+package river
+
+import (
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// JobFilterParams specifies the jobs affected by a bulk operation like
+// Client.JobCancelMany, Client.JobRetryMany, or Client.JobDeleteMany. It must be
+// initialized with NewJobFilterParams. Params can be built by chaining methods
+// on the JobFilterParams object:
+//
+//	params := river.NewJobFilterParams().Kinds("my_kind").States(rivertype.JobStateAvailable)
+//
+// Filters are combined so that only jobs matching all of them are affected. A
+// filter that's not set doesn't constrain which jobs are affected, so an
+// unfiltered JobFilterParams matches every job.
+type JobFilterParams struct {
+	createdAfter     *time.Time
+	createdBefore    *time.Time
+	kinds            []string
+	metadataFragment string
+	queues           []string
+	states           []rivertype.JobState
+}
+
+// NewJobFilterParams creates a new JobFilterParams that matches all jobs.
+func NewJobFilterParams() *JobFilterParams {
+	return &JobFilterParams{}
+}
+
+func (p *JobFilterParams) copy() *JobFilterParams {
+	return &JobFilterParams{
+		createdAfter:     p.createdAfter,
+		createdBefore:    p.createdBefore,
+		kinds:            append([]string(nil), p.kinds...),
+		metadataFragment: p.metadataFragment,
+		queues:           append([]string(nil), p.queues...),
+		states:           append([]rivertype.JobState(nil), p.states...),
+	}
+}
+
+func (p *JobFilterParams) toDriverFilter() *riverdriver.JobFilter {
+	filter := &riverdriver.JobFilter{
+		CreatedAfter:  p.createdAfter,
+		CreatedBefore: p.createdBefore,
+		Kinds:         p.kinds,
+		Queues:        p.queues,
+		States:        p.states,
+	}
+	if p.metadataFragment != "" {
+		filter.MetadataFragment = []byte(p.metadataFragment)
+	}
+	return filter
+}
+
+// CreatedAfter returns an updated filter set that will only match jobs created
+// at or after the given time.
+func (p *JobFilterParams) CreatedAfter(createdAfter time.Time) *JobFilterParams {
+	result := p.copy()
+	result.createdAfter = &createdAfter
+	return result
+}
+
+// CreatedBefore returns an updated filter set that will only match jobs created
+// before the given time.
+func (p *JobFilterParams) CreatedBefore(createdBefore time.Time) *JobFilterParams {
+	result := p.copy()
+	result.createdBefore = &createdBefore
+	return result
+}
+
+// Kinds returns an updated filter set that will only match jobs of the given
+// kinds.
+func (p *JobFilterParams) Kinds(kinds ...string) *JobFilterParams {
+	result := p.copy()
+	result.kinds = make([]string, len(kinds))
+	copy(result.kinds, kinds)
+	return result
+}
+
+// Metadata returns an updated filter set that will only match jobs whose
+// metadata contains the given JSON fragment, as determined by Postgres' `@>`
+// operator.
+func (p *JobFilterParams) Metadata(json string) *JobFilterParams {
+	result := p.copy()
+	result.metadataFragment = json
+	return result
+}
+
+// Queues returns an updated filter set that will only match jobs in the given
+// queues.
+func (p *JobFilterParams) Queues(queues ...string) *JobFilterParams {
+	result := p.copy()
+	result.queues = make([]string, len(queues))
+	copy(result.queues, queues)
+	return result
+}
+
+// States returns an updated filter set that will only match jobs in the given
+// states.
+func (p *JobFilterParams) States(states ...rivertype.JobState) *JobFilterParams {
+	result := p.copy()
+	result.states = make([]rivertype.JobState, len(states))
+	copy(result.states, states)
+	return result
+}
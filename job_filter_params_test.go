@@ -0,0 +1,56 @@
+package river
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestJobFilterParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t, &riverdriver.JobFilter{}, NewJobFilterParams().toDriverFilter())
+	})
+
+	t.Run("AllFilters", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			createdAfter  = time.Now().Add(-time.Hour)
+			createdBefore = time.Now()
+		)
+
+		params := NewJobFilterParams().
+			CreatedAfter(createdAfter).
+			CreatedBefore(createdBefore).
+			Kinds("kind1", "kind2").
+			Metadata(`{"tenant": "a"}`).
+			Queues("queue1").
+			States(rivertype.JobStateAvailable, rivertype.JobStateRetryable)
+
+		require.Equal(t, &riverdriver.JobFilter{
+			CreatedAfter:     &createdAfter,
+			CreatedBefore:    &createdBefore,
+			Kinds:            []string{"kind1", "kind2"},
+			MetadataFragment: []byte(`{"tenant": "a"}`),
+			Queues:           []string{"queue1"},
+			States:           []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateRetryable},
+		}, params.toDriverFilter())
+	})
+
+	t.Run("ChainingDoesNotModifyOriginal", func(t *testing.T) {
+		t.Parallel()
+
+		params := NewJobFilterParams().Kinds("kind1")
+		_ = params.Kinds("kind2").Queues("queue1")
+
+		require.Equal(t, &riverdriver.JobFilter{Kinds: []string{"kind1"}}, params.toDriverFilter())
+	})
+}
@@ -0,0 +1,98 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/riverqueue/river/riverdriver"
+)
+
+var errHeartbeatNotInContext = errors.New("river: heartbeats can only be recorded in a Worker")
+
+// jobHeartbeater records heartbeats for the job being worked.
+type jobHeartbeater struct {
+	exec  riverdriver.Executor
+	jobID int64
+}
+
+func (h *jobHeartbeater) heartbeat(ctx context.Context, progress []byte) error {
+	if _, err := h.exec.JobHeartbeat(ctx, &riverdriver.JobHeartbeatParams{
+		ID:       h.jobID,
+		Progress: progress,
+	}); err != nil {
+		return fmt.Errorf("error recording heartbeat: %w", err)
+	}
+	return nil
+}
+
+func withJobHeartbeater(ctx context.Context, heartbeater *jobHeartbeater) context.Context {
+	return context.WithValue(ctx, ctxKeyJobHeartbeater, heartbeater)
+}
+
+func jobHeartbeaterFromContext(ctx context.Context) *jobHeartbeater {
+	heartbeater, _ := ctx.Value(ctxKeyJobHeartbeater).(*jobHeartbeater)
+	return heartbeater
+}
+
+// JobHeartbeat records a heartbeat for the job being worked, signaling that
+// it's still making progress. The time of the last heartbeat is readable
+// through JobRow.HeartbeatAt.
+//
+// A job that's recorded a heartbeat is only considered stuck and rescued once
+// Config.RescueStuckJobsAfter has elapsed since its last heartbeat rather than
+// since it started, so long running jobs that record heartbeats regularly
+// don't need RescueStuckJobsAfter to be set higher than their longest
+// expected run time:
+//
+//	func (w *MyWorker) Work(ctx context.Context, job *river.Job[MyArgs]) error {
+//		for _, item := range job.Args.Items {
+//			...
+//
+//			if err := river.JobHeartbeat(ctx); err != nil {
+//				return err
+//			}
+//		}
+//
+//		return nil
+//	}
+//
+// Each heartbeat is written to the database immediately. This function can
+// only be used within a Worker's Work method, and returns an error if called
+// elsewhere. It returns an error wrapping rivertype.ErrNotFound if the job is
+// no longer running, like if it's been rescued in the meantime.
+func JobHeartbeat(ctx context.Context) error {
+	heartbeater := jobHeartbeaterFromContext(ctx)
+	if heartbeater == nil {
+		return errHeartbeatNotInContext
+	}
+
+	return heartbeater.heartbeat(ctx, nil)
+}
+
+// JobHeartbeatWithProgress records a heartbeat for the job being worked like
+// JobHeartbeat, along with a progress payload that's encoded to JSON and
+// stored in the job's metadata. Progress is readable through JobRow.Progress,
+// like on a job fetched with Client.JobGet, and replaces any progress that was
+// previously recorded.
+//
+//	if err := river.JobHeartbeatWithProgress(ctx, MyProgress{Processed: i, Total: total}); err != nil {
+//		return err
+//	}
+//
+// It returns an error if progress can't be encoded to JSON, along with those
+// returned by JobHeartbeat.
+func JobHeartbeatWithProgress(ctx context.Context, progress any) error {
+	heartbeater := jobHeartbeaterFromContext(ctx)
+	if heartbeater == nil {
+		return errHeartbeatNotInContext
+	}
+
+	encodedProgress, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("error marshaling progress to JSON: %w", err)
+	}
+
+	return heartbeater.heartbeat(ctx, encodedProgress)
+}
@@ -0,0 +1,97 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/internal/riverinternaltest/testfactory"
+	"github.com/riverqueue/river/internal/util/ptrutil"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestJobHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		return &testBundle{
+			exec: riverpgxv5.New(nil).UnwrapExecutor(riverinternaltest.TestTx(ctx, t)),
+		}
+	}
+
+	t.Run("RecordsHeartbeat", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		require.Nil(t, job.HeartbeatAt)
+
+		workCtx := withJobHeartbeater(ctx, &jobHeartbeater{exec: bundle.exec, jobID: job.ID})
+		require.NoError(t, JobHeartbeat(workCtx))
+
+		updatedJob, err := bundle.exec.JobGetByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updatedJob.HeartbeatAt)
+		require.WithinDuration(t, time.Now(), *updatedJob.HeartbeatAt, 2*time.Second)
+		require.Nil(t, updatedJob.Progress())
+	})
+
+	t.Run("RecordsHeartbeatWithProgress", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		workCtx := withJobHeartbeater(ctx, &jobHeartbeater{exec: bundle.exec, jobID: job.ID})
+		require.NoError(t, JobHeartbeatWithProgress(workCtx, map[string]int{"processed": 1}))
+		require.NoError(t, JobHeartbeatWithProgress(workCtx, map[string]int{"processed": 2}))
+
+		// A heartbeat without progress leaves previous progress in place.
+		require.NoError(t, JobHeartbeat(workCtx))
+
+		updatedJob, err := bundle.exec.JobGetByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.NotNil(t, updatedJob.HeartbeatAt)
+		require.JSONEq(t, `{"processed": 2}`, string(updatedJob.Progress()))
+	})
+
+	t.Run("ErrorJobNotRunning", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+
+		workCtx := withJobHeartbeater(ctx, &jobHeartbeater{exec: bundle.exec, jobID: job.ID})
+		require.ErrorIs(t, JobHeartbeat(workCtx), rivertype.ErrNotFound)
+	})
+
+	t.Run("ErrorOutsideWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, JobHeartbeat(ctx), errHeartbeatNotInContext)
+		require.ErrorIs(t, JobHeartbeatWithProgress(ctx, "progress"), errHeartbeatNotInContext)
+	})
+
+	t.Run("ErrorUnmarshalable", func(t *testing.T) {
+		t.Parallel()
+
+		err := JobHeartbeatWithProgress(withJobHeartbeater(ctx, &jobHeartbeater{}), func() {})
+		require.ErrorContains(t, err, "error marshaling progress to JSON")
+	})
+}
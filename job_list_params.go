@@ -15,19 +15,23 @@ import (
 // JobListCursor is used to specify a starting point for a paginated
 // job list query.
 type JobListCursor struct {
-	id    int64
-	kind  string
-	queue string
-	time  time.Time
+	createdAt time.Time
+	id        int64
+	kind      string
+	priority  int
+	queue     string
+	time      time.Time
 }
 
 // JobListCursorFromJob creates a JobListCursor from a JobRow.
 func JobListCursorFromJob(job *rivertype.JobRow) *JobListCursor {
 	return &JobListCursor{
-		id:    job.ID,
-		kind:  job.Kind,
-		queue: job.Queue,
-		time:  jobListTimeValue(job),
+		createdAt: job.CreatedAt,
+		id:        job.ID,
+		kind:      job.Kind,
+		priority:  job.Priority,
+		queue:     job.Queue,
+		time:      jobListTimeValue(job),
 	}
 }
 
@@ -46,10 +50,12 @@ func (c *JobListCursor) UnmarshalText(text []byte) error {
 		return err
 	}
 	*c = JobListCursor{
-		id:    wrapperValue.ID,
-		kind:  wrapperValue.Kind,
-		queue: wrapperValue.Queue,
-		time:  wrapperValue.Time,
+		createdAt: wrapperValue.CreatedAt,
+		id:        wrapperValue.ID,
+		kind:      wrapperValue.Kind,
+		priority:  wrapperValue.Priority,
+		queue:     wrapperValue.Queue,
+		time:      wrapperValue.Time,
 	}
 	return nil
 }
@@ -58,10 +64,12 @@ func (c *JobListCursor) UnmarshalText(text []byte) error {
 // opaque string.
 func (c JobListCursor) MarshalText() ([]byte, error) {
 	wrapperValue := jobListPaginationCursorJSON{
-		ID:    c.id,
-		Kind:  c.kind,
-		Queue: c.queue,
-		Time:  c.time,
+		CreatedAt: c.createdAt,
+		ID:        c.id,
+		Kind:      c.kind,
+		Priority:  c.priority,
+		Queue:     c.queue,
+		Time:      c.time,
 	}
 	data, err := json.Marshal(wrapperValue)
 	if err != nil {
@@ -73,10 +81,12 @@ func (c JobListCursor) MarshalText() ([]byte, error) {
 }
 
 type jobListPaginationCursorJSON struct {
-	ID    int64     `json:"id"`
-	Kind  string    `json:"kind"`
-	Queue string    `json:"queue"`
-	Time  time.Time `json:"time"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Priority  int       `json:"priority"`
+	Queue     string    `json:"queue"`
+	Time      time.Time `json:"time"`
 }
 
 // SortOrder specifies the direction of a sort.
@@ -94,8 +104,21 @@ type JobListOrderByField int
 
 const (
 	// JobListOrderByTime specifies that the sort should be by time. The specific
-	// time field used will vary by job state.
+	// time field used will vary by job state. If the list is filtered to
+	// states that use different time fields, or isn't filtered by state at
+	// all, jobs are sorted by created_at instead.
 	JobListOrderByTime JobListOrderByField = iota
+
+	// JobListOrderByCreatedAt specifies that the sort should be by the time
+	// jobs were created.
+	JobListOrderByCreatedAt
+
+	// JobListOrderByID specifies that the sort should be by job ID.
+	JobListOrderByID
+
+	// JobListOrderByPriority specifies that the sort should be by priority,
+	// with jobs of the same priority sorted by ID.
+	JobListOrderByPriority
 )
 
 // JobListParams specifies the parameters for a JobList query. It must be
@@ -105,13 +128,27 @@ const (
 //	params := NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc).First(100)
 type JobListParams struct {
 	after            *JobListCursor
+	argsFragment     string
+	attemptAtLeast   *int
+	attemptAtMost    *int
+	attemptedBy      []string
+	createdAfter     *time.Time
+	createdBefore    *time.Time
+	finalizedAfter   *time.Time
+	finalizedBefore  *time.Time
+	ids              []int64
 	kinds            []string
 	metadataFragment string
 	paginationCount  int32
+	priorities       []int16
 	queues           []string
+	scheduledAfter   *time.Time
+	scheduledBefore  *time.Time
 	sortField        JobListOrderByField
 	sortOrder        SortOrder
-	state            rivertype.JobState
+	states           []rivertype.JobState
+	tagsAll          []string
+	tagsAny          []string
 }
 
 // NewJobListParams creates a new JobListParams to return available jobs sorted
@@ -121,20 +158,34 @@ func NewJobListParams() *JobListParams {
 		paginationCount: 100,
 		sortField:       JobListOrderByTime,
 		sortOrder:       SortOrderAsc,
-		state:           rivertype.JobStateAvailable,
+		states:          []rivertype.JobState{rivertype.JobStateAvailable},
 	}
 }
 
 func (p *JobListParams) copy() *JobListParams {
 	return &JobListParams{
 		after:            p.after,
+		argsFragment:     p.argsFragment,
+		attemptAtLeast:   p.attemptAtLeast,
+		attemptAtMost:    p.attemptAtMost,
+		attemptedBy:      append([]string(nil), p.attemptedBy...),
+		createdAfter:     p.createdAfter,
+		createdBefore:    p.createdBefore,
+		finalizedAfter:   p.finalizedAfter,
+		finalizedBefore:  p.finalizedBefore,
+		ids:              append([]int64(nil), p.ids...),
 		kinds:            append([]string(nil), p.kinds...),
 		metadataFragment: p.metadataFragment,
 		paginationCount:  p.paginationCount,
+		priorities:       append([]int16(nil), p.priorities...),
 		queues:           append([]string(nil), p.queues...),
+		scheduledAfter:   p.scheduledAfter,
+		scheduledBefore:  p.scheduledBefore,
 		sortField:        p.sortField,
 		sortOrder:        p.sortOrder,
-		state:            p.state,
+		states:           append([]rivertype.JobState(nil), p.states...),
+		tagsAll:          append([]string(nil), p.tagsAll...),
+		tagsAny:          append([]string(nil), p.tagsAny...),
 	}
 }
 
@@ -142,7 +193,7 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 	conditionsBuilder := &strings.Builder{}
 	conditions := make([]string, 0, 10)
 	namedArgs := make(map[string]any)
-	orderBy := []dblist.JobListOrderBy{}
+	orderBy := make([]dblist.JobListOrderBy, 0, 2)
 
 	var sortOrder dblist.SortOrder
 	switch p.sortOrder {
@@ -154,27 +205,108 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 		return nil, errors.New("invalid sort order")
 	}
 
-	if p.sortField != JobListOrderByTime {
+	var (
+		sortField   string
+		cursorValue any
+	)
+	switch p.sortField {
+	case JobListOrderByTime:
+		sortField = jobListTimeFieldForStates(p.states)
+		if p.after != nil {
+			cursorValue = p.after.time
+			if sortField == "created_at" {
+				cursorValue = p.after.createdAt
+			}
+		}
+	case JobListOrderByCreatedAt:
+		sortField = "created_at"
+		if p.after != nil {
+			cursorValue = p.after.createdAt
+		}
+	case JobListOrderByID:
+		sortField = "id"
+	case JobListOrderByPriority:
+		sortField = "priority"
+		if p.after != nil {
+			cursorValue = p.after.priority
+		}
+	default:
 		return nil, errors.New("invalid sort field")
 	}
-	timeField := jobListTimeFieldForState(p.state)
-	orderBy = append(orderBy, []dblist.JobListOrderBy{
-		{Expr: timeField, Order: sortOrder},
-		{Expr: "id", Order: sortOrder},
-	}...)
+
+	if sortField != "id" {
+		orderBy = append(orderBy, dblist.JobListOrderBy{Expr: sortField, Order: sortOrder})
+	}
+	orderBy = append(orderBy, dblist.JobListOrderBy{Expr: "id", Order: sortOrder})
+
+	if p.argsFragment != "" {
+		conditions = append(conditions, `args @> @args_fragment::jsonb`)
+		namedArgs["args_fragment"] = p.argsFragment
+	}
+
+	if p.attemptAtLeast != nil {
+		conditions = append(conditions, `attempt >= @attempt_at_least::smallint`)
+		namedArgs["attempt_at_least"] = *p.attemptAtLeast
+	}
+
+	if p.attemptAtMost != nil {
+		conditions = append(conditions, `attempt <= @attempt_at_most::smallint`)
+		namedArgs["attempt_at_most"] = *p.attemptAtMost
+	}
+
+	if len(p.attemptedBy) > 0 {
+		conditions = append(conditions, `attempted_by && @attempted_by::text[]`)
+		namedArgs["attempted_by"] = p.attemptedBy
+	}
+
+	for _, timeRange := range []struct {
+		field         string
+		after, before *time.Time
+	}{
+		{"created_at", p.createdAfter, p.createdBefore},
+		{"finalized_at", p.finalizedAfter, p.finalizedBefore},
+		{"scheduled_at", p.scheduledAfter, p.scheduledBefore},
+	} {
+		if timeRange.after != nil {
+			conditions = append(conditions, fmt.Sprintf(`"%s" >= @%s_after::timestamptz`, timeRange.field, timeRange.field))
+			namedArgs[timeRange.field+"_after"] = *timeRange.after
+		}
+		if timeRange.before != nil {
+			conditions = append(conditions, fmt.Sprintf(`"%s" < @%s_before::timestamptz`, timeRange.field, timeRange.field))
+			namedArgs[timeRange.field+"_before"] = *timeRange.before
+		}
+	}
 
 	if p.metadataFragment != "" {
 		conditions = append(conditions, `metadata @> @metadata_fragment::jsonb`)
 		namedArgs["metadata_fragment"] = p.metadataFragment
 	}
 
+	if len(p.tagsAll) > 0 {
+		conditions = append(conditions, `tags @> @tags_all::varchar(255)[]`)
+		namedArgs["tags_all"] = p.tagsAll
+	}
+
+	if len(p.tagsAny) > 0 {
+		conditions = append(conditions, `tags && @tags_any::varchar(255)[]`)
+		namedArgs["tags_any"] = p.tagsAny
+	}
+
 	if p.after != nil {
-		if sortOrder == dblist.SortOrderAsc {
-			conditions = append(conditions, fmt.Sprintf(`("%s" > @cursor_time OR ("%s" = @cursor_time AND "id" > @after_id))`, timeField, timeField))
+		if sortField == "id" {
+			if sortOrder == dblist.SortOrderAsc {
+				conditions = append(conditions, `"id" > @after_id`)
+			} else {
+				conditions = append(conditions, `"id" < @after_id`)
+			}
 		} else {
-			conditions = append(conditions, fmt.Sprintf(`("%s" < @cursor_time OR ("%s" = @cursor_time AND "id" < @after_id))`, timeField, timeField))
+			if sortOrder == dblist.SortOrderAsc {
+				conditions = append(conditions, fmt.Sprintf(`("%s" > @cursor_value OR ("%s" = @cursor_value AND "id" > @after_id))`, sortField, sortField))
+			} else {
+				conditions = append(conditions, fmt.Sprintf(`("%s" < @cursor_value OR ("%s" = @cursor_value AND "id" < @after_id))`, sortField, sortField))
+			}
+			namedArgs["cursor_value"] = cursorValue
 		}
-		namedArgs["cursor_time"] = p.after.time
 		namedArgs["after_id"] = p.after.id
 	}
 
@@ -187,13 +319,14 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 
 	dbParams := &dblist.JobListParams{
 		Conditions: conditionsBuilder.String(),
+		IDs:        p.ids,
 		Kinds:      p.kinds,
 		LimitCount: p.paginationCount,
 		NamedArgs:  namedArgs,
 		OrderBy:    orderBy,
-		Priorities: nil,
+		Priorities: p.priorities,
 		Queues:     p.queues,
-		State:      p.state,
+		States:     p.states,
 	}
 
 	return dbParams, nil
@@ -207,6 +340,73 @@ func (p *JobListParams) After(cursor *JobListCursor) *JobListParams {
 	return result
 }
 
+// Args returns an updated filter set that will only return jobs whose args
+// contain the given JSON fragment, as determined by Postgres' `@>` operator.
+func (p *JobListParams) Args(json string) *JobListParams {
+	result := p.copy()
+	result.argsFragment = json
+	return result
+}
+
+// AttemptAtLeast returns an updated filter set that will only return jobs
+// that have been attempted at least the given number of times.
+func (p *JobListParams) AttemptAtLeast(attempt int) *JobListParams {
+	result := p.copy()
+	result.attemptAtLeast = &attempt
+	return result
+}
+
+// AttemptAtMost returns an updated filter set that will only return jobs
+// that have been attempted at most the given number of times.
+func (p *JobListParams) AttemptAtMost(attempt int) *JobListParams {
+	result := p.copy()
+	result.attemptAtMost = &attempt
+	return result
+}
+
+// AttemptedBy returns an updated filter set that will only return jobs that
+// were attempted by any of the clients with the given IDs.
+func (p *JobListParams) AttemptedBy(clientIDs ...string) *JobListParams {
+	result := p.copy()
+	result.attemptedBy = make([]string, len(clientIDs))
+	copy(result.attemptedBy, clientIDs)
+	return result
+}
+
+// CreatedAfter returns an updated filter set that will only return jobs
+// created at or after the given time.
+func (p *JobListParams) CreatedAfter(createdAfter time.Time) *JobListParams {
+	result := p.copy()
+	result.createdAfter = &createdAfter
+	return result
+}
+
+// CreatedBefore returns an updated filter set that will only return jobs
+// created before the given time.
+func (p *JobListParams) CreatedBefore(createdBefore time.Time) *JobListParams {
+	result := p.copy()
+	result.createdBefore = &createdBefore
+	return result
+}
+
+// FinalizedAfter returns an updated filter set that will only return jobs
+// finalized at or after the given time. Jobs that haven't been finalized are
+// never returned.
+func (p *JobListParams) FinalizedAfter(finalizedAfter time.Time) *JobListParams {
+	result := p.copy()
+	result.finalizedAfter = &finalizedAfter
+	return result
+}
+
+// FinalizedBefore returns an updated filter set that will only return jobs
+// finalized before the given time. Jobs that haven't been finalized are never
+// returned.
+func (p *JobListParams) FinalizedBefore(finalizedBefore time.Time) *JobListParams {
+	result := p.copy()
+	result.finalizedBefore = &finalizedBefore
+	return result
+}
+
 // First returns an updated filter set that will only return the first
 // count jobs.
 //
@@ -223,6 +423,15 @@ func (p *JobListParams) First(count int) *JobListParams {
 	return result
 }
 
+// IDs returns an updated filter set that will only return jobs with the given
+// IDs.
+func (p *JobListParams) IDs(ids ...int64) *JobListParams {
+	result := p.copy()
+	result.ids = make([]int64, len(ids))
+	copy(result.ids, ids)
+	return result
+}
+
 // Kinds returns an updated filter set that will only return jobs of the given
 // kinds.
 func (p *JobListParams) Kinds(kinds ...string) *JobListParams {
@@ -232,12 +441,26 @@ func (p *JobListParams) Kinds(kinds ...string) *JobListParams {
 	return result
 }
 
+// Metadata returns an updated filter set that will only return jobs whose
+// metadata contains the given JSON fragment, as determined by Postgres' `@>`
+// operator.
 func (p *JobListParams) Metadata(json string) *JobListParams {
 	result := p.copy()
 	result.metadataFragment = json
 	return result
 }
 
+// Priorities returns an updated filter set that will only return jobs with the
+// given priorities.
+func (p *JobListParams) Priorities(priorities ...int) *JobListParams {
+	result := p.copy()
+	result.priorities = make([]int16, len(priorities))
+	for i, priority := range priorities {
+		result.priorities[i] = int16(priority)
+	}
+	return result
+}
+
 // Queues returns an updated filter set that will only return jobs from the
 // given queues.
 func (p *JobListParams) Queues(queues ...string) *JobListParams {
@@ -256,17 +479,78 @@ func (p *JobListParams) OrderBy(field JobListOrderByField, direction SortOrder)
 	return result
 }
 
+// ScheduledAfter returns an updated filter set that will only return jobs
+// scheduled to run at or after the given time.
+func (p *JobListParams) ScheduledAfter(scheduledAfter time.Time) *JobListParams {
+	result := p.copy()
+	result.scheduledAfter = &scheduledAfter
+	return result
+}
+
+// ScheduledBefore returns an updated filter set that will only return jobs
+// scheduled to run before the given time.
+func (p *JobListParams) ScheduledBefore(scheduledBefore time.Time) *JobListParams {
+	result := p.copy()
+	result.scheduledBefore = &scheduledBefore
+	return result
+}
+
 // State returns an updated filter set that will only return jobs in the given
-// state.
+// state. An empty state returns jobs in any state.
 func (p *JobListParams) State(state rivertype.JobState) *JobListParams {
+	if state == "" {
+		return p.States()
+	}
+	return p.States(state)
+}
+
+// States returns an updated filter set that will only return jobs in any of
+// the given states. Calling it without any states returns jobs in any state.
+func (p *JobListParams) States(states ...rivertype.JobState) *JobListParams {
+	result := p.copy()
+	result.states = make([]rivertype.JobState, len(states))
+	copy(result.states, states)
+	return result
+}
+
+// TagsAll returns an updated filter set that will only return jobs that have
+// all of the given tags.
+func (p *JobListParams) TagsAll(tags ...string) *JobListParams {
+	result := p.copy()
+	result.tagsAll = make([]string, len(tags))
+	copy(result.tagsAll, tags)
+	return result
+}
+
+// TagsAny returns an updated filter set that will only return jobs that have
+// any of the given tags.
+func (p *JobListParams) TagsAny(tags ...string) *JobListParams {
 	result := p.copy()
-	result.state = state
+	result.tagsAny = make([]string, len(tags))
+	copy(result.tagsAny, tags)
 	return result
 }
 
+// jobListTimeFieldForStates returns the time field to sort by for jobs in the
+// given states, falling back to created_at if there are none or they don't all
+// share the same time field.
+func jobListTimeFieldForStates(states []rivertype.JobState) string {
+	if len(states) < 1 {
+		return "created_at"
+	}
+
+	timeField := jobListTimeFieldForState(states[0])
+	for _, state := range states[1:] {
+		if jobListTimeFieldForState(state) != timeField {
+			return "created_at"
+		}
+	}
+	return timeField
+}
+
 func jobListTimeFieldForState(state rivertype.JobState) string {
 	switch state {
-	case rivertype.JobStateAvailable, rivertype.JobStateRetryable, rivertype.JobStateScheduled:
+	case rivertype.JobStateAvailable, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateScheduled:
 		return "scheduled_at"
 	case rivertype.JobStateRunning:
 		return "attempted_at"
@@ -279,7 +563,7 @@ func jobListTimeFieldForState(state rivertype.JobState) string {
 
 func jobListTimeValue(job *rivertype.JobRow) time.Time {
 	switch job.State {
-	case rivertype.JobStateAvailable, rivertype.JobStateRetryable, rivertype.JobStateScheduled:
+	case rivertype.JobStateAvailable, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateScheduled:
 		return job.ScheduledAt
 	case rivertype.JobStateRunning:
 		if job.AttemptedAt == nil {
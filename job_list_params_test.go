@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/riverqueue/river/internal/dblist"
 	"github.com/riverqueue/river/internal/util/ptrutil"
 	"github.com/riverqueue/river/rivertype"
 )
@@ -73,6 +74,25 @@ func Test_JobListCursor_JobListCursorFromJob(t *testing.T) {
 		})
 	}
 
+	t.Run("IncludesCreatedAtAndPriority", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now().UTC()
+		jobRow := &rivertype.JobRow{
+			CreatedAt:   now.Add(-11 * time.Second),
+			ID:          4,
+			Kind:        "test",
+			Priority:    3,
+			Queue:       "test",
+			State:       rivertype.JobStateAvailable,
+			ScheduledAt: now.Add(-10 * time.Second),
+		}
+
+		cursor := JobListCursorFromJob(jobRow)
+		require.Equal(t, jobRow.CreatedAt, cursor.createdAt)
+		require.Equal(t, jobRow.Priority, cursor.priority)
+	})
+
 	t.Run("RunningJobUsesAttemptedAt", func(t *testing.T) {
 		t.Parallel()
 
@@ -123,10 +143,12 @@ func Test_JobListCursor_MarshalJSON(t *testing.T) {
 
 		now := time.Now().UTC()
 		params := &JobListCursor{
-			id:    4,
-			kind:  "test_kind",
-			queue: "test_queue",
-			time:  now,
+			createdAt: now.Add(-1 * time.Minute),
+			id:        4,
+			kind:      "test_kind",
+			priority:  2,
+			queue:     "test_queue",
+			time:      now,
 		}
 
 		text, err := json.Marshal(params)
@@ -139,3 +161,101 @@ func Test_JobListCursor_MarshalJSON(t *testing.T) {
 		require.Equal(t, params, unmarshaledParams)
 	})
 }
+
+func Test_jobListTimeFieldForStates(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "created_at", jobListTimeFieldForStates(nil))
+	require.Equal(t, "scheduled_at", jobListTimeFieldForStates([]rivertype.JobState{rivertype.JobStateAvailable}))
+	require.Equal(t, "scheduled_at", jobListTimeFieldForStates([]rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateScheduled}))
+	require.Equal(t, "finalized_at", jobListTimeFieldForStates([]rivertype.JobState{rivertype.JobStateCancelled, rivertype.JobStateCompleted}))
+	require.Equal(t, "created_at", jobListTimeFieldForStates([]rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateCompleted}))
+}
+
+func TestJobListParams_toDBParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		dbParams, err := NewJobListParams().toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, []rivertype.JobState{rivertype.JobStateAvailable}, dbParams.States)
+		require.Equal(t, []dblist.JobListOrderBy{
+			{Expr: "scheduled_at", Order: dblist.SortOrderAsc},
+			{Expr: "id", Order: dblist.SortOrderAsc},
+		}, dbParams.OrderBy)
+		require.Empty(t, dbParams.Conditions)
+	})
+
+	t.Run("OrderByID", func(t *testing.T) {
+		t.Parallel()
+
+		dbParams, err := NewJobListParams().
+			OrderBy(JobListOrderByID, SortOrderDesc).
+			After(&JobListCursor{id: 123}).
+			toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, []dblist.JobListOrderBy{{Expr: "id", Order: dblist.SortOrderDesc}}, dbParams.OrderBy)
+		require.Equal(t, `"id" < @after_id`, dbParams.Conditions)
+		require.Equal(t, int64(123), dbParams.NamedArgs["after_id"])
+	})
+
+	t.Run("OrderByPriority", func(t *testing.T) {
+		t.Parallel()
+
+		dbParams, err := NewJobListParams().
+			OrderBy(JobListOrderByPriority, SortOrderAsc).
+			After(&JobListCursor{id: 123, priority: 2}).
+			toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, []dblist.JobListOrderBy{
+			{Expr: "priority", Order: dblist.SortOrderAsc},
+			{Expr: "id", Order: dblist.SortOrderAsc},
+		}, dbParams.OrderBy)
+		require.Equal(t, 2, dbParams.NamedArgs["cursor_value"])
+	})
+
+	t.Run("InvalidSortField", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewJobListParams().OrderBy(JobListOrderByField(99), SortOrderAsc).toDBParams()
+		require.EqualError(t, err, "invalid sort field")
+	})
+
+	t.Run("AllFilters", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+
+		dbParams, err := NewJobListParams().
+			Args(`{"foo": "bar"}`).
+			AttemptAtLeast(1).
+			AttemptAtMost(3).
+			AttemptedBy("client1").
+			CreatedAfter(now).
+			FinalizedBefore(now).
+			IDs(1, 2).
+			Priorities(1, 2).
+			ScheduledAfter(now).
+			States(rivertype.JobStateAvailable, rivertype.JobStateRetryable).
+			TagsAll("tag1").
+			TagsAny("tag2").
+			toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 2}, dbParams.IDs)
+		require.Equal(t, []int16{1, 2}, dbParams.Priorities)
+		require.Equal(t, []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateRetryable}, dbParams.States)
+		require.Equal(t, map[string]any{
+			"args_fragment":       `{"foo": "bar"}`,
+			"attempt_at_least":    1,
+			"attempt_at_most":     3,
+			"attempted_by":        []string{"client1"},
+			"created_at_after":    now,
+			"finalized_at_before": now,
+			"scheduled_at_after":  now,
+			"tags_all":            []string{"tag1"},
+			"tags_any":            []string{"tag2"},
+		}, dbParams.NamedArgs)
+	})
+}
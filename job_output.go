@@ -0,0 +1,87 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Maximum size of an encoded output recorded with RecordOutput. Outputs are
+// stored in job metadata, which is covered by a GIN index and read along with
+// every job row, so they're kept small.
+const maxOutputSize = 1024 * 1024 // 1 MB
+
+// Metadata key under which a job's output is stored. It's namespaced so that it
+// won't collide with keys set by users in their own metadata.
+const metadataKeyOutput = "river:output"
+
+var errOutputNotInContext = errors.New("river: output can only be recorded in a Worker")
+
+// jobOutput holds the output recorded by a job while it's being worked so
+// that it can be persisted when the job is completed.
+type jobOutput struct {
+	output json.RawMessage
+}
+
+// metadataUpdates returns a JSON object to merge into the job's metadata to
+// store its output, or nil if no output was recorded.
+func (o *jobOutput) metadataUpdates() ([]byte, error) {
+	if o == nil || o.output == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(map[string]json.RawMessage{metadataKeyOutput: o.output})
+}
+
+func withJobOutput(ctx context.Context, output *jobOutput) context.Context {
+	return context.WithValue(ctx, ctxKeyJobOutput, output)
+}
+
+func jobOutputFromContext(ctx context.Context) *jobOutput {
+	output, _ := ctx.Value(ctxKeyJobOutput).(*jobOutput)
+	return output
+}
+
+// RecordOutput records an output for the job being worked. The output is
+// encoded to JSON and stored in the job's metadata when the job completes,
+// written by the same update that sets the job to completed. It's readable
+// afterwards through JobRow.Output (e.g. on a job fetched with Client.JobGet or
+// received in an EventKindJobCompleted event), and by downstream workflow
+// tasks with WorkflowTask.Output.
+//
+//	func (w *MyWorker) Work(ctx context.Context, job *river.Job[MyArgs]) error {
+//		...
+//
+//		if err := river.RecordOutput(ctx, MyOutput{Total: total}); err != nil {
+//			return err
+//		}
+//
+//		return nil
+//	}
+//
+// Calling RecordOutput more than once replaces any previously recorded output.
+// Output is only stored if the job completes successfully, including when it's
+// completed with JobCompleteTx.
+//
+// This function can only be used within a Worker's Work method, and returns an
+// error if called elsewhere, if v can't be encoded to JSON, or if its encoded
+// size exceeds 1 MB.
+func RecordOutput(ctx context.Context, v any) error {
+	output := jobOutputFromContext(ctx)
+	if output == nil {
+		return errOutputNotInContext
+	}
+
+	encodedOutput, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling output to JSON: %w", err)
+	}
+
+	if len(encodedOutput) > maxOutputSize {
+		return fmt.Errorf("output is too large: %d bytes (maximum is %d bytes)", len(encodedOutput), maxOutputSize)
+	}
+
+	output.output = encodedOutput
+	return nil
+}
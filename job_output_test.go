@@ -0,0 +1,75 @@
+package river
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordOutput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("RecordsOutput", func(t *testing.T) {
+		t.Parallel()
+
+		output := &jobOutput{}
+		workCtx := withJobOutput(ctx, output)
+
+		require.NoError(t, RecordOutput(workCtx, map[string]int{"result": 123}))
+		require.JSONEq(t, `{"result": 123}`, string(output.output))
+
+		metadataUpdates, err := output.metadataUpdates()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"river:output": {"result": 123}}`, string(metadataUpdates))
+	})
+
+	t.Run("ReplacesPreviousOutput", func(t *testing.T) {
+		t.Parallel()
+
+		output := &jobOutput{}
+		workCtx := withJobOutput(ctx, output)
+
+		require.NoError(t, RecordOutput(workCtx, map[string]int{"result": 123}))
+		require.NoError(t, RecordOutput(workCtx, map[string]int{"result": 456}))
+		require.JSONEq(t, `{"result": 456}`, string(output.output))
+	})
+
+	t.Run("NoOutputNoMetadataUpdates", func(t *testing.T) {
+		t.Parallel()
+
+		metadataUpdates, err := (&jobOutput{}).metadataUpdates()
+		require.NoError(t, err)
+		require.Nil(t, metadataUpdates)
+
+		metadataUpdates, err = (*jobOutput)(nil).metadataUpdates()
+		require.NoError(t, err)
+		require.Nil(t, metadataUpdates)
+	})
+
+	t.Run("ErrorOutsideWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, RecordOutput(ctx, "output"), errOutputNotInContext)
+	})
+
+	t.Run("ErrorUnmarshalable", func(t *testing.T) {
+		t.Parallel()
+
+		err := RecordOutput(withJobOutput(ctx, &jobOutput{}), func() {})
+		require.ErrorContains(t, err, "error marshaling output to JSON")
+	})
+
+	t.Run("ErrorTooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		output := &jobOutput{}
+
+		err := RecordOutput(withJobOutput(ctx, output), strings.Repeat("a", maxOutputSize))
+		require.ErrorContains(t, err, "output is too large")
+		require.Nil(t, output.output)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"sync/atomic"
 	"time"
@@ -13,15 +14,33 @@ import (
 	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/notifier"
 	"github.com/riverqueue/river/internal/util/chanutil"
+	"github.com/riverqueue/river/internal/util/hashutil"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivertype"
 )
 
+// Default interval between refreshes of a queue's persisted settings when
+// pauses and resumes are also being received through the notifier.
+const queuePollIntervalDefault = 30 * time.Second
+
 type producerConfig struct {
-	ClientID     string
+	// AdvisoryLockPrefix is a prefix for the advisory lock used to serialize
+	// fetches when GlobalMaxWorkerCount or ConcurrencyLimits are set.
+	AdvisoryLockPrefix int32
+
+	ClientID string
+
+	// ConcurrencyLimits limit the number of jobs of particular kinds that may
+	// be running at once across all clients.
+	ConcurrencyLimits []*riverdriver.JobGetAvailableConcurrencyLimit
+
 	ErrorHandler ErrorHandler
 
+	// FairnessMetadataKey is a metadata key whose values partition the queue's
+	// jobs so that they're fetched round-robin across partitions.
+	FairnessMetadataKey string
+
 	// FetchCooldown is the minimum amount of time to wait between fetches of new
 	// jobs. Jobs will only be fetched *at most* this often, but if no new jobs
 	// are coming in via LISTEN/NOTIFY then feches may be delayed as long as
@@ -33,13 +52,45 @@ type producerConfig struct {
 	// LISTEN/NOTIFY, but this provides a fallback.
 	FetchPollInterval time.Duration
 
-	JobTimeout        time.Duration
-	MaxWorkerCount    uint16
-	Notifier          *notifier.Notifier
-	Queue             string
+	// GlobalMaxWorkerCount is the maximum number of jobs in the queue that may
+	// be running at once across all clients. Zero means no global limit.
+	GlobalMaxWorkerCount int
+
+	JobTimeout     time.Duration
+	MaxWorkerCount uint16
+	Notifier       *notifier.Notifier
+
+	// PollOnly indicates that the producer should operate without a notifier.
+	// New jobs are found only through FetchPollInterval, and cancellation of
+	// running jobs is detected by polling for them at the same interval.
+	PollOnly bool
+
+	// PriorityAgingInterval is the amount of time after which a job waiting
+	// to be worked has its effective priority improved by one. Zero disables
+	// priority aging.
+	PriorityAgingInterval time.Duration
+
+	Queue string
+
+	// QueuePollInterval is the amount of time between periodic refreshes of
+	// the queue's persisted settings, like whether it's paused. Refreshing also
+	// bumps the queue's updated_at timestamp. Pauses and resumes are normally
+	// received immediately through the notifier, so this is only a fallback
+	// unless PollOnly is set.
+	QueuePollInterval time.Duration
+
+	// RateLimits limit the rate at which jobs in the queue, or jobs of
+	// particular kinds, are started.
+	RateLimits []*RateLimit
+
 	RetryPolicy       ClientRetryPolicy
 	SchedulerInterval time.Duration
-	Workers           *Workers
+
+	// WorkerMiddleware is middleware invoked around the Work function of every
+	// job worked by the producer.
+	WorkerMiddleware []rivertype.WorkerMiddleware
+
+	Workers *Workers
 }
 
 // producer manages a fleet of Workers up to a maximum size. It periodically fetches jobs
@@ -65,17 +116,52 @@ type producer struct {
 	// main goroutine.
 	cancelCh chan int64
 
+	// Set while a poll for cancelled jobs is in flight so that another isn't
+	// started until it finishes. Only used in poll-only mode.
+	cancelPollInFlight atomic.Bool
+
 	// Receives completed jobs from workers. Written by completed workers, only
 	// read from main goroutine.
 	jobResultCh chan *rivertype.JobRow
 
 	jobTimeout time.Duration
 
+	// The maximum number of jobs that can be worked at once as configured for
+	// the client, which may be changed while the producer is running with
+	// SetMaxWorkerCount. Overridden by maxWorkerCountOverride if it's set.
+	maxWorkerCount atomic.Int32
+
+	// Receives a signal when maxWorkerCount is changed so that a fetch can be
+	// triggered in case capacity has increased. Only read from main goroutine.
+	maxWorkerCountChangedCh chan struct{}
+
+	// A maximum number of workers persisted in the queue's settings, which
+	// takes precedence over maxWorkerCount when greater than zero. Only used by
+	// main goroutine.
+	maxWorkerCountOverride int
+
 	// An atomic count of the number of jobs actively being worked on. This is
 	// written to by the main goroutine, but read by the dispatcher.
 	numJobsActive atomic.Int32
 
-	numJobsRan  atomic.Uint64
+	numJobsRan atomic.Uint64
+
+	// Whether the queue is paused, in which case no new jobs are fetched. Only
+	// used by main goroutine.
+	paused bool
+
+	// The updated_at timestamp of the most recent queue change that's been
+	// applied. Only used by main goroutine.
+	queueSettingsUpdatedAt time.Time
+
+	// Receives queue pauses and resumes. Written by notifier goroutine and
+	// queue settings refreshes, only read from main goroutine.
+	queueControlCh chan *queueControlPayload
+
+	// Tracks token buckets for the queue's rate limits, or nil if it has none.
+	// Only used by the goroutine fetching jobs.
+	rateLimiter *rateLimiter
+
 	retryPolicy ClientRetryPolicy
 }
 
@@ -95,18 +181,27 @@ func newProducer(archetype *baseservice.Archetype, exec riverdriver.Executor, co
 	if config.FetchPollInterval <= 0 {
 		return nil, errors.New("FetchPollInterval must be greater than zero")
 	}
+	if config.GlobalMaxWorkerCount < 0 {
+		return nil, errors.New("GlobalMaxWorkerCount must be greater or equal to zero")
+	}
 	if config.JobTimeout < -1 {
 		return nil, errors.New("JobTimeout must be greater or equal to zero")
 	}
 	if config.MaxWorkerCount == 0 {
 		return nil, errors.New("MaxWorkerCount is required")
 	}
-	if config.Notifier == nil {
-		return nil, errors.New("Notifier is required") //nolint:stylecheck
+	if config.Notifier == nil && !config.PollOnly {
+		return nil, errors.New("Notifier is required unless PollOnly is set") //nolint:stylecheck
+	}
+	if config.PriorityAgingInterval < 0 {
+		return nil, errors.New("PriorityAgingInterval must be greater or equal to zero")
 	}
 	if config.Queue == "" {
 		return nil, errors.New("Queue is required") //nolint:stylecheck
 	}
+	if config.QueuePollInterval <= 0 {
+		return nil, errors.New("QueuePollInterval must be greater than zero")
+	}
 	if config.RetryPolicy == nil {
 		return nil, errors.New("RetryPolicy is required")
 	}
@@ -117,18 +212,47 @@ func newProducer(archetype *baseservice.Archetype, exec riverdriver.Executor, co
 		return nil, errors.New("Workers is required")
 	}
 
-	return baseservice.Init(archetype, &producer{
+	producer := &producer{
 		activeJobs:   make(map[int64]*jobExecutor),
 		cancelCh:     make(chan int64, 1000),
 		completer:    completer,
 		config:       config,
 		exec:         exec,
 		errorHandler: config.ErrorHandler,
-		jobResultCh:  make(chan *rivertype.JobRow, config.MaxWorkerCount),
-		jobTimeout:   config.JobTimeout,
-		retryPolicy:  config.RetryPolicy,
-		workers:      config.Workers,
-	}), nil
+
+		// Sized to the initial number of workers. If the maximum is raised
+		// later, workers finishing beyond the buffer's capacity block briefly
+		// until the main loop receives their results.
+		jobResultCh: make(chan *rivertype.JobRow, config.MaxWorkerCount),
+
+		jobTimeout:              config.JobTimeout,
+		maxWorkerCountChangedCh: make(chan struct{}, 1),
+		queueControlCh:          make(chan *queueControlPayload, 10),
+		retryPolicy:             config.RetryPolicy,
+		workers:                 config.Workers,
+	}
+	producer.maxWorkerCount.Store(int32(config.MaxWorkerCount))
+
+	if len(config.RateLimits) > 0 {
+		producer.rateLimiter = newRateLimiter(config.Queue, config.RateLimits)
+	}
+
+	return baseservice.Init(archetype, producer), nil
+}
+
+// SetMaxWorkerCount changes the maximum number of jobs that the producer will
+// work at once. It's safe to call while the producer is running, in which case
+// a higher maximum is put to use right away by fetching more jobs, and a lower
+// one takes effect as jobs that are already running finish. A maximum
+// persisted in the queue's settings takes precedence over this one.
+func (p *producer) SetMaxWorkerCount(maxWorkerCount uint16) {
+	p.maxWorkerCount.Store(int32(maxWorkerCount))
+
+	select {
+	case p.maxWorkerCountChangedCh <- struct{}{}:
+	default:
+		// A change is already waiting to be picked up.
+	}
 }
 
 type producerStatusUpdateFunc func(queue string, status componentstatus.Status)
@@ -172,8 +296,46 @@ func (p *producer) Run(fetchCtx, workCtx context.Context, statusFunc producerSta
 			slog.String("queue", decoded.Queue),
 		)
 	}
-	sub := p.config.Notifier.Listen(notifier.NotificationTopicJobControl, handleJobControlNotification)
-	defer sub.Unlisten()
+	handleQueueControlNotification := func(topic notifier.NotificationTopic, payload string) {
+		var decoded queueControlPayload
+		if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+			p.Logger.ErrorContext(workCtx, p.Name+": Failed to unmarshal queue control notification payload", slog.String("err", err.Error()))
+			return
+		}
+		if decoded.Queue != p.config.Queue {
+			return
+		}
+		select {
+		case p.queueControlCh <- &decoded:
+		default:
+			p.Logger.WarnContext(workCtx, p.Name+": Queue control notification dropped due to full buffer", slog.String("action", string(decoded.Action)))
+		}
+	}
+	if !p.config.PollOnly {
+		sub := p.config.Notifier.Listen(notifier.NotificationTopicJobControl, handleJobControlNotification)
+		defer sub.Unlisten()
+
+		queueSub := p.config.Notifier.Listen(notifier.NotificationTopicQueueControl, handleQueueControlNotification)
+		defer queueSub.Unlisten()
+	}
+
+	// Register the queue and load its persisted settings, like whether it's
+	// been paused, before any jobs are fetched. On error, the queue is assumed
+	// not to be paused and its settings are refreshed again on the next queue
+	// poll.
+	queue, err := p.exec.QueueCreateOrSetUpdatedAt(workCtx, &riverdriver.QueueCreateOrSetUpdatedAtParams{Name: p.config.Queue})
+	if err != nil {
+		p.Logger.ErrorContext(workCtx, p.Name+": Error registering queue", slog.String("queue", p.config.Queue), slog.String("err", err.Error()))
+	} else {
+		p.paused = queue.PausedAt != nil
+		p.queueSettingsUpdatedAt = queue.UpdatedAt
+
+		if settings, err := queueSettingsFromMetadata(queue.Metadata); err != nil {
+			p.Logger.ErrorContext(workCtx, p.Name+": Error reading queue settings", slog.String("queue", p.config.Queue), slog.String("err", err.Error()))
+		} else {
+			p.maxWorkerCountOverride = settings.MaxWorkers
+		}
+	}
 
 	p.fetchAndRunLoop(fetchCtx, workCtx, fetchLimiter, statusFunc)
 	statusFunc(p.config.Queue, componentstatus.ShuttingDown)
@@ -193,6 +355,50 @@ type jobControlPayload struct {
 	Queue  string           `json:"queue"`
 }
 
+type queueControlAction string
+
+const (
+	queueControlActionPause  queueControlAction = "pause"
+	queueControlActionResume queueControlAction = "resume"
+	queueControlActionUpdate queueControlAction = "update"
+)
+
+type queueControlPayload struct {
+	Action queueControlAction `json:"action"`
+
+	// MaxWorkers is the queue's persisted maximum number of workers, sent
+	// with the update action. Zero indicates that no maximum is persisted and
+	// the client's configured maximum should be used.
+	MaxWorkers int `json:"max_workers,omitempty"`
+
+	Queue string `json:"queue"`
+
+	// UpdatedAt is the queue's updated_at timestamp as of the change. Changes
+	// older than one that's already been applied are ignored, so that a slow
+	// queue poll can't undo a pause or resume that happened after it read the
+	// queue. Zero if unknown, in which case the change is always applied.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Metadata key under which a queue's persisted maximum number of workers is
+// stored.
+const queueMetadataKeyMaxWorkers = "max_workers"
+
+// queueSettings are the settings persisted in a queue's metadata.
+type queueSettings struct {
+	MaxWorkers int `json:"max_workers"`
+}
+
+func queueSettingsFromMetadata(metadata []byte) (*queueSettings, error) {
+	var settings queueSettings
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &settings); err != nil {
+			return nil, fmt.Errorf("error unmarshaling queue metadata: %w", err)
+		}
+	}
+	return &settings, nil
+}
+
 type insertPayload struct {
 	Queue string `json:"queue"`
 }
@@ -217,8 +423,20 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context, fetchLimit
 		p.Logger.DebugContext(workCtx, p.Name+": Received insert notification", slog.String("queue", decoded.Queue))
 		fetchLimiter.Call()
 	}
-	sub := p.config.Notifier.Listen(notifier.NotificationTopicInsert, handleInsertNotification)
-	defer sub.Unlisten()
+	// Without a notifier, running jobs are periodically checked to see whether
+	// a cancellation has been attempted on any of them.
+	var cancelPollTickerC <-chan time.Time
+	if p.config.PollOnly {
+		cancelPollTicker := time.NewTicker(p.config.FetchPollInterval)
+		defer cancelPollTicker.Stop()
+		cancelPollTickerC = cancelPollTicker.C
+	} else {
+		sub := p.config.Notifier.Listen(notifier.NotificationTopicInsert, handleInsertNotification)
+		defer sub.Unlisten()
+	}
+
+	queuePollTicker := time.NewTicker(p.config.QueuePollInterval)
+	defer queuePollTicker.Stop()
 
 	fetchPollTimer := time.NewTimer(p.config.FetchPollInterval)
 	go func() {
@@ -245,6 +463,14 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context, fetchLimit
 		case <-fetchCtx.Done():
 			return
 		case <-fetchLimiter.C():
+			// Apply pauses and resumes received before the fetch was
+			// triggered, which wouldn't otherwise be guaranteed to be handled
+			// first because select picks randomly between ready channels.
+			p.drainQueueControl(workCtx, fetchLimiter)
+			if p.paused {
+				continue
+			}
+
 			p.innerFetchLoop(workCtx, fetchResultCh)
 			// Ensure we can't start another fetch when fetchCtx is done, even if
 			// the fetchLimiter is also ready to fire:
@@ -255,12 +481,179 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context, fetchLimit
 			}
 		case result := <-p.jobResultCh:
 			p.removeActiveJob(result.ID)
+		case <-cancelPollTickerC:
+			p.pollForCancelledJobs(workCtx)
+		case jobID := <-p.cancelCh:
+			p.maybeCancelJob(jobID)
+		case <-queuePollTicker.C:
+			p.pollQueueSettings(workCtx)
+		case control := <-p.queueControlCh:
+			p.handleQueueControl(workCtx, control, fetchLimiter)
+		case <-p.maxWorkerCountChangedCh:
+			fetchLimiter.Call()
+		}
+	}
+}
+
+// handleQueueControl pauses, resumes, or updates the settings of the producer.
+// When resumed or given more capacity, a fetch is triggered right away so that
+// available jobs are picked up without waiting for the next fetch poll.
+func (p *producer) handleQueueControl(ctx context.Context, control *queueControlPayload, fetchLimiter *chanutil.DebouncedChan) {
+	if !control.UpdatedAt.IsZero() {
+		if control.UpdatedAt.Before(p.queueSettingsUpdatedAt) {
+			p.Logger.DebugContext(ctx, p.Name+": Ignoring stale queue control", slog.String("action", string(control.Action)))
+			return
+		}
+		p.queueSettingsUpdatedAt = control.UpdatedAt
+	}
+
+	switch control.Action {
+	case queueControlActionPause:
+		if !p.paused {
+			p.Logger.InfoContext(ctx, p.Name+": Queue paused", slog.String("queue", p.config.Queue))
+		}
+		p.paused = true
+	case queueControlActionResume:
+		if p.paused {
+			p.Logger.InfoContext(ctx, p.Name+": Queue resumed", slog.String("queue", p.config.Queue))
+			fetchLimiter.Call()
+		}
+		p.paused = false
+	case queueControlActionUpdate:
+		if control.MaxWorkers == p.maxWorkerCountOverride {
+			return
+		}
+
+		previousMaxWorkerCount := p.effectiveMaxWorkerCount()
+		p.maxWorkerCountOverride = max(control.MaxWorkers, 0)
+		p.Logger.InfoContext(ctx, p.Name+": Queue max workers updated",
+			slog.Int("max_workers", p.effectiveMaxWorkerCount()),
+			slog.String("queue", p.config.Queue),
+		)
+
+		if p.effectiveMaxWorkerCount() > previousMaxWorkerCount {
+			fetchLimiter.Call()
 		}
+	default:
+		p.Logger.DebugContext(ctx, p.Name+": Received queue control notification with unknown action", slog.String("action", string(control.Action)))
 	}
 }
 
+// drainQueueControl handles any queue control messages waiting in
+// queueControlCh.
+func (p *producer) drainQueueControl(ctx context.Context, fetchLimiter *chanutil.DebouncedChan) {
+	for {
+		select {
+		case control := <-p.queueControlCh:
+			p.handleQueueControl(ctx, control, fetchLimiter)
+		default:
+			return
+		}
+	}
+}
+
+// pollQueueSettings refreshes the queue's persisted settings, bumping its
+// updated_at timestamp at the same time. It's a fallback in case a queue
+// control notification was missed, and the only way pauses and resumes are
+// found in poll-only mode.
+//
+// The refresh happens in a separate goroutine so as not to block the main
+// loop, with results sent back through queueControlCh.
+func (p *producer) pollQueueSettings(ctx context.Context) {
+	go func() {
+		queue, err := p.exec.QueueCreateOrSetUpdatedAt(ctx, &riverdriver.QueueCreateOrSetUpdatedAtParams{Name: p.config.Queue})
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				p.Logger.ErrorContext(ctx, p.Name+": Error polling for queue settings", slog.String("err", err.Error()))
+			}
+			return
+		}
+
+		settings, err := queueSettingsFromMetadata(queue.Metadata)
+		if err != nil {
+			p.Logger.ErrorContext(ctx, p.Name+": Error reading queue settings", slog.String("err", err.Error()))
+			return
+		}
+
+		pauseControl := &queueControlPayload{Action: queueControlActionResume, Queue: queue.Name, UpdatedAt: queue.UpdatedAt}
+		if queue.PausedAt != nil {
+			pauseControl.Action = queueControlActionPause
+		}
+
+		for _, control := range []*queueControlPayload{
+			pauseControl,
+			{Action: queueControlActionUpdate, MaxWorkers: settings.MaxWorkers, Queue: queue.Name, UpdatedAt: queue.UpdatedAt},
+		} {
+			select {
+			case p.queueControlCh <- control:
+			default:
+				p.Logger.WarnContext(ctx, p.Name+": Queue settings dropped due to full buffer", slog.String("action", string(control.Action)))
+			}
+		}
+	}()
+}
+
+// pollForCancelledJobs checks whether a cancellation has been attempted on any
+// active job, and if so, sends it for cancellation. It's used in poll-only
+// mode, where cancellations can't be received through the notifier.
+//
+// The lookup happens in a separate goroutine so as not to block the main loop,
+// with results sent back through cancelCh. If a previous lookup is still in
+// flight, the tick is skipped.
+func (p *producer) pollForCancelledJobs(ctx context.Context) {
+	if len(p.activeJobs) < 1 {
+		return
+	}
+
+	if !p.cancelPollInFlight.CompareAndSwap(false, true) {
+		return
+	}
+
+	jobIDs := make([]int64, 0, len(p.activeJobs))
+	for id := range p.activeJobs {
+		jobIDs = append(jobIDs, id)
+	}
+
+	go func() {
+		defer p.cancelPollInFlight.Store(false)
+
+		jobs, err := p.exec.JobGetByIDMany(ctx, jobIDs)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				p.Logger.ErrorContext(ctx, p.Name+": Error polling for cancelled jobs", slog.String("err", err.Error()))
+			}
+			return
+		}
+
+		for _, job := range jobs {
+			var metadata struct {
+				CancelAttemptedAt *time.Time `json:"cancel_attempted_at"`
+			}
+			if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+				p.Logger.ErrorContext(ctx, p.Name+": Failed to unmarshal job metadata", slog.Int64("job_id", job.ID), slog.String("err", err.Error()))
+				continue
+			}
+			if metadata.CancelAttemptedAt == nil {
+				continue
+			}
+
+			select {
+			case p.cancelCh <- job.ID:
+			default:
+				p.Logger.WarnContext(ctx, p.Name+": Job cancellation dropped due to full buffer", slog.Int64("job_id", job.ID))
+			}
+		}
+	}()
+}
+
 func (p *producer) innerFetchLoop(workCtx context.Context, fetchResultCh chan producerFetchResult) {
 	limit := p.maxJobsToFetch()
+	if limit < 1 {
+		// All workers are busy, possibly because the maximum number of workers
+		// was lowered below the number of jobs already running.
+		return
+	}
+
 	go p.dispatchWork(limit, fetchResultCh) //nolint:contextcheck
 
 	for {
@@ -318,11 +711,21 @@ func (p *producer) dispatchWork(count int, jobsFetchedCh chan<- producerFetchRes
 	// them, and then stop. Otherwise we'd have a risk of shutting down when we
 	// had already fetched jobs in the database, leaving those jobs stranded. We'd
 	// then potentially have to release them back to the queue.
-	jobs, err := p.exec.JobGetAvailable(context.Background(), &riverdriver.JobGetAvailableParams{
-		AttemptedBy: p.config.ClientID,
-		Max:         count,
-		Queue:       p.config.Queue,
-	})
+	var (
+		jobs []*rivertype.JobRow
+		err  error
+	)
+	if p.config.GlobalMaxWorkerCount > 0 || len(p.config.ConcurrencyLimits) > 0 || p.rateLimiter != nil {
+		jobs, err = p.fetchLimited(context.Background(), count)
+	} else {
+		jobs, err = p.exec.JobGetAvailable(context.Background(), &riverdriver.JobGetAvailableParams{
+			AttemptedBy:           p.config.ClientID,
+			FairnessKey:           p.config.FairnessMetadataKey,
+			Max:                   count,
+			PriorityAgingInterval: p.config.PriorityAgingInterval,
+			Queue:                 p.config.Queue,
+		})
+	}
 	if err != nil {
 		jobsFetchedCh <- producerFetchResult{err: err}
 		return
@@ -330,6 +733,72 @@ func (p *producer) dispatchWork(count int, jobsFetchedCh chan<- producerFetchRes
 	jobsFetchedCh <- producerFetchResult{jobs: jobs}
 }
 
+// fetchLimited fetches jobs while respecting the queue's global maximum number
+// of running jobs, its concurrency limits, and its rate limits. Where limits
+// are shared with other clients (a global maximum, concurrency limits, or
+// global rate limits), fetches for the queue are serialized across clients by
+// an advisory lock so that each one sees the running jobs and rate limit
+// buckets of those that came before it. Local rate limits alone don't need it.
+func (p *producer) fetchLimited(ctx context.Context, count int) ([]*rivertype.JobRow, error) {
+	tx, err := p.exec.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if p.config.GlobalMaxWorkerCount > 0 || len(p.config.ConcurrencyLimits) > 0 || (p.rateLimiter != nil && p.rateLimiter.hasGlobal()) {
+		advisoryLockHash := hashutil.NewAdvisoryLockHash(p.config.AdvisoryLockPrefix)
+		advisoryLockHash.Write([]byte("limited_fetch"))
+		advisoryLockHash.Write([]byte("queue=" + p.config.Queue))
+
+		if _, err := tx.PGAdvisoryXactLock(ctx, advisoryLockHash.Key()); err != nil {
+			return nil, fmt.Errorf("error acquiring limited fetch lock: %w", err)
+		}
+	}
+
+	var (
+		kindMax          map[string]int
+		rateLimitBuckets map[*RateLimit]*rateLimitBucket
+	)
+	if p.rateLimiter != nil {
+		rateLimitBuckets, err = p.rateLimiter.loadBuckets(ctx, tx, p.TimeNowUTC())
+		if err != nil {
+			return nil, err
+		}
+
+		count, kindMax = p.rateLimiter.allowances(rateLimitBuckets, count)
+		if count < 1 {
+			return nil, nil
+		}
+	}
+
+	jobs, err := tx.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+		AttemptedBy:           p.config.ClientID,
+		ConcurrencyLimits:     p.config.ConcurrencyLimits,
+		FairnessKey:           p.config.FairnessMetadataKey,
+		GlobalMax:             p.config.GlobalMaxWorkerCount,
+		KindMax:               kindMax,
+		Max:                   count,
+		PriorityAgingInterval: p.config.PriorityAgingInterval,
+		Queue:                 p.config.Queue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.rateLimiter != nil {
+		if err := p.rateLimiter.consumeAndSaveBuckets(ctx, tx, rateLimitBuckets, jobs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
 // Periodically logs an informational log line giving some insight into the
 // current state of the producer.
 func (p *producer) heartbeatLogLoop(ctx context.Context) {
@@ -367,9 +836,11 @@ func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.
 			ClientRetryPolicy:      p.retryPolicy,
 			Completer:              p.completer,
 			ErrorHandler:           p.errorHandler,
+			Exec:                   p.exec,
 			InformProducerDoneFunc: p.handleWorkerDone,
 			JobRow:                 job,
 			SchedulerInterval:      p.config.SchedulerInterval,
+			WorkerMiddleware:       p.config.WorkerMiddleware,
 			WorkUnit:               workUnit,
 		})
 		p.addActiveJob(job.ID, executor)
@@ -385,8 +856,17 @@ func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.
 	}
 }
 
+// effectiveMaxWorkerCount returns the maximum number of jobs that can be worked
+// at once, taking into account any maximum persisted in the queue's settings.
+func (p *producer) effectiveMaxWorkerCount() int {
+	if p.maxWorkerCountOverride > 0 {
+		return p.maxWorkerCountOverride
+	}
+	return int(p.maxWorkerCount.Load())
+}
+
 func (p *producer) maxJobsToFetch() int {
-	return int(p.config.MaxWorkerCount) - int(p.numJobsActive.Load())
+	return p.effectiveMaxWorkerCount() - int(p.numJobsActive.Load())
 }
 
 func (p *producer) handleWorkerDone(job *rivertype.JobRow) {
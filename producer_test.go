@@ -17,6 +17,8 @@ import (
 	"github.com/riverqueue/river/internal/notifier"
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/internal/riverinternaltest/testfactory"
+	"github.com/riverqueue/river/internal/util/ptrutil"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivertype"
@@ -82,6 +84,7 @@ func Test_Producer_CanSafelyCompleteJobsWhileFetchingNewOnes(t *testing.T) {
 		MaxWorkerCount:    1000,
 		Notifier:          notifier,
 		Queue:             rivercommon.QueueDefault,
+		QueuePollInterval: queuePollIntervalDefault,
 		RetryPolicy:       &DefaultClientRetryPolicy{},
 		SchedulerInterval: maintenance.JobSchedulerIntervalDefault,
 		ClientID:          "fakeWorkerNameTODO",
@@ -92,7 +95,7 @@ func Test_Producer_CanSafelyCompleteJobsWhileFetchingNewOnes(t *testing.T) {
 
 	params := make([]*riverdriver.JobInsertFastParams, maxJobCount)
 	for i := range params {
-		insertParams, _, err := insertParamsFromArgsAndOptions(WithJobNumArgs{JobNum: i}, nil)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, WithJobNumArgs{JobNum: i}, nil)
 		require.NoError(err)
 
 		params[i] = insertParams
@@ -180,6 +183,7 @@ func Test_Producer_Run(t *testing.T) {
 			MaxWorkerCount:    1000,
 			Notifier:          notifier,
 			Queue:             rivercommon.QueueDefault,
+			QueuePollInterval: 50 * time.Millisecond, // more aggressive than normal so pauses and resumes are picked up quickly without a running notifier
 			RetryPolicy:       &DefaultClientRetryPolicy{},
 			SchedulerInterval: riverinternaltest.SchedulerShortInterval,
 			ClientID:          "fakeWorkerNameTODO",
@@ -199,7 +203,7 @@ func Test_Producer_Run(t *testing.T) {
 	mustInsert := func(ctx context.Context, t *testing.T, exec riverdriver.Executor, args JobArgs) {
 		t.Helper()
 
-		insertParams, _, err := insertParamsFromArgsAndOptions(args, nil)
+		insertParams, _, err := insertParamsFromArgsAndOptions(ctx, &Config{}, args, nil)
 		require.NoError(t, err)
 
 		_, err = exec.JobInsertFast(ctx, insertParams)
@@ -251,6 +255,403 @@ func Test_Producer_Run(t *testing.T) {
 		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
 	})
 
+	t.Run("QueuePausedOnStart", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+
+		_ = testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{
+			Name:     ptrutil.Ptr(rivercommon.QueueDefault),
+			PausedAt: ptrutil.Ptr(time.Now()),
+		})
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		// Give the producer a number of fetch polls to make sure it's not
+		// working the job.
+		select {
+		case update := <-bundle.jobUpdates:
+			require.FailNow(t, "Job unexpectedly worked in paused queue", "Job: %+v", update.Job)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+
+		_, err := bundle.exec.QueueResume(ctx, rivercommon.QueueDefault)
+		require.NoError(t, err)
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+	})
+
+	t.Run("QueuePausedAndResumed", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+
+		// The notifier isn't running, so the pause is picked up on the next
+		// queue poll instead. Wait a few of them to make sure it has been.
+		_, err := bundle.exec.QueuePause(ctx, rivercommon.QueueDefault)
+		require.NoError(t, err)
+		time.Sleep(3 * producer.config.QueuePollInterval)
+
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		select {
+		case update := <-bundle.jobUpdates:
+			require.FailNow(t, "Job unexpectedly worked in paused queue", "Job: %+v", update.Job)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+
+		_, err = bundle.exec.QueueResume(ctx, rivercommon.QueueDefault)
+		require.NoError(t, err)
+
+		update = riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+	})
+
+	t.Run("StaleQueueControlIgnored", func(t *testing.T) {
+		t.Parallel()
+
+		producer, _ := setup(t)
+
+		now := time.Now()
+
+		producer.handleQueueControl(ctx, &queueControlPayload{Action: queueControlActionPause, Queue: rivercommon.QueueDefault, UpdatedAt: now}, nil)
+		require.True(t, producer.paused)
+
+		// A resume read before the pause was applied is ignored.
+		producer.handleQueueControl(ctx, &queueControlPayload{Action: queueControlActionResume, Queue: rivercommon.QueueDefault, UpdatedAt: now.Add(-1 * time.Second)}, nil)
+		require.True(t, producer.paused)
+		require.Equal(t, now, producer.queueSettingsUpdatedAt)
+	})
+
+	t.Run("MaxWorkerCountChanged", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.maxWorkerCount.Store(1)
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		startedCh := make(chan int64)
+		doneCh := make(chan struct{})
+		AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+		t.Cleanup(func() { close(doneCh) })
+
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+
+		// Only one worker is available, so the second job must wait.
+		select {
+		case jobID := <-startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond max workers", "Job ID: %d", jobID)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+
+		producer.SetMaxWorkerCount(2)
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+	})
+
+	t.Run("GlobalMaxWorkerCount", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.GlobalMaxWorkerCount = 2
+
+		// A job already running on another client counts against the limit.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		startedCh := make(chan int64)
+		doneCh := make(chan struct{})
+		AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+		t.Cleanup(func() { close(doneCh) })
+
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+
+		// The producer has plenty of workers available, but the global max has
+		// been reached.
+		select {
+		case jobID := <-startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond global max workers", "Job ID: %d", jobID)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+	})
+
+	t.Run("ConcurrencyLimits", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.ConcurrencyLimits = []*riverdriver.JobGetAvailableConcurrencyLimit{
+			{Kind: (&callbackArgs{}).Kind(), Max: 1},
+		}
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		startedCh := make(chan int64)
+		doneCh := make(chan struct{})
+		AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+		t.Cleanup(func() { close(doneCh) })
+
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+
+		// Jobs of other kinds are still worked while the limited kind is at
+		// its limit.
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, (&noOpArgs{}).Kind(), update.Job.Kind)
+
+		select {
+		case jobID := <-startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond concurrency limit", "Job ID: %d", jobID)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+	})
+
+	t.Run("FairnessMetadataKey", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.FairnessMetadataKey = "tenant"
+		producer.maxWorkerCount.Store(2)
+
+		// Tenant 1 inserts a burst of jobs before tenant 2 inserts one of its
+		// own. Tenant 2's job should be worked alongside tenant 1's first job
+		// rather than waiting for the burst to be worked through.
+		for i := 0; i < 3; i++ {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				Kind:     ptrutil.Ptr((&callbackArgs{}).Kind()),
+				Metadata: []byte(`{"tenant": "tenant1"}`),
+			})
+		}
+		tenant2Job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Kind:     ptrutil.Ptr((&callbackArgs{}).Kind()),
+			Metadata: []byte(`{"tenant": "tenant2"}`),
+		})
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		startedCh := make(chan int64)
+		doneCh := make(chan struct{})
+		AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+		t.Cleanup(func() { close(doneCh) })
+
+		startedJobIDs := []int64{
+			riverinternaltest.WaitOrTimeout(t, startedCh),
+			riverinternaltest.WaitOrTimeout(t, startedCh),
+		}
+		require.Contains(t, startedJobIDs, tenant2Job.ID)
+	})
+
+	t.Run("RateLimits", func(t *testing.T) {
+		t.Parallel()
+
+		for _, global := range []bool{false, true} {
+			global := global
+
+			t.Run(fmt.Sprintf("Global=%t", global), func(t *testing.T) {
+				t.Parallel()
+
+				producer, bundle := setup(t)
+				producer.config.RateLimits = []*RateLimit{{Global: global, Kind: (&callbackArgs{}).Kind(), Limit: 1, Period: time.Hour}}
+				producer.rateLimiter = newRateLimiter(producer.config.Queue, producer.config.RateLimits)
+
+				fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+				startedCh := make(chan int64)
+				doneCh := make(chan struct{})
+				AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+				AddWorker(bundle.workers, &noOpWorker{})
+
+				var wg sync.WaitGroup
+				wg.Add(1)
+				go func() {
+					producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+					wg.Done()
+				}()
+
+				// LIFO, so guarantee run loop finishes and producer exits, even in
+				// the event of a test failure.
+				t.Cleanup(wg.Wait)
+				t.Cleanup(fetchCtxDone)
+				t.Cleanup(func() { close(doneCh) })
+
+				mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+				mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+
+				riverinternaltest.WaitOrTimeout(t, startedCh)
+
+				// Jobs of other kinds are still worked while the limited kind
+				// is over its limit.
+				mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+				update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+				require.Equal(t, (&noOpArgs{}).Kind(), update.Job.Kind)
+
+				select {
+				case jobID := <-startedCh:
+					require.FailNow(t, "Job unexpectedly started beyond rate limit", "Job ID: %d", jobID)
+				case <-time.After(5 * producer.config.FetchPollInterval):
+				}
+
+				if global {
+					buckets, err := bundle.exec.RateLimitGetMany(ctx, []string{"queue=" + producer.config.Queue + "&kind=" + (&callbackArgs{}).Kind()})
+					require.NoError(t, err)
+					require.Len(t, buckets, 1)
+					require.Less(t, buckets[0].Tokens, 1.0)
+				}
+			})
+		}
+	})
+
+	t.Run("MaxWorkerCountPersisted", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+
+		_ = testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{
+			Metadata: []byte(`{"max_workers": 1}`),
+			Name:     ptrutil.Ptr(rivercommon.QueueDefault),
+		})
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		startedCh := make(chan int64)
+		doneCh := make(chan struct{})
+		AddWorker(bundle.workers, &callbackWorker{fn: makeAwaitCallback(startedCh, doneCh)})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+		t.Cleanup(func() { close(doneCh) })
+
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+		mustInsert(ctx, t, bundle.exec, &callbackArgs{})
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+
+		// The persisted maximum takes precedence over the configured one.
+		select {
+		case jobID := <-startedCh:
+			require.FailNow(t, "Job unexpectedly started beyond persisted max workers", "Job ID: %d", jobID)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+
+		// The notifier isn't running, so removal of the persisted maximum is
+		// picked up on the next queue poll.
+		_, err := bundle.exec.QueueUpdateMetadata(ctx, &riverdriver.QueueUpdateMetadataParams{
+			MetadataDeleteKeys: []string{"max_workers"},
+			Name:               rivercommon.QueueDefault,
+		})
+		require.NoError(t, err)
+
+		riverinternaltest.WaitOrTimeout(t, startedCh)
+	})
+
 	t.Run("UnknownJobKind", func(t *testing.T) {
 		t.Parallel()
 
@@ -300,4 +701,122 @@ func Test_Producer_Run(t *testing.T) {
 			require.Equal(t, rivertype.JobStateCompleted, job.State)
 		}
 	})
+	t.Run("PollOnlySimpleJob", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.Notifier = nil
+		producer.config.PollOnly = true
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+	})
+
+	t.Run("PollOnlyQueuePausedOnStart", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.Notifier = nil
+		producer.config.PollOnly = true
+
+		_ = testfactory.Queue(ctx, t, bundle.exec, &testfactory.QueueOpts{
+			Name:     ptrutil.Ptr(rivercommon.QueueDefault),
+			PausedAt: ptrutil.Ptr(time.Now()),
+		})
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+
+		mustInsert(ctx, t, bundle.exec, &noOpArgs{})
+
+		select {
+		case update := <-bundle.jobUpdates:
+			require.FailNow(t, "Job unexpectedly worked in paused queue", "Job: %+v", update.Job)
+		case <-time.After(5 * producer.config.FetchPollInterval):
+		}
+
+		_, err := bundle.exec.QueueResume(ctx, rivercommon.QueueDefault)
+		require.NoError(t, err)
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+	})
+
+	t.Run("PollOnlyCancelsRunningJob", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.Notifier = nil
+		producer.config.PollOnly = true
+
+		fetchCtx, fetchCtxDone := context.WithCancel(ctx)
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		startedChan := make(chan int64, 1)
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			startedChan <- job.ID
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			producer.Run(fetchCtx, ctx, func(queue string, status componentstatus.Status) {})
+			wg.Done()
+		}()
+
+		// LIFO, so guarantee run loop finishes and producer exits, even in the
+		// event of a test failure.
+		t.Cleanup(wg.Wait)
+		t.Cleanup(fetchCtxDone)
+
+		mustInsert(ctx, t, bundle.exec, &JobArgs{})
+
+		jobID := riverinternaltest.WaitOrTimeout(t, startedChan)
+
+		_, err := bundle.exec.JobCancel(ctx, &riverdriver.JobCancelParams{
+			ID:                jobID,
+			CancelAttemptedAt: time.Now(),
+			JobControlTopic:   string(notifier.NotificationTopicJobControl),
+		})
+		require.NoError(t, err)
+
+		update := riverinternaltest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCancelled, update.Job.State)
+	})
 }
@@ -0,0 +1,247 @@
+package river
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// RateLimit limits the rate at which jobs in a queue are started, either for
+// all jobs in the queue or for jobs of a particular kind. It's configured on a
+// queue with QueueConfig.RateLimits:
+//
+//	Queues: map[string]river.QueueConfig{
+//		river.QueueDefault: {
+//			MaxWorkers: 100,
+//			RateLimits: []river.RateLimit{
+//				{Kind: "call_partner_api", Limit: 100, Period: time.Minute, Global: true},
+//			},
+//		},
+//	},
+//
+// Limits are enforced with a token bucket. Up to Limit jobs may be started in
+// a burst, after which capacity is regained gradually at a rate of Limit jobs
+// per Period. Producers respect limits when deciding how many jobs to fetch, so
+// jobs over a limit stay available in the queue until there's capacity to
+// start them, and jobs of other kinds continue to be worked in the meantime.
+type RateLimit struct {
+	// Global enforces the limit across all clients working the queue by
+	// storing its token bucket in the database, in which case all clients
+	// should be configured with the same limit. By default, each client
+	// enforces the limit on its own, so the overall rate scales with the
+	// number of clients.
+	//
+	// Global limits require a database migration (version 006).
+	Global bool
+
+	// Kind is the kind of job to limit, as returned by its JobArgs' Kind. If
+	// empty, the limit applies to all jobs in the queue.
+	Kind string
+
+	// Limit is the maximum number of jobs that may be started per Period.
+	//
+	// Requires a minimum of 1.
+	Limit int
+
+	// Period is the period of time over which Limit applies.
+	//
+	// Requires a duration greater than zero.
+	Period time.Duration
+}
+
+func (l *RateLimit) validate(queueName string) error {
+	if l.Limit < 1 {
+		return fmt.Errorf("invalid limit for rate limit of kind %q in queue %q: %d", l.Kind, queueName, l.Limit)
+	}
+	if l.Period <= 0 {
+		return fmt.Errorf("invalid period for rate limit of kind %q in queue %q: %s", l.Kind, queueName, l.Period)
+	}
+	return nil
+}
+
+func validateRateLimits(queueName string, limits []RateLimit) error {
+	kinds := make(map[string]struct{}, len(limits))
+	for _, limit := range limits {
+		if err := limit.validate(queueName); err != nil {
+			return err
+		}
+		if _, ok := kinds[limit.Kind]; ok {
+			return fmt.Errorf("queue %q has more than one rate limit for kind %q", queueName, limit.Kind)
+		}
+		kinds[limit.Kind] = struct{}{}
+	}
+	return nil
+}
+
+// rateLimitBucket is the state of a token bucket enforcing a rate limit.
+type rateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimitBucket(limit *RateLimit, now time.Time) *rateLimitBucket {
+	return &rateLimitBucket{tokens: float64(limit.Limit), updatedAt: now}
+}
+
+// available returns the number of whole tokens in the bucket, which is the
+// number of jobs that may be started.
+func (b *rateLimitBucket) available() int {
+	return max(int(math.Floor(b.tokens)), 0)
+}
+
+// refill adds tokens to the bucket for the time elapsed since it was last
+// updated, up to the limit. A time earlier than the last update (as can happen
+// with clock skew between clients sharing a global bucket) adds no tokens and
+// doesn't move the last update back.
+func (b *rateLimitBucket) refill(limit *RateLimit, now time.Time) {
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Limit), b.tokens+float64(limit.Limit)*elapsed.Seconds()/limit.Period.Seconds())
+		b.updatedAt = now
+	}
+}
+
+// rateLimiter tracks the token buckets of a queue's rate limits. Buckets of
+// local limits are kept in memory, while those of global limits are loaded from
+// and saved to the database around each fetch.
+//
+// It's not safe for concurrent use, but a producer only fetches jobs from one
+// goroutine at a time.
+type rateLimiter struct {
+	limits       []*RateLimit
+	localBuckets map[*RateLimit]*rateLimitBucket
+	queue        string
+}
+
+func newRateLimiter(queue string, limits []*RateLimit) *rateLimiter {
+	return &rateLimiter{
+		limits:       limits,
+		localBuckets: make(map[*RateLimit]*rateLimitBucket),
+		queue:        queue,
+	}
+}
+
+// globalKey returns the key under which the bucket of a global limit is stored.
+func (r *rateLimiter) globalKey(limit *RateLimit) string {
+	if limit.Kind == "" {
+		return "queue=" + r.queue
+	}
+	return "queue=" + r.queue + "&kind=" + limit.Kind
+}
+
+// loadBuckets returns the buckets of all limits, refilled up to now. Global
+// buckets are loaded using the given executor, and should be saved with
+// consumeAndSaveBuckets in the same transaction.
+func (r *rateLimiter) loadBuckets(ctx context.Context, exec riverdriver.Executor, now time.Time) (map[*RateLimit]*rateLimitBucket, error) {
+	buckets := make(map[*RateLimit]*rateLimitBucket, len(r.limits))
+
+	var globalKeys []string
+	for _, limit := range r.limits {
+		if limit.Global {
+			globalKeys = append(globalKeys, r.globalKey(limit))
+			continue
+		}
+
+		bucket, ok := r.localBuckets[limit]
+		if !ok {
+			bucket = newRateLimitBucket(limit, now)
+			r.localBuckets[limit] = bucket
+		}
+		buckets[limit] = bucket
+	}
+
+	if len(globalKeys) > 0 {
+		globalBuckets, err := exec.RateLimitGetMany(ctx, globalKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error getting rate limit buckets: %w", err)
+		}
+
+		globalBucketsByKey := make(map[string]*riverdriver.RateLimitBucket, len(globalBuckets))
+		for _, bucket := range globalBuckets {
+			globalBucketsByKey[bucket.Key] = bucket
+		}
+
+		for _, limit := range r.limits {
+			if !limit.Global {
+				continue
+			}
+
+			if bucket, ok := globalBucketsByKey[r.globalKey(limit)]; ok {
+				buckets[limit] = &rateLimitBucket{tokens: bucket.Tokens, updatedAt: bucket.UpdatedAt}
+			} else {
+				buckets[limit] = newRateLimitBucket(limit, now)
+			}
+		}
+	}
+
+	for limit, bucket := range buckets {
+		bucket.refill(limit, now)
+	}
+
+	return buckets, nil
+}
+
+// allowances returns the maximum number of jobs that may be started under the
+// given buckets, starting from the given maximum for the queue, along with
+// maximums for individual kinds.
+func (r *rateLimiter) allowances(buckets map[*RateLimit]*rateLimitBucket, queueMax int) (int, map[string]int) {
+	var kindMax map[string]int
+	for limit, bucket := range buckets {
+		if limit.Kind == "" {
+			queueMax = min(queueMax, bucket.available())
+			continue
+		}
+
+		if kindMax == nil {
+			kindMax = make(map[string]int)
+		}
+		kindMax[limit.Kind] = bucket.available()
+	}
+	return queueMax, kindMax
+}
+
+// hasGlobal returns true if any of the limits are global, in which case their
+// buckets are shared with other clients through the database.
+func (r *rateLimiter) hasGlobal() bool {
+	for _, limit := range r.limits {
+		if limit.Global {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeAndSaveBuckets takes tokens from buckets for the jobs that were
+// fetched, then saves global buckets using the given executor.
+func (r *rateLimiter) consumeAndSaveBuckets(ctx context.Context, exec riverdriver.Executor, buckets map[*RateLimit]*rateLimitBucket, jobs []*rivertype.JobRow) error {
+	numJobsByKind := make(map[string]int)
+	for _, job := range jobs {
+		numJobsByKind[job.Kind]++
+	}
+
+	params := &riverdriver.RateLimitSetManyParams{}
+	for limit, bucket := range buckets {
+		if limit.Kind == "" {
+			bucket.tokens -= float64(len(jobs))
+		} else {
+			bucket.tokens -= float64(numJobsByKind[limit.Kind])
+		}
+
+		if limit.Global {
+			params.Key = append(params.Key, r.globalKey(limit))
+			params.Tokens = append(params.Tokens, bucket.tokens)
+			params.UpdatedAt = append(params.UpdatedAt, bucket.updatedAt)
+		}
+	}
+
+	if len(params.Key) > 0 {
+		if err := exec.RateLimitSetMany(ctx, params); err != nil {
+			return fmt.Errorf("error saving rate limit buckets: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package river
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestValidateRateLimits(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateRateLimits(QueueDefault, nil))
+	require.NoError(t, validateRateLimits(QueueDefault, []RateLimit{
+		{Limit: 10, Period: time.Second},
+		{Kind: "kind1", Limit: 1, Period: time.Minute, Global: true},
+	}))
+
+	require.EqualError(t,
+		validateRateLimits(QueueDefault, []RateLimit{{Kind: "kind1", Period: time.Second}}),
+		`invalid limit for rate limit of kind "kind1" in queue "default": 0`,
+	)
+	require.EqualError(t,
+		validateRateLimits(QueueDefault, []RateLimit{{Kind: "kind1", Limit: 1}}),
+		`invalid period for rate limit of kind "kind1" in queue "default": 0s`,
+	)
+	require.EqualError(t,
+		validateRateLimits(QueueDefault, []RateLimit{{Limit: 1, Period: time.Second}, {Limit: 2, Period: time.Second}}),
+		`queue "default" has more than one rate limit for kind ""`,
+	)
+}
+
+func TestRateLimitBucket(t *testing.T) {
+	t.Parallel()
+
+	var (
+		limit = &RateLimit{Limit: 10, Period: 10 * time.Second}
+		now   = time.Now()
+	)
+
+	bucket := newRateLimitBucket(limit, now)
+	require.Equal(t, 10, bucket.available())
+
+	bucket.tokens = 0
+	require.Equal(t, 0, bucket.available())
+
+	// One token is regained per second.
+	bucket.refill(limit, now.Add(2500*time.Millisecond))
+	require.InDelta(t, 2.5, bucket.tokens, 0.001)
+	require.Equal(t, 2, bucket.available())
+
+	// Refilling from an earlier time has no effect.
+	bucket.refill(limit, now)
+	require.InDelta(t, 2.5, bucket.tokens, 0.001)
+	require.Equal(t, now.Add(2500*time.Millisecond), bucket.updatedAt)
+
+	// Never refilled beyond the limit.
+	bucket.refill(limit, now.Add(time.Hour))
+	require.Equal(t, 10, bucket.available())
+
+	// Tokens may become negative if more jobs were fetched than there were
+	// tokens available, but available never is.
+	bucket.tokens = -1.5
+	require.Equal(t, 0, bucket.available())
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		kindLimit  = &RateLimit{Kind: "kind1", Limit: 2, Period: time.Hour}
+		queueLimit = &RateLimit{Limit: 5, Period: time.Hour}
+		now        = time.Now()
+	)
+
+	limiter := newRateLimiter(QueueDefault, []*RateLimit{kindLimit, queueLimit})
+
+	// Local limits don't use the executor.
+	buckets, err := limiter.loadBuckets(ctx, nil, now)
+	require.NoError(t, err)
+
+	count, kindMax := limiter.allowances(buckets, 100)
+	require.Equal(t, 5, count)
+	require.Equal(t, map[string]int{"kind1": 2}, kindMax)
+
+	count, _ = limiter.allowances(buckets, 3)
+	require.Equal(t, 3, count)
+
+	require.NoError(t, limiter.consumeAndSaveBuckets(ctx, nil, buckets, []*rivertype.JobRow{
+		{Kind: "kind1"},
+		{Kind: "kind1"},
+		{Kind: "kind2"},
+	}))
+
+	// Local buckets are kept between fetches.
+	buckets, err = limiter.loadBuckets(ctx, nil, now.Add(time.Second))
+	require.NoError(t, err)
+
+	count, kindMax = limiter.allowances(buckets, 100)
+	require.Equal(t, 2, count)
+	require.Equal(t, map[string]int{"kind1": 0}, kindMax)
+}
+
+func TestRateLimiterGlobalKey(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(QueueDefault, nil)
+	require.Equal(t, "queue=default", limiter.globalKey(&RateLimit{}))
+	require.Equal(t, "queue=default&kind=kind1", limiter.globalKey(&RateLimit{Kind: "kind1"}))
+}
+
+func TestRateLimiterHasGlobal(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, newRateLimiter(QueueDefault, []*RateLimit{{Limit: 1, Period: time.Second}}).hasGlobal())
+	require.True(t, newRateLimiter(QueueDefault, []*RateLimit{{Limit: 1, Period: time.Second}, {Global: true, Kind: "kind1", Limit: 1, Period: time.Second}}).hasGlobal())
+}
@@ -59,6 +59,14 @@ type Driver[TTx any] interface {
 	//
 	// API is not stable. DO NOT USE.
 	UnwrapExecutor(tx TTx) ExecutorTx
+
+	// WithSchema returns a copy of the driver whose executors and listeners
+	// target River's tables in the given schema rather than those found on
+	// the connection's search_path, and whose notification topics are scoped
+	// to the schema. An empty schema reverts to search_path.
+	//
+	// API is not stable. DO NOT USE.
+	WithSchema(schema string) Driver[TTx]
 }
 
 // Executor provides River operations against a database. It may be a database
@@ -68,30 +76,91 @@ type Driver[TTx any] interface {
 type Executor interface {
 	// Begin begins a new subtransaction. ErrSubTxNotSupported may be returned
 	// if the executor is a transaction and the driver doesn't support
-	// subtransactions (like riverdriver/riverdatabasesql for database/sql).
+	// subtransactions.
 	Begin(ctx context.Context) (ExecutorTx, error)
 
 	// Exec executes raw SQL. Used for migrations.
 	Exec(ctx context.Context, sql string) (struct{}, error)
 
 	JobCancel(ctx context.Context, params *JobCancelParams) (*rivertype.JobRow, error)
+
+	// JobCancelMany cancels a batch of up to Max jobs matching a filter with
+	// IDs greater than AfterID, in ID order, following the same rules as
+	// JobCancel. JobDeleteMany and JobRetryMany work the same way. The
+	// returned LastID is used as AfterID to fetch the next batch.
+	JobCancelMany(ctx context.Context, params *JobCancelManyParams) (*JobManyResult, error)
+
+	// JobCountByQueueKindAndState counts jobs grouped by queue, kind, and
+	// state, optionally limited to the given queues. Only combinations with at
+	// least one job are returned.
+	JobCountByQueueKindAndState(ctx context.Context, params *JobCountByQueueKindAndStateParams) ([]*JobCountByQueueKindAndState, error)
+
+	// JobCountByState counts jobs in each state. States without any jobs
+	// aren't included.
+	JobCountByState(ctx context.Context) (map[rivertype.JobState]int, error)
+
+	// JobDelete deletes the job with the given ID, returning the deleted job.
+	// Returns rivertype.ErrJobRunning if the job is running, in which case
+	// it's not deleted.
+	JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error)
+
 	JobDeleteBefore(ctx context.Context, params *JobDeleteBeforeParams) (int, error)
+	JobDeleteMany(ctx context.Context, params *JobDeleteManyParams) (*JobManyResult, error)
+
+	// JobExpire discards jobs that weren't started before their expiration
+	// time, returning the number of jobs that were discarded.
+	JobExpire(ctx context.Context, params *JobExpireParams) (int, error)
+
 	JobGetAvailable(ctx context.Context, params *JobGetAvailableParams) ([]*rivertype.JobRow, error)
 	JobGetByID(ctx context.Context, id int64) (*rivertype.JobRow, error)
 	JobGetByIDMany(ctx context.Context, id []int64) ([]*rivertype.JobRow, error)
 	JobGetByKindAndUniqueProperties(ctx context.Context, params *JobGetByKindAndUniquePropertiesParams) (*rivertype.JobRow, error)
 	JobGetByKindMany(ctx context.Context, kind []string) ([]*rivertype.JobRow, error)
 	JobGetStuck(ctx context.Context, params *JobGetStuckParams) ([]*rivertype.JobRow, error)
+	JobHeartbeat(ctx context.Context, params *JobHeartbeatParams) (*rivertype.JobRow, error)
 	JobInsertFast(ctx context.Context, params *JobInsertFastParams) (*rivertype.JobRow, error)
 	JobInsertFastMany(ctx context.Context, params []*JobInsertFastParams) (int64, error)
 	JobInsertFull(ctx context.Context, params *JobInsertFullParams) (*rivertype.JobRow, error)
 	JobList(ctx context.Context, sql string, namedArgs map[string]any) ([]*rivertype.JobRow, error)
 	JobListFields() string
+
+	// JobPromotePending moves pending jobs whose dependencies have all
+	// completed to `available` (or `scheduled` if they're scheduled for the
+	// future). Pending jobs with a dependency that was cancelled or discarded
+	// are set to DependencyFailedState instead. Dependencies are either job
+	// IDs in `depends_on` or tasks of the same workflow named in the job's
+	// `workflow_deps` metadata.
+	//
+	// Only up to Max pending jobs with IDs greater than AfterID are checked.
+	// The result's LastID is the highest ID among them, to be used as AfterID
+	// for the next batch.
+	JobPromotePending(ctx context.Context, params *JobPromotePendingParams) (*JobPromotePendingResult, error)
+
+	// JobQueueStats returns the scheduled time of the oldest available job in
+	// each queue, along with the median and 95th percentile run durations of
+	// jobs completed since FinalizedAfter. Only queues that have available jobs
+	// or recently completed ones are returned.
+	JobQueueStats(ctx context.Context, params *JobQueueStatsParams) ([]*JobQueueStats, error)
+
 	JobRescueMany(ctx context.Context, params *JobRescueManyParams) (*struct{}, error)
 	JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	JobRetryMany(ctx context.Context, params *JobRetryManyParams) (*JobManyResult, error)
 	JobSchedule(ctx context.Context, params *JobScheduleParams) (int, error)
 	JobSetStateIfRunning(ctx context.Context, params *JobSetStateIfRunningParams) (*rivertype.JobRow, error)
+
+	// JobSetStateIfRunningMany sets the state of many jobs in a single query,
+	// as long as each is still running. Jobs are returned regardless of whether
+	// they were updated, but in no particular order.
+	JobSetStateIfRunningMany(ctx context.Context, params []*JobSetStateIfRunningParams) ([]*rivertype.JobRow, error)
+
 	JobUpdate(ctx context.Context, params *JobUpdateParams) (*rivertype.JobRow, error)
+
+	// JobUpdateIfNotRunning updates the properties of a job that's not
+	// running, moving it between available and scheduled (or from retryable to
+	// available) if its scheduled time changes. Returns rivertype.ErrJobRunning
+	// if the job is running, in which case it's not updated.
+	JobUpdateIfNotRunning(ctx context.Context, params *JobUpdateIfNotRunningParams) (*rivertype.JobRow, error)
+
 	LeaderAttemptElect(ctx context.Context, params *LeaderElectParams) (bool, error)
 	LeaderAttemptReelect(ctx context.Context, params *LeaderElectParams) (bool, error)
 	LeaderDeleteExpired(ctx context.Context, name string) (int, error)
@@ -111,8 +180,38 @@ type Executor interface {
 	Notify(ctx context.Context, topic string, payload string) error
 	PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}, error)
 
-	// TableExists checks whether a table exists for the schema in the current
-	// search schema.
+	// QueueCreateOrSetUpdatedAt inserts a queue if it doesn't already exist,
+	// or bumps its updated_at timestamp if it does. The queue is returned in
+	// either case so that its current settings can be read.
+	QueueCreateOrSetUpdatedAt(ctx context.Context, params *QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error)
+
+	QueueGet(ctx context.Context, name string) (*rivertype.Queue, error)
+	QueueList(ctx context.Context, limit int) ([]*rivertype.Queue, error)
+
+	// QueuePause pauses the given queue, creating it if it doesn't exist yet so
+	// that a queue can be paused before any client has started working it.
+	// Pausing a queue that's already paused leaves its paused_at timestamp
+	// unchanged.
+	QueuePause(ctx context.Context, name string) (*rivertype.Queue, error)
+
+	// QueueResume resumes the given queue. Returns ErrNotFound if the queue
+	// doesn't exist.
+	QueueResume(ctx context.Context, name string) (*rivertype.Queue, error)
+
+	// QueueUpdateMetadata removes the given keys from a queue's metadata, then
+	// merges in the given updates. Returns ErrNotFound if the queue doesn't
+	// exist.
+	QueueUpdateMetadata(ctx context.Context, params *QueueUpdateMetadataParams) (*rivertype.Queue, error)
+
+	// RateLimitGetMany gets the rate limit buckets with the given keys.
+	// Buckets that don't exist yet are omitted from the result.
+	RateLimitGetMany(ctx context.Context, keys []string) ([]*RateLimitBucket, error)
+
+	// RateLimitSetMany creates or updates many rate limit buckets at once.
+	RateLimitSetMany(ctx context.Context, params *RateLimitSetManyParams) error
+
+	// TableExists checks whether a table exists in the driver's schema, or in
+	// the current search schema if the driver has none.
 	TableExists(ctx context.Context, tableName string) (bool, error)
 }
 
@@ -158,6 +257,25 @@ type JobCancelParams struct {
 	JobControlTopic   string
 }
 
+type JobCancelManyParams struct {
+	AfterID           int64
+	CancelAttemptedAt time.Time
+	Filter            *JobFilter
+	JobControlTopic   string
+	Max               int
+}
+
+type JobCountByQueueKindAndStateParams struct {
+	Queues []string
+}
+
+type JobCountByQueueKindAndState struct {
+	Count int
+	Kind  string
+	Queue string
+	State rivertype.JobState
+}
+
 type JobDeleteBeforeParams struct {
 	CancelledFinalizedAtHorizon time.Time
 	CompletedFinalizedAtHorizon time.Time
@@ -165,10 +283,71 @@ type JobDeleteBeforeParams struct {
 	Max                         int
 }
 
+type JobDeleteManyParams struct {
+	AfterID int64
+	Filter  *JobFilter
+	Max     int
+}
+
+type JobExpireParams struct {
+	Max int
+	Now time.Time
+}
+
+// JobFilter selects jobs for bulk operations like JobCancelMany. Empty fields
+// don't filter.
+type JobFilter struct {
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	Kinds            []string
+	MetadataFragment []byte
+	Queues           []string
+	States           []rivertype.JobState
+}
+
 type JobGetAvailableParams struct {
 	AttemptedBy string
-	Max         int
-	Queue       string
+
+	// ConcurrencyLimits limit the number of jobs of particular kinds that may
+	// be running at once across all clients. Like GlobalMax, they're only
+	// enforced reliably if concurrent fetches for the queue are serialized.
+	ConcurrencyLimits []*JobGetAvailableConcurrencyLimit
+
+	// FairnessKey is a metadata key whose values partition the queue's jobs
+	// for fair scheduling. If set, jobs of the same priority are fetched
+	// round-robin across partitions rather than strictly in order of
+	// scheduled_at.
+	FairnessKey string
+
+	// GlobalMax is the maximum number of jobs in the queue that may be running
+	// at once across all clients, with no limit if zero. It's only enforced
+	// reliably if concurrent fetches for the queue are serialized, like by
+	// holding an advisory lock in the same transaction.
+	GlobalMax int
+
+	// KindMax is the maximum number of jobs of each kind to fetch, with kinds
+	// that aren't present not limited beyond Max.
+	KindMax map[string]int
+
+	Max int
+
+	// PriorityAgingInterval is the amount of time after which a job waiting to
+	// be worked has its effective priority improved by one, so that old jobs
+	// of a low priority aren't starved by a steady stream of higher priority
+	// ones. Zero disables priority aging.
+	PriorityAgingInterval time.Duration
+
+	Queue string
+}
+
+// JobGetAvailableConcurrencyLimit limits the number of running jobs of a kind.
+// If partition keys are given, the limit applies separately to each
+// combination of values found under those keys in a job's args and metadata.
+type JobGetAvailableConcurrencyLimit struct {
+	Kind                string   `json:"kind"`
+	Max                 int      `json:"max"`
+	PartitionByArgs     []string `json:"partition_by_args,omitempty"`
+	PartitionByMetadata []string `json:"partition_by_metadata,omitempty"`
 }
 
 type JobGetByKindAndUniquePropertiesParams struct {
@@ -189,8 +368,19 @@ type JobGetStuckParams struct {
 	StuckHorizon time.Time
 }
 
+type JobHeartbeatParams struct {
+	ID int64
+
+	// Progress is an optional JSON-encoded progress payload to store in the
+	// job's metadata along with the heartbeat. Progress is left unchanged if
+	// nil.
+	Progress []byte
+}
+
 type JobInsertFastParams struct {
+	DependsOn   []int64
 	EncodedArgs []byte
+	ExpiresAt   *time.Time
 	Kind        string
 	MaxAttempts int
 	Metadata    []byte
@@ -205,9 +395,12 @@ type JobInsertFullParams struct {
 	Attempt     int
 	AttemptedAt *time.Time
 	CreatedAt   *time.Time
+	DependsOn   []int64
 	EncodedArgs []byte
 	Errors      [][]byte
+	ExpiresAt   *time.Time
 	FinalizedAt *time.Time
+	HeartbeatAt *time.Time
 	Kind        string
 	MaxAttempts int
 	Metadata    []byte
@@ -218,6 +411,51 @@ type JobInsertFullParams struct {
 	Tags        []string
 }
 
+type JobPromotePendingParams struct {
+	AfterID               int64
+	DependencyFailedState rivertype.JobState
+	InsertTopic           string
+	Max                   int
+	Now                   time.Time
+}
+
+// JobManyResult is the result of a batch of a bulk operation like
+// JobCancelMany.
+type JobManyResult struct {
+	// LastID is the highest ID among jobs in the batch, or zero if there were
+	// none.
+	LastID int64
+
+	// NumJobs is the number of jobs in the batch.
+	NumJobs int
+}
+
+type JobPromotePendingResult struct {
+	LastID      int64
+	NumChecked  int
+	NumFailed   int
+	NumPromoted int
+}
+
+type JobQueueStatsParams struct {
+	FinalizedAfter time.Time
+	Queues         []string
+}
+
+type JobQueueStats struct {
+	// OldestAvailableScheduledAt is the scheduled time of the queue's oldest
+	// available job, or nil if it has none.
+	OldestAvailableScheduledAt *time.Time
+
+	Queue string
+
+	// RunDurationP50 and RunDurationP95 are the median and 95th percentile run
+	// durations of the queue's recently completed jobs, or zero if it has
+	// none.
+	RunDurationP50 time.Duration
+	RunDurationP95 time.Duration
+}
+
 type JobRescueManyParams struct {
 	ID          []int64
 	Error       [][]byte
@@ -226,6 +464,12 @@ type JobRescueManyParams struct {
 	State       []string
 }
 
+type JobRetryManyParams struct {
+	AfterID int64
+	Filter  *JobFilter
+	Max     int
+}
+
 type JobScheduleParams struct {
 	InsertTopic string
 	Max         int
@@ -236,20 +480,24 @@ type JobScheduleParams struct {
 // job. Use one of the constructors below to ensure a correct combination of
 // parameters.
 type JobSetStateIfRunningParams struct {
-	ID          int64
-	ErrData     []byte
-	FinalizedAt *time.Time
-	MaxAttempts *int
-	ScheduledAt *time.Time
-	State       rivertype.JobState
+	ID              int64
+	ErrData         []byte
+	FinalizedAt     *time.Time
+	MaxAttempts     *int
+	MetadataUpdates []byte
+	ScheduledAt     *time.Time
+	State           rivertype.JobState
 }
 
 func JobSetStateCancelled(id int64, finalizedAt time.Time, errData []byte) *JobSetStateIfRunningParams {
 	return &JobSetStateIfRunningParams{ID: id, ErrData: errData, FinalizedAt: &finalizedAt, State: rivertype.JobStateCancelled}
 }
 
-func JobSetStateCompleted(id int64, finalizedAt time.Time) *JobSetStateIfRunningParams {
-	return &JobSetStateIfRunningParams{ID: id, FinalizedAt: &finalizedAt, State: rivertype.JobStateCompleted}
+// JobSetStateCompleted produces parameters to complete a job. Metadata updates
+// are an optional JSON object that's merged into the job's metadata, and are
+// used to store its output.
+func JobSetStateCompleted(id int64, finalizedAt time.Time, metadataUpdates []byte) *JobSetStateIfRunningParams {
+	return &JobSetStateIfRunningParams{ID: id, FinalizedAt: &finalizedAt, MetadataUpdates: metadataUpdates, State: rivertype.JobStateCompleted}
 }
 
 func JobSetStateDiscarded(id int64, finalizedAt time.Time, errData []byte) *JobSetStateIfRunningParams {
@@ -286,6 +534,23 @@ type JobUpdateParams struct {
 	State               rivertype.JobState
 }
 
+type JobUpdateIfNotRunningParams struct {
+	ID                  int64
+	MaxAttemptsDoUpdate bool
+	MaxAttempts         int
+	MetadataDoMerge     bool
+	Metadata            []byte
+	Now                 time.Time
+	PriorityDoUpdate    bool
+	Priority            int
+	QueueDoUpdate       bool
+	Queue               string
+	ScheduledAtDoUpdate bool
+	ScheduledAt         time.Time
+	TagsDoUpdate        bool
+	Tags                []string
+}
+
 // Leader represents a River leader.
 //
 // API is not stable. DO NOT USE.
@@ -316,6 +581,35 @@ type LeaderResignParams struct {
 	Name            string
 }
 
+type QueueCreateOrSetUpdatedAtParams struct {
+	Metadata  []byte
+	Name      string
+	PausedAt  *time.Time
+	UpdatedAt *time.Time
+}
+
+type QueueUpdateMetadataParams struct {
+	MetadataDeleteKeys []string
+	MetadataUpdates    []byte
+	Name               string
+}
+
+// RateLimitBucket is the persisted state of a token bucket used to enforce a
+// rate limit across all clients.
+//
+// API is not stable. DO NOT USE.
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+type RateLimitSetManyParams struct {
+	Key       []string
+	Tokens    []float64
+	UpdatedAt []time.Time
+}
+
 // Migration represents a River migration.
 //
 // API is not stable. DO NOT USE.
@@ -1,6 +1,10 @@
 package dbsqlc
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type AttemptError struct {
 	At      time.Time `json:"at"`
@@ -8,3 +12,16 @@ type AttemptError struct {
 	Error   string    `json:"error"`
 	Trace   string    `json:"trace"`
 }
+
+// Scan implements sql.Scanner so that attempt errors can be scanned out of a
+// jsonb array with pq.Array.
+func (e *AttemptError) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, e)
+	case string:
+		return json.Unmarshal([]byte(src), e)
+	}
+
+	return fmt.Errorf("unsupported scan type for AttemptError: %T", src)
+}
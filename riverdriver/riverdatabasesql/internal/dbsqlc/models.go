@@ -6,7 +6,6 @@ package dbsqlc
 
 import (
 	"database/sql/driver"
-	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -18,6 +17,7 @@ const (
 	RiverJobStateCancelled JobState = "cancelled"
 	RiverJobStateCompleted JobState = "completed"
 	RiverJobStateDiscarded JobState = "discarded"
+	RiverJobStatePending   JobState = "pending"
 	RiverJobStateRetryable JobState = "retryable"
 	RiverJobStateRunning   JobState = "running"
 	RiverJobStateScheduled JobState = "scheduled"
@@ -69,12 +69,15 @@ type RiverJob struct {
 	FinalizedAt *time.Time
 	Kind        string
 	MaxAttempts int16
-	Metadata    json.RawMessage
+	Metadata    string
 	Priority    int16
 	Queue       string
 	State       JobState
 	ScheduledAt time.Time
 	Tags        []string
+	DependsOn   []int64
+	ExpiresAt   *time.Time
+	HeartbeatAt *time.Time
 }
 
 type RiverLeader struct {
@@ -89,3 +92,17 @@ type RiverMigration struct {
 	CreatedAt time.Time
 	Version   int64
 }
+
+type RiverQueue struct {
+	Name      string
+	CreatedAt time.Time
+	Metadata  string
+	PausedAt  *time.Time
+	UpdatedAt time.Time
+}
+
+type RiverRateLimit struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
@@ -7,7 +7,6 @@ package dbsqlc
 
 import (
 	"context"
-	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -17,7 +16,7 @@ const jobCancel = `-- name: JobCancel :one
 WITH locked_job AS (
     SELECT
         id, queue, state, finalized_at
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE river_job.id = $1
     FOR UPDATE
 ),
@@ -32,32 +31,32 @@ notification AS (
         AND finalized_at IS NULL
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
         -- If the job is actively running, we want to let its current client and
         -- producer handle the cancellation. Otherwise, immediately cancel it.
-        state = CASE WHEN state = 'running'::river_job_state THEN state ELSE 'cancelled'::river_job_state END,
-        finalized_at = CASE WHEN state = 'running'::river_job_state THEN finalized_at ELSE now() END,
+        state = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN state ELSE 'cancelled'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN finalized_at ELSE now() END,
         -- Mark the job as cancelled by query so that the rescuer knows not to
         -- rescue it, even if it gets stuck in the running state:
         metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], $3::jsonb, true)
     FROM notification
     WHERE river_job.id = notification.id
-    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
 )
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE id = $1::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 FROM updated_job
 `
 
 type JobCancelParams struct {
 	ID                int64
 	JobControlTopic   string
-	CancelAttemptedAt json.RawMessage
+	CancelAttemptedAt string
 }
 
 func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams) (*RiverJob, error) {
@@ -80,16 +79,241 @@ func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams)
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
+	)
+	return &i, err
+}
+
+const jobCancelMany = `-- name: JobCancelMany :one
+WITH locked_jobs AS (
+    SELECT
+        id, queue
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        id > $1::bigint
+        AND ($2::timestamptz IS NULL OR created_at >= $2::timestamptz)
+        AND ($3::timestamptz IS NULL OR created_at < $3::timestamptz)
+        AND (cardinality($4::text[]) = 0 OR kind = any($4::text[]))
+        AND ($5::jsonb IS NULL OR metadata @> $5::jsonb)
+        AND (cardinality($6::text[]) = 0 OR queue = any($6::text[]))
+        AND (cardinality($7::text[]) = 0 OR state::text = any($7::text[]))
+        AND state NOT IN ('cancelled', 'completed', 'discarded')
+        AND finalized_at IS NULL
+    ORDER BY id
+    LIMIT $8::bigint
+    FOR UPDATE
+),
+notification AS (
+    SELECT
+        id,
+        pg_notify($9, json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text)
+    FROM
+        locked_jobs
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        -- If the job is actively running, we want to let its current client and
+        -- producer handle the cancellation. Otherwise, immediately cancel it.
+        state = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN state ELSE 'cancelled'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN finalized_at ELSE now() END,
+        -- Mark the job as cancelled by query so that the rescuer knows not to
+        -- rescue it, even if it gets stuck in the running state:
+        metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], $10::jsonb, true)
+    FROM notification
+    WHERE river_job.id = notification.id
+    RETURNING river_job.id
+)
+SELECT
+    coalesce((SELECT max(id) FROM updated_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM updated_jobs) AS num_cancelled
+`
+
+type JobCancelManyParams struct {
+	AfterID           int64
+	CreatedAfter      *time.Time
+	CreatedBefore     *time.Time
+	Kinds             []string
+	MetadataFragment  *string
+	Queues            []string
+	States            []string
+	Max               int64
+	JobControlTopic   string
+	CancelAttemptedAt string
+}
+
+type JobCancelManyRow struct {
+	LastID       int64
+	NumCancelled int64
+}
+
+// Cancels a batch of jobs matching a filter with the same rules as JobCancel,
+// selecting jobs with IDs greater than after_id in ID order so that a caller
+// can page through all matching jobs. Returns the highest ID cancelled, to be
+// used as after_id for the next batch.
+func (q *Queries) JobCancelMany(ctx context.Context, db DBTX, arg *JobCancelManyParams) (*JobCancelManyRow, error) {
+	row := db.QueryRowContext(ctx, jobCancelMany,
+		arg.AfterID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		pq.Array(arg.Kinds),
+		arg.MetadataFragment,
+		pq.Array(arg.Queues),
+		pq.Array(arg.States),
+		arg.Max,
+		arg.JobControlTopic,
+		arg.CancelAttemptedAt,
+	)
+	var i JobCancelManyRow
+	err := row.Scan(&i.LastID, &i.NumCancelled)
+	return &i, err
+}
+
+const jobCountByQueueKindAndState = `-- name: JobCountByQueueKindAndState :many
+SELECT
+    queue,
+    kind,
+    state,
+    count(*) AS count
+FROM /* TEMPLATE: schema */river_job
+WHERE cardinality($1::text[]) = 0 OR queue = any($1::text[])
+GROUP BY queue, kind, state
+`
+
+type JobCountByQueueKindAndStateRow struct {
+	Queue string
+	Kind  string
+	State JobState
+	Count int64
+}
+
+func (q *Queries) JobCountByQueueKindAndState(ctx context.Context, db DBTX, queues []string) ([]*JobCountByQueueKindAndStateRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountByQueueKindAndState, pq.Array(queues))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountByQueueKindAndStateRow
+	for rows.Next() {
+		var i JobCountByQueueKindAndStateRow
+		if err := rows.Scan(
+			&i.Queue,
+			&i.Kind,
+			&i.State,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobCountByState = `-- name: JobCountByState :many
+SELECT
+    state,
+    count(*) AS count
+FROM /* TEMPLATE: schema */river_job
+GROUP BY state
+`
+
+type JobCountByStateRow struct {
+	State JobState
+	Count int64
+}
+
+func (q *Queries) JobCountByState(ctx context.Context, db DBTX) ([]*JobCountByStateRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountByState)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountByStateRow
+	for rows.Next() {
+		var i JobCountByStateRow
+		if err := rows.Scan(
+			&i.State,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobDelete = `-- name: JobDelete :one
+WITH job_to_delete AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = $1
+    FOR UPDATE
+),
+deleted_job AS (
+    DELETE
+    FROM /* TEMPLATE: schema */river_job
+    USING job_to_delete
+    WHERE river_job.id = job_to_delete.id
+        -- Do not touch running jobs:
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
+WHERE id = $1::bigint
+    AND id NOT IN (SELECT id FROM deleted_job)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM deleted_job
+`
+
+func (q *Queries) JobDelete(ctx context.Context, db DBTX, id int64) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobDelete, id)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
 const jobDeleteBefore = `-- name: JobDeleteBefore :one
 WITH deleted_jobs AS (
-    DELETE FROM river_job
+    DELETE FROM /* TEMPLATE: schema */river_job
     WHERE id IN (
         SELECT id
-        FROM river_job
+        FROM /* TEMPLATE: schema */river_job
         WHERE
             (state = 'cancelled' AND finalized_at < $1::timestamptz) OR
             (state = 'completed' AND finalized_at < $2::timestamptz) OR
@@ -97,7 +321,7 @@ WITH deleted_jobs AS (
         ORDER BY id
         LIMIT $4::bigint
     )
-    RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+    RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 )
 SELECT count(*)
 FROM deleted_jobs
@@ -122,28 +346,156 @@ func (q *Queries) JobDeleteBefore(ctx context.Context, db DBTX, arg *JobDeleteBe
 	return count, err
 }
 
+const jobDeleteMany = `-- name: JobDeleteMany :one
+WITH deleted_jobs AS (
+    DELETE FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (
+        SELECT id
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            id > $1::bigint
+            AND ($2::timestamptz IS NULL OR created_at >= $2::timestamptz)
+            AND ($3::timestamptz IS NULL OR created_at < $3::timestamptz)
+            AND (cardinality($4::text[]) = 0 OR kind = any($4::text[]))
+            AND ($5::jsonb IS NULL OR metadata @> $5::jsonb)
+            AND (cardinality($6::text[]) = 0 OR queue = any($6::text[]))
+            AND (cardinality($7::text[]) = 0 OR state::text = any($7::text[]))
+            AND state != 'running'::/* TEMPLATE: schema */river_job_state
+        ORDER BY id
+        LIMIT $8::bigint
+        FOR UPDATE
+    )
+    RETURNING id
+)
+SELECT
+    coalesce((SELECT max(id) FROM deleted_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM deleted_jobs) AS num_deleted
+`
+
+type JobDeleteManyParams struct {
+	AfterID          int64
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	Kinds            []string
+	MetadataFragment *string
+	Queues           []string
+	States           []string
+	Max              int64
+}
+
+type JobDeleteManyRow struct {
+	LastID     int64
+	NumDeleted int64
+}
+
+// Deletes a batch of jobs matching a filter, skipping any that are running,
+// selecting jobs with IDs greater than after_id in ID order so that a caller
+// can page through all matching jobs. Returns the highest ID deleted, to be
+// used as after_id for the next batch.
+func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, arg *JobDeleteManyParams) (*JobDeleteManyRow, error) {
+	row := db.QueryRowContext(ctx, jobDeleteMany,
+		arg.AfterID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		pq.Array(arg.Kinds),
+		arg.MetadataFragment,
+		pq.Array(arg.Queues),
+		pq.Array(arg.States),
+		arg.Max,
+	)
+	var i JobDeleteManyRow
+	err := row.Scan(&i.LastID, &i.NumDeleted)
+	return &i, err
+}
+
+const jobExpire = `-- name: JobExpire :one
+WITH job_to_expire AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        state IN ('available', 'pending', 'retryable', 'scheduled')
+        AND expires_at <= $1::timestamptz
+    ORDER BY id
+    LIMIT $2::bigint
+    FOR UPDATE
+    SKIP LOCKED
+),
+expired_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = 'discarded'::/* TEMPLATE: schema */river_job_state,
+        finalized_at = $1::timestamptz,
+        errors = array_append(
+            river_job.errors,
+            jsonb_build_object(
+                'at', $1::timestamptz,
+                'attempt', greatest(river_job.attempt, 0),
+                'error', 'Job expired before it was started',
+                'trace', ''
+            )
+        )
+    FROM job_to_expire
+    WHERE river_job.id = job_to_expire.id
+    RETURNING river_job.id
+)
+SELECT count(*)
+FROM expired_job
+`
+
+type JobExpireParams struct {
+	Now time.Time
+	Max int64
+}
+
+// Discards jobs that haven't been started before the expiration time stored in
+// their metadata, recording an error with the reason.
+func (q *Queries) JobExpire(ctx context.Context, db DBTX, arg *JobExpireParams) (int64, error) {
+	row := db.QueryRowContext(ctx, jobExpire, arg.Now, arg.Max)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const jobGetAvailable = `-- name: JobGetAvailable :many
 WITH locked_jobs AS (
     SELECT
-        id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+        id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
     FROM
-        river_job
+        /* TEMPLATE: schema */river_job
     WHERE
-        state = 'available'::river_job_state
+        state = 'available'::/* TEMPLATE: schema */river_job_state
         AND queue = $2::text
         AND scheduled_at <= now()
+        AND (expires_at IS NULL OR expires_at > now())
     ORDER BY
         priority ASC,
         scheduled_at ASC,
         id ASC
-    LIMIT $3::integer
+    -- With a global max, fetch no more than the number of jobs that can be
+    -- added to those already running in the queue across all clients.
+    LIMIT CASE
+        WHEN $3::integer > 0 THEN
+            least(
+                $4::integer,
+                greatest(
+                    $3::integer - (
+                        SELECT count(*)
+                        FROM /* TEMPLATE: schema */river_job
+                        WHERE queue = $2::text
+                            AND state = 'running'::/* TEMPLATE: schema */river_job_state
+                    ),
+                    0
+                )
+            )
+        ELSE $4::integer
+    END
     FOR UPDATE
     SKIP LOCKED
 )
 UPDATE
-    river_job
+    /* TEMPLATE: schema */river_job
 SET
-    state = 'running'::river_job_state,
+    state = 'running'::/* TEMPLATE: schema */river_job_state,
     attempt = river_job.attempt + 1,
     attempted_at = now(),
     attempted_by = array_append(river_job.attempted_by, $1::text)
@@ -152,17 +504,18 @@ FROM
 WHERE
     river_job.id = locked_jobs.id
 RETURNING
-    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags
+    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
 `
 
 type JobGetAvailableParams struct {
 	AttemptedBy string
 	Queue       string
+	GlobalMax   int32
 	Max         int32
 }
 
 func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvailableParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetAvailable, arg.AttemptedBy, arg.Queue, arg.Max)
+	rows, err := db.QueryContext(ctx, jobGetAvailable, arg.AttemptedBy, arg.Queue, arg.GlobalMax, arg.Max)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +540,306 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 			&i.State,
 			&i.ScheduledAt,
 			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailableLimited = `-- name: JobGetAvailableLimited :many
+WITH concurrency_limit AS (
+    SELECT
+        kind,
+        max,
+        partition_by_args,
+        partition_by_metadata
+    FROM jsonb_to_recordset($2::jsonb)
+        AS concurrency_limit(kind text, max integer, partition_by_args jsonb, partition_by_metadata jsonb)
+),
+job_with_partition AS (
+    SELECT
+        river_job.id,
+        river_job.kind,
+        river_job.metadata ->> $3::text AS fairness_partition,
+        -- With priority aging, a job's effective priority improves by one for
+        -- every interval it's been waiting since it was scheduled, to a best
+        -- of 1.
+        CASE
+            WHEN $4::double precision > 0 THEN
+                greatest(
+                    river_job.priority - floor(extract(epoch FROM now() - river_job.scheduled_at) / $4::double precision)::integer,
+                    1
+                )
+            ELSE river_job.priority
+        END AS priority,
+        river_job.scheduled_at,
+        river_job.state,
+        concurrency_limit.kind AS limit_kind,
+        concurrency_limit.max AS limit_max,
+        jsonb_build_array(
+            (
+                SELECT coalesce(jsonb_agg(river_job.args -> arg_key.key ORDER BY arg_key.ordinality), '[]'::jsonb)
+                FROM jsonb_array_elements_text(concurrency_limit.partition_by_args) WITH ORDINALITY AS arg_key(key, ordinality)
+            ),
+            (
+                SELECT coalesce(jsonb_agg(river_job.metadata -> metadata_key.key ORDER BY metadata_key.ordinality), '[]'::jsonb)
+                FROM jsonb_array_elements_text(concurrency_limit.partition_by_metadata) WITH ORDINALITY AS metadata_key(key, ordinality)
+            )
+        ) AS partition_key
+    FROM
+        /* TEMPLATE: schema */river_job
+        LEFT JOIN concurrency_limit ON concurrency_limit.kind = river_job.kind
+    WHERE
+        river_job.queue = $5::text
+        AND (
+            river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
+            OR (
+                river_job.state = 'available'::/* TEMPLATE: schema */river_job_state
+                AND river_job.scheduled_at <= now()
+                AND (river_job.expires_at IS NULL OR river_job.expires_at > now())
+            )
+        )
+),
+running_count AS (
+    SELECT
+        limit_kind,
+        partition_key,
+        count(*) AS count
+    FROM job_with_partition
+    WHERE
+        state = 'running'::/* TEMPLATE: schema */river_job_state
+        AND limit_kind IS NOT NULL
+    GROUP BY limit_kind, partition_key
+),
+fairness_running_count AS (
+    SELECT
+        fairness_partition,
+        count(*) AS count
+    FROM job_with_partition
+    WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+    GROUP BY fairness_partition
+),
+-- Of the available jobs sharing a kind, concurrency partition, and fairness
+-- partition, those ranked after the maximum number of jobs to fetch can never
+-- be fetched because every job ahead of them would be fetched first, and those
+-- ranked after the partition's concurrency limit can't be fetched either.
+-- Dropping them here bounds the number of jobs ranked below to a handful per
+-- partition instead of the whole backlog.
+candidate_jobs AS (
+    SELECT
+        id,
+        fairness_partition,
+        kind,
+        limit_kind,
+        limit_max,
+        partition_key,
+        priority,
+        scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            limit_kind,
+            limit_max,
+            partition_key,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind, partition_key, fairness_partition
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS candidate_rank
+        FROM job_with_partition
+        WHERE state = 'available'::/* TEMPLATE: schema */river_job_state
+    ) AS candidate_ranked_jobs
+    WHERE
+        candidate_rank <= $8::integer
+        AND (limit_max IS NULL OR candidate_rank <= limit_max)
+),
+concurrency_eligible_jobs AS (
+    SELECT
+        ranked_jobs.id,
+        ranked_jobs.fairness_partition,
+        ranked_jobs.kind,
+        ranked_jobs.priority,
+        ranked_jobs.scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            limit_kind,
+            limit_max,
+            partition_key,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY limit_kind, partition_key
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS partition_rank
+        FROM candidate_jobs
+    ) AS ranked_jobs
+        LEFT JOIN running_count
+            ON running_count.limit_kind = ranked_jobs.limit_kind
+            AND running_count.partition_key = ranked_jobs.partition_key
+    WHERE
+        ranked_jobs.limit_kind IS NULL
+        OR ranked_jobs.partition_rank + coalesce(running_count.count, 0) <= ranked_jobs.limit_max
+),
+kind_eligible_jobs AS (
+    SELECT
+        kind_ranked_jobs.id,
+        kind_ranked_jobs.fairness_partition,
+        kind_ranked_jobs.priority,
+        kind_ranked_jobs.scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank
+        FROM concurrency_eligible_jobs
+    ) AS kind_ranked_jobs
+    WHERE
+        ($6::jsonb ->> kind_ranked_jobs.kind) IS NULL
+        OR kind_ranked_jobs.kind_rank <= ($6::jsonb ->> kind_ranked_jobs.kind)::integer
+),
+-- Jobs are ranked within their fairness partition after those of the partition
+-- that are already running, so that partitions with fewer running jobs go
+-- first. Without a fairness key, all jobs share a single partition and this is
+-- equivalent to ordering by scheduled_at and id.
+eligible_jobs AS (
+    SELECT
+        fairness_ranked_jobs.id,
+        fairness_ranked_jobs.priority,
+        fairness_ranked_jobs.scheduled_at,
+        fairness_ranked_jobs.fairness_rank + coalesce(fairness_running_count.count, 0) AS fairness_rank
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY fairness_partition
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS fairness_rank
+        FROM kind_eligible_jobs
+    ) AS fairness_ranked_jobs
+        LEFT JOIN fairness_running_count
+            ON fairness_running_count.fairness_partition IS NOT DISTINCT FROM fairness_ranked_jobs.fairness_partition
+),
+locked_jobs AS (
+    SELECT
+        river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN eligible_jobs ON eligible_jobs.id = river_job.id
+    ORDER BY
+        eligible_jobs.priority ASC,
+        eligible_jobs.fairness_rank ASC,
+        eligible_jobs.scheduled_at ASC,
+        eligible_jobs.id ASC
+    LIMIT CASE
+        WHEN $7::integer > 0 THEN
+            least(
+                $8::integer,
+                greatest(
+                    $7::integer - (
+                        SELECT count(*)
+                        FROM job_with_partition
+                        WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+                    ),
+                    0
+                )
+            )
+        ELSE $8::integer
+    END
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running'::/* TEMPLATE: schema */river_job_state,
+    attempt = river_job.attempt + 1,
+    attempted_at = now(),
+    attempted_by = array_append(river_job.attempted_by, $1::text)
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
+`
+
+type JobGetAvailableLimitedParams struct {
+	AttemptedBy                  string
+	ConcurrencyLimits            string
+	FairnessKey                  string
+	PriorityAgingIntervalSeconds float64
+	Queue                        string
+	KindMax                      string
+	GlobalMax                    int32
+	Max                          int32
+}
+
+// A variant of JobGetAvailable that also respects concurrency limits on job
+// kinds, optionally partitioned by values in each job's args or metadata, and
+// maximums on the number of jobs of each kind to fetch. Available jobs are
+// ranked within their kind and partition, and only those that fit alongside
+// the partition's running jobs are eligible to be fetched. With a fairness
+// key, jobs of the same priority are fetched round-robin across the values of
+// that key in their metadata, taking into account jobs that are already
+// running. With priority aging, jobs are ordered by an effective priority that
+// improves the longer they've been waiting. Unlike JobGetAvailable, this
+// considers every available job in the queue, but only the first few of each
+// partition are carried through to the more expensive ranking that follows.
+func (q *Queries) JobGetAvailableLimited(ctx context.Context, db DBTX, arg *JobGetAvailableLimitedParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableLimited, arg.AttemptedBy, arg.ConcurrencyLimits, arg.FairnessKey, arg.PriorityAgingIntervalSeconds, arg.Queue, arg.KindMax, arg.GlobalMax, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -202,8 +855,8 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 }
 
 const jobGetByID = `-- name: JobGetByID :one
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE id = $1
 LIMIT 1
 `
@@ -228,13 +881,16 @@ func (q *Queries) JobGetByID(ctx context.Context, db DBTX, id int64) (*RiverJob,
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
 const jobGetByIDMany = `-- name: JobGetByIDMany :many
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE id = any($1::bigint[])
 ORDER BY id
 `
@@ -265,6 +921,9 @@ func (q *Queries) JobGetByIDMany(ctx context.Context, db DBTX, id []int64) ([]*R
 			&i.State,
 			&i.ScheduledAt,
 			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -280,10 +939,10 @@ func (q *Queries) JobGetByIDMany(ctx context.Context, db DBTX, id []int64) ([]*R
 }
 
 const jobGetByKindAndUniqueProperties = `-- name: JobGetByKindAndUniqueProperties :one
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE kind = $1
-    AND CASE WHEN $2::boolean THEN args = $3 ELSE true END
+    AND CASE WHEN $2::boolean THEN args = $3::jsonb ELSE true END
     AND CASE WHEN $4::boolean THEN tstzrange($5::timestamptz, $6::timestamptz, '[)') @> created_at ELSE true END
     AND CASE WHEN $7::boolean THEN queue = $8 ELSE true END
     AND CASE WHEN $9::boolean THEN state::text = any($10::text[]) ELSE true END
@@ -292,7 +951,7 @@ WHERE kind = $1
 type JobGetByKindAndUniquePropertiesParams struct {
 	Kind           string
 	ByArgs         bool
-	Args           []byte
+	Args           *string
 	ByCreatedAt    bool
 	CreatedAtBegin time.Time
 	CreatedAtEnd   time.Time
@@ -333,13 +992,16 @@ func (q *Queries) JobGetByKindAndUniqueProperties(ctx context.Context, db DBTX,
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
 const jobGetByKindMany = `-- name: JobGetByKindMany :many
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE kind = any($1::text[])
 ORDER BY id
 `
@@ -370,6 +1032,9 @@ func (q *Queries) JobGetByKindMany(ctx context.Context, db DBTX, kind []string)
 			&i.State,
 			&i.ScheduledAt,
 			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -385,10 +1050,10 @@ func (q *Queries) JobGetByKindMany(ctx context.Context, db DBTX, kind []string)
 }
 
 const jobGetStuck = `-- name: JobGetStuck :many
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
-WHERE state = 'running'::river_job_state
-    AND attempted_at < $1::timestamptz
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+    AND greatest(heartbeat_at, attempted_at) < $1::timestamptz
 ORDER BY id
 LIMIT $2
 `
@@ -424,6 +1089,9 @@ func (q *Queries) JobGetStuck(ctx context.Context, db DBTX, arg *JobGetStuckPara
 			&i.State,
 			&i.ScheduledAt,
 			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -438,9 +1106,56 @@ func (q *Queries) JobGetStuck(ctx context.Context, db DBTX, arg *JobGetStuckPara
 	return items, nil
 }
 
+const jobHeartbeat = `-- name: JobHeartbeat :one
+UPDATE /* TEMPLATE: schema */river_job
+SET heartbeat_at = now(),
+    metadata = CASE WHEN $1::jsonb IS NULL THEN metadata
+                    ELSE metadata || jsonb_build_object('river:progress', $1::jsonb)
+               END
+WHERE id = $2::bigint
+    AND state = 'running'::/* TEMPLATE: schema */river_job_state
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+`
+
+type JobHeartbeatParams struct {
+	Progress *string
+	ID       int64
+}
+
+// Records a heartbeat for a running job, along with its progress in its
+// metadata if one is given. Jobs that aren't running aren't updated.
+func (q *Queries) JobHeartbeat(ctx context.Context, db DBTX, arg *JobHeartbeatParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobHeartbeat, arg.Progress, arg.ID)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
+	)
+	return &i, err
+}
+
 const jobInsertFast = `-- name: JobInsertFast :one
-INSERT INTO river_job(
+INSERT INTO /* TEMPLATE: schema */river_job(
     args,
+    depends_on,
+    expires_at,
     finalized_at,
     kind,
     max_attempts,
@@ -452,24 +1167,28 @@ INSERT INTO river_job(
     tags
 ) VALUES (
     $1::jsonb,
-    $2,
-    $3::text,
-    $4::smallint,
-    coalesce($5::jsonb, '{}'),
+    $2::bigint[],
+    $3,
+    $4,
+    $5::text,
     $6::smallint,
-    $7::text,
-    coalesce($8::timestamptz, now()),
-    $9::river_job_state,
-    coalesce($10::varchar(255)[], '{}')
-) RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+    coalesce($7::jsonb, '{}'),
+    $8::smallint,
+    $9::text,
+    coalesce($10::timestamptz, now()),
+    $11::/* TEMPLATE: schema */river_job_state,
+    coalesce($12::varchar(255)[], '{}')
+) RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 `
 
 type JobInsertFastParams struct {
-	Args        json.RawMessage
+	Args        string
+	DependsOn   []int64
+	ExpiresAt   *time.Time
 	FinalizedAt *time.Time
 	Kind        string
 	MaxAttempts int16
-	Metadata    json.RawMessage
+	Metadata    *string
 	Priority    int16
 	Queue       string
 	ScheduledAt *time.Time
@@ -480,6 +1199,8 @@ type JobInsertFastParams struct {
 func (q *Queries) JobInsertFast(ctx context.Context, db DBTX, arg *JobInsertFastParams) (*RiverJob, error) {
 	row := db.QueryRowContext(ctx, jobInsertFast,
 		arg.Args,
+		pq.Array(arg.DependsOn),
+		arg.ExpiresAt,
 		arg.FinalizedAt,
 		arg.Kind,
 		arg.MaxAttempts,
@@ -508,18 +1229,106 @@ func (q *Queries) JobInsertFast(ctx context.Context, db DBTX, arg *JobInsertFast
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
+const jobInsertFastMany = `-- name: JobInsertFastMany :execrows
+INSERT INTO /* TEMPLATE: schema */river_job(
+    args,
+    depends_on,
+    expires_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state,
+    tags
+) SELECT
+    args,
+
+    -- Unnest on a multi-dimensional array "fully flattens" the array, so
+    -- dependencies and tags are each encoded as a JSON array and converted
+    -- back to a Postgres array here. No dependencies are stored as NULL.
+    nullif(array(SELECT jsonb_array_elements_text(depends_on)::bigint), '{}'),
+
+    -- Expiration times are sent as text so that jobs without one can be sent
+    -- as an empty string, which is stored as NULL.
+    nullif(expires_at, '')::timestamptz,
+
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state::/* TEMPLATE: schema */river_job_state,
+    array(SELECT jsonb_array_elements_text(tags))
+FROM unnest(
+    $1::jsonb[],
+    $2::jsonb[],
+    $3::text[],
+    $4::text[],
+    $5::smallint[],
+    $6::jsonb[],
+    $7::smallint[],
+    $8::text[],
+    $9::timestamptz[],
+    $10::text[],
+    $11::jsonb[]
+) AS job_params(args, depends_on, expires_at, kind, max_attempts, metadata, priority, queue, scheduled_at, state, tags)
+`
+
+type JobInsertFastManyParams struct {
+	Args        []string
+	DependsOn   []string
+	ExpiresAt   []string
+	Kind        []string
+	MaxAttempts []int16
+	Metadata    []string
+	Priority    []int16
+	Queue       []string
+	ScheduledAt []time.Time
+	State       []string
+	Tags        []string
+}
+
+func (q *Queries) JobInsertFastMany(ctx context.Context, db DBTX, arg *JobInsertFastManyParams) (int64, error) {
+	result, err := db.ExecContext(ctx, jobInsertFastMany,
+		pq.Array(arg.Args),
+		pq.Array(arg.DependsOn),
+		pq.Array(arg.ExpiresAt),
+		pq.Array(arg.Kind),
+		pq.Array(arg.MaxAttempts),
+		pq.Array(arg.Metadata),
+		pq.Array(arg.Priority),
+		pq.Array(arg.Queue),
+		pq.Array(arg.ScheduledAt),
+		pq.Array(arg.State),
+		pq.Array(arg.Tags),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const jobInsertFull = `-- name: JobInsertFull :one
-INSERT INTO river_job(
+INSERT INTO /* TEMPLATE: schema */river_job(
     args,
     attempt,
     attempted_at,
     created_at,
+    depends_on,
     errors,
+    expires_at,
     finalized_at,
+    heartbeat_at,
     kind,
     max_attempts,
     metadata,
@@ -533,29 +1342,35 @@ INSERT INTO river_job(
     coalesce($2::smallint, 0),
     $3,
     coalesce($4::timestamptz, now()),
-    $5::jsonb[],
-    $6,
-    $7::text,
-    $8::smallint,
-    coalesce($9::jsonb, '{}'),
-    $10::smallint,
-    $11::text,
-    coalesce($12::timestamptz, now()),
-    $13::river_job_state,
-    coalesce($14::varchar(255)[], '{}')
-) RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+    $5::bigint[],
+    $6::jsonb[],
+    $7,
+    $8,
+    $9,
+    $10::text,
+    $11::smallint,
+    coalesce($12::jsonb, '{}'),
+    $13::smallint,
+    $14::text,
+    coalesce($15::timestamptz, now()),
+    $16::/* TEMPLATE: schema */river_job_state,
+    coalesce($17::varchar(255)[], '{}')
+) RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 `
 
 type JobInsertFullParams struct {
-	Args        json.RawMessage
+	Args        string
 	Attempt     int16
 	AttemptedAt *time.Time
 	CreatedAt   *time.Time
-	Errors      []json.RawMessage
+	DependsOn   []int64
+	Errors      []string
+	ExpiresAt   *time.Time
 	FinalizedAt *time.Time
+	HeartbeatAt *time.Time
 	Kind        string
 	MaxAttempts int16
-	Metadata    json.RawMessage
+	Metadata    *string
 	Priority    int16
 	Queue       string
 	ScheduledAt *time.Time
@@ -569,8 +1384,11 @@ func (q *Queries) JobInsertFull(ctx context.Context, db DBTX, arg *JobInsertFull
 		arg.Attempt,
 		arg.AttemptedAt,
 		arg.CreatedAt,
+		pq.Array(arg.DependsOn),
 		pq.Array(arg.Errors),
+		arg.ExpiresAt,
 		arg.FinalizedAt,
+		arg.HeartbeatAt,
 		arg.Kind,
 		arg.MaxAttempts,
 		arg.Metadata,
@@ -598,12 +1416,209 @@ func (q *Queries) JobInsertFull(ctx context.Context, db DBTX, arg *JobInsertFull
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
+const jobPromotePending = `-- name: JobPromotePending :one
+WITH pending_job AS (
+    SELECT
+        id,
+        depends_on,
+        metadata
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND id > $1::bigint
+    ORDER BY id
+    LIMIT $2::bigint
+    FOR UPDATE
+    SKIP LOCKED
+),
+pending_job_dependency AS (
+    SELECT
+        pending_job.id,
+        dependency.state AS dependency_state
+    FROM pending_job
+    JOIN /* TEMPLATE: schema */river_job AS dependency
+        ON dependency.id = any(pending_job.depends_on)
+    UNION ALL
+    SELECT
+        pending_job.id,
+        dependency.state AS dependency_state
+    FROM pending_job
+    JOIN /* TEMPLATE: schema */river_job AS dependency
+        -- Containment rather than comparing extracted values so that the
+        -- lookup can use the GIN index on metadata.
+        ON dependency.metadata @> jsonb_build_object('workflow_id', pending_job.metadata->'workflow_id')
+        AND pending_job.metadata->'workflow_deps' ? (dependency.metadata->>'workflow_task')
+    WHERE pending_job.metadata ? 'workflow_deps'
+),
+job_to_promote AS (
+    SELECT
+        pending_job.id,
+        -- Dependencies are checked to exist when a job is inserted, so one
+        -- that doesn't was deleted since (e.g. a completed job removed by the
+        -- cleaner, or any job removed with JobDelete), and is considered
+        -- satisfied.
+        coalesce(bool_or(pending_job_dependency.dependency_state IN ('cancelled', 'discarded')), false) AS dependency_failed
+    FROM pending_job
+    LEFT JOIN pending_job_dependency
+        ON pending_job_dependency.id = pending_job.id
+    GROUP BY pending_job.id
+    HAVING coalesce(bool_or(pending_job_dependency.dependency_state IN ('cancelled', 'discarded')), false)
+        OR NOT coalesce(bool_or(pending_job_dependency.dependency_state NOT IN ('cancelled', 'completed', 'discarded')), false)
+),
+promoted_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN job_to_promote.dependency_failed THEN $3::/* TEMPLATE: schema */river_job_state
+                     WHEN river_job.scheduled_at > $4::timestamptz THEN 'scheduled'::/* TEMPLATE: schema */river_job_state
+                     ELSE 'available'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN job_to_promote.dependency_failed THEN $4::timestamptz
+                            ELSE river_job.finalized_at END
+    FROM job_to_promote
+    WHERE river_job.id = job_to_promote.id
+    RETURNING river_job.queue, job_to_promote.dependency_failed
+)
+SELECT
+    coalesce((SELECT max(id) FROM pending_job), 0)::bigint AS last_id,
+    (SELECT count(*) FROM pending_job) AS num_checked,
+    (
+        SELECT count(*)
+        FROM (
+            SELECT pg_notify($5, json_build_object('queue', queue)::text)
+            FROM promoted_job
+            WHERE NOT dependency_failed
+        ) AS notifications_sent
+    ) AS num_promoted,
+    (
+        SELECT count(*)
+        FROM promoted_job
+        WHERE dependency_failed
+    ) AS num_failed
+`
+
+type JobPromotePendingParams struct {
+	AfterID               int64
+	Max                   int64
+	DependencyFailedState JobState
+	Now                   time.Time
+	InsertTopic           string
+}
+
+type JobPromotePendingRow struct {
+	LastID      int64
+	NumChecked  int64
+	NumPromoted int64
+	NumFailed   int64
+}
+
+// Run by the pending job promoter to move jobs whose dependencies have all
+// completed out of `pending`. Jobs with a dependency that was cancelled or
+// discarded are instead finalized with the given state. Dependencies are
+// either job IDs in `depends_on` or, for jobs inserted as part of a workflow,
+// the names of other tasks in the same workflow in `workflow_deps` metadata.
+//
+// Only a batch of up to `max` pending jobs with IDs greater than `after_id` is
+// checked, so the promoter pages through pending jobs using the returned
+// `last_id` rather than having every call check all of them.
+func (q *Queries) JobPromotePending(ctx context.Context, db DBTX, arg *JobPromotePendingParams) (*JobPromotePendingRow, error) {
+	row := db.QueryRowContext(ctx, jobPromotePending,
+		arg.AfterID,
+		arg.Max,
+		arg.DependencyFailedState,
+		arg.Now,
+		arg.InsertTopic,
+	)
+	var i JobPromotePendingRow
+	err := row.Scan(
+		&i.LastID,
+		&i.NumChecked,
+		&i.NumPromoted,
+		&i.NumFailed,
+	)
+	return &i, err
+}
+
+const jobQueueStats = `-- name: JobQueueStats :many
+WITH oldest_available AS (
+    SELECT
+        queue,
+        min(scheduled_at) AS oldest_available_scheduled_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'::/* TEMPLATE: schema */river_job_state
+        AND (cardinality($1::text[]) = 0 OR queue = any($1::text[]))
+    GROUP BY queue
+),
+run_durations AS (
+    SELECT
+        queue,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY extract(epoch FROM finalized_at - attempted_at)) AS run_duration_p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY extract(epoch FROM finalized_at - attempted_at)) AS run_duration_p95
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'completed'::/* TEMPLATE: schema */river_job_state
+        AND finalized_at >= $2::timestamptz
+        AND attempted_at IS NOT NULL
+        AND (cardinality($1::text[]) = 0 OR queue = any($1::text[]))
+    GROUP BY queue
+)
+SELECT
+    coalesce(oldest_available.queue, run_durations.queue)::text AS queue,
+    oldest_available.oldest_available_scheduled_at::timestamptz AS oldest_available_scheduled_at,
+    coalesce(run_durations.run_duration_p50, 0)::float8 AS run_duration_p50,
+    coalesce(run_durations.run_duration_p95, 0)::float8 AS run_duration_p95
+FROM oldest_available
+FULL OUTER JOIN run_durations ON oldest_available.queue = run_durations.queue
+`
+
+type JobQueueStatsParams struct {
+	Queues         []string
+	FinalizedAfter time.Time
+}
+
+type JobQueueStatsRow struct {
+	Queue                      string
+	OldestAvailableScheduledAt *time.Time
+	RunDurationP50             float64
+	RunDurationP95             float64
+}
+
+// Oldest available job and run durations of recently completed jobs for each
+// queue. Both are narrowed to a state first so that they're served by the
+// prioritized fetching and finalized at indexes respectively.
+func (q *Queries) JobQueueStats(ctx context.Context, db DBTX, arg *JobQueueStatsParams) ([]*JobQueueStatsRow, error) {
+	rows, err := db.QueryContext(ctx, jobQueueStats, pq.Array(arg.Queues), arg.FinalizedAfter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobQueueStatsRow
+	for rows.Next() {
+		var i JobQueueStatsRow
+		if err := rows.Scan(
+			&i.Queue,
+			&i.OldestAvailableScheduledAt,
+			&i.RunDurationP50,
+			&i.RunDurationP95,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobRescueMany = `-- name: JobRescueMany :exec
-UPDATE river_job
+UPDATE /* TEMPLATE: schema */river_job
 SET
     errors = array_append(errors, updated_job.error),
     finalized_at = updated_job.finalized_at,
@@ -615,14 +1630,14 @@ FROM (
         unnest($2::jsonb[]) AS error,
         nullif(unnest($3::timestamptz[]), '0001-01-01 00:00:00 +0000') AS finalized_at,
         unnest($4::timestamptz[]) AS scheduled_at,
-        unnest($5::text[])::river_job_state AS state
+        unnest($5::text[])::/* TEMPLATE: schema */river_job_state AS state
 ) AS updated_job
 WHERE river_job.id = updated_job.id
 `
 
 type JobRescueManyParams struct {
 	ID          []int64
-	Error       []json.RawMessage
+	Error       []string
 	FinalizedAt []time.Time
 	ScheduledAt []time.Time
 	State       []string
@@ -643,31 +1658,33 @@ func (q *Queries) JobRescueMany(ctx context.Context, db DBTX, arg *JobRescueMany
 const jobRetry = `-- name: JobRetry :one
 WITH job_to_update AS (
     SELECT id
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE river_job.id = $1
     FOR UPDATE
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
-        state = 'available'::river_job_state,
+        state = 'available'::/* TEMPLATE: schema */river_job_state,
         scheduled_at = now(),
         max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
         finalized_at = NULL
     FROM job_to_update
     WHERE river_job.id = job_to_update.id
         -- Do not touch running jobs:
-        AND river_job.state != 'running'::river_job_state
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+        -- Or pending jobs, which must wait for their dependencies:
+        AND river_job.state != 'pending'::/* TEMPLATE: schema */river_job_state
         -- If the job is already available with a prior scheduled_at, leave it alone.
-        AND NOT (river_job.state = 'available'::river_job_state AND river_job.scheduled_at < now())
-    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags
+        AND NOT (river_job.state = 'available'::/* TEMPLATE: schema */river_job_state AND river_job.scheduled_at < now())
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
 )
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE id = $1::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 FROM updated_job
 `
 
@@ -691,14 +1708,91 @@ func (q *Queries) JobRetry(ctx context.Context, db DBTX, id int64) (*RiverJob, e
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
+	)
+	return &i, err
+}
+
+const jobRetryMany = `-- name: JobRetryMany :one
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        id > $1::bigint
+        AND ($2::timestamptz IS NULL OR created_at >= $2::timestamptz)
+        AND ($3::timestamptz IS NULL OR created_at < $3::timestamptz)
+        AND (cardinality($4::text[]) = 0 OR kind = any($4::text[]))
+        AND ($5::jsonb IS NULL OR metadata @> $5::jsonb)
+        AND (cardinality($6::text[]) = 0 OR queue = any($6::text[]))
+        AND (cardinality($7::text[]) = 0 OR state::text = any($7::text[]))
+        -- Do not touch running jobs:
+        AND state != 'running'::/* TEMPLATE: schema */river_job_state
+        -- Or pending jobs, which must wait for their dependencies:
+        AND state != 'pending'::/* TEMPLATE: schema */river_job_state
+        -- If the job is already available with a prior scheduled_at, leave it alone.
+        AND NOT (state = 'available'::/* TEMPLATE: schema */river_job_state AND scheduled_at < now())
+    ORDER BY id
+    LIMIT $8::bigint
+    FOR UPDATE
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = 'available'::/* TEMPLATE: schema */river_job_state,
+        scheduled_at = now(),
+        max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
+        finalized_at = NULL
+    FROM jobs_to_update
+    WHERE river_job.id = jobs_to_update.id
+    RETURNING river_job.id
+)
+SELECT
+    coalesce((SELECT max(id) FROM updated_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM updated_jobs) AS num_retried
+`
+
+type JobRetryManyParams struct {
+	AfterID          int64
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	Kinds            []string
+	MetadataFragment *string
+	Queues           []string
+	States           []string
+	Max              int64
+}
+
+type JobRetryManyRow struct {
+	LastID     int64
+	NumRetried int64
+}
+
+// Makes a batch of jobs matching a filter available to be retried with the
+// same rules as JobRetry, selecting jobs with IDs greater than after_id in ID
+// order so that a caller can page through all matching jobs. Returns the
+// highest ID retried, to be used as after_id for the next batch.
+func (q *Queries) JobRetryMany(ctx context.Context, db DBTX, arg *JobRetryManyParams) (*JobRetryManyRow, error) {
+	row := db.QueryRowContext(ctx, jobRetryMany,
+		arg.AfterID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		pq.Array(arg.Kinds),
+		arg.MetadataFragment,
+		pq.Array(arg.Queues),
+		pq.Array(arg.States),
+		arg.Max,
 	)
+	var i JobRetryManyRow
+	err := row.Scan(&i.LastID, &i.NumRetried)
 	return &i, err
 }
 
 const jobSchedule = `-- name: JobSchedule :one
 WITH jobs_to_schedule AS (
     SELECT id
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE
         state IN ('retryable', 'scheduled')
         AND queue IS NOT NULL
@@ -712,11 +1806,11 @@ WITH jobs_to_schedule AS (
     FOR UPDATE
 ),
 river_job_scheduled AS (
-    UPDATE river_job
-    SET state = 'available'::river_job_state
+    UPDATE /* TEMPLATE: schema */river_job
+    SET state = 'available'::/* TEMPLATE: schema */river_job_state
     FROM jobs_to_schedule
     WHERE river_job.id = jobs_to_schedule.id
-    RETURNING jobs_to_schedule.id, river_job.id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+    RETURNING jobs_to_schedule.id, river_job.id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 )
 SELECT count(*)
 FROM (
@@ -742,16 +1836,16 @@ const jobSetStateIfRunning = `-- name: JobSetStateIfRunning :one
 WITH job_to_update AS (
     SELECT
       id,
-      $1::river_job_state IN ('retryable'::river_job_state, 'scheduled'::river_job_state) AND metadata ? 'cancel_attempted_at' AS should_cancel
-    FROM river_job
+      $1::/* TEMPLATE: schema */river_job_state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state) AND metadata ? 'cancel_attempted_at' AS should_cancel
+    FROM /* TEMPLATE: schema */river_job
     WHERE id = $2::bigint
     FOR UPDATE
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
-      state        = CASE WHEN should_cancel                                          THEN 'cancelled'::river_job_state
-                          ELSE $1::river_job_state END,
+      state        = CASE WHEN should_cancel                                          THEN 'cancelled'::/* TEMPLATE: schema */river_job_state
+                          ELSE $1::/* TEMPLATE: schema */river_job_state END,
       finalized_at = CASE WHEN should_cancel                                          THEN now()
                           WHEN $3::boolean                       THEN $4
                           ELSE finalized_at END,
@@ -759,19 +1853,21 @@ updated_job AS (
                           ELSE errors       END,
       max_attempts = CASE WHEN NOT should_cancel AND $7::boolean    THEN $8
                           ELSE max_attempts END,
-      scheduled_at = CASE WHEN NOT should_cancel AND $9::boolean THEN $10::timestamptz
+      metadata     = CASE WHEN $9::boolean                            THEN metadata || $10::jsonb
+                          ELSE metadata     END,
+      scheduled_at = CASE WHEN NOT should_cancel AND $11::boolean THEN $12::timestamptz
                           ELSE scheduled_at END
     FROM job_to_update
     WHERE river_job.id = job_to_update.id
-        AND river_job.state = 'running'::river_job_state
-    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags
+        AND river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
 )
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
-FROM river_job
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
 WHERE id = $2::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
-SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 FROM updated_job
 `
 
@@ -781,9 +1877,11 @@ type JobSetStateIfRunningParams struct {
 	FinalizedAtDoUpdate bool
 	FinalizedAt         *time.Time
 	ErrorDoUpdate       bool
-	Error               json.RawMessage
+	Error               *string
 	MaxAttemptsUpdate   bool
 	MaxAttempts         int16
+	MetadataDoMerge     bool
+	MetadataUpdates     string
 	ScheduledAtDoUpdate bool
 	ScheduledAt         *time.Time
 }
@@ -798,6 +1896,8 @@ func (q *Queries) JobSetStateIfRunning(ctx context.Context, db DBTX, arg *JobSet
 		arg.Error,
 		arg.MaxAttemptsUpdate,
 		arg.MaxAttempts,
+		arg.MetadataDoMerge,
+		arg.MetadataUpdates,
 		arg.ScheduledAtDoUpdate,
 		arg.ScheduledAt,
 	)
@@ -819,12 +1919,142 @@ func (q *Queries) JobSetStateIfRunning(ctx context.Context, db DBTX, arg *JobSet
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
 
+const jobSetStateIfRunningMany = `-- name: JobSetStateIfRunningMany :many
+WITH job_input AS (
+    SELECT
+        unnest($1::bigint[]) AS id,
+        -- Sent as text[] so that users don't need to register the OID of the
+        -- river_job_state[] type with their driver.
+        unnest($2::text[])::/* TEMPLATE: schema */river_job_state AS state,
+        unnest($3::boolean[]) AS finalized_at_do_update,
+        unnest($4::timestamptz[]) AS finalized_at,
+        unnest($5::boolean[]) AS error_do_update,
+        unnest($6::jsonb[]) AS error,
+        unnest($7::boolean[]) AS max_attempts_do_update,
+        unnest($8::integer[]) AS max_attempts,
+        unnest($9::boolean[]) AS metadata_do_merge,
+        unnest($10::jsonb[]) AS metadata_updates,
+        unnest($11::boolean[]) AS scheduled_at_do_update,
+        unnest($12::timestamptz[]) AS scheduled_at
+),
+job_to_update AS (
+    SELECT
+      job_input.id, job_input.state, job_input.finalized_at_do_update, job_input.finalized_at, job_input.error_do_update, job_input.error, job_input.max_attempts_do_update, job_input.max_attempts, job_input.metadata_do_merge, job_input.metadata_updates, job_input.scheduled_at_do_update, job_input.scheduled_at,
+      job_input.state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state) AND river_job.metadata ? 'cancel_attempted_at' AS should_cancel
+    FROM /* TEMPLATE: schema */river_job
+    JOIN job_input ON river_job.id = job_input.id
+    FOR UPDATE OF river_job
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+      state        = CASE WHEN job_to_update.should_cancel                                          THEN 'cancelled'::/* TEMPLATE: schema */river_job_state
+                          ELSE job_to_update.state END,
+      finalized_at = CASE WHEN job_to_update.should_cancel                                          THEN now()
+                          WHEN job_to_update.finalized_at_do_update                                 THEN job_to_update.finalized_at
+                          ELSE river_job.finalized_at END,
+      errors       = CASE WHEN job_to_update.error_do_update                                        THEN array_append(river_job.errors, job_to_update.error)
+                          ELSE river_job.errors END,
+      max_attempts = CASE WHEN NOT job_to_update.should_cancel AND job_to_update.max_attempts_do_update THEN job_to_update.max_attempts
+                          ELSE river_job.max_attempts END,
+      metadata     = CASE WHEN job_to_update.metadata_do_merge                                      THEN river_job.metadata || job_to_update.metadata_updates
+                          ELSE river_job.metadata END,
+      scheduled_at = CASE WHEN NOT job_to_update.should_cancel AND job_to_update.scheduled_at_do_update THEN job_to_update.scheduled_at
+                          ELSE river_job.scheduled_at END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        AND river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
+WHERE id = any($1::bigint[])
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM updated_job
+`
+
+type JobSetStateIfRunningManyParams struct {
+	ID                  []int64
+	State               []string
+	FinalizedAtDoUpdate []bool
+	FinalizedAt         []time.Time
+	ErrorDoUpdate       []bool
+	Error               []string
+	MaxAttemptsDoUpdate []bool
+	MaxAttempts         []int32
+	MetadataDoMerge     []bool
+	MetadataUpdates     []string
+	ScheduledAtDoUpdate []bool
+	ScheduledAt         []time.Time
+}
+
+func (q *Queries) JobSetStateIfRunningMany(ctx context.Context, db DBTX, arg *JobSetStateIfRunningManyParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobSetStateIfRunningMany,
+		pq.Array(arg.ID),
+		pq.Array(arg.State),
+		pq.Array(arg.FinalizedAtDoUpdate),
+		pq.Array(arg.FinalizedAt),
+		pq.Array(arg.ErrorDoUpdate),
+		pq.Array(arg.Error),
+		pq.Array(arg.MaxAttemptsDoUpdate),
+		pq.Array(arg.MaxAttempts),
+		pq.Array(arg.MetadataDoMerge),
+		pq.Array(arg.MetadataUpdates),
+		pq.Array(arg.ScheduledAtDoUpdate),
+		pq.Array(arg.ScheduledAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobUpdate = `-- name: JobUpdate :one
-UPDATE river_job
+UPDATE /* TEMPLATE: schema */river_job
 SET
     attempt = CASE WHEN $1::boolean THEN $2 ELSE attempt END,
     attempted_at = CASE WHEN $3::boolean THEN $4 ELSE attempted_at END,
@@ -832,7 +2062,7 @@ SET
     finalized_at = CASE WHEN $7::boolean THEN $8 ELSE finalized_at END,
     state = CASE WHEN $9::boolean THEN $10 ELSE state END
 WHERE id = $11
-RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
 `
 
 type JobUpdateParams struct {
@@ -841,7 +2071,7 @@ type JobUpdateParams struct {
 	AttemptedAtDoUpdate bool
 	AttemptedAt         *time.Time
 	ErrorsDoUpdate      bool
-	Errors              []json.RawMessage
+	Errors              []string
 	FinalizedAtDoUpdate bool
 	FinalizedAt         *time.Time
 	StateDoUpdate       bool
@@ -883,6 +2113,112 @@ func (q *Queries) JobUpdate(ctx context.Context, db DBTX, arg *JobUpdateParams)
 		&i.State,
 		&i.ScheduledAt,
 		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
+	)
+	return &i, err
+}
+
+const jobUpdateIfNotRunning = `-- name: JobUpdateIfNotRunning :one
+WITH job_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = $1
+    FOR UPDATE
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        max_attempts = CASE WHEN $2::boolean THEN $3::smallint ELSE max_attempts END,
+        metadata = CASE WHEN $4::boolean THEN metadata || $5::jsonb ELSE metadata END,
+        priority = CASE WHEN $6::boolean THEN $7::smallint ELSE priority END,
+        queue = CASE WHEN $8::boolean THEN $9::text ELSE queue END,
+        scheduled_at = CASE WHEN $10::boolean THEN $11::timestamptz ELSE scheduled_at END,
+        -- Moving a job's scheduled time moves it between available and
+        -- scheduled as appropriate. Retryable jobs moved to now or the past are
+        -- made available immediately rather than waiting for the scheduler.
+        state = CASE
+            WHEN NOT $10::boolean THEN state
+            WHEN state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state)
+                AND $11::timestamptz <= $12::timestamptz
+                THEN 'available'::/* TEMPLATE: schema */river_job_state
+            WHEN state = 'available'::/* TEMPLATE: schema */river_job_state
+                AND $11::timestamptz > $12::timestamptz
+                THEN 'scheduled'::/* TEMPLATE: schema */river_job_state
+            ELSE state
+        END,
+        tags = CASE WHEN $13::boolean THEN $14::varchar(255)[] ELSE tags END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        -- Do not touch running jobs:
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.depends_on, river_job.expires_at, river_job.heartbeat_at
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM /* TEMPLATE: schema */river_job
+WHERE id = $1::bigint
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at
+FROM updated_job
+`
+
+type JobUpdateIfNotRunningParams struct {
+	ID                  int64
+	MaxAttemptsDoUpdate bool
+	MaxAttempts         int16
+	MetadataDoMerge     bool
+	Metadata            string
+	PriorityDoUpdate    bool
+	Priority            int16
+	QueueDoUpdate       bool
+	Queue               string
+	ScheduledAtDoUpdate bool
+	ScheduledAt         time.Time
+	Now                 time.Time
+	TagsDoUpdate        bool
+	Tags                []string
+}
+
+func (q *Queries) JobUpdateIfNotRunning(ctx context.Context, db DBTX, arg *JobUpdateIfNotRunningParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobUpdateIfNotRunning,
+		arg.ID,
+		arg.MaxAttemptsDoUpdate,
+		arg.MaxAttempts,
+		arg.MetadataDoMerge,
+		arg.Metadata,
+		arg.PriorityDoUpdate,
+		arg.Priority,
+		arg.QueueDoUpdate,
+		arg.Queue,
+		arg.ScheduledAtDoUpdate,
+		arg.ScheduledAt,
+		arg.Now,
+		arg.TagsDoUpdate,
+		pq.Array(arg.Tags),
+	)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		pq.Array(&i.DependsOn),
+		&i.ExpiresAt,
+		&i.HeartbeatAt,
 	)
 	return &i, err
 }
@@ -1,14 +1,28 @@
 // Package riverdatabasesql bundles a River driver for Go's built in database/sql.
 //
-// This is _not_ a fully functional driver, and only supports use through
-// rivermigrate for purposes of interacting with migration frameworks like
-// Goose. Using it with a River client will panic.
+// The driver is fully functional and may be used to run a River client, but
+// its listener, which is used to receive notifications from Postgres, requires
+// that the underlying database/sql pool be backed by Pgx's stdlib package
+// (github.com/jackc/pgx/v5/stdlib). Executor functionality like inserting jobs
+// or running migrations works with any Postgres database/sql driver, including
+// lib/pq.
 package riverdatabasesql
 
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
 
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql/internal/dbsqlc"
@@ -17,39 +31,61 @@ import (
 
 // Driver is an implementation of riverdriver.Driver for database/sql.
 type Driver struct {
-	dbPool  *sql.DB
-	queries *dbsqlc.Queries
+	dbPool   *sql.DB
+	queries  *dbsqlc.Queries
+	replacer *strings.Replacer
+	schema   string
 }
 
 // New returns a new database/sql River driver for use with River.
 //
-// It takes an sql.DB to use for use with River. The pool should already be
-// configured to use the schema specified in the client's Schema field. The pool
+// It takes an sql.DB to use for use with River. River's tables are found
+// through the pool's search_path unless a schema is set with WithSchema (which
+// River's client does automatically when its Schema option is set). The pool
 // must not be closed while associated River objects are running.
 //
-// This is _not_ a fully functional driver, and only supports use through
-// rivermigrate for purposes of interacting with migration frameworks like
-// Goose. Using it with a River client will panic.
+// The database pool may be nil. If it is, a client that it's sent into will not
+// be able to start up (calls to Start will error) and the Insert and InsertMany
+// functions will be disabled, but the transactional-variants InsertTx and
+// InsertManyTx continue to function.
 func New(dbPool *sql.DB) *Driver {
-	return &Driver{dbPool: dbPool, queries: dbsqlc.New()}
+	return &Driver{dbPool: dbPool, queries: dbsqlc.New(), replacer: riverdriver.SchemaReplacer("")}
 }
 
 func (d *Driver) GetExecutor() riverdriver.Executor {
-	return &Executor{d.dbPool, d.dbPool, dbsqlc.New()}
+	return newExecutor(d.dbPool, d.dbPool, d.replacer, d.schema)
 }
 
-func (d *Driver) GetListener() riverdriver.Listener { panic(riverdriver.ErrNotImplemented) }
+func (d *Driver) GetListener() riverdriver.Listener {
+	return &Listener{dbPool: d.dbPool, schema: d.schema}
+}
 
 func (d *Driver) HasPool() bool { return d.dbPool != nil }
 
 func (d *Driver) UnwrapExecutor(tx *sql.Tx) riverdriver.ExecutorTx {
-	return &ExecutorTx{Executor: Executor{nil, tx, dbsqlc.New()}, tx: tx}
+	return &ExecutorTx{Executor: *newExecutor(nil, tx, d.replacer, d.schema), tx: tx}
+}
+
+func (d *Driver) WithSchema(schema string) riverdriver.Driver[*sql.Tx] {
+	return &Driver{dbPool: d.dbPool, queries: d.queries, replacer: riverdriver.SchemaReplacer(schema), schema: schema}
 }
 
 type Executor struct {
-	dbPool  *sql.DB
-	dbtx    dbsqlc.DBTX
-	queries *dbsqlc.Queries
+	dbPool   *sql.DB
+	dbtx     dbsqlc.DBTX
+	queries  *dbsqlc.Queries
+	replacer *strings.Replacer
+	schema   string
+}
+
+func newExecutor(dbPool *sql.DB, dbtx dbsqlc.DBTX, replacer *strings.Replacer, schema string) *Executor {
+	return &Executor{
+		dbPool:   dbPool,
+		dbtx:     &templateDBTX{dbtx: dbtx, replacer: replacer},
+		queries:  dbsqlc.New(),
+		replacer: replacer,
+		schema:   schema,
+	}
 }
 
 func (e *Executor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
@@ -61,7 +97,7 @@ func (e *Executor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ExecutorTx{Executor: Executor{nil, tx, e.queries}, tx: tx}, nil
+	return &ExecutorTx{Executor: *newExecutor(nil, tx, e.replacer, e.schema), tx: tx}, nil
 }
 
 func (e *Executor) Exec(ctx context.Context, sql string) (struct{}, error) {
@@ -70,99 +106,704 @@ func (e *Executor) Exec(ctx context.Context, sql string) (struct{}, error) {
 }
 
 func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := e.queries.JobCancel(ctx, e.dbtx, &dbsqlc.JobCancelParams{
+		ID:                params.ID,
+		CancelAttemptedAt: string(cancelledAt),
+		JobControlTopic:   riverdriver.SchemaTopic(e.schema, params.JobControlTopic),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
+}
+
+func (e *Executor) JobCancelMany(ctx context.Context, params *riverdriver.JobCancelManyParams) (*riverdriver.JobManyResult, error) {
+	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobCancelMany(ctx, e.dbtx, &dbsqlc.JobCancelManyParams{
+		AfterID:           params.AfterID,
+		CancelAttemptedAt: string(cancelledAt),
+		CreatedAfter:      filter.CreatedAfter,
+		CreatedBefore:     filter.CreatedBefore,
+		JobControlTopic:   riverdriver.SchemaTopic(e.schema, params.JobControlTopic),
+		Kinds:             filter.Kinds,
+		Max:               int64(params.Max),
+		MetadataFragment:  nullableJSON(filter.MetadataFragment),
+		Queues:            filter.Queues,
+		States:            filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumCancelled)}, nil
+}
+
+func (e *Executor) JobCountByQueueKindAndState(ctx context.Context, params *riverdriver.JobCountByQueueKindAndStateParams) ([]*riverdriver.JobCountByQueueKindAndState, error) {
+	queues := params.Queues
+	if queues == nil {
+		queues = []string{}
+	}
+
+	rows, err := e.queries.JobCountByQueueKindAndState(ctx, e.dbtx, queues)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(rows, func(row *dbsqlc.JobCountByQueueKindAndStateRow) *riverdriver.JobCountByQueueKindAndState {
+		return &riverdriver.JobCountByQueueKindAndState{
+			Count: int(row.Count),
+			Kind:  row.Kind,
+			Queue: row.Queue,
+			State: rivertype.JobState(row.State),
+		}
+	}), nil
+}
+
+func (e *Executor) JobCountByState(ctx context.Context) (map[rivertype.JobState]int, error) {
+	rows, err := e.queries.JobCountByState(ctx, e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	countByState := make(map[rivertype.JobState]int, len(rows))
+	for _, row := range rows {
+		countByState[rivertype.JobState(row.State)] = int(row.Count)
+	}
+	return countByState, nil
+}
+
+func (e *Executor) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	job, err := e.queries.JobDelete(ctx, e.dbtx, id)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	if job.State == "running" {
+		return nil, rivertype.ErrJobRunning
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobDeleteBeforeParams) (int, error) {
-	return 0, riverdriver.ErrNotImplemented
+	numDeleted, err := e.queries.JobDeleteBefore(ctx, e.dbtx, &dbsqlc.JobDeleteBeforeParams{
+		CancelledFinalizedAtHorizon: params.CancelledFinalizedAtHorizon,
+		CompletedFinalizedAtHorizon: params.CompletedFinalizedAtHorizon,
+		DiscardedFinalizedAtHorizon: params.DiscardedFinalizedAtHorizon,
+		Max:                         int64(params.Max),
+	})
+	return int(numDeleted), interpretError(err)
+}
+
+func (e *Executor) JobDeleteMany(ctx context.Context, params *riverdriver.JobDeleteManyParams) (*riverdriver.JobManyResult, error) {
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobDeleteMany(ctx, e.dbtx, &dbsqlc.JobDeleteManyParams{
+		AfterID:          params.AfterID,
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		Max:              int64(params.Max),
+		MetadataFragment: nullableJSON(filter.MetadataFragment),
+		Queues:           filter.Queues,
+		States:           filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumDeleted)}, nil
+}
+
+func (e *Executor) JobExpire(ctx context.Context, params *riverdriver.JobExpireParams) (int, error) {
+	numExpired, err := e.queries.JobExpire(ctx, e.dbtx, &dbsqlc.JobExpireParams{
+		Max: int64(params.Max),
+		Now: params.Now,
+	})
+	return int(numExpired), interpretError(err)
 }
 
 func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	if len(params.ConcurrencyLimits) > 0 || params.FairnessKey != "" || len(params.KindMax) > 0 || params.PriorityAgingInterval > 0 {
+		// Both are marshaled from non-nil values so that they're sent as
+		// empty JSON collections rather than null.
+		concurrencyLimitsParam := params.ConcurrencyLimits
+		if concurrencyLimitsParam == nil {
+			concurrencyLimitsParam = []*riverdriver.JobGetAvailableConcurrencyLimit{}
+		}
+		concurrencyLimits, err := json.Marshal(concurrencyLimitsParam)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling concurrency limits: %w", err)
+		}
+
+		kindMaxParam := params.KindMax
+		if kindMaxParam == nil {
+			kindMaxParam = map[string]int{}
+		}
+		kindMax, err := json.Marshal(kindMaxParam)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling kind max: %w", err)
+		}
+
+		jobs, err := e.queries.JobGetAvailableLimited(ctx, e.dbtx, &dbsqlc.JobGetAvailableLimitedParams{
+			AttemptedBy:                  params.AttemptedBy,
+			ConcurrencyLimits:            string(concurrencyLimits),
+			FairnessKey:                  params.FairnessKey,
+			GlobalMax:                    int32(params.GlobalMax),
+			KindMax:                      string(kindMax),
+			Max:                          int32(params.Max),
+			PriorityAgingIntervalSeconds: params.PriorityAgingInterval.Seconds(),
+			Queue:                        params.Queue,
+		})
+		return mapSlice(jobs, jobRowFromInternal), interpretError(err)
+	}
+
+	jobs, err := e.queries.JobGetAvailable(ctx, e.dbtx, &dbsqlc.JobGetAvailableParams{
+		AttemptedBy: params.AttemptedBy,
+		GlobalMax:   int32(params.GlobalMax),
+		Max:         int32(params.Max),
+		Queue:       params.Queue,
+	})
+	return mapSlice(jobs, jobRowFromInternal), interpretError(err)
 }
 
 func (e *Executor) JobGetByID(ctx context.Context, id int64) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobGetByID(ctx, e.dbtx, id)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobGetByIDMany(ctx context.Context, id []int64) ([]*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	jobs, err := e.queries.JobGetByIDMany(ctx, e.dbtx, id)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(jobs, jobRowFromInternal), nil
 }
 
 func (e *Executor) JobGetByKindAndUniqueProperties(ctx context.Context, params *riverdriver.JobGetByKindAndUniquePropertiesParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobGetByKindAndUniqueProperties(ctx, e.dbtx, &dbsqlc.JobGetByKindAndUniquePropertiesParams{
+		Kind:           params.Kind,
+		ByArgs:         params.ByArgs,
+		Args:           nullableJSON(params.Args),
+		ByCreatedAt:    params.ByCreatedAt,
+		CreatedAtBegin: params.CreatedAtBegin,
+		CreatedAtEnd:   params.CreatedAtEnd,
+		ByQueue:        params.ByQueue,
+		Queue:          params.Queue,
+		ByState:        params.ByState,
+		State:          params.State,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobGetByKindMany(ctx context.Context, kind []string) ([]*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	jobs, err := e.queries.JobGetByKindMany(ctx, e.dbtx, kind)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(jobs, jobRowFromInternal), nil
 }
 
 func (e *Executor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	jobs, err := e.queries.JobGetStuck(ctx, e.dbtx, &dbsqlc.JobGetStuckParams{Max: int32(params.Max), StuckHorizon: params.StuckHorizon})
+	return mapSlice(jobs, jobRowFromInternal), interpretError(err)
+}
+
+func (e *Executor) JobHeartbeat(ctx context.Context, params *riverdriver.JobHeartbeatParams) (*rivertype.JobRow, error) {
+	job, err := e.queries.JobHeartbeat(ctx, e.dbtx, &dbsqlc.JobHeartbeatParams{ID: params.ID, Progress: nullableJSON(params.Progress)})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobInsertFast(ctx context.Context, params *riverdriver.JobInsertFastParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobInsertFast(ctx, e.dbtx, &dbsqlc.JobInsertFastParams{
+		DependsOn:   params.DependsOn,
+		ExpiresAt:   params.ExpiresAt,
+		Args:        string(params.EncodedArgs),
+		Kind:        params.Kind,
+		MaxAttempts: int16(min(params.MaxAttempts, math.MaxInt16)),
+		Metadata:    nullableJSON(params.Metadata),
+		Priority:    int16(min(params.Priority, math.MaxInt16)),
+		Queue:       params.Queue,
+		ScheduledAt: params.ScheduledAt,
+		State:       dbsqlc.JobState(params.State),
+		Tags:        params.Tags,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobInsertFastMany(ctx context.Context, params []*riverdriver.JobInsertFastParams) (int64, error) {
-	return 0, riverdriver.ErrNotImplemented
+	insertJobsParams := &dbsqlc.JobInsertFastManyParams{
+		Args:        make([]string, len(params)),
+		DependsOn:   make([]string, len(params)),
+		ExpiresAt:   make([]string, len(params)),
+		Kind:        make([]string, len(params)),
+		MaxAttempts: make([]int16, len(params)),
+		Metadata:    make([]string, len(params)),
+		Priority:    make([]int16, len(params)),
+		Queue:       make([]string, len(params)),
+		ScheduledAt: make([]time.Time, len(params)),
+		State:       make([]string, len(params)),
+		Tags:        make([]string, len(params)),
+	}
+	now := time.Now()
+
+	for i := 0; i < len(params); i++ {
+		params := params[i]
+
+		metadata := params.Metadata
+		if metadata == nil {
+			metadata = []byte("{}")
+		}
+
+		scheduledAt := now
+		if params.ScheduledAt != nil {
+			scheduledAt = *params.ScheduledAt
+		}
+
+		dependsOn := params.DependsOn
+		if dependsOn == nil {
+			dependsOn = []int64{}
+		}
+
+		tags := params.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		// An empty string is stored as no expiration time.
+		var expiresAt string
+		if params.ExpiresAt != nil {
+			expiresAt = params.ExpiresAt.Format(time.RFC3339Nano)
+		}
+
+		dependsOnJSON, err := json.Marshal(dependsOn)
+		if err != nil {
+			return 0, fmt.Errorf("error marshaling dependencies: %w", err)
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return 0, fmt.Errorf("error marshaling tags: %w", err)
+		}
+
+		insertJobsParams.Args[i] = string(params.EncodedArgs)
+		insertJobsParams.DependsOn[i] = string(dependsOnJSON)
+		insertJobsParams.ExpiresAt[i] = expiresAt
+		insertJobsParams.Kind[i] = params.Kind
+		insertJobsParams.MaxAttempts[i] = int16(min(params.MaxAttempts, math.MaxInt16))
+		insertJobsParams.Metadata[i] = string(metadata)
+		insertJobsParams.Priority[i] = int16(min(params.Priority, math.MaxInt16))
+		insertJobsParams.Queue[i] = params.Queue
+		insertJobsParams.ScheduledAt[i] = scheduledAt
+		insertJobsParams.State[i] = string(params.State)
+		insertJobsParams.Tags[i] = string(tagsJSON)
+	}
+
+	numInserted, err := e.queries.JobInsertFastMany(ctx, e.dbtx, insertJobsParams)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting many jobs: %w", err)
+	}
+
+	return numInserted, nil
 }
 
 func (e *Executor) JobInsertFull(ctx context.Context, params *riverdriver.JobInsertFullParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobInsertFull(ctx, e.dbtx, &dbsqlc.JobInsertFullParams{
+		Attempt:     int16(params.Attempt),
+		AttemptedAt: params.AttemptedAt,
+		Args:        string(params.EncodedArgs),
+		CreatedAt:   params.CreatedAt,
+		DependsOn:   params.DependsOn,
+		Errors:      mapSlice(params.Errors, func(e []byte) string { return string(e) }),
+		ExpiresAt:   params.ExpiresAt,
+		FinalizedAt: params.FinalizedAt,
+		HeartbeatAt: params.HeartbeatAt,
+		Kind:        params.Kind,
+		MaxAttempts: int16(min(params.MaxAttempts, math.MaxInt16)),
+		Metadata:    nullableJSON(params.Metadata),
+		Priority:    int16(min(params.Priority, math.MaxInt16)),
+		Queue:       params.Queue,
+		ScheduledAt: params.ScheduledAt,
+		State:       dbsqlc.JobState(params.State),
+		Tags:        params.Tags,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) JobList(ctx context.Context, sql string, namedArgs map[string]any) ([]*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	// database/sql has no concept of named arguments that Postgres will
+	// understand, so rewrite them to ordinal placeholders using the same
+	// lexer Pgx uses for its NamedArgs. The connection argument is unused.
+	sql, args, err := pgx.NamedArgs(namedArgs).RewriteQuery(ctx, nil, sql, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, arg := range args {
+		args[i] = arrayArg(arg)
+	}
+
+	rows, err := e.dbtx.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*dbsqlc.RiverJob
+	for rows.Next() {
+		var i dbsqlc.RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			pq.Array(&i.DependsOn),
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, interpretError(err)
+	}
+
+	return mapSlice(items, jobRowFromInternal), nil
 }
 
 func (e *Executor) JobListFields() string {
-	panic(riverdriver.ErrNotImplemented)
+	return "id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at"
+}
+
+func (e *Executor) JobPromotePending(ctx context.Context, params *riverdriver.JobPromotePendingParams) (*riverdriver.JobPromotePendingResult, error) {
+	res, err := e.queries.JobPromotePending(ctx, e.dbtx, &dbsqlc.JobPromotePendingParams{
+		AfterID:               params.AfterID,
+		DependencyFailedState: dbsqlc.JobState(params.DependencyFailedState),
+		InsertTopic:           riverdriver.SchemaTopic(e.schema, params.InsertTopic),
+		Max:                   int64(params.Max),
+		Now:                   params.Now,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobPromotePendingResult{
+		LastID:      res.LastID,
+		NumChecked:  int(res.NumChecked),
+		NumFailed:   int(res.NumFailed),
+		NumPromoted: int(res.NumPromoted),
+	}, nil
+}
+
+func (e *Executor) JobQueueStats(ctx context.Context, params *riverdriver.JobQueueStatsParams) ([]*riverdriver.JobQueueStats, error) {
+	queues := params.Queues
+	if queues == nil {
+		queues = []string{}
+	}
+
+	rows, err := e.queries.JobQueueStats(ctx, e.dbtx, &dbsqlc.JobQueueStatsParams{
+		FinalizedAfter: params.FinalizedAfter,
+		Queues:         queues,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(rows, func(row *dbsqlc.JobQueueStatsRow) *riverdriver.JobQueueStats {
+		var oldestAvailableScheduledAt *time.Time
+		if row.OldestAvailableScheduledAt != nil {
+			t := row.OldestAvailableScheduledAt.UTC()
+			oldestAvailableScheduledAt = &t
+		}
+
+		return &riverdriver.JobQueueStats{
+			OldestAvailableScheduledAt: oldestAvailableScheduledAt,
+			Queue:                      row.Queue,
+			RunDurationP50:             time.Duration(row.RunDurationP50 * float64(time.Second)),
+			RunDurationP95:             time.Duration(row.RunDurationP95 * float64(time.Second)),
+		}
+	}), nil
 }
 
 func (e *Executor) JobRescueMany(ctx context.Context, params *riverdriver.JobRescueManyParams) (*struct{}, error) {
-	return nil, riverdriver.ErrNotImplemented
+	err := e.queries.JobRescueMany(ctx, e.dbtx, &dbsqlc.JobRescueManyParams{
+		ID:          params.ID,
+		Error:       mapSlice(params.Error, func(e []byte) string { return string(e) }),
+		FinalizedAt: params.FinalizedAt,
+		ScheduledAt: params.ScheduledAt,
+		State:       params.State,
+	})
+	return &struct{}{}, interpretError(err)
 }
 
 func (e *Executor) JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobRetry(ctx, e.dbtx, id)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
+}
+
+func (e *Executor) JobRetryMany(ctx context.Context, params *riverdriver.JobRetryManyParams) (*riverdriver.JobManyResult, error) {
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobRetryMany(ctx, e.dbtx, &dbsqlc.JobRetryManyParams{
+		AfterID:          params.AfterID,
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		Max:              int64(params.Max),
+		MetadataFragment: nullableJSON(filter.MetadataFragment),
+		Queues:           filter.Queues,
+		States:           filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumRetried)}, nil
 }
 
 func (e *Executor) JobSchedule(ctx context.Context, params *riverdriver.JobScheduleParams) (int, error) {
-	return 0, riverdriver.ErrNotImplemented
+	numScheduled, err := e.queries.JobSchedule(ctx, e.dbtx, &dbsqlc.JobScheduleParams{
+		InsertTopic: riverdriver.SchemaTopic(e.schema, params.InsertTopic),
+		Max:         int64(params.Max),
+		Now:         params.Now,
+	})
+	return int(numScheduled), interpretError(err)
 }
 
 func (e *Executor) JobSetStateIfRunning(ctx context.Context, params *riverdriver.JobSetStateIfRunningParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	var maxAttempts int16
+	if params.MaxAttempts != nil {
+		maxAttempts = int16(*params.MaxAttempts)
+	}
+
+	// Metadata updates are sent as a string, which can't be null, so a
+	// placeholder is used when there's nothing to merge.
+	metadataUpdates := "{}"
+	if params.MetadataUpdates != nil {
+		metadataUpdates = string(params.MetadataUpdates)
+	}
+
+	job, err := e.queries.JobSetStateIfRunning(ctx, e.dbtx, &dbsqlc.JobSetStateIfRunningParams{
+		ID:                  params.ID,
+		ErrorDoUpdate:       params.ErrData != nil,
+		Error:               nullableJSON(params.ErrData),
+		FinalizedAtDoUpdate: params.FinalizedAt != nil,
+		FinalizedAt:         params.FinalizedAt,
+		MaxAttemptsUpdate:   params.MaxAttempts != nil,
+		MaxAttempts:         maxAttempts,
+		MetadataDoMerge:     params.MetadataUpdates != nil,
+		MetadataUpdates:     metadataUpdates,
+		ScheduledAtDoUpdate: params.ScheduledAt != nil,
+		ScheduledAt:         params.ScheduledAt,
+		State:               dbsqlc.JobState(params.State),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
+}
+
+func (e *Executor) JobSetStateIfRunningMany(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+	setStateParams := &dbsqlc.JobSetStateIfRunningManyParams{
+		ID:                  make([]int64, len(params)),
+		State:               make([]string, len(params)),
+		FinalizedAtDoUpdate: make([]bool, len(params)),
+		FinalizedAt:         make([]time.Time, len(params)),
+		ErrorDoUpdate:       make([]bool, len(params)),
+		Error:               make([]string, len(params)),
+		MaxAttemptsDoUpdate: make([]bool, len(params)),
+		MaxAttempts:         make([]int32, len(params)),
+		MetadataDoMerge:     make([]bool, len(params)),
+		MetadataUpdates:     make([]string, len(params)),
+		ScheduledAtDoUpdate: make([]bool, len(params)),
+		ScheduledAt:         make([]time.Time, len(params)),
+	}
+
+	for i, params := range params {
+		setStateParams.ID[i] = params.ID
+		setStateParams.State[i] = string(params.State)
+
+		if params.FinalizedAt != nil {
+			setStateParams.FinalizedAtDoUpdate[i] = true
+			setStateParams.FinalizedAt[i] = *params.FinalizedAt
+		}
+		// Errors are sent as strings, which can't be null, so a placeholder is
+		// used when there's no error to append.
+		setStateParams.Error[i] = "{}"
+		if params.ErrData != nil {
+			setStateParams.ErrorDoUpdate[i] = true
+			setStateParams.Error[i] = string(params.ErrData)
+		}
+		if params.MaxAttempts != nil {
+			setStateParams.MaxAttemptsDoUpdate[i] = true
+			setStateParams.MaxAttempts[i] = int32(min(*params.MaxAttempts, math.MaxInt32))
+		}
+		setStateParams.MetadataUpdates[i] = "{}"
+		if params.MetadataUpdates != nil {
+			setStateParams.MetadataDoMerge[i] = true
+			setStateParams.MetadataUpdates[i] = string(params.MetadataUpdates)
+		}
+		if params.ScheduledAt != nil {
+			setStateParams.ScheduledAtDoUpdate[i] = true
+			setStateParams.ScheduledAt[i] = *params.ScheduledAt
+		}
+	}
+
+	jobs, err := e.queries.JobSetStateIfRunningMany(ctx, e.dbtx, setStateParams)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(jobs, jobRowFromInternal), nil
 }
 
 func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateParams) (*rivertype.JobRow, error) {
-	return nil, riverdriver.ErrNotImplemented
+	job, err := e.queries.JobUpdate(ctx, e.dbtx, &dbsqlc.JobUpdateParams{
+		ID:                  params.ID,
+		AttemptedAtDoUpdate: params.AttemptedAtDoUpdate,
+		AttemptedAt:         params.AttemptedAt,
+		AttemptDoUpdate:     params.AttemptDoUpdate,
+		Attempt:             int16(params.Attempt),
+		ErrorsDoUpdate:      params.ErrorsDoUpdate,
+		Errors:              mapSlice(params.Errors, func(e []byte) string { return string(e) }),
+		FinalizedAtDoUpdate: params.FinalizedAtDoUpdate,
+		FinalizedAt:         params.FinalizedAt,
+		StateDoUpdate:       params.StateDoUpdate,
+		State:               dbsqlc.JobState(params.State),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	return jobRowFromInternal(job), nil
+}
+
+func (e *Executor) JobUpdateIfNotRunning(ctx context.Context, params *riverdriver.JobUpdateIfNotRunningParams) (*rivertype.JobRow, error) {
+	// Metadata is cast to jsonb even when it's not merged, so make sure it's
+	// always valid JSON.
+	metadata := params.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	job, err := e.queries.JobUpdateIfNotRunning(ctx, e.dbtx, &dbsqlc.JobUpdateIfNotRunningParams{
+		ID:                  params.ID,
+		MaxAttemptsDoUpdate: params.MaxAttemptsDoUpdate,
+		MaxAttempts:         int16(min(params.MaxAttempts, math.MaxInt16)),
+		MetadataDoMerge:     params.MetadataDoMerge,
+		Metadata:            string(metadata),
+		Now:                 params.Now,
+		PriorityDoUpdate:    params.PriorityDoUpdate,
+		Priority:            int16(min(params.Priority, math.MaxInt16)),
+		QueueDoUpdate:       params.QueueDoUpdate,
+		Queue:               params.Queue,
+		ScheduledAtDoUpdate: params.ScheduledAtDoUpdate,
+		ScheduledAt:         params.ScheduledAt,
+		TagsDoUpdate:        params.TagsDoUpdate,
+		Tags:                params.Tags,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	if job.State == "running" {
+		return nil, rivertype.ErrJobRunning
+	}
+	return jobRowFromInternal(job), nil
 }
 
 func (e *Executor) LeaderAttemptElect(ctx context.Context, params *riverdriver.LeaderElectParams) (bool, error) {
-	return false, riverdriver.ErrNotImplemented
+	numElectionsWon, err := e.queries.LeaderAttemptElect(ctx, e.dbtx, &dbsqlc.LeaderAttemptElectParams{
+		Name:     params.Name,
+		LeaderID: params.LeaderID,
+		TTL:      params.TTL.Seconds(),
+	})
+	if err != nil {
+		return false, interpretError(err)
+	}
+	return numElectionsWon > 0, nil
 }
 
 func (e *Executor) LeaderAttemptReelect(ctx context.Context, params *riverdriver.LeaderElectParams) (bool, error) {
-	return false, riverdriver.ErrNotImplemented
+	numElectionsWon, err := e.queries.LeaderAttemptReelect(ctx, e.dbtx, &dbsqlc.LeaderAttemptReelectParams{
+		Name:     params.Name,
+		LeaderID: params.LeaderID,
+		TTL:      params.TTL.Seconds(),
+	})
+	if err != nil {
+		return false, interpretError(err)
+	}
+	return numElectionsWon > 0, nil
 }
 
 func (e *Executor) LeaderDeleteExpired(ctx context.Context, name string) (int, error) {
-	return 0, riverdriver.ErrNotImplemented
+	numDeleted, err := e.queries.LeaderDeleteExpired(ctx, e.dbtx, name)
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return int(numDeleted), nil
 }
 
 func (e *Executor) LeaderGetElectedLeader(ctx context.Context, name string) (*riverdriver.Leader, error) {
-	return nil, riverdriver.ErrNotImplemented
+	leader, err := e.queries.LeaderGetElectedLeader(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return leaderFromInternal(leader), nil
 }
 
 func (e *Executor) LeaderInsert(ctx context.Context, params *riverdriver.LeaderInsertParams) (*riverdriver.Leader, error) {
-	return nil, riverdriver.ErrNotImplemented
+	leader, err := e.queries.LeaderInsert(ctx, e.dbtx, &dbsqlc.LeaderInsertParams{
+		ElectedAt: params.ElectedAt,
+		ExpiresAt: params.ExpiresAt,
+		LeaderID:  params.LeaderID,
+		Name:      params.Name,
+		TTL:       params.TTL.Seconds(),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return leaderFromInternal(leader), nil
 }
 
 func (e *Executor) LeaderResign(ctx context.Context, params *riverdriver.LeaderResignParams) (bool, error) {
-	return false, riverdriver.ErrNotImplemented
+	numResigned, err := e.queries.LeaderResign(ctx, e.dbtx, &dbsqlc.LeaderResignParams{
+		LeaderID:        params.LeaderID,
+		LeadershipTopic: riverdriver.SchemaTopic(e.schema, params.LeadershipTopic),
+		Name:            params.Name,
+	})
+	if err != nil {
+		return false, interpretError(err)
+	}
+	return numResigned > 0, nil
 }
 
 func (e *Executor) MigrationDeleteByVersionMany(ctx context.Context, versions []int) ([]*riverdriver.Migration, error) {
@@ -192,14 +833,103 @@ func (e *Executor) MigrationInsertMany(ctx context.Context, versions []int) ([]*
 }
 
 func (e *Executor) Notify(ctx context.Context, topic string, payload string) error {
-	return riverdriver.ErrNotImplemented
+	return e.queries.PGNotify(ctx, e.dbtx, &dbsqlc.PGNotifyParams{
+		Payload: payload,
+		Topic:   riverdriver.SchemaTopic(e.schema, topic),
+	})
 }
 
 func (e *Executor) PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}, error) {
-	return nil, riverdriver.ErrNotImplemented
+	err := e.queries.PGAdvisoryXactLock(ctx, e.dbtx, key)
+	return &struct{}{}, interpretError(err)
+}
+
+func (e *Executor) QueueCreateOrSetUpdatedAt(ctx context.Context, params *riverdriver.QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueCreateOrSetUpdatedAt(ctx, e.dbtx, &dbsqlc.QueueCreateOrSetUpdatedAtParams{
+		Metadata:  nullableJSON(params.Metadata),
+		Name:      params.Name,
+		PausedAt:  params.PausedAt,
+		UpdatedAt: params.UpdatedAt,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueGet(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueGet(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueList(ctx context.Context, limit int) ([]*rivertype.Queue, error) {
+	queues, err := e.queries.QueueList(ctx, e.dbtx, int32(limit))
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(queues, queueFromInternal), nil
+}
+
+func (e *Executor) QueuePause(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueuePause(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueResume(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueResume(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueUpdateMetadata(ctx context.Context, params *riverdriver.QueueUpdateMetadataParams) (*rivertype.Queue, error) {
+	// Metadata updates are sent as a string, which can't be null, so default
+	// it to an empty object that leaves metadata unchanged when merged.
+	metadataUpdates := "{}"
+	if params.MetadataUpdates != nil {
+		metadataUpdates = string(params.MetadataUpdates)
+	}
+
+	queue, err := e.queries.QueueUpdateMetadata(ctx, e.dbtx, &dbsqlc.QueueUpdateMetadataParams{
+		MetadataDeleteKeys: params.MetadataDeleteKeys,
+		MetadataUpdates:    metadataUpdates,
+		Name:               params.Name,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) RateLimitGetMany(ctx context.Context, keys []string) ([]*riverdriver.RateLimitBucket, error) {
+	buckets, err := e.queries.RateLimitGetMany(ctx, e.dbtx, keys)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(buckets, rateLimitBucketFromInternal), nil
+}
+
+func (e *Executor) RateLimitSetMany(ctx context.Context, params *riverdriver.RateLimitSetManyParams) error {
+	err := e.queries.RateLimitSetMany(ctx, e.dbtx, &dbsqlc.RateLimitSetManyParams{
+		Key:       params.Key,
+		Tokens:    params.Tokens,
+		UpdatedAt: params.UpdatedAt,
+	})
+	return interpretError(err)
 }
 
 func (e *Executor) TableExists(ctx context.Context, tableName string) (bool, error) {
+	if e.schema != "" {
+		tableName = pq.QuoteIdentifier(e.schema) + "." + pq.QuoteIdentifier(tableName)
+	}
+
 	exists, err := e.queries.TableExists(ctx, e.dbtx, tableName)
 	return exists, interpretError(err)
 }
@@ -219,6 +949,211 @@ func (t *ExecutorTx) Rollback(ctx context.Context) error {
 	return t.tx.Rollback()
 }
 
+// Begin starts a subtransaction. database/sql doesn't support nested
+// transactions, so they're emulated with savepoints in the same way that Pgx
+// does it.
+func (t *ExecutorTx) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
+	return beginSubTx(ctx, &t.Executor, t.tx, 1)
+}
+
+// ExecutorSubTx is a subtransaction started on an existing transaction, and
+// implemented as a savepoint.
+type ExecutorSubTx struct {
+	Executor
+	savepointNum int
+	tx           *sql.Tx
+}
+
+func beginSubTx(ctx context.Context, parent *Executor, tx *sql.Tx, savepointNum int) (*ExecutorSubTx, error) {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SAVEPOINT sp_%d", savepointNum)); err != nil {
+		return nil, err
+	}
+	return &ExecutorSubTx{Executor: *newExecutor(nil, tx, parent.replacer, parent.schema), savepointNum: savepointNum, tx: tx}, nil
+}
+
+func (t *ExecutorSubTx) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
+	return beginSubTx(ctx, &t.Executor, t.tx, t.savepointNum+1)
+}
+
+func (t *ExecutorSubTx) Commit(ctx context.Context) error {
+	_, err := t.tx.ExecContext(ctx, fmt.Sprintf("RELEASE SAVEPOINT sp_%d", t.savepointNum))
+	return err
+}
+
+func (t *ExecutorSubTx) Rollback(ctx context.Context) error {
+	_, err := t.tx.ExecContext(ctx, fmt.Sprintf("ROLLBACK TO SAVEPOINT sp_%d", t.savepointNum))
+	return err
+}
+
+// Listener is a riverdriver.Listener for database/sql. database/sql has no
+// built in support for LISTEN/NOTIFY, so the listener holds a dedicated
+// connection from the pool and reaches into the underlying driver connection to
+// wait for notifications. This is only possible when the pool was opened with
+// Pgx's stdlib package.
+type Listener struct {
+	conn   *sql.Conn
+	dbPool *sql.DB
+	mu     sync.RWMutex
+	schema string
+}
+
+// pgxDriverConn is implemented by *stdlib.Conn, the driver connection used by
+// database/sql pools opened through Pgx's stdlib package.
+type pgxDriverConn interface {
+	Conn() *pgx.Conn
+}
+
+var errListenerRequiresPgx = errors.New("listener requires a database/sql pool opened through Pgx's stdlib package (github.com/jackc/pgx/v5/stdlib)")
+
+func (l *Listener) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	// Returning driver.ErrBadConn signals to database/sql that the connection
+	// should be closed rather than returned to the pool where it'd be reused
+	// while still subscribed to topics.
+	err := l.conn.Raw(func(driverConn any) error { return driver.ErrBadConn })
+	if err != nil && !errors.Is(err, driver.ErrBadConn) {
+		return err
+	}
+	l.conn = nil
+	return nil
+}
+
+func (l *Listener) Connect(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return errors.New("connection already established")
+	}
+
+	conn, err := l.dbPool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Raw(func(driverConn any) error {
+		if _, ok := driverConn.(pgxDriverConn); !ok {
+			return errListenerRequiresPgx
+		}
+		return nil
+	}); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	l.conn = conn
+	return nil
+}
+
+func (l *Listener) Listen(ctx context.Context, topic string) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, err := l.conn.ExecContext(ctx, "LISTEN "+pq.QuoteIdentifier(riverdriver.SchemaTopic(l.schema, topic)))
+	return err
+}
+
+func (l *Listener) Ping(ctx context.Context) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.conn.PingContext(ctx)
+}
+
+func (l *Listener) Unlisten(ctx context.Context, topic string) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, err := l.conn.ExecContext(ctx, "UNLISTEN "+pq.QuoteIdentifier(riverdriver.SchemaTopic(l.schema, topic)))
+	return err
+}
+
+func (l *Listener) WaitForNotification(ctx context.Context) (*riverdriver.Notification, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var notification *riverdriver.Notification
+	err := l.conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(pgxDriverConn)
+		if !ok {
+			return errListenerRequiresPgx
+		}
+
+		pgNotification, err := pgxConn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		notification = &riverdriver.Notification{
+			Topic:   riverdriver.SchemaTopicUnscoped(l.schema, pgNotification.Channel),
+			Payload: pgNotification.Payload,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// templateDBTX wraps a database pool or transaction, filling in the schema
+// templates in River's SQL (see riverdriver.SchemaTemplate) before any of it
+// is sent to Postgres.
+type templateDBTX struct {
+	dbtx     dbsqlc.DBTX
+	replacer *strings.Replacer
+}
+
+func (t *templateDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.dbtx.ExecContext(ctx, t.replacer.Replace(query), args...)
+}
+
+func (t *templateDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.dbtx.PrepareContext(ctx, t.replacer.Replace(query))
+}
+
+func (t *templateDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.dbtx.QueryContext(ctx, t.replacer.Replace(query), args...)
+}
+
+func (t *templateDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.dbtx.QueryRowContext(ctx, t.replacer.Replace(query), args...)
+}
+
+// arrayArg wraps slices (other than byte slices) in pq.Array so that they're
+// encoded as Postgres arrays, something database/sql can't do on its own.
+func arrayArg(arg any) any {
+	if arg == nil {
+		return nil
+	}
+
+	if _, ok := arg.([]byte); ok {
+		return arg
+	}
+
+	if reflect.TypeOf(arg).Kind() == reflect.Slice {
+		return pq.Array(arg)
+	}
+
+	return arg
+}
+
+func attemptErrorFromInternal(e *dbsqlc.AttemptError) rivertype.AttemptError {
+	return rivertype.AttemptError{
+		At:      e.At.UTC(),
+		Attempt: int(e.Attempt),
+		Error:   e.Error,
+		Trace:   e.Trace,
+	}
+}
+
 func interpretError(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return rivertype.ErrNotFound
@@ -226,6 +1161,101 @@ func interpretError(err error) error {
 	return err
 }
 
+// jobFilter is a riverdriver.JobFilter prepared to be sent as query
+// parameters. Arrays are never nil so that they're sent as empty arrays rather
+// than NULL, which the filter's conditions don't account for.
+type jobFilter struct {
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	Kinds            []string
+	MetadataFragment []byte
+	Queues           []string
+	States           []string
+}
+
+func jobFilterToInternal(filter *riverdriver.JobFilter) *jobFilter {
+	if filter == nil {
+		filter = &riverdriver.JobFilter{}
+	}
+
+	internal := &jobFilter{
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		MetadataFragment: filter.MetadataFragment,
+		Queues:           filter.Queues,
+		States:           make([]string, len(filter.States)),
+	}
+	if internal.Kinds == nil {
+		internal.Kinds = []string{}
+	}
+	if internal.Queues == nil {
+		internal.Queues = []string{}
+	}
+	for i, state := range filter.States {
+		internal.States[i] = string(state)
+	}
+
+	return internal
+}
+
+func jobRowFromInternal(internal *dbsqlc.RiverJob) *rivertype.JobRow {
+	var attemptedAt *time.Time
+	if internal.AttemptedAt != nil {
+		t := internal.AttemptedAt.UTC()
+		attemptedAt = &t
+	}
+
+	var expiresAt *time.Time
+	if internal.ExpiresAt != nil {
+		t := internal.ExpiresAt.UTC()
+		expiresAt = &t
+	}
+
+	var finalizedAt *time.Time
+	if internal.FinalizedAt != nil {
+		t := internal.FinalizedAt.UTC()
+		finalizedAt = &t
+	}
+
+	var heartbeatAt *time.Time
+	if internal.HeartbeatAt != nil {
+		t := internal.HeartbeatAt.UTC()
+		heartbeatAt = &t
+	}
+
+	return &rivertype.JobRow{
+		ID:          internal.ID,
+		Attempt:     max(int(internal.Attempt), 0),
+		AttemptedAt: attemptedAt,
+		AttemptedBy: internal.AttemptedBy,
+		CreatedAt:   internal.CreatedAt.UTC(),
+		DependsOn:   internal.DependsOn,
+		EncodedArgs: internal.Args,
+		Errors:      mapSlice(internal.Errors, func(e dbsqlc.AttemptError) rivertype.AttemptError { return attemptErrorFromInternal(&e) }),
+		ExpiresAt:   expiresAt,
+		FinalizedAt: finalizedAt,
+		HeartbeatAt: heartbeatAt,
+		Kind:        internal.Kind,
+		MaxAttempts: max(int(internal.MaxAttempts), 0),
+		Metadata:    []byte(internal.Metadata),
+		Priority:    max(int(internal.Priority), 0),
+		Queue:       internal.Queue,
+		ScheduledAt: internal.ScheduledAt.UTC(),
+		State:       rivertype.JobState(internal.State),
+		Tags:        internal.Tags,
+	}
+}
+
+func leaderFromInternal(internal *dbsqlc.RiverLeader) *riverdriver.Leader {
+	return &riverdriver.Leader{
+		ElectedAt: internal.ElectedAt.UTC(),
+		ExpiresAt: internal.ExpiresAt.UTC(),
+		LeaderID:  internal.LeaderID,
+		Name:      internal.Name,
+	}
+}
+
 // mapSlice manipulates a slice and transforms it to a slice of another type.
 func mapSlice[T any, R any](collection []T, mapFunc func(T) R) []R {
 	if collection == nil {
@@ -248,3 +1278,36 @@ func migrationFromInternal(internal *dbsqlc.RiverMigration) *riverdriver.Migrati
 		Version:   int(internal.Version),
 	}
 }
+
+// nullableJSON converts an optional JSON byte slice to a string pointer
+// suitable for use as a nullable jsonb parameter.
+func nullableJSON(data []byte) *string {
+	if data == nil {
+		return nil
+	}
+	str := string(data)
+	return &str
+}
+
+func queueFromInternal(internal *dbsqlc.RiverQueue) *rivertype.Queue {
+	var pausedAt *time.Time
+	if internal.PausedAt != nil {
+		t := internal.PausedAt.UTC()
+		pausedAt = &t
+	}
+	return &rivertype.Queue{
+		CreatedAt: internal.CreatedAt.UTC(),
+		Metadata:  []byte(internal.Metadata),
+		Name:      internal.Name,
+		PausedAt:  pausedAt,
+		UpdatedAt: internal.UpdatedAt.UTC(),
+	}
+}
+
+func rateLimitBucketFromInternal(internal *dbsqlc.RiverRateLimit) *riverdriver.RateLimitBucket {
+	return &riverdriver.RateLimitBucket{
+		Key:       internal.Key,
+		Tokens:    internal.Tokens,
+		UpdatedAt: internal.UpdatedAt.UTC(),
+	}
+}
@@ -30,6 +30,8 @@ func (r *iteratorForJobInsertMany) Next() bool {
 func (r iteratorForJobInsertMany) Values() ([]interface{}, error) {
 	return []interface{}{
 		r.rows[0].Args,
+		r.rows[0].DependsOn,
+		r.rows[0].ExpiresAt,
 		r.rows[0].FinalizedAt,
 		r.rows[0].Kind,
 		r.rows[0].MaxAttempts,
@@ -47,5 +49,5 @@ func (r iteratorForJobInsertMany) Err() error {
 }
 
 func (q *Queries) JobInsertMany(ctx context.Context, db DBTX, arg []*JobInsertManyParams) (int64, error) {
-	return db.CopyFrom(ctx, []string{"river_job"}, []string{"args", "finalized_at", "kind", "max_attempts", "metadata", "priority", "queue", "scheduled_at", "state", "tags"}, &iteratorForJobInsertMany{rows: arg})
+	return db.CopyFrom(ctx, []string{"river_job"}, []string{"args", "depends_on", "expires_at", "finalized_at", "kind", "max_attempts", "metadata", "priority", "queue", "scheduled_at", "state", "tags"}, &iteratorForJobInsertMany{rows: arg})
 }
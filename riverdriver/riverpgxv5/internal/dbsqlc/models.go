@@ -17,6 +17,7 @@ const (
 	RiverJobStateCancelled RiverJobState = "cancelled"
 	RiverJobStateCompleted RiverJobState = "completed"
 	RiverJobStateDiscarded RiverJobState = "discarded"
+	RiverJobStatePending   RiverJobState = "pending"
 	RiverJobStateRetryable RiverJobState = "retryable"
 	RiverJobStateRunning   RiverJobState = "running"
 	RiverJobStateScheduled RiverJobState = "scheduled"
@@ -74,6 +75,9 @@ type RiverJob struct {
 	State       RiverJobState
 	ScheduledAt time.Time
 	Tags        []string
+	DependsOn   []int64
+	ExpiresAt   *time.Time
+	HeartbeatAt *time.Time
 }
 
 type RiverLeader struct {
@@ -88,3 +92,17 @@ type RiverMigration struct {
 	CreatedAt time.Time
 	Version   int64
 }
+
+type RiverQueue struct {
+	Name      string
+	CreatedAt time.Time
+	Metadata  []byte
+	PausedAt  *time.Time
+	UpdatedAt time.Time
+}
+
+type RiverRateLimit struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
@@ -3,6 +3,7 @@ CREATE TYPE river_job_state AS ENUM(
     'cancelled',
     'completed',
     'discarded',
+    'pending',
     'retryable',
     'running',
     'scheduled'
@@ -25,6 +26,9 @@ CREATE TABLE river_job(
     state river_job_state NOT NULL DEFAULT 'available' ::river_job_state,
     scheduled_at timestamptz NOT NULL DEFAULT NOW(),
     tags varchar(255)[] NOT NULL DEFAULT '{}' ::varchar(255)[],
+    depends_on bigint[],
+    expires_at timestamptz,
+    heartbeat_at timestamptz,
     CONSTRAINT finalized_or_finalized_at_null CHECK ((state IN ('cancelled', 'completed', 'discarded') AND finalized_at IS NOT NULL) OR finalized_at IS NULL),
     CONSTRAINT priority_in_range CHECK (priority >= 1 AND priority <= 4),
     CONSTRAINT queue_length CHECK (char_length(queue) > 0 AND char_length(queue) < 128),
@@ -35,7 +39,7 @@ CREATE TABLE river_job(
 WITH locked_job AS (
     SELECT
         id, queue, state, finalized_at
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE river_job.id = @id
     FOR UPDATE
 ),
@@ -50,12 +54,12 @@ notification AS (
         AND finalized_at IS NULL
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
         -- If the job is actively running, we want to let its current client and
         -- producer handle the cancellation. Otherwise, immediately cancel it.
-        state = CASE WHEN state = 'running'::river_job_state THEN state ELSE 'cancelled'::river_job_state END,
-        finalized_at = CASE WHEN state = 'running'::river_job_state THEN finalized_at ELSE now() END,
+        state = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN state ELSE 'cancelled'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN finalized_at ELSE now() END,
         -- Mark the job as cancelled by query so that the rescuer knows not to
         -- rescue it, even if it gets stuck in the running state:
         metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], @cancel_attempted_at::jsonb, true)
@@ -64,19 +68,108 @@ updated_job AS (
     RETURNING river_job.*
 )
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE id = @id::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobCancelMany :one
+-- Cancels a batch of jobs matching a filter with the same rules as JobCancel,
+-- selecting jobs with IDs greater than after_id in ID order so that a caller
+-- can page through all matching jobs. Returns the highest ID cancelled, to be
+-- used as after_id for the next batch.
+WITH locked_jobs AS (
+    SELECT
+        id, queue
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        id > @after_id::bigint
+        AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after')::timestamptz)
+        AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before')::timestamptz)
+        AND (cardinality(@kinds::text[]) = 0 OR kind = any(@kinds::text[]))
+        AND (sqlc.narg('metadata_fragment')::jsonb IS NULL OR metadata @> sqlc.narg('metadata_fragment')::jsonb)
+        AND (cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[]))
+        AND (cardinality(@states::text[]) = 0 OR state::text = any(@states::text[]))
+        AND state NOT IN ('cancelled', 'completed', 'discarded')
+        AND finalized_at IS NULL
+    ORDER BY id
+    LIMIT @max::bigint
+    FOR UPDATE
+),
+notification AS (
+    SELECT
+        id,
+        pg_notify(@job_control_topic, json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text)
+    FROM
+        locked_jobs
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        -- If the job is actively running, we want to let its current client and
+        -- producer handle the cancellation. Otherwise, immediately cancel it.
+        state = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN state ELSE 'cancelled'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN state = 'running'::/* TEMPLATE: schema */river_job_state THEN finalized_at ELSE now() END,
+        -- Mark the job as cancelled by query so that the rescuer knows not to
+        -- rescue it, even if it gets stuck in the running state:
+        metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], @cancel_attempted_at::jsonb, true)
+    FROM notification
+    WHERE river_job.id = notification.id
+    RETURNING river_job.id
+)
+SELECT
+    coalesce((SELECT max(id) FROM updated_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM updated_jobs) AS num_cancelled;
+
+-- name: JobCountByQueueKindAndState :many
+SELECT
+    queue,
+    kind,
+    state,
+    count(*) AS count
+FROM /* TEMPLATE: schema */river_job
+WHERE cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[])
+GROUP BY queue, kind, state;
+
+-- name: JobCountByState :many
+SELECT
+    state,
+    count(*) AS count
+FROM /* TEMPLATE: schema */river_job
+GROUP BY state;
+
+-- name: JobDelete :one
+WITH job_to_delete AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = @id
+    FOR UPDATE
+),
+deleted_job AS (
+    DELETE
+    FROM /* TEMPLATE: schema */river_job
+    USING job_to_delete
+    WHERE river_job.id = job_to_delete.id
+        -- Do not touch running jobs:
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id = @id::bigint
+    AND id NOT IN (SELECT id FROM deleted_job)
+UNION
+SELECT *
+FROM deleted_job;
+
 -- name: JobDeleteBefore :one
 WITH deleted_jobs AS (
-    DELETE FROM river_job
+    DELETE FROM /* TEMPLATE: schema */river_job
     WHERE id IN (
         SELECT id
-        FROM river_job
+        FROM /* TEMPLATE: schema */river_job
         WHERE
             (state = 'cancelled' AND finalized_at < @cancelled_finalized_at_horizon::timestamptz) OR
             (state = 'completed' AND finalized_at < @completed_finalized_at_horizon::timestamptz) OR
@@ -89,28 +182,352 @@ WITH deleted_jobs AS (
 SELECT count(*)
 FROM deleted_jobs;
 
+-- name: JobDeleteMany :one
+-- Deletes a batch of jobs matching a filter, skipping any that are running,
+-- selecting jobs with IDs greater than after_id in ID order so that a caller
+-- can page through all matching jobs. Returns the highest ID deleted, to be
+-- used as after_id for the next batch.
+WITH deleted_jobs AS (
+    DELETE FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (
+        SELECT id
+        FROM /* TEMPLATE: schema */river_job
+        WHERE
+            id > @after_id::bigint
+            AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after')::timestamptz)
+            AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before')::timestamptz)
+            AND (cardinality(@kinds::text[]) = 0 OR kind = any(@kinds::text[]))
+            AND (sqlc.narg('metadata_fragment')::jsonb IS NULL OR metadata @> sqlc.narg('metadata_fragment')::jsonb)
+            AND (cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[]))
+            AND (cardinality(@states::text[]) = 0 OR state::text = any(@states::text[]))
+            AND state != 'running'::/* TEMPLATE: schema */river_job_state
+        ORDER BY id
+        LIMIT @max::bigint
+        FOR UPDATE
+    )
+    RETURNING id
+)
+SELECT
+    coalesce((SELECT max(id) FROM deleted_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM deleted_jobs) AS num_deleted;
+
+-- name: JobExpire :one
+-- Discards jobs that haven't been started before their expiration time,
+-- recording an error with the reason.
+WITH job_to_expire AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        state IN ('available', 'pending', 'retryable', 'scheduled')
+        AND expires_at <= @now::timestamptz
+    ORDER BY id
+    LIMIT @max::bigint
+    FOR UPDATE
+    SKIP LOCKED
+),
+expired_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = 'discarded'::/* TEMPLATE: schema */river_job_state,
+        finalized_at = @now::timestamptz,
+        errors = array_append(
+            river_job.errors,
+            jsonb_build_object(
+                'at', @now::timestamptz,
+                'attempt', greatest(river_job.attempt, 0),
+                'error', 'Job expired before it was started',
+                'trace', ''
+            )
+        )
+    FROM job_to_expire
+    WHERE river_job.id = job_to_expire.id
+    RETURNING river_job.id
+)
+SELECT count(*)
+FROM expired_job;
+
 -- name: JobGetAvailable :many
 WITH locked_jobs AS (
     SELECT
         *
     FROM
-        river_job
+        /* TEMPLATE: schema */river_job
     WHERE
-        state = 'available'::river_job_state
+        state = 'available'::/* TEMPLATE: schema */river_job_state
         AND queue = @queue::text
         AND scheduled_at <= now()
+        AND (expires_at IS NULL OR expires_at > now())
     ORDER BY
         priority ASC,
         scheduled_at ASC,
         id ASC
-    LIMIT @max::integer
+    -- With a global max, fetch no more than the number of jobs that can be
+    -- added to those already running in the queue across all clients.
+    LIMIT CASE
+        WHEN @global_max::integer > 0 THEN
+            least(
+                @max::integer,
+                greatest(
+                    @global_max::integer - (
+                        SELECT count(*)
+                        FROM /* TEMPLATE: schema */river_job
+                        WHERE queue = @queue::text
+                            AND state = 'running'::/* TEMPLATE: schema */river_job_state
+                    ),
+                    0
+                )
+            )
+        ELSE @max::integer
+    END
     FOR UPDATE
     SKIP LOCKED
 )
 UPDATE
-    river_job
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running'::/* TEMPLATE: schema */river_job_state,
+    attempt = river_job.attempt + 1,
+    attempted_at = now(),
+    attempted_by = array_append(river_job.attempted_by, @attempted_by::text)
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.*;
+
+-- name: JobGetAvailableLimited :many
+-- A variant of JobGetAvailable that also respects concurrency limits on job
+-- kinds, optionally partitioned by values in each job's args or metadata, and
+-- maximums on the number of jobs of each kind to fetch. Available jobs are
+-- ranked within their kind and partition, and only those that fit alongside
+-- the partition's running jobs are eligible to be fetched. With a fairness
+-- key, jobs of the same priority are fetched round-robin across the values of
+-- that key in their metadata, taking into account jobs that are already
+-- running. With priority aging, jobs are ordered by an effective priority that
+-- improves the longer they've been waiting. Unlike JobGetAvailable, this
+-- considers every available job in the queue, but only the first few of each
+-- partition are carried through to the more expensive ranking that follows.
+WITH concurrency_limit AS (
+    SELECT
+        kind,
+        max,
+        partition_by_args,
+        partition_by_metadata
+    FROM jsonb_to_recordset(@concurrency_limits::jsonb)
+        AS concurrency_limit(kind text, max integer, partition_by_args jsonb, partition_by_metadata jsonb)
+),
+job_with_partition AS (
+    SELECT
+        river_job.id,
+        river_job.kind,
+        river_job.metadata ->> @fairness_key::text AS fairness_partition,
+        -- With priority aging, a job's effective priority improves by one for
+        -- every interval it's been waiting since it was scheduled, to a best
+        -- of 1.
+        CASE
+            WHEN @priority_aging_interval_seconds::double precision > 0 THEN
+                greatest(
+                    river_job.priority - floor(extract(epoch FROM now() - river_job.scheduled_at) / @priority_aging_interval_seconds::double precision)::integer,
+                    1
+                )
+            ELSE river_job.priority
+        END AS priority,
+        river_job.scheduled_at,
+        river_job.state,
+        concurrency_limit.kind AS limit_kind,
+        concurrency_limit.max AS limit_max,
+        jsonb_build_array(
+            (
+                SELECT coalesce(jsonb_agg(river_job.args -> arg_key.key ORDER BY arg_key.ordinality), '[]'::jsonb)
+                FROM jsonb_array_elements_text(concurrency_limit.partition_by_args) WITH ORDINALITY AS arg_key(key, ordinality)
+            ),
+            (
+                SELECT coalesce(jsonb_agg(river_job.metadata -> metadata_key.key ORDER BY metadata_key.ordinality), '[]'::jsonb)
+                FROM jsonb_array_elements_text(concurrency_limit.partition_by_metadata) WITH ORDINALITY AS metadata_key(key, ordinality)
+            )
+        ) AS partition_key
+    FROM
+        /* TEMPLATE: schema */river_job
+        LEFT JOIN concurrency_limit ON concurrency_limit.kind = river_job.kind
+    WHERE
+        river_job.queue = @queue::text
+        AND (
+            river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
+            OR (
+                river_job.state = 'available'::/* TEMPLATE: schema */river_job_state
+                AND river_job.scheduled_at <= now()
+                AND (river_job.expires_at IS NULL OR river_job.expires_at > now())
+            )
+        )
+),
+running_count AS (
+    SELECT
+        limit_kind,
+        partition_key,
+        count(*) AS count
+    FROM job_with_partition
+    WHERE
+        state = 'running'::/* TEMPLATE: schema */river_job_state
+        AND limit_kind IS NOT NULL
+    GROUP BY limit_kind, partition_key
+),
+fairness_running_count AS (
+    SELECT
+        fairness_partition,
+        count(*) AS count
+    FROM job_with_partition
+    WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+    GROUP BY fairness_partition
+),
+-- Of the available jobs sharing a kind, concurrency partition, and fairness
+-- partition, those ranked after the maximum number of jobs to fetch can never
+-- be fetched because every job ahead of them would be fetched first, and those
+-- ranked after the partition's concurrency limit can't be fetched either.
+-- Dropping them here bounds the number of jobs ranked below to a handful per
+-- partition instead of the whole backlog.
+candidate_jobs AS (
+    SELECT
+        id,
+        fairness_partition,
+        kind,
+        limit_kind,
+        limit_max,
+        partition_key,
+        priority,
+        scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            limit_kind,
+            limit_max,
+            partition_key,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind, partition_key, fairness_partition
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS candidate_rank
+        FROM job_with_partition
+        WHERE state = 'available'::/* TEMPLATE: schema */river_job_state
+    ) AS candidate_ranked_jobs
+    WHERE
+        candidate_rank <= @max::integer
+        AND (limit_max IS NULL OR candidate_rank <= limit_max)
+),
+concurrency_eligible_jobs AS (
+    SELECT
+        ranked_jobs.id,
+        ranked_jobs.fairness_partition,
+        ranked_jobs.kind,
+        ranked_jobs.priority,
+        ranked_jobs.scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            limit_kind,
+            limit_max,
+            partition_key,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY limit_kind, partition_key
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS partition_rank
+        FROM candidate_jobs
+    ) AS ranked_jobs
+        LEFT JOIN running_count
+            ON running_count.limit_kind = ranked_jobs.limit_kind
+            AND running_count.partition_key = ranked_jobs.partition_key
+    WHERE
+        ranked_jobs.limit_kind IS NULL
+        OR ranked_jobs.partition_rank + coalesce(running_count.count, 0) <= ranked_jobs.limit_max
+),
+kind_eligible_jobs AS (
+    SELECT
+        kind_ranked_jobs.id,
+        kind_ranked_jobs.fairness_partition,
+        kind_ranked_jobs.priority,
+        kind_ranked_jobs.scheduled_at
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            kind,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY kind
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS kind_rank
+        FROM concurrency_eligible_jobs
+    ) AS kind_ranked_jobs
+    WHERE
+        (@kind_max::jsonb ->> kind_ranked_jobs.kind) IS NULL
+        OR kind_ranked_jobs.kind_rank <= (@kind_max::jsonb ->> kind_ranked_jobs.kind)::integer
+),
+-- Jobs are ranked within their fairness partition after those of the partition
+-- that are already running, so that partitions with fewer running jobs go
+-- first. Without a fairness key, all jobs share a single partition and this is
+-- equivalent to ordering by scheduled_at and id.
+eligible_jobs AS (
+    SELECT
+        fairness_ranked_jobs.id,
+        fairness_ranked_jobs.priority,
+        fairness_ranked_jobs.scheduled_at,
+        fairness_ranked_jobs.fairness_rank + coalesce(fairness_running_count.count, 0) AS fairness_rank
+    FROM (
+        SELECT
+            id,
+            fairness_partition,
+            priority,
+            scheduled_at,
+            row_number() OVER (
+                PARTITION BY fairness_partition
+                ORDER BY priority ASC, scheduled_at ASC, id ASC
+            ) AS fairness_rank
+        FROM kind_eligible_jobs
+    ) AS fairness_ranked_jobs
+        LEFT JOIN fairness_running_count
+            ON fairness_running_count.fairness_partition IS NOT DISTINCT FROM fairness_ranked_jobs.fairness_partition
+),
+locked_jobs AS (
+    SELECT
+        river_job.*
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN eligible_jobs ON eligible_jobs.id = river_job.id
+    ORDER BY
+        eligible_jobs.priority ASC,
+        eligible_jobs.fairness_rank ASC,
+        eligible_jobs.scheduled_at ASC,
+        eligible_jobs.id ASC
+    LIMIT CASE
+        WHEN @global_max::integer > 0 THEN
+            least(
+                @max::integer,
+                greatest(
+                    @global_max::integer - (
+                        SELECT count(*)
+                        FROM job_with_partition
+                        WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+                    ),
+                    0
+                )
+            )
+        ELSE @max::integer
+    END
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
 SET
-    state = 'running'::river_job_state,
+    state = 'running'::/* TEMPLATE: schema */river_job_state,
     attempt = river_job.attempt + 1,
     attempted_at = now(),
     attempted_by = array_append(river_job.attempted_by, @attempted_by::text)
@@ -123,43 +540,56 @@ RETURNING
 
 -- name: JobGetByKindAndUniqueProperties :one
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE kind = @kind
-    AND CASE WHEN @by_args::boolean THEN args = @args ELSE true END
+    AND CASE WHEN @by_args::boolean THEN args = sqlc.narg('args')::jsonb ELSE true END
     AND CASE WHEN @by_created_at::boolean THEN tstzrange(@created_at_begin::timestamptz, @created_at_end::timestamptz, '[)') @> created_at ELSE true END
     AND CASE WHEN @by_queue::boolean THEN queue = @queue ELSE true END
     AND CASE WHEN @by_state::boolean THEN state::text = any(@state::text[]) ELSE true END;
 
 -- name: JobGetByKindMany :many
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE kind = any(@kind::text[])
 ORDER BY id;
 
 -- name: JobGetByID :one
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE id = @id
 LIMIT 1;
 
 -- name: JobGetByIDMany :many
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE id = any(@id::bigint[])
 ORDER BY id;
 
 -- name: JobGetStuck :many
 SELECT *
-FROM river_job
-WHERE state = 'running'::river_job_state
-    AND attempted_at < @stuck_horizon::timestamptz
+FROM /* TEMPLATE: schema */river_job
+WHERE state = 'running'::/* TEMPLATE: schema */river_job_state
+    AND greatest(heartbeat_at, attempted_at) < @stuck_horizon::timestamptz
 ORDER BY id
 LIMIT @max;
 
+-- name: JobHeartbeat :one
+-- Records a heartbeat for a running job, along with its progress in its
+-- metadata if one is given. Jobs that aren't running aren't updated.
+UPDATE /* TEMPLATE: schema */river_job
+SET heartbeat_at = now(),
+    metadata = CASE WHEN sqlc.narg('progress')::jsonb IS NULL THEN metadata
+                    ELSE metadata || jsonb_build_object('river:progress', sqlc.narg('progress')::jsonb)
+               END
+WHERE id = @id::bigint
+    AND state = 'running'::/* TEMPLATE: schema */river_job_state
+RETURNING *;
 
 -- name: JobInsertFast :one
-INSERT INTO river_job(
+INSERT INTO /* TEMPLATE: schema */river_job(
     args,
+    depends_on,
+    expires_at,
     finalized_at,
     kind,
     max_attempts,
@@ -171,25 +601,77 @@ INSERT INTO river_job(
     tags
 ) VALUES (
     @args::jsonb,
+    @depends_on::bigint[],
+    @expires_at,
     @finalized_at,
     @kind::text,
     @max_attempts::smallint,
-    coalesce(@metadata::jsonb, '{}'),
+    coalesce(sqlc.narg('metadata')::jsonb, '{}'),
     @priority::smallint,
     @queue::text,
     coalesce(sqlc.narg('scheduled_at')::timestamptz, now()),
-    @state::river_job_state,
+    @state::/* TEMPLATE: schema */river_job_state,
     coalesce(@tags::varchar(255)[], '{}')
 ) RETURNING *;
 
+-- name: JobInsertFastMany :execrows
+INSERT INTO /* TEMPLATE: schema */river_job(
+    args,
+    depends_on,
+    expires_at,
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state,
+    tags
+) SELECT
+    args,
+
+    -- Unnest on a multi-dimensional array "fully flattens" the array, so
+    -- dependencies and tags are each encoded as a JSON array and converted
+    -- back to a Postgres array here. No dependencies are stored as NULL.
+    nullif(array(SELECT jsonb_array_elements_text(depends_on)::bigint), '{}'),
+
+    -- Expiration times are sent as text so that jobs without one can be sent
+    -- as an empty string, which is stored as NULL.
+    nullif(expires_at, '')::timestamptz,
+
+    kind,
+    max_attempts,
+    metadata,
+    priority,
+    queue,
+    scheduled_at,
+    state::/* TEMPLATE: schema */river_job_state,
+    array(SELECT jsonb_array_elements_text(tags))
+FROM unnest(
+    @args::jsonb[],
+    @depends_on::jsonb[],
+    @expires_at::text[],
+    @kind::text[],
+    @max_attempts::smallint[],
+    @metadata::jsonb[],
+    @priority::smallint[],
+    @queue::text[],
+    @scheduled_at::timestamptz[],
+    @state::text[],
+    @tags::jsonb[]
+) AS job_params(args, depends_on, expires_at, kind, max_attempts, metadata, priority, queue, scheduled_at, state, tags);
+
 -- name: JobInsertFull :one
-INSERT INTO river_job(
+INSERT INTO /* TEMPLATE: schema */river_job(
     args,
     attempt,
     attempted_at,
     created_at,
+    depends_on,
     errors,
+    expires_at,
     finalized_at,
+    heartbeat_at,
     kind,
     max_attempts,
     metadata,
@@ -203,21 +685,143 @@ INSERT INTO river_job(
     coalesce(@attempt::smallint, 0),
     @attempted_at,
     coalesce(sqlc.narg('created_at')::timestamptz, now()),
+    @depends_on::bigint[],
     @errors::jsonb[],
+    @expires_at,
     @finalized_at,
+    @heartbeat_at,
     @kind::text,
     @max_attempts::smallint,
-    coalesce(@metadata::jsonb, '{}'),
+    coalesce(sqlc.narg('metadata')::jsonb, '{}'),
     @priority::smallint,
     @queue::text,
     coalesce(sqlc.narg('scheduled_at')::timestamptz, now()),
-    @state::river_job_state,
+    @state::/* TEMPLATE: schema */river_job_state,
     coalesce(@tags::varchar(255)[], '{}')
 ) RETURNING *;
 
+-- Run by the pending job promoter to move jobs whose dependencies have all
+-- completed out of `pending`. Jobs with a dependency that was cancelled or
+-- discarded are instead finalized with the given state. Dependencies are
+-- either job IDs in `depends_on` or, for jobs inserted as part of a workflow,
+-- the names of other tasks in the same workflow in `workflow_deps` metadata.
+--
+-- Only a batch of up to `max` pending jobs with IDs greater than `after_id` is
+-- checked, so the promoter pages through pending jobs using the returned
+-- `last_id` rather than having every call check all of them.
+-- name: JobPromotePending :one
+WITH pending_job AS (
+    SELECT
+        id,
+        depends_on,
+        metadata
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND id > @after_id::bigint
+    ORDER BY id
+    LIMIT @max::bigint
+    FOR UPDATE
+    SKIP LOCKED
+),
+pending_job_dependency AS (
+    SELECT
+        pending_job.id,
+        dependency.state AS dependency_state
+    FROM pending_job
+    JOIN /* TEMPLATE: schema */river_job AS dependency
+        ON dependency.id = any(pending_job.depends_on)
+    UNION ALL
+    SELECT
+        pending_job.id,
+        dependency.state AS dependency_state
+    FROM pending_job
+    JOIN /* TEMPLATE: schema */river_job AS dependency
+        -- Containment rather than comparing extracted values so that the
+        -- lookup can use the GIN index on metadata.
+        ON dependency.metadata @> jsonb_build_object('workflow_id', pending_job.metadata->'workflow_id')
+        AND pending_job.metadata->'workflow_deps' ? (dependency.metadata->>'workflow_task')
+    WHERE pending_job.metadata ? 'workflow_deps'
+),
+job_to_promote AS (
+    SELECT
+        pending_job.id,
+        -- Dependencies are checked to exist when a job is inserted, so one
+        -- that doesn't was deleted since (e.g. a completed job removed by the
+        -- cleaner, or any job removed with JobDelete), and is considered
+        -- satisfied.
+        coalesce(bool_or(pending_job_dependency.dependency_state IN ('cancelled', 'discarded')), false) AS dependency_failed
+    FROM pending_job
+    LEFT JOIN pending_job_dependency
+        ON pending_job_dependency.id = pending_job.id
+    GROUP BY pending_job.id
+    HAVING coalesce(bool_or(pending_job_dependency.dependency_state IN ('cancelled', 'discarded')), false)
+        OR NOT coalesce(bool_or(pending_job_dependency.dependency_state NOT IN ('cancelled', 'completed', 'discarded')), false)
+),
+promoted_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN job_to_promote.dependency_failed THEN @dependency_failed_state::/* TEMPLATE: schema */river_job_state
+                     WHEN river_job.scheduled_at > @now::timestamptz THEN 'scheduled'::/* TEMPLATE: schema */river_job_state
+                     ELSE 'available'::/* TEMPLATE: schema */river_job_state END,
+        finalized_at = CASE WHEN job_to_promote.dependency_failed THEN @now::timestamptz
+                            ELSE river_job.finalized_at END
+    FROM job_to_promote
+    WHERE river_job.id = job_to_promote.id
+    RETURNING river_job.queue, job_to_promote.dependency_failed
+)
+SELECT
+    coalesce((SELECT max(id) FROM pending_job), 0)::bigint AS last_id,
+    (SELECT count(*) FROM pending_job) AS num_checked,
+    (
+        SELECT count(*)
+        FROM (
+            SELECT pg_notify(@insert_topic, json_build_object('queue', queue)::text)
+            FROM promoted_job
+            WHERE NOT dependency_failed
+        ) AS notifications_sent
+    ) AS num_promoted,
+    (
+        SELECT count(*)
+        FROM promoted_job
+        WHERE dependency_failed
+    ) AS num_failed;
+
+-- Oldest available job and run durations of recently completed jobs for each
+-- queue. Both are narrowed to a state first so that they're served by the
+-- prioritized fetching and finalized at indexes respectively.
+-- name: JobQueueStats :many
+WITH oldest_available AS (
+    SELECT
+        queue,
+        min(scheduled_at) AS oldest_available_scheduled_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'::/* TEMPLATE: schema */river_job_state
+        AND (cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[]))
+    GROUP BY queue
+),
+run_durations AS (
+    SELECT
+        queue,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY extract(epoch FROM finalized_at - attempted_at)) AS run_duration_p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY extract(epoch FROM finalized_at - attempted_at)) AS run_duration_p95
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'completed'::/* TEMPLATE: schema */river_job_state
+        AND finalized_at >= @finalized_after::timestamptz
+        AND attempted_at IS NOT NULL
+        AND (cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[]))
+    GROUP BY queue
+)
+SELECT
+    coalesce(oldest_available.queue, run_durations.queue)::text AS queue,
+    oldest_available.oldest_available_scheduled_at::timestamptz AS oldest_available_scheduled_at,
+    coalesce(run_durations.run_duration_p50, 0)::float8 AS run_duration_p50,
+    coalesce(run_durations.run_duration_p95, 0)::float8 AS run_duration_p95
+FROM oldest_available
+FULL OUTER JOIN run_durations ON oldest_available.queue = run_durations.queue;
+
 -- Run by the rescuer to queue for retry or discard depending on job state.
 -- name: JobRescueMany :exec
-UPDATE river_job
+UPDATE /* TEMPLATE: schema */river_job
 SET
     errors = array_append(errors, updated_job.error),
     finalized_at = updated_job.finalized_at,
@@ -229,44 +833,87 @@ FROM (
         unnest(@error::jsonb[]) AS error,
         nullif(unnest(@finalized_at::timestamptz[]), '0001-01-01 00:00:00 +0000') AS finalized_at,
         unnest(@scheduled_at::timestamptz[]) AS scheduled_at,
-        unnest(@state::text[])::river_job_state AS state
+        unnest(@state::text[])::/* TEMPLATE: schema */river_job_state AS state
 ) AS updated_job
 WHERE river_job.id = updated_job.id;
 
 -- name: JobRetry :one
 WITH job_to_update AS (
     SELECT id
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE river_job.id = @id
     FOR UPDATE
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
-        state = 'available'::river_job_state,
+        state = 'available'::/* TEMPLATE: schema */river_job_state,
         scheduled_at = now(),
         max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
         finalized_at = NULL
     FROM job_to_update
     WHERE river_job.id = job_to_update.id
         -- Do not touch running jobs:
-        AND river_job.state != 'running'::river_job_state
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+        -- Or pending jobs, which must wait for their dependencies:
+        AND river_job.state != 'pending'::/* TEMPLATE: schema */river_job_state
         -- If the job is already available with a prior scheduled_at, leave it alone.
-        AND NOT (river_job.state = 'available'::river_job_state AND river_job.scheduled_at < now())
+        AND NOT (river_job.state = 'available'::/* TEMPLATE: schema */river_job_state AND river_job.scheduled_at < now())
     RETURNING river_job.*
 )
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE id = @id::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobRetryMany :one
+-- Makes a batch of jobs matching a filter available to be retried with the
+-- same rules as JobRetry, selecting jobs with IDs greater than after_id in ID
+-- order so that a caller can page through all matching jobs. Returns the
+-- highest ID retried, to be used as after_id for the next batch.
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE
+        id > @after_id::bigint
+        AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after')::timestamptz)
+        AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before')::timestamptz)
+        AND (cardinality(@kinds::text[]) = 0 OR kind = any(@kinds::text[]))
+        AND (sqlc.narg('metadata_fragment')::jsonb IS NULL OR metadata @> sqlc.narg('metadata_fragment')::jsonb)
+        AND (cardinality(@queues::text[]) = 0 OR queue = any(@queues::text[]))
+        AND (cardinality(@states::text[]) = 0 OR state::text = any(@states::text[]))
+        -- Do not touch running jobs:
+        AND state != 'running'::/* TEMPLATE: schema */river_job_state
+        -- Or pending jobs, which must wait for their dependencies:
+        AND state != 'pending'::/* TEMPLATE: schema */river_job_state
+        -- If the job is already available with a prior scheduled_at, leave it alone.
+        AND NOT (state = 'available'::/* TEMPLATE: schema */river_job_state AND scheduled_at < now())
+    ORDER BY id
+    LIMIT @max::bigint
+    FOR UPDATE
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = 'available'::/* TEMPLATE: schema */river_job_state,
+        scheduled_at = now(),
+        max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
+        finalized_at = NULL
+    FROM jobs_to_update
+    WHERE river_job.id = jobs_to_update.id
+    RETURNING river_job.id
+)
+SELECT
+    coalesce((SELECT max(id) FROM updated_jobs), 0)::bigint AS last_id,
+    (SELECT count(*) FROM updated_jobs) AS num_retried;
+
 -- name: JobSchedule :one
 WITH jobs_to_schedule AS (
     SELECT id
-    FROM river_job
+    FROM /* TEMPLATE: schema */river_job
     WHERE
         state IN ('retryable', 'scheduled')
         AND queue IS NOT NULL
@@ -280,8 +927,8 @@ WITH jobs_to_schedule AS (
     FOR UPDATE
 ),
 river_job_scheduled AS (
-    UPDATE river_job
-    SET state = 'available'::river_job_state
+    UPDATE /* TEMPLATE: schema */river_job
+    SET state = 'available'::/* TEMPLATE: schema */river_job_state
     FROM jobs_to_schedule
     WHERE river_job.id = jobs_to_schedule.id
     RETURNING *
@@ -296,42 +943,99 @@ FROM (
 WITH job_to_update AS (
     SELECT
       id,
-      @state::river_job_state IN ('retryable'::river_job_state, 'scheduled'::river_job_state) AND metadata ? 'cancel_attempted_at' AS should_cancel
-    FROM river_job
+      @state::/* TEMPLATE: schema */river_job_state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state) AND metadata ? 'cancel_attempted_at' AS should_cancel
+    FROM /* TEMPLATE: schema */river_job
     WHERE id = @id::bigint
     FOR UPDATE
 ),
 updated_job AS (
-    UPDATE river_job
+    UPDATE /* TEMPLATE: schema */river_job
     SET
-      state        = CASE WHEN should_cancel                                          THEN 'cancelled'::river_job_state
-                          ELSE @state::river_job_state END,
+      state        = CASE WHEN should_cancel                                          THEN 'cancelled'::/* TEMPLATE: schema */river_job_state
+                          ELSE @state::/* TEMPLATE: schema */river_job_state END,
       finalized_at = CASE WHEN should_cancel                                          THEN now()
                           WHEN @finalized_at_do_update::boolean                       THEN @finalized_at
                           ELSE finalized_at END,
-      errors       = CASE WHEN @error_do_update::boolean                              THEN array_append(errors, @error::jsonb)
+      errors       = CASE WHEN @error_do_update::boolean                              THEN array_append(errors, sqlc.narg('error')::jsonb)
                           ELSE errors       END,
       max_attempts = CASE WHEN NOT should_cancel AND @max_attempts_update::boolean    THEN @max_attempts
                           ELSE max_attempts END,
+      metadata     = CASE WHEN @metadata_do_merge::boolean                            THEN metadata || @metadata_updates::jsonb
+                          ELSE metadata     END,
       scheduled_at = CASE WHEN NOT should_cancel AND @scheduled_at_do_update::boolean THEN sqlc.narg('scheduled_at')::timestamptz
                           ELSE scheduled_at END
     FROM job_to_update
     WHERE river_job.id = job_to_update.id
-        AND river_job.state = 'running'::river_job_state
+        AND river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
     RETURNING river_job.*
 )
 SELECT *
-FROM river_job
+FROM /* TEMPLATE: schema */river_job
 WHERE id = @id::bigint
     AND id NOT IN (SELECT id FROM updated_job)
 UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobSetStateIfRunningMany :many
+WITH job_input AS (
+    SELECT
+        unnest(@id::bigint[]) AS id,
+        -- Sent as text[] so that users don't need to register the OID of the
+        -- river_job_state[] type with their driver.
+        unnest(@state::text[])::/* TEMPLATE: schema */river_job_state AS state,
+        unnest(@finalized_at_do_update::boolean[]) AS finalized_at_do_update,
+        unnest(@finalized_at::timestamptz[]) AS finalized_at,
+        unnest(@error_do_update::boolean[]) AS error_do_update,
+        unnest(@error::jsonb[]) AS error,
+        unnest(@max_attempts_do_update::boolean[]) AS max_attempts_do_update,
+        unnest(@max_attempts::integer[]) AS max_attempts,
+        unnest(@metadata_do_merge::boolean[]) AS metadata_do_merge,
+        unnest(@metadata_updates::jsonb[]) AS metadata_updates,
+        unnest(@scheduled_at_do_update::boolean[]) AS scheduled_at_do_update,
+        unnest(@scheduled_at::timestamptz[]) AS scheduled_at
+),
+job_to_update AS (
+    SELECT
+      job_input.*,
+      job_input.state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state) AND river_job.metadata ? 'cancel_attempted_at' AS should_cancel
+    FROM /* TEMPLATE: schema */river_job
+    JOIN job_input ON river_job.id = job_input.id
+    FOR UPDATE OF river_job
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+      state        = CASE WHEN job_to_update.should_cancel                                          THEN 'cancelled'::/* TEMPLATE: schema */river_job_state
+                          ELSE job_to_update.state END,
+      finalized_at = CASE WHEN job_to_update.should_cancel                                          THEN now()
+                          WHEN job_to_update.finalized_at_do_update                                 THEN job_to_update.finalized_at
+                          ELSE river_job.finalized_at END,
+      errors       = CASE WHEN job_to_update.error_do_update                                        THEN array_append(river_job.errors, job_to_update.error)
+                          ELSE river_job.errors END,
+      max_attempts = CASE WHEN NOT job_to_update.should_cancel AND job_to_update.max_attempts_do_update THEN job_to_update.max_attempts
+                          ELSE river_job.max_attempts END,
+      metadata     = CASE WHEN job_to_update.metadata_do_merge                                      THEN river_job.metadata || job_to_update.metadata_updates
+                          ELSE river_job.metadata END,
+      scheduled_at = CASE WHEN NOT job_to_update.should_cancel AND job_to_update.scheduled_at_do_update THEN job_to_update.scheduled_at
+                          ELSE river_job.scheduled_at END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        AND river_job.state = 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id = any(@id::bigint[])
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT *
+FROM updated_job;
+
 -- A generalized update for any property on a job. This brings in a large number
 -- of parameters and therefore may be more suitable for testing than production.
 -- name: JobUpdate :one
-UPDATE river_job
+UPDATE /* TEMPLATE: schema */river_job
 SET
     attempt = CASE WHEN @attempt_do_update::boolean THEN @attempt ELSE attempt END,
     attempted_at = CASE WHEN @attempted_at_do_update::boolean THEN @attempted_at ELSE attempted_at END,
@@ -339,4 +1043,47 @@ SET
     finalized_at = CASE WHEN @finalized_at_do_update::boolean THEN @finalized_at ELSE finalized_at END,
     state = CASE WHEN @state_do_update::boolean THEN @state ELSE state END
 WHERE id = @id
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: JobUpdateIfNotRunning :one
+WITH job_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = @id
+    FOR UPDATE
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        max_attempts = CASE WHEN @max_attempts_do_update::boolean THEN @max_attempts::smallint ELSE max_attempts END,
+        metadata = CASE WHEN @metadata_do_merge::boolean THEN metadata || @metadata::jsonb ELSE metadata END,
+        priority = CASE WHEN @priority_do_update::boolean THEN @priority::smallint ELSE priority END,
+        queue = CASE WHEN @queue_do_update::boolean THEN @queue::text ELSE queue END,
+        scheduled_at = CASE WHEN @scheduled_at_do_update::boolean THEN @scheduled_at::timestamptz ELSE scheduled_at END,
+        -- Moving a job's scheduled time moves it between available and
+        -- scheduled as appropriate. Retryable jobs moved to now or the past are
+        -- made available immediately rather than waiting for the scheduler.
+        state = CASE
+            WHEN NOT @scheduled_at_do_update::boolean THEN state
+            WHEN state IN ('retryable'::/* TEMPLATE: schema */river_job_state, 'scheduled'::/* TEMPLATE: schema */river_job_state)
+                AND @scheduled_at::timestamptz <= @now::timestamptz
+                THEN 'available'::/* TEMPLATE: schema */river_job_state
+            WHEN state = 'available'::/* TEMPLATE: schema */river_job_state
+                AND @scheduled_at::timestamptz > @now::timestamptz
+                THEN 'scheduled'::/* TEMPLATE: schema */river_job_state
+            ELSE state
+        END,
+        tags = CASE WHEN @tags_do_update::boolean THEN @tags::varchar(255)[] ELSE tags END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        -- Do not touch running jobs:
+        AND river_job.state != 'running'::/* TEMPLATE: schema */river_job_state
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id = @id::bigint
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT *
+FROM updated_job;
@@ -1,6 +1,8 @@
 -- name: JobInsertMany :copyfrom
 INSERT INTO river_job(
     args,
+    depends_on,
+    expires_at,
     finalized_at,
     kind,
     max_attempts,
@@ -12,6 +14,8 @@ INSERT INTO river_job(
     tags
 ) VALUES (
     @args,
+    @depends_on,
+    @expires_at,
     @finalized_at,
     @kind,
     @max_attempts,
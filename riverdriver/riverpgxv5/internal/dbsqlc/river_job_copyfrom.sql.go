@@ -11,6 +11,8 @@ import (
 
 type JobInsertManyParams struct {
 	Args        []byte
+	DependsOn   []int64
+	ExpiresAt   *time.Time
 	FinalizedAt *time.Time
 	Kind        string
 	MaxAttempts int16
@@ -8,39 +8,39 @@ CREATE UNLOGGED TABLE river_leader(
 );
 
 -- name: LeaderAttemptElect :execrows
-INSERT INTO river_leader(name, leader_id, elected_at, expires_at)
-    VALUES (@name::text, @leader_id::text, now(), now() + @ttl::interval)
+INSERT INTO /* TEMPLATE: schema */river_leader(name, leader_id, elected_at, expires_at)
+    VALUES (@name::text, @leader_id::text, now(), now() + make_interval(secs => @ttl::float8))
 ON CONFLICT (name)
     DO NOTHING;
 
 -- name: LeaderAttemptReelect :execrows
-INSERT INTO river_leader(name, leader_id, elected_at, expires_at)
-    VALUES (@name::text, @leader_id::text, now(), now() + @ttl::interval)
+INSERT INTO /* TEMPLATE: schema */river_leader(name, leader_id, elected_at, expires_at)
+    VALUES (@name::text, @leader_id::text, now(), now() + make_interval(secs => @ttl::float8))
 ON CONFLICT (name)
     DO UPDATE SET
-        expires_at = now() + @ttl::interval
+        expires_at = now() + make_interval(secs => @ttl::float8)
     WHERE
         river_leader.leader_id = @leader_id::text;
 
 -- name: LeaderDeleteExpired :execrows
-DELETE FROM river_leader
+DELETE FROM /* TEMPLATE: schema */river_leader
 WHERE name = @name::text
     AND expires_at < now();
 
 -- name: LeaderGetElectedLeader :one
 SELECT *
-FROM river_leader
+FROM /* TEMPLATE: schema */river_leader
 WHERE name = @name;
 
 -- name: LeaderInsert :one
-INSERT INTO river_leader(
+INSERT INTO /* TEMPLATE: schema */river_leader(
     elected_at,
     expires_at,
     leader_id,
     name
 ) VALUES (
     coalesce(sqlc.narg('elected_at')::timestamptz, now()),
-    coalesce(sqlc.narg('expires_at')::timestamptz, now() + @ttl::interval),
+    coalesce(sqlc.narg('expires_at')::timestamptz, now() + make_interval(secs => @ttl::float8)),
     @leader_id,
     @name
 ) RETURNING *;
@@ -48,7 +48,7 @@ INSERT INTO river_leader(
 -- name: LeaderResign :execrows
 WITH currently_held_leaders AS (
   SELECT *
-  FROM river_leader
+  FROM /* TEMPLATE: schema */river_leader
   WHERE
       name = @name::text
       AND leader_id = @leader_id::text
@@ -60,5 +60,5 @@ notified_resignations AS (
       currently_held_leaders.name
   FROM currently_held_leaders
 )
-DELETE FROM river_leader USING notified_resignations
+DELETE FROM /* TEMPLATE: schema */river_leader USING notified_resignations
 WHERE river_leader.name = notified_resignations.name;
\ No newline at end of file
@@ -11,8 +11,8 @@ import (
 )
 
 const leaderAttemptElect = `-- name: LeaderAttemptElect :execrows
-INSERT INTO river_leader(name, leader_id, elected_at, expires_at)
-    VALUES ($1::text, $2::text, now(), now() + $3::interval)
+INSERT INTO /* TEMPLATE: schema */river_leader(name, leader_id, elected_at, expires_at)
+    VALUES ($1::text, $2::text, now(), now() + make_interval(secs => $3::float8))
 ON CONFLICT (name)
     DO NOTHING
 `
@@ -20,7 +20,7 @@ ON CONFLICT (name)
 type LeaderAttemptElectParams struct {
 	Name     string
 	LeaderID string
-	TTL      time.Duration
+	TTL      float64
 }
 
 func (q *Queries) LeaderAttemptElect(ctx context.Context, db DBTX, arg *LeaderAttemptElectParams) (int64, error) {
@@ -32,11 +32,11 @@ func (q *Queries) LeaderAttemptElect(ctx context.Context, db DBTX, arg *LeaderAt
 }
 
 const leaderAttemptReelect = `-- name: LeaderAttemptReelect :execrows
-INSERT INTO river_leader(name, leader_id, elected_at, expires_at)
-    VALUES ($1::text, $2::text, now(), now() + $3::interval)
+INSERT INTO /* TEMPLATE: schema */river_leader(name, leader_id, elected_at, expires_at)
+    VALUES ($1::text, $2::text, now(), now() + make_interval(secs => $3::float8))
 ON CONFLICT (name)
     DO UPDATE SET
-        expires_at = now() + $3::interval
+        expires_at = now() + make_interval(secs => $3::float8)
     WHERE
         river_leader.leader_id = $2::text
 `
@@ -44,7 +44,7 @@ ON CONFLICT (name)
 type LeaderAttemptReelectParams struct {
 	Name     string
 	LeaderID string
-	TTL      time.Duration
+	TTL      float64
 }
 
 func (q *Queries) LeaderAttemptReelect(ctx context.Context, db DBTX, arg *LeaderAttemptReelectParams) (int64, error) {
@@ -56,7 +56,7 @@ func (q *Queries) LeaderAttemptReelect(ctx context.Context, db DBTX, arg *Leader
 }
 
 const leaderDeleteExpired = `-- name: LeaderDeleteExpired :execrows
-DELETE FROM river_leader
+DELETE FROM /* TEMPLATE: schema */river_leader
 WHERE name = $1::text
     AND expires_at < now()
 `
@@ -71,7 +71,7 @@ func (q *Queries) LeaderDeleteExpired(ctx context.Context, db DBTX, name string)
 
 const leaderGetElectedLeader = `-- name: LeaderGetElectedLeader :one
 SELECT elected_at, expires_at, leader_id, name
-FROM river_leader
+FROM /* TEMPLATE: schema */river_leader
 WHERE name = $1
 `
 
@@ -88,14 +88,14 @@ func (q *Queries) LeaderGetElectedLeader(ctx context.Context, db DBTX, name stri
 }
 
 const leaderInsert = `-- name: LeaderInsert :one
-INSERT INTO river_leader(
+INSERT INTO /* TEMPLATE: schema */river_leader(
     elected_at,
     expires_at,
     leader_id,
     name
 ) VALUES (
     coalesce($1::timestamptz, now()),
-    coalesce($2::timestamptz, now() + $3::interval),
+    coalesce($2::timestamptz, now() + make_interval(secs => $3::float8)),
     $4,
     $5
 ) RETURNING elected_at, expires_at, leader_id, name
@@ -104,7 +104,7 @@ INSERT INTO river_leader(
 type LeaderInsertParams struct {
 	ElectedAt *time.Time
 	ExpiresAt *time.Time
-	TTL       time.Duration
+	TTL       float64
 	LeaderID  string
 	Name      string
 }
@@ -130,7 +130,7 @@ func (q *Queries) LeaderInsert(ctx context.Context, db DBTX, arg *LeaderInsertPa
 const leaderResign = `-- name: LeaderResign :execrows
 WITH currently_held_leaders AS (
   SELECT elected_at, expires_at, leader_id, name
-  FROM river_leader
+  FROM /* TEMPLATE: schema */river_leader
   WHERE
       name = $1::text
       AND leader_id = $2::text
@@ -142,7 +142,7 @@ notified_resignations AS (
       currently_held_leaders.name
   FROM currently_held_leaders
 )
-DELETE FROM river_leader USING notified_resignations
+DELETE FROM /* TEMPLATE: schema */river_leader USING notified_resignations
 WHERE river_leader.name = notified_resignations.name
 `
 
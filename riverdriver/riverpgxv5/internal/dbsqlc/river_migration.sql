@@ -6,24 +6,24 @@ CREATE TABLE river_migration(
 );
 
 -- name: RiverMigrationDeleteByVersionMany :many
-DELETE FROM river_migration
+DELETE FROM /* TEMPLATE: schema */river_migration
 WHERE version = any(@version::bigint[])
 RETURNING *;
 
 -- name: RiverMigrationGetAll :many
 SELECT *
-FROM river_migration
+FROM /* TEMPLATE: schema */river_migration
 ORDER BY version;
 
 -- name: RiverMigrationInsert :one
-INSERT INTO river_migration (
+INSERT INTO /* TEMPLATE: schema */river_migration (
     version
 ) VALUES (
     @version
 ) RETURNING *;
 
 -- name: RiverMigrationInsertMany :many
-INSERT INTO river_migration (
+INSERT INTO /* TEMPLATE: schema */river_migration (
     version
 )
 SELECT
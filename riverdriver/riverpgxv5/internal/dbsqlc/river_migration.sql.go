@@ -10,7 +10,7 @@ import (
 )
 
 const riverMigrationDeleteByVersionMany = `-- name: RiverMigrationDeleteByVersionMany :many
-DELETE FROM river_migration
+DELETE FROM /* TEMPLATE: schema */river_migration
 WHERE version = any($1::bigint[])
 RETURNING id, created_at, version
 `
@@ -37,7 +37,7 @@ func (q *Queries) RiverMigrationDeleteByVersionMany(ctx context.Context, db DBTX
 
 const riverMigrationGetAll = `-- name: RiverMigrationGetAll :many
 SELECT id, created_at, version
-FROM river_migration
+FROM /* TEMPLATE: schema */river_migration
 ORDER BY version
 `
 
@@ -62,7 +62,7 @@ func (q *Queries) RiverMigrationGetAll(ctx context.Context, db DBTX) ([]*RiverMi
 }
 
 const riverMigrationInsert = `-- name: RiverMigrationInsert :one
-INSERT INTO river_migration (
+INSERT INTO /* TEMPLATE: schema */river_migration (
     version
 ) VALUES (
     $1
@@ -77,7 +77,7 @@ func (q *Queries) RiverMigrationInsert(ctx context.Context, db DBTX, version int
 }
 
 const riverMigrationInsertMany = `-- name: RiverMigrationInsertMany :many
-INSERT INTO river_migration (
+INSERT INTO /* TEMPLATE: schema */river_migration (
     version
 )
 SELECT
@@ -0,0 +1,70 @@
+CREATE TABLE river_queue(
+    name text PRIMARY KEY NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    metadata jsonb NOT NULL DEFAULT '{}' ::jsonb,
+    paused_at timestamptz,
+    updated_at timestamptz NOT NULL,
+    CONSTRAINT name_length CHECK (char_length(name) > 0 AND char_length(name) < 128)
+);
+
+-- name: QueueCreateOrSetUpdatedAt :one
+INSERT INTO /* TEMPLATE: schema */river_queue(
+    created_at,
+    metadata,
+    name,
+    paused_at,
+    updated_at
+) VALUES (
+    now(),
+    coalesce(sqlc.narg('metadata')::jsonb, '{}'::jsonb),
+    @name::text,
+    coalesce(sqlc.narg('paused_at')::timestamptz, NULL),
+    coalesce(sqlc.narg('updated_at')::timestamptz, now())
+) ON CONFLICT (name) DO UPDATE
+SET
+    updated_at = coalesce(sqlc.narg('updated_at')::timestamptz, now())
+RETURNING *;
+
+-- name: QueueGet :one
+SELECT *
+FROM /* TEMPLATE: schema */river_queue
+WHERE name = @name::text;
+
+-- name: QueueList :many
+SELECT *
+FROM /* TEMPLATE: schema */river_queue
+ORDER BY name ASC
+LIMIT @limit_count::integer;
+
+-- name: QueuePause :one
+INSERT INTO /* TEMPLATE: schema */river_queue(
+    created_at,
+    name,
+    paused_at,
+    updated_at
+) VALUES (
+    now(),
+    @name::text,
+    now(),
+    now()
+) ON CONFLICT (name) DO UPDATE
+SET
+    paused_at = CASE WHEN river_queue.paused_at IS NULL THEN now() ELSE river_queue.paused_at END,
+    updated_at = now()
+RETURNING *;
+
+-- name: QueueResume :one
+UPDATE /* TEMPLATE: schema */river_queue
+SET
+    paused_at = NULL,
+    updated_at = now()
+WHERE name = @name::text
+RETURNING *;
+
+-- name: QueueUpdateMetadata :one
+UPDATE /* TEMPLATE: schema */river_queue
+SET
+    metadata = (metadata - coalesce(@metadata_delete_keys::text[], '{}')) || coalesce(@metadata_updates::jsonb, '{}'),
+    updated_at = now()
+WHERE name = @name::text
+RETURNING *;
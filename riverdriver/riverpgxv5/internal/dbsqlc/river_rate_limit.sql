@@ -0,0 +1,26 @@
+CREATE TABLE river_rate_limit(
+    key text PRIMARY KEY NOT NULL,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL,
+    CONSTRAINT key_length CHECK (char_length(key) > 0 AND char_length(key) < 512)
+);
+
+-- name: RateLimitGetMany :many
+SELECT *
+FROM /* TEMPLATE: schema */river_rate_limit
+WHERE key = any(@key::text[])
+ORDER BY key;
+
+-- name: RateLimitSetMany :exec
+INSERT INTO /* TEMPLATE: schema */river_rate_limit(
+    key,
+    tokens,
+    updated_at
+) SELECT
+    unnest(@key::text[]),
+    unnest(@tokens::float8[]),
+    unnest(@updated_at::timestamptz[])
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = EXCLUDED.tokens,
+    updated_at = EXCLUDED.updated_at;
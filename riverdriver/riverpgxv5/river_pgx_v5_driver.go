@@ -7,13 +7,16 @@ package riverpgxv5
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/riverqueue/river/riverdriver"
@@ -23,14 +26,17 @@ import (
 
 // Driver is an implementation of riverdriver.Driver for Pgx v5.
 type Driver struct {
-	dbPool  *pgxpool.Pool
-	queries *dbsqlc.Queries
+	dbPool   *pgxpool.Pool
+	queries  *dbsqlc.Queries
+	replacer *strings.Replacer
+	schema   string
 }
 
 // New returns a new Pgx v5 River driver for use with River.
 //
-// It takes a pgxpool.Pool to use for use with River. The pool should already be
-// configured to use the schema specified in the client's Schema field. The pool
+// It takes a pgxpool.Pool to use for use with River. River's tables are found
+// through the pool's search_path unless a schema is set with WithSchema (which
+// River's client does automatically when its Schema option is set). The pool
 // must not be closed while associated River objects are running.
 //
 // The database pool may be nil. If it is, a client that it's sent into will not
@@ -40,23 +46,39 @@ type Driver struct {
 // in testing so that inserts can be performed and verified on a test
 // transaction that will be rolled back.
 func New(dbPool *pgxpool.Pool) *Driver {
-	return &Driver{dbPool: dbPool, queries: dbsqlc.New()}
+	return &Driver{dbPool: dbPool, queries: dbsqlc.New(), replacer: riverdriver.SchemaReplacer("")}
 }
 
-func (d *Driver) GetExecutor() riverdriver.Executor { return &Executor{d.dbPool, dbsqlc.New()} }
-func (d *Driver) GetListener() riverdriver.Listener { return &Listener{dbPool: d.dbPool} }
-func (d *Driver) HasPool() bool                     { return d.dbPool != nil }
+func (d *Driver) GetExecutor() riverdriver.Executor {
+	return newExecutor(d.dbPool, d.replacer, d.schema)
+}
+func (d *Driver) GetListener() riverdriver.Listener {
+	return &Listener{dbPool: d.dbPool, schema: d.schema}
+}
+func (d *Driver) HasPool() bool { return d.dbPool != nil }
 
 func (d *Driver) UnwrapExecutor(tx pgx.Tx) riverdriver.ExecutorTx {
-	return &ExecutorTx{Executor: Executor{tx, dbsqlc.New()}, tx: tx}
+	return &ExecutorTx{Executor: *newExecutor(tx, d.replacer, d.schema), tx: tx}
+}
+
+func (d *Driver) WithSchema(schema string) riverdriver.Driver[pgx.Tx] {
+	return &Driver{dbPool: d.dbPool, queries: d.queries, replacer: riverdriver.SchemaReplacer(schema), schema: schema}
 }
 
 type Executor struct {
-	dbtx interface {
-		dbsqlc.DBTX
-		Begin(ctx context.Context) (pgx.Tx, error)
+	dbtx     dbtxWithBegin
+	queries  *dbsqlc.Queries
+	replacer *strings.Replacer
+	schema   string
+}
+
+func newExecutor(dbtx dbtxWithBegin, replacer *strings.Replacer, schema string) *Executor {
+	return &Executor{
+		dbtx:     &templateDBTX{dbtx: dbtx, replacer: replacer, schema: schema},
+		queries:  dbsqlc.New(),
+		replacer: replacer,
+		schema:   schema,
 	}
-	queries *dbsqlc.Queries
 }
 
 func (e *Executor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
@@ -64,7 +86,7 @@ func (e *Executor) Begin(ctx context.Context) (riverdriver.ExecutorTx, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ExecutorTx{Executor: Executor{tx, e.queries}, tx: tx}, nil
+	return &ExecutorTx{Executor: *newExecutor(tx, e.replacer, e.schema), tx: tx}, nil
 }
 
 func (e *Executor) Exec(ctx context.Context, sql string) (struct{}, error) {
@@ -81,7 +103,7 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 	job, err := e.queries.JobCancel(ctx, e.dbtx, &dbsqlc.JobCancelParams{
 		ID:                params.ID,
 		CancelAttemptedAt: cancelledAt,
-		JobControlTopic:   params.JobControlTopic,
+		JobControlTopic:   riverdriver.SchemaTopic(e.schema, params.JobControlTopic),
 	})
 	if err != nil {
 		return nil, interpretError(err)
@@ -89,6 +111,75 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 	return jobRowFromInternal(job), nil
 }
 
+func (e *Executor) JobCancelMany(ctx context.Context, params *riverdriver.JobCancelManyParams) (*riverdriver.JobManyResult, error) {
+	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobCancelMany(ctx, e.dbtx, &dbsqlc.JobCancelManyParams{
+		AfterID:           params.AfterID,
+		CancelAttemptedAt: cancelledAt,
+		CreatedAfter:      filter.CreatedAfter,
+		CreatedBefore:     filter.CreatedBefore,
+		JobControlTopic:   riverdriver.SchemaTopic(e.schema, params.JobControlTopic),
+		Kinds:             filter.Kinds,
+		Max:               int64(params.Max),
+		MetadataFragment:  filter.MetadataFragment,
+		Queues:            filter.Queues,
+		States:            filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumCancelled)}, nil
+}
+
+func (e *Executor) JobCountByQueueKindAndState(ctx context.Context, params *riverdriver.JobCountByQueueKindAndStateParams) ([]*riverdriver.JobCountByQueueKindAndState, error) {
+	queues := params.Queues
+	if queues == nil {
+		queues = []string{}
+	}
+
+	rows, err := e.queries.JobCountByQueueKindAndState(ctx, e.dbtx, queues)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(rows, func(row *dbsqlc.JobCountByQueueKindAndStateRow) *riverdriver.JobCountByQueueKindAndState {
+		return &riverdriver.JobCountByQueueKindAndState{
+			Count: int(row.Count),
+			Kind:  row.Kind,
+			Queue: row.Queue,
+			State: rivertype.JobState(row.State),
+		}
+	}), nil
+}
+
+func (e *Executor) JobCountByState(ctx context.Context) (map[rivertype.JobState]int, error) {
+	rows, err := e.queries.JobCountByState(ctx, e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	countByState := make(map[rivertype.JobState]int, len(rows))
+	for _, row := range rows {
+		countByState[rivertype.JobState(row.State)] = int(row.Count)
+	}
+	return countByState, nil
+}
+
+func (e *Executor) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	job, err := e.queries.JobDelete(ctx, e.dbtx, id)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	if job.State == "running" {
+		return nil, rivertype.ErrJobRunning
+	}
+	return jobRowFromInternal(job), nil
+}
+
 func (e *Executor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobDeleteBeforeParams) (int, error) {
 	numDeleted, err := e.queries.JobDeleteBefore(ctx, e.dbtx, &dbsqlc.JobDeleteBeforeParams{
 		CancelledFinalizedAtHorizon: params.CancelledFinalizedAtHorizon,
@@ -99,9 +190,70 @@ func (e *Executor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobD
 	return int(numDeleted), interpretError(err)
 }
 
+func (e *Executor) JobDeleteMany(ctx context.Context, params *riverdriver.JobDeleteManyParams) (*riverdriver.JobManyResult, error) {
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobDeleteMany(ctx, e.dbtx, &dbsqlc.JobDeleteManyParams{
+		AfterID:          params.AfterID,
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		Max:              int64(params.Max),
+		MetadataFragment: filter.MetadataFragment,
+		Queues:           filter.Queues,
+		States:           filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumDeleted)}, nil
+}
+
+func (e *Executor) JobExpire(ctx context.Context, params *riverdriver.JobExpireParams) (int, error) {
+	numExpired, err := e.queries.JobExpire(ctx, e.dbtx, &dbsqlc.JobExpireParams{
+		Max: int64(params.Max),
+		Now: params.Now,
+	})
+	return int(numExpired), interpretError(err)
+}
+
 func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	if len(params.ConcurrencyLimits) > 0 || params.FairnessKey != "" || len(params.KindMax) > 0 || params.PriorityAgingInterval > 0 {
+		// Both are marshaled from non-nil values so that they're sent as
+		// empty JSON collections rather than null.
+		concurrencyLimitsParam := params.ConcurrencyLimits
+		if concurrencyLimitsParam == nil {
+			concurrencyLimitsParam = []*riverdriver.JobGetAvailableConcurrencyLimit{}
+		}
+		concurrencyLimits, err := json.Marshal(concurrencyLimitsParam)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling concurrency limits: %w", err)
+		}
+
+		kindMaxParam := params.KindMax
+		if kindMaxParam == nil {
+			kindMaxParam = map[string]int{}
+		}
+		kindMax, err := json.Marshal(kindMaxParam)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling kind max: %w", err)
+		}
+
+		jobs, err := e.queries.JobGetAvailableLimited(ctx, e.dbtx, &dbsqlc.JobGetAvailableLimitedParams{
+			AttemptedBy:                  params.AttemptedBy,
+			ConcurrencyLimits:            concurrencyLimits,
+			FairnessKey:                  params.FairnessKey,
+			GlobalMax:                    int32(params.GlobalMax),
+			KindMax:                      kindMax,
+			Max:                          int32(params.Max),
+			PriorityAgingIntervalSeconds: params.PriorityAgingInterval.Seconds(),
+			Queue:                        params.Queue,
+		})
+		return mapSlice(jobs, jobRowFromInternal), interpretError(err)
+	}
+
 	jobs, err := e.queries.JobGetAvailable(ctx, e.dbtx, &dbsqlc.JobGetAvailableParams{
 		AttemptedBy: params.AttemptedBy,
+		GlobalMax:   int32(params.GlobalMax),
 		Max:         int32(params.Max),
 		Queue:       params.Queue,
 	})
@@ -145,8 +297,18 @@ func (e *Executor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetSt
 	return mapSlice(jobs, jobRowFromInternal), interpretError(err)
 }
 
+func (e *Executor) JobHeartbeat(ctx context.Context, params *riverdriver.JobHeartbeatParams) (*rivertype.JobRow, error) {
+	job, err := e.queries.JobHeartbeat(ctx, e.dbtx, &dbsqlc.JobHeartbeatParams{ID: params.ID, Progress: params.Progress})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job), nil
+}
+
 func (e *Executor) JobInsertFast(ctx context.Context, params *riverdriver.JobInsertFastParams) (*rivertype.JobRow, error) {
 	job, err := e.queries.JobInsertFast(ctx, e.dbtx, &dbsqlc.JobInsertFastParams{
+		DependsOn:   params.DependsOn,
+		ExpiresAt:   params.ExpiresAt,
 		Args:        params.EncodedArgs,
 		Kind:        params.Kind,
 		MaxAttempts: int16(min(params.MaxAttempts, math.MaxInt16)),
@@ -187,6 +349,8 @@ func (e *Executor) JobInsertFastMany(ctx context.Context, params []*riverdriver.
 
 		insertJobsParams[i] = &dbsqlc.JobInsertManyParams{
 			Args:        params.EncodedArgs,
+			DependsOn:   params.DependsOn,
+			ExpiresAt:   params.ExpiresAt,
 			Kind:        params.Kind,
 			MaxAttempts: int16(min(params.MaxAttempts, math.MaxInt16)),
 			Metadata:    metadata,
@@ -212,8 +376,11 @@ func (e *Executor) JobInsertFull(ctx context.Context, params *riverdriver.JobIns
 		AttemptedAt: params.AttemptedAt,
 		Args:        params.EncodedArgs,
 		CreatedAt:   params.CreatedAt,
+		DependsOn:   params.DependsOn,
 		Errors:      params.Errors,
+		ExpiresAt:   params.ExpiresAt,
 		FinalizedAt: params.FinalizedAt,
+		HeartbeatAt: params.HeartbeatAt,
 		Kind:        params.Kind,
 		MaxAttempts: int16(min(params.MaxAttempts, math.MaxInt16)),
 		Metadata:    params.Metadata,
@@ -256,6 +423,9 @@ func (e *Executor) JobList(ctx context.Context, sql string, namedArgs map[string
 			&i.State,
 			&i.ScheduledAt,
 			&i.Tags,
+			&i.DependsOn,
+			&i.ExpiresAt,
+			&i.HeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -269,7 +439,7 @@ func (e *Executor) JobList(ctx context.Context, sql string, namedArgs map[string
 }
 
 func (e *Executor) JobListFields() string {
-	return "id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags"
+	return "id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, depends_on, expires_at, heartbeat_at"
 }
 
 func (e *Executor) JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, error) {
@@ -280,6 +450,72 @@ func (e *Executor) JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, e
 	return jobRowFromInternal(job), nil
 }
 
+func (e *Executor) JobRetryMany(ctx context.Context, params *riverdriver.JobRetryManyParams) (*riverdriver.JobManyResult, error) {
+	filter := jobFilterToInternal(params.Filter)
+	res, err := e.queries.JobRetryMany(ctx, e.dbtx, &dbsqlc.JobRetryManyParams{
+		AfterID:          params.AfterID,
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		Max:              int64(params.Max),
+		MetadataFragment: filter.MetadataFragment,
+		Queues:           filter.Queues,
+		States:           filter.States,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobManyResult{LastID: res.LastID, NumJobs: int(res.NumRetried)}, nil
+}
+
+func (e *Executor) JobPromotePending(ctx context.Context, params *riverdriver.JobPromotePendingParams) (*riverdriver.JobPromotePendingResult, error) {
+	res, err := e.queries.JobPromotePending(ctx, e.dbtx, &dbsqlc.JobPromotePendingParams{
+		AfterID:               params.AfterID,
+		DependencyFailedState: dbsqlc.RiverJobState(params.DependencyFailedState),
+		InsertTopic:           riverdriver.SchemaTopic(e.schema, params.InsertTopic),
+		Max:                   int64(params.Max),
+		Now:                   params.Now,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return &riverdriver.JobPromotePendingResult{
+		LastID:      res.LastID,
+		NumChecked:  int(res.NumChecked),
+		NumFailed:   int(res.NumFailed),
+		NumPromoted: int(res.NumPromoted),
+	}, nil
+}
+
+func (e *Executor) JobQueueStats(ctx context.Context, params *riverdriver.JobQueueStatsParams) ([]*riverdriver.JobQueueStats, error) {
+	queues := params.Queues
+	if queues == nil {
+		queues = []string{}
+	}
+
+	rows, err := e.queries.JobQueueStats(ctx, e.dbtx, &dbsqlc.JobQueueStatsParams{
+		FinalizedAfter: params.FinalizedAfter,
+		Queues:         queues,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(rows, func(row *dbsqlc.JobQueueStatsRow) *riverdriver.JobQueueStats {
+		var oldestAvailableScheduledAt *time.Time
+		if row.OldestAvailableScheduledAt != nil {
+			t := row.OldestAvailableScheduledAt.UTC()
+			oldestAvailableScheduledAt = &t
+		}
+
+		return &riverdriver.JobQueueStats{
+			OldestAvailableScheduledAt: oldestAvailableScheduledAt,
+			Queue:                      row.Queue,
+			RunDurationP50:             time.Duration(row.RunDurationP50 * float64(time.Second)),
+			RunDurationP95:             time.Duration(row.RunDurationP95 * float64(time.Second)),
+		}
+	}), nil
+}
+
 func (e *Executor) JobRescueMany(ctx context.Context, params *riverdriver.JobRescueManyParams) (*struct{}, error) {
 	err := e.queries.JobRescueMany(ctx, e.dbtx, (*dbsqlc.JobRescueManyParams)(params))
 	return &struct{}{}, interpretError(err)
@@ -287,7 +523,7 @@ func (e *Executor) JobRescueMany(ctx context.Context, params *riverdriver.JobRes
 
 func (e *Executor) JobSchedule(ctx context.Context, params *riverdriver.JobScheduleParams) (int, error) {
 	numScheduled, err := e.queries.JobSchedule(ctx, e.dbtx, &dbsqlc.JobScheduleParams{
-		InsertTopic: params.InsertTopic,
+		InsertTopic: riverdriver.SchemaTopic(e.schema, params.InsertTopic),
 		Max:         int64(params.Max),
 		Now:         params.Now,
 	})
@@ -308,6 +544,8 @@ func (e *Executor) JobSetStateIfRunning(ctx context.Context, params *riverdriver
 		FinalizedAt:         params.FinalizedAt,
 		MaxAttemptsUpdate:   params.MaxAttempts != nil,
 		MaxAttempts:         maxAttempts,
+		MetadataDoMerge:     params.MetadataUpdates != nil,
+		MetadataUpdates:     params.MetadataUpdates,
 		ScheduledAtDoUpdate: params.ScheduledAt != nil,
 		ScheduledAt:         params.ScheduledAt,
 		State:               dbsqlc.RiverJobState(params.State),
@@ -318,6 +556,55 @@ func (e *Executor) JobSetStateIfRunning(ctx context.Context, params *riverdriver
 	return jobRowFromInternal(job), nil
 }
 
+func (e *Executor) JobSetStateIfRunningMany(ctx context.Context, params []*riverdriver.JobSetStateIfRunningParams) ([]*rivertype.JobRow, error) {
+	setStateParams := &dbsqlc.JobSetStateIfRunningManyParams{
+		ID:                  make([]int64, len(params)),
+		State:               make([]string, len(params)),
+		FinalizedAtDoUpdate: make([]bool, len(params)),
+		FinalizedAt:         make([]time.Time, len(params)),
+		ErrorDoUpdate:       make([]bool, len(params)),
+		Error:               make([][]byte, len(params)),
+		MaxAttemptsDoUpdate: make([]bool, len(params)),
+		MaxAttempts:         make([]int32, len(params)),
+		MetadataDoMerge:     make([]bool, len(params)),
+		MetadataUpdates:     make([][]byte, len(params)),
+		ScheduledAtDoUpdate: make([]bool, len(params)),
+		ScheduledAt:         make([]time.Time, len(params)),
+	}
+
+	for i, params := range params {
+		setStateParams.ID[i] = params.ID
+		setStateParams.State[i] = string(params.State)
+
+		if params.FinalizedAt != nil {
+			setStateParams.FinalizedAtDoUpdate[i] = true
+			setStateParams.FinalizedAt[i] = *params.FinalizedAt
+		}
+		if params.ErrData != nil {
+			setStateParams.ErrorDoUpdate[i] = true
+			setStateParams.Error[i] = params.ErrData
+		}
+		if params.MaxAttempts != nil {
+			setStateParams.MaxAttemptsDoUpdate[i] = true
+			setStateParams.MaxAttempts[i] = int32(min(*params.MaxAttempts, math.MaxInt32))
+		}
+		if params.MetadataUpdates != nil {
+			setStateParams.MetadataDoMerge[i] = true
+			setStateParams.MetadataUpdates[i] = params.MetadataUpdates
+		}
+		if params.ScheduledAt != nil {
+			setStateParams.ScheduledAtDoUpdate[i] = true
+			setStateParams.ScheduledAt[i] = *params.ScheduledAt
+		}
+	}
+
+	jobs, err := e.queries.JobSetStateIfRunningMany(ctx, e.dbtx, setStateParams)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(jobs, jobRowFromInternal), nil
+}
+
 func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateParams) (*rivertype.JobRow, error) {
 	job, err := e.queries.JobUpdate(ctx, e.dbtx, &dbsqlc.JobUpdateParams{
 		ID:                  params.ID,
@@ -339,11 +626,44 @@ func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateP
 	return jobRowFromInternal(job), nil
 }
 
+func (e *Executor) JobUpdateIfNotRunning(ctx context.Context, params *riverdriver.JobUpdateIfNotRunningParams) (*rivertype.JobRow, error) {
+	// Metadata is cast to jsonb even when it's not merged, so make sure it's
+	// always valid JSON.
+	metadata := params.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	job, err := e.queries.JobUpdateIfNotRunning(ctx, e.dbtx, &dbsqlc.JobUpdateIfNotRunningParams{
+		ID:                  params.ID,
+		MaxAttemptsDoUpdate: params.MaxAttemptsDoUpdate,
+		MaxAttempts:         int16(min(params.MaxAttempts, math.MaxInt16)),
+		MetadataDoMerge:     params.MetadataDoMerge,
+		Metadata:            metadata,
+		Now:                 params.Now,
+		PriorityDoUpdate:    params.PriorityDoUpdate,
+		Priority:            int16(min(params.Priority, math.MaxInt16)),
+		QueueDoUpdate:       params.QueueDoUpdate,
+		Queue:               params.Queue,
+		ScheduledAtDoUpdate: params.ScheduledAtDoUpdate,
+		ScheduledAt:         params.ScheduledAt,
+		TagsDoUpdate:        params.TagsDoUpdate,
+		Tags:                params.Tags,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	if job.State == "running" {
+		return nil, rivertype.ErrJobRunning
+	}
+	return jobRowFromInternal(job), nil
+}
+
 func (e *Executor) LeaderAttemptElect(ctx context.Context, params *riverdriver.LeaderElectParams) (bool, error) {
 	numElectionsWon, err := e.queries.LeaderAttemptElect(ctx, e.dbtx, &dbsqlc.LeaderAttemptElectParams{
 		Name:     params.Name,
 		LeaderID: params.LeaderID,
-		TTL:      params.TTL,
+		TTL:      params.TTL.Seconds(),
 	})
 	if err != nil {
 		return false, interpretError(err)
@@ -355,7 +675,7 @@ func (e *Executor) LeaderAttemptReelect(ctx context.Context, params *riverdriver
 	numElectionsWon, err := e.queries.LeaderAttemptReelect(ctx, e.dbtx, &dbsqlc.LeaderAttemptReelectParams{
 		Name:     params.Name,
 		LeaderID: params.LeaderID,
-		TTL:      params.TTL,
+		TTL:      params.TTL.Seconds(),
 	})
 	if err != nil {
 		return false, interpretError(err)
@@ -385,7 +705,7 @@ func (e *Executor) LeaderInsert(ctx context.Context, params *riverdriver.LeaderI
 		ExpiresAt: params.ExpiresAt,
 		LeaderID:  params.LeaderID,
 		Name:      params.Name,
-		TTL:       params.TTL,
+		TTL:       params.TTL.Seconds(),
 	})
 	if err != nil {
 		return nil, interpretError(err)
@@ -396,7 +716,7 @@ func (e *Executor) LeaderInsert(ctx context.Context, params *riverdriver.LeaderI
 func (e *Executor) LeaderResign(ctx context.Context, params *riverdriver.LeaderResignParams) (bool, error) {
 	numResigned, err := e.queries.LeaderResign(ctx, e.dbtx, &dbsqlc.LeaderResignParams{
 		LeaderID:        params.LeaderID,
-		LeadershipTopic: params.LeadershipTopic,
+		LeadershipTopic: riverdriver.SchemaTopic(e.schema, params.LeadershipTopic),
 		Name:            params.Name,
 	})
 	if err != nil {
@@ -434,7 +754,7 @@ func (e *Executor) MigrationInsertMany(ctx context.Context, versions []int) ([]*
 func (e *Executor) Notify(ctx context.Context, topic string, payload string) error {
 	return e.queries.PGNotify(ctx, e.dbtx, &dbsqlc.PGNotifyParams{
 		Payload: payload,
-		Topic:   topic,
+		Topic:   riverdriver.SchemaTopic(e.schema, topic),
 	})
 }
 
@@ -443,7 +763,92 @@ func (e *Executor) PGAdvisoryXactLock(ctx context.Context, key int64) (*struct{}
 	return &struct{}{}, interpretError(err)
 }
 
+func (e *Executor) QueueCreateOrSetUpdatedAt(ctx context.Context, params *riverdriver.QueueCreateOrSetUpdatedAtParams) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueCreateOrSetUpdatedAt(ctx, e.dbtx, &dbsqlc.QueueCreateOrSetUpdatedAtParams{
+		Metadata:  params.Metadata,
+		Name:      params.Name,
+		PausedAt:  params.PausedAt,
+		UpdatedAt: params.UpdatedAt,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueGet(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueGet(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueList(ctx context.Context, limit int) ([]*rivertype.Queue, error) {
+	queues, err := e.queries.QueueList(ctx, e.dbtx, int32(limit))
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(queues, queueFromInternal), nil
+}
+
+func (e *Executor) QueuePause(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueuePause(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueResume(ctx context.Context, name string) (*rivertype.Queue, error) {
+	queue, err := e.queries.QueueResume(ctx, e.dbtx, name)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) QueueUpdateMetadata(ctx context.Context, params *riverdriver.QueueUpdateMetadataParams) (*rivertype.Queue, error) {
+	// The metadata updates param isn't nullable because merging in null would
+	// null the whole column, so default it to an empty object.
+	metadataUpdates := params.MetadataUpdates
+	if metadataUpdates == nil {
+		metadataUpdates = []byte("{}")
+	}
+
+	queue, err := e.queries.QueueUpdateMetadata(ctx, e.dbtx, &dbsqlc.QueueUpdateMetadataParams{
+		MetadataDeleteKeys: params.MetadataDeleteKeys,
+		MetadataUpdates:    metadataUpdates,
+		Name:               params.Name,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return queueFromInternal(queue), nil
+}
+
+func (e *Executor) RateLimitGetMany(ctx context.Context, keys []string) ([]*riverdriver.RateLimitBucket, error) {
+	buckets, err := e.queries.RateLimitGetMany(ctx, e.dbtx, keys)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSlice(buckets, rateLimitBucketFromInternal), nil
+}
+
+func (e *Executor) RateLimitSetMany(ctx context.Context, params *riverdriver.RateLimitSetManyParams) error {
+	err := e.queries.RateLimitSetMany(ctx, e.dbtx, &dbsqlc.RateLimitSetManyParams{
+		Key:       params.Key,
+		Tokens:    params.Tokens,
+		UpdatedAt: params.UpdatedAt,
+	})
+	return interpretError(err)
+}
+
 func (e *Executor) TableExists(ctx context.Context, tableName string) (bool, error) {
+	if e.schema != "" {
+		tableName = pgx.Identifier{e.schema, tableName}.Sanitize()
+	}
+
 	exists, err := e.queries.TableExists(ctx, e.dbtx, tableName)
 	return exists, interpretError(err)
 }
@@ -465,6 +870,7 @@ type Listener struct {
 	conn   *pgxpool.Conn
 	dbPool *pgxpool.Pool
 	mu     sync.RWMutex
+	schema string
 }
 
 func (l *Listener) Close(ctx context.Context) error {
@@ -504,7 +910,7 @@ func (l *Listener) Listen(ctx context.Context, topic string) error {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	_, err := l.conn.Exec(ctx, "LISTEN "+topic)
+	_, err := l.conn.Exec(ctx, "LISTEN "+pgx.Identifier{riverdriver.SchemaTopic(l.schema, topic)}.Sanitize())
 	return err
 }
 
@@ -519,7 +925,7 @@ func (l *Listener) Unlisten(ctx context.Context, topic string) error {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	_, err := l.conn.Exec(ctx, "UNLISTEN "+topic)
+	_, err := l.conn.Exec(ctx, "UNLISTEN "+pgx.Identifier{riverdriver.SchemaTopic(l.schema, topic)}.Sanitize())
 	return err
 }
 
@@ -533,11 +939,49 @@ func (l *Listener) WaitForNotification(ctx context.Context) (*riverdriver.Notifi
 	}
 
 	return &riverdriver.Notification{
-		Topic:   notification.Channel,
+		Topic:   riverdriver.SchemaTopicUnscoped(l.schema, notification.Channel),
 		Payload: notification.Payload,
 	}, nil
 }
 
+// dbtxWithBegin is a database pool or transaction.
+type dbtxWithBegin interface {
+	dbsqlc.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// templateDBTX wraps a database pool or transaction, filling in the schema
+// templates in River's SQL (see riverdriver.SchemaTemplate) before any of it
+// is sent to Postgres. Transactions started from it are returned unwrapped.
+type templateDBTX struct {
+	dbtx     dbtxWithBegin
+	replacer *strings.Replacer
+	schema   string
+}
+
+func (t *templateDBTX) Begin(ctx context.Context) (pgx.Tx, error) {
+	return t.dbtx.Begin(ctx)
+}
+
+func (t *templateDBTX) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	if t.schema != "" && len(tableName) == 1 {
+		tableName = pgx.Identifier{t.schema, tableName[0]}
+	}
+	return t.dbtx.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (t *templateDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.dbtx.Exec(ctx, t.replacer.Replace(sql), args...)
+}
+
+func (t *templateDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return t.dbtx.Query(ctx, t.replacer.Replace(sql), args...)
+}
+
+func (t *templateDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return t.dbtx.QueryRow(ctx, t.replacer.Replace(sql), args...)
+}
+
 func attemptErrorFromInternal(e *dbsqlc.AttemptError) rivertype.AttemptError {
 	return rivertype.AttemptError{
 		At:      e.At.UTC(),
@@ -554,6 +998,44 @@ func interpretError(err error) error {
 	return err
 }
 
+// jobFilter is a riverdriver.JobFilter prepared to be sent as query
+// parameters. Arrays are never nil so that they're sent as empty arrays rather
+// than NULL, which the filter's conditions don't account for.
+type jobFilter struct {
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	Kinds            []string
+	MetadataFragment []byte
+	Queues           []string
+	States           []string
+}
+
+func jobFilterToInternal(filter *riverdriver.JobFilter) *jobFilter {
+	if filter == nil {
+		filter = &riverdriver.JobFilter{}
+	}
+
+	internal := &jobFilter{
+		CreatedAfter:     filter.CreatedAfter,
+		CreatedBefore:    filter.CreatedBefore,
+		Kinds:            filter.Kinds,
+		MetadataFragment: filter.MetadataFragment,
+		Queues:           filter.Queues,
+		States:           make([]string, len(filter.States)),
+	}
+	if internal.Kinds == nil {
+		internal.Kinds = []string{}
+	}
+	if internal.Queues == nil {
+		internal.Queues = []string{}
+	}
+	for i, state := range filter.States {
+		internal.States[i] = string(state)
+	}
+
+	return internal
+}
+
 func jobRowFromInternal(internal *dbsqlc.RiverJob) *rivertype.JobRow {
 	var attemptedAt *time.Time
 	if internal.AttemptedAt != nil {
@@ -561,21 +1043,36 @@ func jobRowFromInternal(internal *dbsqlc.RiverJob) *rivertype.JobRow {
 		attemptedAt = &t
 	}
 
+	var expiresAt *time.Time
+	if internal.ExpiresAt != nil {
+		t := internal.ExpiresAt.UTC()
+		expiresAt = &t
+	}
+
 	var finalizedAt *time.Time
 	if internal.FinalizedAt != nil {
 		t := internal.FinalizedAt.UTC()
 		finalizedAt = &t
 	}
 
+	var heartbeatAt *time.Time
+	if internal.HeartbeatAt != nil {
+		t := internal.HeartbeatAt.UTC()
+		heartbeatAt = &t
+	}
+
 	return &rivertype.JobRow{
 		ID:          internal.ID,
 		Attempt:     max(int(internal.Attempt), 0),
 		AttemptedAt: attemptedAt,
 		AttemptedBy: internal.AttemptedBy,
 		CreatedAt:   internal.CreatedAt.UTC(),
+		DependsOn:   internal.DependsOn,
 		EncodedArgs: internal.Args,
 		Errors:      mapSlice(internal.Errors, func(e dbsqlc.AttemptError) rivertype.AttemptError { return attemptErrorFromInternal(&e) }),
+		ExpiresAt:   expiresAt,
 		FinalizedAt: finalizedAt,
+		HeartbeatAt: heartbeatAt,
 		Kind:        internal.Kind,
 		MaxAttempts: max(int(internal.MaxAttempts), 0),
 		Metadata:    internal.Metadata,
@@ -618,3 +1115,26 @@ func migrationFromInternal(internal *dbsqlc.RiverMigration) *riverdriver.Migrati
 		Version:   int(internal.Version),
 	}
 }
+
+func queueFromInternal(internal *dbsqlc.RiverQueue) *rivertype.Queue {
+	var pausedAt *time.Time
+	if internal.PausedAt != nil {
+		t := internal.PausedAt.UTC()
+		pausedAt = &t
+	}
+	return &rivertype.Queue{
+		CreatedAt: internal.CreatedAt.UTC(),
+		Metadata:  internal.Metadata,
+		Name:      internal.Name,
+		PausedAt:  pausedAt,
+		UpdatedAt: internal.UpdatedAt.UTC(),
+	}
+}
+
+func rateLimitBucketFromInternal(internal *dbsqlc.RiverRateLimit) *riverdriver.RateLimitBucket {
+	return &riverdriver.RateLimitBucket{
+		Key:       internal.Key,
+		Tokens:    internal.Tokens,
+		UpdatedAt: internal.UpdatedAt.UTC(),
+	}
+}
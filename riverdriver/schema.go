@@ -0,0 +1,62 @@
+package riverdriver
+
+import "strings"
+
+const (
+	// SchemaTemplate is a placeholder found in River's SQL in front of every
+	// reference to one of its tables, types, or functions. Drivers replace it
+	// with a quoted schema name followed by a dot, or with nothing when no
+	// schema is configured, in which case Postgres falls back to search_path.
+	//
+	// API is not stable. DO NOT USE.
+	SchemaTemplate = "/* TEMPLATE: schema */"
+
+	// TopicPrefixTemplate is a placeholder found in River's SQL in front of
+	// notification topics that are emitted from within Postgres (e.g. by the
+	// insert trigger). Drivers replace it with the schema name followed by a
+	// dot so that installations in different schemas don't receive each
+	// other's notifications.
+	//
+	// API is not stable. DO NOT USE.
+	TopicPrefixTemplate = "/* TEMPLATE: topic_prefix */"
+)
+
+// SchemaReplacer returns a replacer that fills in SchemaTemplate and
+// TopicPrefixTemplate in SQL for the given schema. An empty schema removes the
+// templates, leaving table references unqualified.
+//
+// API is not stable. DO NOT USE.
+func SchemaReplacer(schema string) *strings.Replacer {
+	if schema == "" {
+		return strings.NewReplacer(SchemaTemplate, "", TopicPrefixTemplate, "")
+	}
+
+	return strings.NewReplacer(
+		SchemaTemplate, `"`+strings.ReplaceAll(schema, `"`, `""`)+`".`,
+		TopicPrefixTemplate, strings.ReplaceAll(SchemaTopic(schema, ""), "'", "''"),
+	)
+}
+
+// SchemaTopic returns the name of a notification topic as scoped to the given
+// schema. An empty schema returns the topic unchanged.
+//
+// API is not stable. DO NOT USE.
+func SchemaTopic(schema, topic string) string {
+	if schema == "" {
+		return topic
+	}
+
+	return schema + "." + topic
+}
+
+// SchemaTopicUnscoped reverses SchemaTopic, returning the name of a
+// notification topic with its schema prefix removed.
+//
+// API is not stable. DO NOT USE.
+func SchemaTopicUnscoped(schema, topic string) string {
+	if schema == "" {
+		return topic
+	}
+
+	return strings.TrimPrefix(topic, schema+".")
+}
@@ -0,0 +1,32 @@
+ALTER TABLE /* TEMPLATE: schema */river_job DROP COLUMN depends_on;
+
+-- Postgres doesn't support removing a value from an enum, so the type has to be
+-- recreated without `pending` and the state column migrated over to it. Any
+-- pending jobs left are made available because there's no longer any way to
+-- track their dependencies. State is compared as text because a new enum value
+-- can't be referenced in the transaction that added it, which is the case if
+-- this migration is run along with its up migration.
+UPDATE /* TEMPLATE: schema */river_job SET state = 'available' WHERE state::text = 'pending';
+
+ALTER TYPE /* TEMPLATE: schema */river_job_state RENAME TO river_job_state_old;
+
+CREATE TYPE /* TEMPLATE: schema */river_job_state AS ENUM(
+  'available',
+  'cancelled',
+  'completed',
+  'discarded',
+  'retryable',
+  'running',
+  'scheduled'
+);
+
+ALTER TABLE /* TEMPLATE: schema */river_job DROP CONSTRAINT finalized_or_finalized_at_null;
+
+ALTER TABLE /* TEMPLATE: schema */river_job
+  ALTER COLUMN state DROP DEFAULT,
+  ALTER COLUMN state TYPE /* TEMPLATE: schema */river_job_state USING state::text::/* TEMPLATE: schema */river_job_state,
+  ALTER COLUMN state SET DEFAULT 'available' ::/* TEMPLATE: schema */river_job_state;
+
+ALTER TABLE /* TEMPLATE: schema */river_job ADD CONSTRAINT finalized_or_finalized_at_null CHECK ((state IN ('cancelled', 'completed', 'discarded') AND finalized_at IS NOT NULL) OR finalized_at IS NULL);
+
+DROP TYPE /* TEMPLATE: schema */river_job_state_old;
@@ -0,0 +1,9 @@
+-- Jobs inserted with dependencies start in `pending` and are promoted to
+-- `available` (or `scheduled`) once all the jobs they depend on have completed.
+--
+-- Note that because migrations run in a single transaction, the new value
+-- can't be used anywhere else in this migration (or any migration that runs
+-- along with it).
+ALTER TYPE /* TEMPLATE: schema */river_job_state ADD VALUE IF NOT EXISTS 'pending' AFTER 'discarded';
+
+ALTER TABLE /* TEMPLATE: schema */river_job ADD COLUMN depends_on bigint[];
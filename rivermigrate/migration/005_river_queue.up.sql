@@ -0,0 +1,12 @@
+-- Queues are tracked in their own table so that state like whether a queue is
+-- paused persists across client restarts. Rows are upserted by each producer
+-- as it starts, so a queue appears here once any client has worked it.
+CREATE TABLE /* TEMPLATE: schema */river_queue(
+  name text PRIMARY KEY NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT NOW(),
+  metadata jsonb NOT NULL DEFAULT '{}' ::jsonb,
+  paused_at timestamptz,
+  updated_at timestamptz NOT NULL,
+
+  CONSTRAINT name_length CHECK (char_length(name) > 0 AND char_length(name) < 128)
+);
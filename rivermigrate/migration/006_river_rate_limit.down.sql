@@ -0,0 +1 @@
+DROP TABLE /* TEMPLATE: schema */river_rate_limit;
@@ -0,0 +1,10 @@
+-- Rate limit buckets that are shared by all clients, for rate limits that are
+-- enforced cluster-wide. Each row holds the state of a token bucket, which is
+-- refilled based on the time elapsed since it was last updated.
+CREATE TABLE /* TEMPLATE: schema */river_rate_limit(
+  key text PRIMARY KEY NOT NULL,
+  tokens double precision NOT NULL,
+  updated_at timestamptz NOT NULL,
+
+  CONSTRAINT key_length CHECK (char_length(key) > 0 AND char_length(key) < 512)
+);
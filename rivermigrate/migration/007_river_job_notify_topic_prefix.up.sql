@@ -0,0 +1,20 @@
+-- Scope insert notifications to the schema River is installed in (when one is
+-- configured) so that installations in different schemas of the same database
+-- don't receive each other's notifications.
+CREATE OR REPLACE FUNCTION /* TEMPLATE: schema */river_job_notify()
+  RETURNS TRIGGER
+  AS $$
+DECLARE
+  payload json;
+BEGIN
+  IF NEW.state = 'available' THEN
+    -- Notify will coalesce duplicate notificiations within a transaction, so
+    -- keep these payloads generalized:
+    payload = json_build_object('queue', NEW.queue);
+    PERFORM
+      pg_notify('/* TEMPLATE: topic_prefix */river_insert', payload::text);
+  END IF;
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
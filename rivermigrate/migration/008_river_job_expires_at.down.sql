@@ -0,0 +1 @@
+ALTER TABLE /* TEMPLATE: schema */river_job DROP COLUMN expires_at;
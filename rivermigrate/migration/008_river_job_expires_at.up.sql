@@ -0,0 +1,6 @@
+-- Deadline before which a job must be started, after which it's discarded
+-- instead. Most jobs don't have one, so only those that do are indexed for the
+-- expirer to find.
+ALTER TABLE /* TEMPLATE: schema */river_job ADD COLUMN expires_at timestamptz;
+
+CREATE INDEX river_job_expires_at_index ON /* TEMPLATE: schema */river_job USING btree(expires_at) WHERE expires_at IS NOT NULL;
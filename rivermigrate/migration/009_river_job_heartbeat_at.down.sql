@@ -0,0 +1 @@
+ALTER TABLE /* TEMPLATE: schema */river_job DROP COLUMN heartbeat_at;
@@ -0,0 +1,3 @@
+-- Time of a running job's last heartbeat, which the rescuer uses in place of
+-- the time it was attempted to decide whether it's stuck.
+ALTER TABLE /* TEMPLATE: schema */river_job ADD COLUMN heartbeat_at timestamptz;
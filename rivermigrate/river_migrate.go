@@ -47,6 +47,12 @@ type Config struct {
 	// specified, logs will be emitted to STDOUT with messages at warn level
 	// or higher.
 	Logger *slog.Logger
+
+	// Schema is the name of the Postgres schema in which River's tables should
+	// be raised or lowered. The schema must already exist. If left empty,
+	// tables are found through the connection's search_path. Clients using
+	// these tables should be configured with the same schema.
+	Schema string
 }
 
 // Migrator is a database migration tool for River which can run up or down
@@ -56,6 +62,7 @@ type Migrator[TTx any] struct {
 
 	driver     riverdriver.Driver[TTx]
 	migrations map[int]*migrationBundle // allows us to inject test migrations
+	schema     string
 }
 
 // New returns a new migrator with the given database driver and configuration.
@@ -99,9 +106,14 @@ func New[TTx any](driver riverdriver.Driver[TTx], config *Config) *Migrator[TTx]
 		TimeNowUTC: func() time.Time { return time.Now().UTC() },
 	}
 
+	if config.Schema != "" {
+		driver = driver.WithSchema(config.Schema)
+	}
+
 	return baseservice.Init(archetype, &Migrator[TTx]{
 		driver:     driver,
 		migrations: riverMigrationsMap,
+		schema:     config.Schema,
 	})
 }
 
@@ -390,6 +402,21 @@ func (m *Migrator[TTx]) applyMigrations(ctx context.Context, exec riverdriver.Ex
 		return res, nil
 	}
 
+	// The earliest migrations predate schema support and reference River's
+	// tables without qualifying them, so point search_path at the configured
+	// schema while migrations run, then put it back. The original value is
+	// saved in a transaction-local setting rather than reset to the default
+	// so that a search_path set by the caller of MigrateTx earlier in the same
+	// transaction survives.
+	if m.schema != "" {
+		if _, err := exec.Exec(ctx, "SELECT set_config('river.search_path_before_migrate', current_setting('search_path'), true)"); err != nil {
+			return nil, fmt.Errorf("error saving search_path: %w", err)
+		}
+		if _, err := exec.Exec(ctx, "SET LOCAL search_path TO "+quoteIdentifier(m.schema)); err != nil {
+			return nil, fmt.Errorf("error setting search_path: %w", err)
+		}
+	}
+
 	for _, versionBundle := range sortedTargetMigrations {
 		sql := versionBundle.Up
 		if direction == DirectionDown {
@@ -410,6 +437,12 @@ func (m *Migrator[TTx]) applyMigrations(ctx context.Context, exec riverdriver.Ex
 		res.Versions = append(res.Versions, MigrateVersion{Version: versionBundle.Version})
 	}
 
+	if m.schema != "" {
+		if _, err := exec.Exec(ctx, "SELECT set_config('search_path', current_setting('river.search_path_before_migrate'), true)"); err != nil {
+			return nil, fmt.Errorf("error restoring search_path: %w", err)
+		}
+	}
+
 	// Only prints if more steps than available were requested.
 	if opts.MaxSteps > 0 && len(res.Versions) < opts.MaxSteps {
 		m.Logger.InfoContext(ctx, m.Name+": No more migrations to apply")
@@ -554,3 +587,7 @@ func validateAndInit(versions []*migrationBundle) map[int]*migrationBundle {
 
 	return migrations
 }
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
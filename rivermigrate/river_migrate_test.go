@@ -118,7 +118,7 @@ func TestMigrator(t *testing.T) {
 			res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{})
 			require.NoError(t, err)
 			require.Equal(t, DirectionDown, res.Direction)
-			require.Equal(t, []int{3}, sliceutil.Map(res.Versions, migrateVersionToInt))
+			require.Equal(t, []int{riverMigrationsMaxVersion}, sliceutil.Map(res.Versions, migrateVersionToInt))
 
 			err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT * FROM river_job")
 			require.NoError(t, err)
@@ -129,7 +129,19 @@ func TestMigrator(t *testing.T) {
 			res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{})
 			require.NoError(t, err)
 			require.Equal(t, DirectionDown, res.Direction)
-			require.Equal(t, []int{2}, sliceutil.Map(res.Versions, migrateVersionToInt))
+			require.Equal(t, []int{riverMigrationsMaxVersion - 1}, sliceutil.Map(res.Versions, migrateVersionToInt))
+
+			migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
+			require.NoError(t, err)
+			require.Equal(t, seqOneTo(riverMigrationsMaxVersion-2), sliceutil.Map(migrations, migrationToInt))
+		}
+
+		// Go the rest of the way down to version 2, which removes river_job.
+		{
+			res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: 1})
+			require.NoError(t, err)
+			require.Equal(t, DirectionDown, res.Direction)
+			require.Equal(t, 2, res.Versions[len(res.Versions)-1].Version)
 
 			err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT * FROM river_job")
 			require.Error(t, err)
@@ -185,7 +197,7 @@ func TestMigrator(t *testing.T) {
 
 		migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
 		require.NoError(t, err)
-		require.Equal(t, seqOneTo(3),
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion),
 			sliceutil.Map(migrations, migrationToInt))
 	})
 
@@ -197,11 +209,11 @@ func TestMigrator(t *testing.T) {
 
 		res, err := migrator.MigrateTx(ctx, tx, DirectionDown, &MigrateOpts{MaxSteps: 1})
 		require.NoError(t, err)
-		require.Equal(t, []int{3}, sliceutil.Map(res.Versions, migrateVersionToInt))
+		require.Equal(t, []int{riverMigrationsMaxVersion}, sliceutil.Map(res.Versions, migrateVersionToInt))
 
 		migrations, err := migrator.driver.UnwrapExecutor(tx).MigrationGetAll(ctx)
 		require.NoError(t, err)
-		require.Equal(t, seqOneTo(2),
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion-1),
 			sliceutil.Map(migrations, migrationToInt))
 	})
 
@@ -213,14 +225,14 @@ func TestMigrator(t *testing.T) {
 		_, err := migrator.MigrateTx(ctx, bundle.tx, DirectionUp, &MigrateOpts{})
 		require.NoError(t, err)
 
-		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: 3})
+		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: riverMigrationsMaxVersion})
 		require.NoError(t, err)
-		require.Equal(t, []int{5, 4},
+		require.Equal(t, []int{riverMigrationsWithTestVersionsMaxVersion, riverMigrationsWithTestVersionsMaxVersion - 1},
 			sliceutil.Map(res.Versions, migrateVersionToInt))
 
 		migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
 		require.NoError(t, err)
-		require.Equal(t, seqOneTo(3),
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion),
 			sliceutil.Map(migrations, migrationToInt))
 
 		err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT name FROM test_table")
@@ -237,7 +249,7 @@ func TestMigrator(t *testing.T) {
 
 		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: -1})
 		require.NoError(t, err)
-		require.Equal(t, seqToOne(5),
+		require.Equal(t, seqToOne(riverMigrationsWithTestVersionsMaxVersion),
 			sliceutil.Map(res.Versions, migrateVersionToInt))
 
 		err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT name FROM river_migrate")
@@ -257,8 +269,8 @@ func TestMigrator(t *testing.T) {
 
 		// migration exists but not one that's applied
 		{
-			_, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: 4})
-			require.EqualError(t, err, "version 4 is not in target list of valid migrations to apply")
+			_, err := migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: riverMigrationsMaxVersion + 1})
+			require.EqualError(t, err, fmt.Sprintf("version %d is not in target list of valid migrations to apply", riverMigrationsMaxVersion+1))
 		}
 	})
 
@@ -269,7 +281,7 @@ func TestMigrator(t *testing.T) {
 
 		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionUp, nil)
 		require.NoError(t, err)
-		require.Equal(t, []int{4, 5}, sliceutil.Map(res.Versions, migrateVersionToInt))
+		require.Equal(t, []int{riverMigrationsMaxVersion + 1, riverMigrationsMaxVersion + 2}, sliceutil.Map(res.Versions, migrateVersionToInt))
 	})
 
 	t.Run("MigrateUpDefault", func(t *testing.T) {
@@ -349,7 +361,7 @@ func TestMigrator(t *testing.T) {
 
 		migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
 		require.NoError(t, err)
-		require.Equal(t, seqOneTo(3),
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion),
 			sliceutil.Map(migrations, migrationToInt))
 	})
 
@@ -369,19 +381,69 @@ func TestMigrator(t *testing.T) {
 			sliceutil.Map(migrations, migrationToInt))
 	})
 
+	t.Run("MigrateUpWithSchema", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		_, err := bundle.tx.Exec(ctx, "CREATE SCHEMA custom_schema")
+		require.NoError(t, err)
+
+		migrator := New(bundle.driver, &Config{Logger: bundle.logger, Schema: "custom_schema"})
+
+		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionUp, &MigrateOpts{})
+		require.NoError(t, err)
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion), sliceutil.Map(res.Versions, migrateVersionToInt))
+
+		err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT * FROM custom_schema.river_job")
+		require.NoError(t, err)
+
+		res, err = migrator.MigrateTx(ctx, bundle.tx, DirectionDown, &MigrateOpts{TargetVersion: -1})
+		require.NoError(t, err)
+		require.Equal(t, seqToOne(riverMigrationsMaxVersion), sliceutil.Map(res.Versions, migrateVersionToInt))
+
+		err = dbExecError(ctx, bundle.driver.UnwrapExecutor(bundle.tx), "SELECT * FROM custom_schema.river_job")
+		require.Error(t, err)
+
+		// River's tables in the default schema are left untouched.
+		migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
+		require.NoError(t, err)
+		require.Equal(t, seqOneTo(riverMigrationsMaxVersion), sliceutil.Map(migrations, migrationToInt))
+	})
+
+	t.Run("MigrateWithSchemaRestoresSearchPath", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		_, err := bundle.tx.Exec(ctx, "CREATE SCHEMA custom_schema")
+		require.NoError(t, err)
+		_, err = bundle.tx.Exec(ctx, "SET LOCAL search_path TO other_schema, public")
+		require.NoError(t, err)
+
+		migrator := New(bundle.driver, &Config{Logger: bundle.logger, Schema: "custom_schema"})
+
+		_, err = migrator.MigrateTx(ctx, bundle.tx, DirectionUp, &MigrateOpts{})
+		require.NoError(t, err)
+
+		var searchPath string
+		require.NoError(t, bundle.tx.QueryRow(ctx, "SHOW search_path").Scan(&searchPath))
+		require.Equal(t, "other_schema, public", searchPath)
+	})
+
 	t.Run("MigrateUpWithTargetVersion", func(t *testing.T) {
 		t.Parallel()
 
 		migrator, bundle := setup(t)
 
-		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionUp, &MigrateOpts{TargetVersion: 5})
+		res, err := migrator.MigrateTx(ctx, bundle.tx, DirectionUp, &MigrateOpts{TargetVersion: riverMigrationsWithTestVersionsMaxVersion})
 		require.NoError(t, err)
-		require.Equal(t, []int{4, 5},
+		require.Equal(t, []int{riverMigrationsMaxVersion + 1, riverMigrationsMaxVersion + 2},
 			sliceutil.Map(res.Versions, migrateVersionToInt))
 
 		migrations, err := bundle.driver.UnwrapExecutor(bundle.tx).MigrationGetAll(ctx)
 		require.NoError(t, err)
-		require.Equal(t, seqOneTo(5), sliceutil.Map(migrations, migrationToInt))
+		require.Equal(t, seqOneTo(riverMigrationsWithTestVersionsMaxVersion), sliceutil.Map(migrations, migrationToInt))
 	})
 
 	t.Run("MigrateUpWithTargetVersionInvalid", func(t *testing.T) {
@@ -4,6 +4,7 @@
 package rivertype
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 )
@@ -13,6 +14,10 @@ import (
 // return this error.
 var ErrNotFound = errors.New("not found")
 
+// ErrJobRunning is returned when a job is running and can't be acted upon,
+// like when attempting to delete it.
+var ErrJobRunning = errors.New("job is running")
+
 // JobRow contains the properties of a job that are persisted to the database.
 // Use of `Job[T]` will generally be preferred in user-facing code like worker
 // interfaces.
@@ -37,6 +42,11 @@ type JobRow struct {
 	// CreatedAt is when the job record was created.
 	CreatedAt time.Time
 
+	// DependsOn are the IDs of jobs that must complete before this job becomes
+	// eligible to be worked. A job with dependencies is inserted as `pending`
+	// and promoted once all of its dependencies have completed.
+	DependsOn []int64
+
 	// EncodedArgs is the job's JobArgs encoded as JSON.
 	EncodedArgs []byte
 
@@ -44,11 +54,20 @@ type JobRow struct {
 	// each attempt. Ordered from earliest error to the latest error.
 	Errors []AttemptError
 
+	// ExpiresAt is a deadline before which the job must be started, as set with
+	// river.InsertOpts.ExpiresAt, or nil if the job has none. Jobs that haven't
+	// started by their deadline are discarded.
+	ExpiresAt *time.Time
+
 	// FinalizedAt is the time at which the job was "finalized", meaning it was
 	// either completed successfully or errored for the last time such that
 	// it'll no longer be retried.
 	FinalizedAt *time.Time
 
+	// HeartbeatAt is the time of the last heartbeat recorded by the job's
+	// worker with river.JobHeartbeat, or nil if the job hasn't recorded any.
+	HeartbeatAt *time.Time
+
 	// Kind uniquely identifies the type of job and instructs which worker
 	// should work it. It is set at insertion time via `Kind()` on the
 	// `JobArgs`.
@@ -98,8 +117,35 @@ type JobRow struct {
 	Tags []string
 }
 
-// JobState is the state of a job. Jobs start as `available` or `scheduled`, and
-// if all goes well eventually transition to `completed` as they're worked.
+// Output returns the JSON-encoded output recorded by the job's worker with
+// river.RecordOutput, or nil if the job hasn't recorded any. Output is stored
+// in the job's metadata under the `river:output` key.
+func (j *JobRow) Output() []byte {
+	var metadata struct {
+		Output json.RawMessage `json:"river:output"`
+	}
+	if err := json.Unmarshal(j.Metadata, &metadata); err != nil {
+		return nil
+	}
+	return metadata.Output
+}
+
+// Progress returns the JSON-encoded progress last reported by the job's worker
+// with river.JobHeartbeatWithProgress, or nil if the job hasn't reported any.
+// Progress is stored in the job's metadata under the `river:progress` key.
+func (j *JobRow) Progress() []byte {
+	var metadata struct {
+		Progress json.RawMessage `json:"river:progress"`
+	}
+	if err := json.Unmarshal(j.Metadata, &metadata); err != nil {
+		return nil
+	}
+	return metadata.Progress
+}
+
+// JobState is the state of a job. Jobs start as `available`, `pending`, or
+// `scheduled`, and if all goes well eventually transition to `completed` as
+// they're worked.
 type JobState string
 
 const (
@@ -107,6 +153,7 @@ const (
 	JobStateCancelled JobState = "cancelled"
 	JobStateCompleted JobState = "completed"
 	JobStateDiscarded JobState = "discarded"
+	JobStatePending   JobState = "pending"
 	JobStateRetryable JobState = "retryable"
 	JobStateRunning   JobState = "running"
 	JobStateScheduled JobState = "scheduled"
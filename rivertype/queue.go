@@ -0,0 +1,28 @@
+package rivertype
+
+import "time"
+
+// Queue is a queue that is currently (or recently was) in use by a client,
+// along with its persisted settings.
+type Queue struct {
+	// CreatedAt is the time at which the queue first began being worked by a
+	// client.
+	CreatedAt time.Time
+
+	// Metadata is a field for storing arbitrary metadata on a queue. It's
+	// currently reserved for River's internal use and should not be modified by
+	// users.
+	Metadata []byte
+
+	// Name is the name of the queue.
+	Name string
+
+	// PausedAt is the time the queue was paused, if any. When a paused queue is
+	// resumed, this field is set to nil.
+	PausedAt *time.Time
+
+	// UpdatedAt is the last time the queue was updated. It's bumped
+	// periodically while any client is configured to work the queue, even if
+	// the queue is paused.
+	UpdatedAt time.Time
+}
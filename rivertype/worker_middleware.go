@@ -0,0 +1,16 @@
+package rivertype
+
+import "context"
+
+// WorkerMiddleware is an interface that wraps the execution of a job's Work
+// function. Middleware can be used to add cross-cutting behavior like tracing
+// spans, log context, or metrics around every job that's worked.
+//
+// Implementations should invoke doInner to continue execution down the chain,
+// optionally with a context derived from the one they were given. Returning
+// an error without invoking doInner short circuits the chain so that neither
+// the job's Work function nor any further middleware is run, and the error
+// is treated exactly as if it'd been returned from Work.
+type WorkerMiddleware interface {
+	Work(ctx context.Context, job *JobRow, doInner func(ctx context.Context) error) error
+}
@@ -0,0 +1,53 @@
+package river
+
+import (
+	"context"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// WorkerMiddlewareFunc is a function that implements the
+// rivertype.WorkerMiddleware interface so that a plain function can be used as
+// middleware:
+//
+//	config := &river.Config{
+//		WorkerMiddleware: []rivertype.WorkerMiddleware{
+//			river.WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+//				ctx = context.WithValue(ctx, jobIDKey{}, job.ID)
+//				return doInner(ctx)
+//			}),
+//		},
+//	}
+type WorkerMiddlewareFunc func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error
+
+// Work invokes the function with the given arguments.
+func (f WorkerMiddlewareFunc) Work(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+	return f(ctx, job, doInner)
+}
+
+// WorkerWithMiddleware is an optional extension to the Worker interface that
+// lets a worker provide middleware that applies only to jobs of its kind.
+// Worker-specific middleware runs inside of any middleware configured on the
+// Client (see Config.WorkerMiddleware), so it's the last to run before the
+// worker's Work function is invoked.
+type WorkerWithMiddleware[T JobArgs] interface {
+	// Middleware returns middleware to run around the given job. It's invoked
+	// after the job's args have been unmarshaled.
+	Middleware(job *Job[T]) []rivertype.WorkerMiddleware
+}
+
+// workerMiddlewareChain composes the given middleware around doInner such
+// that the first middleware in the list is the outermost, and the last is the
+// innermost and the closest to doInner.
+func workerMiddlewareChain(middleware []rivertype.WorkerMiddleware, jobRow *rivertype.JobRow, doInner func(ctx context.Context) error) func(ctx context.Context) error {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		var (
+			mw    = middleware[i]
+			inner = doInner
+		)
+		doInner = func(ctx context.Context) error {
+			return mw.Work(ctx, jobRow, inner)
+		}
+	}
+	return doInner
+}
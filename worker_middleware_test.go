@@ -0,0 +1,126 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestWorkerMiddlewareChain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type ctxKey string
+
+	recordingMiddleware := func(name string, calls *[]string) rivertype.WorkerMiddleware {
+		return WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+			*calls = append(*calls, name+"_before")
+			err := doInner(ctx)
+			*calls = append(*calls, name+"_after")
+			return err
+		})
+	}
+
+	t.Run("NoMiddleware", func(t *testing.T) {
+		t.Parallel()
+
+		var innerCalled bool
+		err := workerMiddlewareChain(nil, &rivertype.JobRow{}, func(ctx context.Context) error {
+			innerCalled = true
+			return nil
+		})(ctx)
+		require.NoError(t, err)
+		require.True(t, innerCalled)
+	})
+
+	t.Run("InvokedInOrder", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		err := workerMiddlewareChain([]rivertype.WorkerMiddleware{
+			recordingMiddleware("first", &calls),
+			recordingMiddleware("second", &calls),
+		}, &rivertype.JobRow{}, func(ctx context.Context) error {
+			calls = append(calls, "inner")
+			return nil
+		})(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"first_before", "second_before", "inner", "second_after", "first_after"}, calls)
+	})
+
+	t.Run("ReceivesJobRow", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{ID: 123}
+
+		var receivedJobRow *rivertype.JobRow
+		err := workerMiddlewareChain([]rivertype.WorkerMiddleware{
+			WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				receivedJobRow = job
+				return doInner(ctx)
+			}),
+		}, jobRow, func(ctx context.Context) error { return nil })(ctx)
+		require.NoError(t, err)
+		require.Equal(t, jobRow, receivedJobRow)
+	})
+
+	t.Run("ModifiesContext", func(t *testing.T) {
+		t.Parallel()
+
+		var innerVal any
+		err := workerMiddlewareChain([]rivertype.WorkerMiddleware{
+			WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				return doInner(context.WithValue(ctx, ctxKey("key"), "value"))
+			}),
+		}, &rivertype.JobRow{}, func(ctx context.Context) error {
+			innerVal = ctx.Value(ctxKey("key"))
+			return nil
+		})(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "value", innerVal)
+	})
+
+	t.Run("ShortCircuitsWithError", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls       []string
+			innerCalled bool
+			rejectErr   = errors.New("rejected by middleware")
+		)
+		err := workerMiddlewareChain([]rivertype.WorkerMiddleware{
+			recordingMiddleware("first", &calls),
+			WorkerMiddlewareFunc(func(ctx context.Context, job *rivertype.JobRow, doInner func(ctx context.Context) error) error {
+				return rejectErr
+			}),
+			recordingMiddleware("third", &calls),
+		}, &rivertype.JobRow{}, func(ctx context.Context) error {
+			innerCalled = true
+			return nil
+		})(ctx)
+		require.ErrorIs(t, err, rejectErr)
+		require.False(t, innerCalled)
+		require.Equal(t, []string{"first_before", "first_after"}, calls)
+	})
+
+	t.Run("PropagatesInnerError", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls    []string
+			innerErr = errors.New("inner error")
+		)
+		err := workerMiddlewareChain([]rivertype.WorkerMiddleware{
+			recordingMiddleware("first", &calls),
+		}, &rivertype.JobRow{}, func(ctx context.Context) error {
+			return innerErr
+		})(ctx)
+		require.ErrorIs(t, err, innerErr)
+		require.Equal(t, []string{"first_before", "first_after"}, calls)
+	})
+}
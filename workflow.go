@@ -0,0 +1,310 @@
+package river
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Metadata keys under which a workflow task's information is stored on its
+// job. The pending job promoter relies on these to resolve dependencies.
+const (
+	metadataKeyWorkflowDeps = "workflow_deps"
+	metadataKeyWorkflowID   = "workflow_id"
+	metadataKeyWorkflowName = "workflow_name"
+	metadataKeyWorkflowTask = "workflow_task"
+)
+
+// WorkflowOpts are optional settings for a new workflow.
+type WorkflowOpts struct {
+	// ID is a unique identifier for the workflow. It's stored in the metadata
+	// of each of the workflow's jobs and is used to look the workflow up again
+	// with Client.WorkflowGet or Client.WorkflowCancel.
+	//
+	// Defaults to a newly generated ULID.
+	ID string
+
+	// Name is an optional human-readable name for the workflow.
+	Name string
+}
+
+// WorkflowTaskOpts are optional settings for a task added to a workflow.
+type WorkflowTaskOpts struct {
+	// Deps are the names of other tasks in the same workflow that must complete
+	// before this task becomes eligible to be worked. A task with dependencies
+	// is inserted in the `pending` state and is promoted once all of them have
+	// completed. If any dependency is cancelled or discarded instead, the task
+	// is finalized according to the client's Config.DependencyFailureAction.
+	Deps []string
+}
+
+// Workflow is a set of jobs (called tasks) with dependencies between them,
+// forming a directed acyclic graph that's inserted in a single operation.
+// Tasks are added to a workflow with Add, after which Prepare produces the
+// parameters to insert it with Client.InsertMany or Client.InsertManyTx:
+//
+//	workflow := river.NewWorkflow(&river.WorkflowOpts{Name: "my_workflow"})
+//	workflow.Add("a", TaskArgs{}, nil, nil)
+//	workflow.Add("b", TaskArgs{}, nil, &river.WorkflowTaskOpts{Deps: []string{"a"}})
+//	workflow.Add("c", TaskArgs{}, nil, &river.WorkflowTaskOpts{Deps: []string{"a"}})
+//	workflow.Add("d", TaskArgs{}, nil, &river.WorkflowTaskOpts{Deps: []string{"b", "c"}})
+//
+//	params, err := workflow.Prepare()
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	if _, err := client.InsertManyTx(ctx, tx, params); err != nil {
+//		// handle error
+//	}
+//
+// Each task's job is tagged with the workflow's ID in its metadata, and tasks
+// with dependencies wait in the `pending` state until their dependencies have
+// completed.
+type Workflow struct {
+	id    string
+	name  string
+	tasks []*workflowTaskParams
+}
+
+type workflowTaskParams struct {
+	args       JobArgs
+	deps       []string
+	insertOpts *InsertOpts
+	name       string
+}
+
+// NewWorkflow initializes a new workflow. Opts may be nil to accept defaults.
+func NewWorkflow(opts *WorkflowOpts) *Workflow {
+	if opts == nil {
+		opts = &WorkflowOpts{}
+	}
+
+	id := opts.ID
+	if id == "" {
+		id = ulid.Make().String()
+	}
+
+	return &Workflow{
+		id:   id,
+		name: opts.Name,
+	}
+}
+
+// ID returns the workflow's ID, which can be used to look it up again with
+// Client.WorkflowGet.
+func (w *Workflow) ID() string { return w.id }
+
+// Add adds a task to the workflow with the given name, which must be unique
+// within the workflow. Insert opts are applied to the task's job like they
+// would be for Client.Insert, and task opts may be used to specify the names of
+// tasks that it depends on. Both opts may be nil.
+//
+// Tasks are validated when the workflow is prepared with Prepare.
+func (w *Workflow) Add(taskName string, args JobArgs, insertOpts *InsertOpts, taskOpts *WorkflowTaskOpts) {
+	if taskOpts == nil {
+		taskOpts = &WorkflowTaskOpts{}
+	}
+
+	w.tasks = append(w.tasks, &workflowTaskParams{
+		args:       args,
+		deps:       taskOpts.Deps,
+		insertOpts: insertOpts,
+		name:       taskName,
+	})
+}
+
+// Prepare validates the workflow and returns a set of parameters that'll
+// insert it when passed to Client.InsertMany or Client.InsertManyTx. It returns
+// an error if a task name is empty or duplicated, if a task depends on a task
+// that's not part of the workflow, or if the workflow's dependencies contain a
+// cycle.
+//
+// Params are returned in an order in which every task comes after all of its
+// dependencies.
+func (w *Workflow) Prepare() ([]InsertManyParams, error) {
+	if len(w.tasks) < 1 {
+		return nil, errors.New("workflow has no tasks")
+	}
+
+	tasksByName := make(map[string]*workflowTaskParams, len(w.tasks))
+	for _, task := range w.tasks {
+		if task.name == "" {
+			return nil, errors.New("workflow task name cannot be empty")
+		}
+		if _, ok := tasksByName[task.name]; ok {
+			return nil, fmt.Errorf("workflow contains duplicate task name %q", task.name)
+		}
+		tasksByName[task.name] = task
+	}
+
+	for _, task := range w.tasks {
+		for _, dep := range task.deps {
+			if _, ok := tasksByName[dep]; !ok {
+				return nil, fmt.Errorf("workflow task %q depends on unknown task %q", task.name, dep)
+			}
+		}
+	}
+
+	// Sort tasks topologically, which detects cycles along the way. Order is
+	// otherwise kept stable with the order in which tasks were added.
+	var (
+		sortedTasks = make([]*workflowTaskParams, 0, len(w.tasks))
+		sorted      = make(map[string]struct{}, len(w.tasks))
+	)
+	for len(sortedTasks) < len(w.tasks) {
+		numSorted := len(sortedTasks)
+
+		for _, task := range w.tasks {
+			if _, ok := sorted[task.name]; ok {
+				continue
+			}
+
+			if !slices.ContainsFunc(task.deps, func(dep string) bool { _, ok := sorted[dep]; return !ok }) {
+				sortedTasks = append(sortedTasks, task)
+				sorted[task.name] = struct{}{}
+			}
+		}
+
+		if len(sortedTasks) == numSorted {
+			return nil, errors.New("workflow contains a dependency cycle")
+		}
+	}
+
+	params := make([]InsertManyParams, len(sortedTasks))
+	for i, task := range sortedTasks {
+		var insertOpts InsertOpts
+		if task.insertOpts != nil {
+			insertOpts = *task.insertOpts
+		}
+
+		metadata, err := w.taskMetadata(task, insertOpts.Metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		insertOpts.Metadata = metadata
+		insertOpts.pending = len(task.deps) > 0
+
+		params[i] = InsertManyParams{Args: task.args, InsertOpts: &insertOpts}
+	}
+
+	return params, nil
+}
+
+// taskMetadata merges a task's workflow information into any metadata that was
+// specified in its insert opts.
+func (w *Workflow) taskMetadata(task *workflowTaskParams, metadata []byte) ([]byte, error) {
+	metadataMap := make(map[string]any)
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling metadata of workflow task %q: %w", task.name, err)
+		}
+	}
+
+	deps := task.deps
+	if deps == nil {
+		deps = []string{}
+	}
+
+	metadataMap[metadataKeyWorkflowDeps] = deps
+	metadataMap[metadataKeyWorkflowID] = w.id
+	metadataMap[metadataKeyWorkflowTask] = task.name
+	if w.name != "" {
+		metadataMap[metadataKeyWorkflowName] = w.name
+	}
+
+	return json.Marshal(metadataMap)
+}
+
+// WorkflowTask is a single task of a workflow that's been inserted, along with
+// its job.
+type WorkflowTask struct {
+	// Deps are the names of other tasks in the workflow that this task depends
+	// on.
+	Deps []string
+
+	// Job is the task's job row.
+	Job *rivertype.JobRow
+
+	// Name is the task's name, unique within its workflow.
+	Name string
+}
+
+// Output unmarshals the output recorded by the task's job with RecordOutput
+// into v. It returns an error if the job hasn't recorded an output.
+func (t *WorkflowTask) Output(v any) error {
+	output := t.Job.Output()
+	if output == nil {
+		return fmt.Errorf("workflow task %q has no recorded output", t.Name)
+	}
+
+	return json.Unmarshal(output, v)
+}
+
+// WorkflowResult is the result of looking up a workflow, containing all of its
+// tasks and their jobs in their current state.
+type WorkflowResult struct {
+	// ID is the workflow's ID.
+	ID string
+
+	// Name is the workflow's name, if it was given one.
+	Name string
+
+	// Tasks are the workflow's tasks, ordered by job ID.
+	Tasks []*WorkflowTask
+}
+
+// CountByState returns the number of the workflow's tasks whose jobs are in
+// each state, which can be used to gauge the workflow's progress.
+func (r *WorkflowResult) CountByState() map[rivertype.JobState]int {
+	counts := make(map[rivertype.JobState]int)
+	for _, task := range r.Tasks {
+		counts[task.Job.State]++
+	}
+	return counts
+}
+
+// Finalized returns true if all of the workflow's tasks have been finalized,
+// i.e. are cancelled, completed, or discarded.
+func (r *WorkflowResult) Finalized() bool {
+	for _, task := range r.Tasks {
+		if task.Job.FinalizedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Task returns the workflow task with the given name, or nil if the workflow
+// doesn't contain one.
+func (r *WorkflowResult) Task(name string) *WorkflowTask {
+	for _, task := range r.Tasks {
+		if task.Name == name {
+			return task
+		}
+	}
+	return nil
+}
+
+// workflowMetadata is the subset of a job's metadata that identifies it as
+// part of a workflow.
+type workflowMetadata struct {
+	Deps []string `json:"workflow_deps"`
+	ID   string   `json:"workflow_id"`
+	Name string   `json:"workflow_name"`
+	Task string   `json:"workflow_task"`
+}
+
+func workflowMetadataFromJob(job *rivertype.JobRow) (*workflowMetadata, error) {
+	var metadata workflowMetadata
+	if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("error unmarshaling metadata: %w", err)
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,167 @@
+package river
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestWorkflow(t *testing.T) {
+	t.Parallel()
+
+	unmarshalMetadata := func(t *testing.T, params InsertManyParams) map[string]any {
+		t.Helper()
+
+		var metadata map[string]any
+		require.NoError(t, json.Unmarshal(params.InsertOpts.Metadata, &metadata))
+		return metadata
+	}
+
+	t.Run("GeneratesID", func(t *testing.T) {
+		t.Parallel()
+
+		require.NotEmpty(t, NewWorkflow(nil).ID())
+		require.NotEqual(t, NewWorkflow(nil).ID(), NewWorkflow(nil).ID())
+		require.Equal(t, "my_id", NewWorkflow(&WorkflowOpts{ID: "my_id"}).ID())
+	})
+
+	t.Run("Prepare", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(&WorkflowOpts{ID: "my_id", Name: "my_workflow"})
+		workflow.Add("d", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"b", "c"}})
+		workflow.Add("b", noOpArgs{}, &InsertOpts{Metadata: []byte(`{"foo": "bar"}`), Queue: "other_queue"}, &WorkflowTaskOpts{Deps: []string{"a"}})
+		workflow.Add("c", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+		workflow.Add("a", noOpArgs{}, nil, nil)
+
+		params, err := workflow.Prepare()
+		require.NoError(t, err)
+		require.Len(t, params, 4)
+
+		// Sorted so that every task comes after its dependencies.
+		require.Equal(t, map[string]any{
+			"workflow_deps": []any{},
+			"workflow_id":   "my_id",
+			"workflow_name": "my_workflow",
+			"workflow_task": "a",
+		}, unmarshalMetadata(t, params[0]))
+		require.False(t, params[0].InsertOpts.pending)
+
+		require.Equal(t, map[string]any{
+			"foo":           "bar",
+			"workflow_deps": []any{"a"},
+			"workflow_id":   "my_id",
+			"workflow_name": "my_workflow",
+			"workflow_task": "b",
+		}, unmarshalMetadata(t, params[1]))
+		require.Equal(t, "other_queue", params[1].InsertOpts.Queue)
+		require.True(t, params[1].InsertOpts.pending)
+
+		require.Equal(t, "c", unmarshalMetadata(t, params[2])["workflow_task"])
+		require.True(t, params[2].InsertOpts.pending)
+
+		require.Equal(t, "d", unmarshalMetadata(t, params[3])["workflow_task"])
+		require.Equal(t, []any{"b", "c"}, unmarshalMetadata(t, params[3])["workflow_deps"])
+		require.True(t, params[3].InsertOpts.pending)
+	})
+
+	t.Run("PrepareDuplicateTaskName", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+
+		_, err := workflow.Prepare()
+		require.EqualError(t, err, `workflow contains duplicate task name "a"`)
+	})
+
+	t.Run("PrepareEmptyTaskName", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("", noOpArgs{}, nil, nil)
+
+		_, err := workflow.Prepare()
+		require.EqualError(t, err, "workflow task name cannot be empty")
+	})
+
+	t.Run("PrepareCycle", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, nil)
+		workflow.Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a", "c"}})
+		workflow.Add("c", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"b"}})
+
+		_, err := workflow.Prepare()
+		require.EqualError(t, err, "workflow contains a dependency cycle")
+	})
+
+	t.Run("PrepareInvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, &InsertOpts{Metadata: []byte("not json")}, nil)
+
+		_, err := workflow.Prepare()
+		require.ErrorContains(t, err, `error unmarshaling metadata of workflow task "a"`)
+	})
+
+	t.Run("PrepareNoTasks", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).Prepare()
+		require.EqualError(t, err, "workflow has no tasks")
+	})
+
+	t.Run("PrepareSelfDependency", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+
+		_, err := workflow.Prepare()
+		require.EqualError(t, err, "workflow contains a dependency cycle")
+	})
+
+	t.Run("PrepareUnknownDependency", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"b"}})
+
+		_, err := workflow.Prepare()
+		require.EqualError(t, err, `workflow task "a" depends on unknown task "b"`)
+	})
+}
+
+func TestWorkflowResult(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	res := &WorkflowResult{
+		Tasks: []*WorkflowTask{
+			{Name: "a", Job: &rivertype.JobRow{FinalizedAt: &now, State: rivertype.JobStateCompleted}},
+			{Name: "b", Job: &rivertype.JobRow{State: rivertype.JobStateRunning}},
+			{Name: "c", Job: &rivertype.JobRow{State: rivertype.JobStatePending}},
+		},
+	}
+
+	require.Equal(t, map[rivertype.JobState]int{
+		rivertype.JobStateCompleted: 1,
+		rivertype.JobStatePending:   1,
+		rivertype.JobStateRunning:   1,
+	}, res.CountByState())
+	require.False(t, res.Finalized())
+	require.Equal(t, "b", res.Task("b").Name)
+	require.Nil(t, res.Task("d"))
+
+	res.Tasks = res.Tasks[0:1]
+	require.True(t, res.Finalized())
+}